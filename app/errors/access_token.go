@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrInvalidAccessToken  = errors.New("invalid access token")
+)
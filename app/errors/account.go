@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrDeletionPending  = errors.New("account deletion already scheduled")
+	ErrDeletionNotFound = errors.New("no pending account deletion")
+)
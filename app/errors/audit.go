@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	ErrAuditEventNotFound      = errors.New("audit event not found")
+	ErrAuditCheckpointNotFound = errors.New("audit checkpoint not found")
+	// ErrAuditSeqTaken means another writer appended to the audit chain
+	// first.
+	ErrAuditSeqTaken = errors.New("audit sequence number already taken")
+)
@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrBreakGlassNotFound    = errors.New("no active break-glass access")
+	ErrJustificationRequired = errors.New("a justification is required")
+)
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrDelegationNotFound = errors.New("delegation not found")
+	ErrInvalidDelegation  = errors.New("invalid delegation")
+	ErrDelegationExists   = errors.New("delegation already exists")
+)
@@ -4,5 +4,7 @@ import "errors"
 
 var (
 	ErrDocumentNotFound = errors.New("document not found")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrEmptySearch      = errors.New("search query is empty")
 	ErrInternal         = errors.New("internal server error")
 )
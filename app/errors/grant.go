@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrGrantNotFound = errors.New("grant not found")
+	ErrInvalidGrant  = errors.New("invalid grant")
+)
@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	ErrMFAUnavailable          = errors.New("two-factor authentication is not configured")
+	ErrMFAAlreadyEnabled       = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnabled           = errors.New("two-factor authentication not enabled")
+	ErrMFAEnrollmentNotStarted = errors.New("two-factor enrollment not started")
+	ErrInvalidMFACode          = errors.New("invalid two-factor code")
+)
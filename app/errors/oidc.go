@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	ErrOIDCProviderNotFound    = errors.New("identity provider not found")
+	ErrOIDCProviderUnavailable = errors.New("identity provider unavailable")
+	ErrInvalidOIDCLogin        = errors.New("invalid or expired sign-in attempt")
+	ErrInvalidIDToken          = errors.New("invalid ID token")
+	ErrIdentityEmailUnverified = errors.New("identity provider did not verify the email address")
+)
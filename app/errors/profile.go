@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileInUse    = errors.New("profile still has documents or files")
+	ErrDefaultProfile  = errors.New("default profile cannot be deleted or given another relationship")
+)
@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	ErrShareLinksUnavailable = errors.New("share links are not configured")
+	ErrShareLinkNotFound     = errors.New("share link not found")
+	ErrShareLinkExpired      = errors.New("share link expired or revoked")
+	ErrInvalidShareLink      = errors.New("invalid share link")
+	ErrInvalidPIN            = errors.New("invalid PIN")
+)
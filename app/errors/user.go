@@ -1,6 +1,9 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrUserNotFound        = errors.New("user not found")
@@ -8,4 +11,26 @@ var (
 	ErrInvalidCredentials  = errors.New("invalid credentials")
 	ErrInvalidToken        = errors.New("invalid token")
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrEmailNotVerified    = errors.New("email not verified")
+	ErrAccountLocked       = errors.New("account temporarily locked")
+	ErrTooManyAttempts     = errors.New("too many login attempts")
+	ErrInvalidRole         = errors.New("invalid role")
 )
+
+// LoginThrottledError is returned while login attempts are refused. Err is
+// ErrAccountLocked or ErrTooManyAttempts.
+type LoginThrottledError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return e.Err
+}
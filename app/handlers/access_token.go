@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+)
+
+func (h *UserHandler) CreateAccessToken(w http.ResponseWriter, r *http.Request) {
+	var req models.PersonalAccessTokenCreation
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	token, err := h.userService.CreateAccessToken(r.Context(), context.GetUserID(r), req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidAccessToken) {
+			http.Error(w, "unknown scope or expiry in the past", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to create access token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *UserHandler) ListAccessTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.userService.ListAccessTokens(r.Context(), context.GetUserID(r))
+	if err != nil {
+		http.Error(w, "failed to get access tokens", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *UserHandler) RevokeAccessToken(w http.ResponseWriter, r *http.Request) {
+	err := h.userService.RevokeAccessToken(r.Context(), context.GetUserID(r), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAccessTokenNotFound) {
+			http.Error(w, "access token not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to revoke access token", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
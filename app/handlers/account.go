@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/account"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+)
+
+type AccountHandler struct {
+	accountService *account.Service
+	userService    *user.UserService
+}
+
+func NewAccountHandler(accountService *account.Service, userService *user.UserService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		userService:    userService,
+	}
+}
+
+func (h *AccountHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	var req models.AccountDeletionRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(r.Context(), context.GetUserID(r), req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidCredentials):
+			http.Error(w, "invalid password", http.StatusForbidden)
+		case errors.Is(err, apperrors.ErrDeletionPending):
+			http.Error(w, "account deletion already scheduled", http.StatusConflict)
+		default:
+			http.Error(w, "failed to schedule account deletion", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(deletion); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *AccountHandler) ConfirmDeletion(w http.ResponseWriter, r *http.Request) {
+	var req models.AccountDeletionConfirmation
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	deletion, err := h.accountService.ConfirmDeletion(r.Context(), context.GetUserID(r), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidToken):
+			http.Error(w, "invalid or expired confirmation link", http.StatusBadRequest)
+		case errors.Is(err, apperrors.ErrDeletionPending):
+			http.Error(w, "account deletion already scheduled", http.StatusConflict)
+		default:
+			http.Error(w, "failed to confirm account deletion", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(deletion); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *AccountHandler) GetDeletion(w http.ResponseWriter, r *http.Request) {
+	deletion, err := h.accountService.GetDeletion(r.Context(), context.GetUserID(r))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrDeletionNotFound) {
+			http.Error(w, "no account deletion scheduled", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get account deletion", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deletion); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *AccountHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	if err := h.accountService.CancelDeletion(r.Context(), context.GetUserID(r)); err != nil {
+		if errors.Is(err, apperrors.ErrDeletionNotFound) {
+			http.Error(w, "no pending account deletion", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to cancel account deletion", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AccountHandler) RegisterRoutes(router *mux.Router) {
+	me := router.PathPrefix("/users/me").Subrouter()
+	me.Use(middleware.Auth(h.userService), middleware.RequireSession())
+
+	me.HandleFunc("", h.RequestDeletion).Methods(http.MethodDelete)
+	me.HandleFunc("/deletion", h.GetDeletion).Methods(http.MethodGet)
+	me.HandleFunc("/deletion", h.CancelDeletion).Methods(http.MethodDelete)
+	me.HandleFunc("/deletion/confirm", h.ConfirmDeletion).Methods(http.MethodPost)
+}
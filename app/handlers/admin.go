@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userService.GetUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeProfileError(w, err, "failed to get user")
+		return
+	}
+
+	writeProfile(w, user)
+}
+
+func (h *UserHandler) SetRoles(w http.ResponseWriter, r *http.Request) {
+	var assignment models.RoleAssignment
+	if !decodeRequest(w, r, &assignment) {
+		return
+	}
+
+	user, err := h.userService.SetRoles(r.Context(), mux.Vars(r)["id"], assignment.Roles)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidRole) {
+			http.Error(w, "invalid role", http.StatusBadRequest)
+			return
+		}
+		writeProfileError(w, err, "failed to set roles")
+		return
+	}
+
+	writeProfile(w, user)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+)
+
+// AuditHandler serves the audit log: all of it to administrators, and to
+// every user what others did with their data.
+type AuditHandler struct {
+	auditService *audit.Service
+	userService  *user.UserService
+}
+
+func NewAuditHandler(auditService *audit.Service, userService *user.UserService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		userService:  userService,
+	}
+}
+
+func (h *AuditHandler) Query(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.ActorID = query.Get("actor_id")
+	filter.OwnerID = query.Get("owner_id")
+	filter.ResourceID = query.Get("resource_id")
+	filter.RequestID = query.Get("request_id")
+	if value := query.Get("break_glass"); value != "" {
+		breakGlass, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "break_glass must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.BreakGlass = breakGlass
+	}
+
+	events, err := h.auditService.Query(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "failed to query audit log", http.StatusInternalServerError)
+		return
+	}
+
+	writeAuditEvents(w, events)
+}
+
+func (h *AuditHandler) AccessToMyData(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.auditService.AccessToUserData(r.Context(), context.GetUserID(r), filter)
+	if err != nil {
+		http.Error(w, "failed to query audit log", http.StatusInternalServerError)
+		return
+	}
+
+	writeAuditEvents(w, events)
+}
+
+// parseAuditFilter reads the query parameters both audit views accept.
+func parseAuditFilter(query url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Action:  query.Get("action"),
+		Outcome: query.Get("outcome"),
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return models.AuditFilter{}, fmt.Errorf("%s must be an RFC 3339 time", name)
+		}
+		*target = &t
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return models.AuditFilter{}, fmt.Errorf("limit must be a positive number")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+func writeAuditEvents(w http.ResponseWriter, events []*models.AuditEvent) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *AuditHandler) RegisterRoutes(router *mux.Router) {
+	own := router.PathPrefix("/audit").Subrouter()
+	own.Use(middleware.Auth(h.userService), middleware.RequireSession())
+	own.HandleFunc("", h.AccessToMyData).Methods(http.MethodGet)
+
+	admin := router.PathPrefix("/admin/audit").Subrouter()
+	admin.Use(middleware.Auth(h.userService), middleware.RequireSession(), middleware.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.Query).Methods(http.MethodGet)
+}
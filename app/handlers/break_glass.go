@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/document"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+)
+
+// BreakGlassHandler lets clinicians open emergency access to a patient's
+// documents. Single documents are then read through the document routes.
+type BreakGlassHandler struct {
+	documentService *document.Service
+	userService     *user.UserService
+}
+
+func NewBreakGlassHandler(documentService *document.Service, userService *user.UserService) *BreakGlassHandler {
+	return &BreakGlassHandler{
+		documentService: documentService,
+		userService:     userService,
+	}
+}
+
+func (h *BreakGlassHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req models.BreakGlassRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	principal, _ := context.GetPrincipal(r)
+	access, err := h.documentService.BreakGlass(r.Context(), principal, req)
+	if err != nil {
+		writeBreakGlassError(w, err, "failed to open emergency access")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(access); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *BreakGlassHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	principal, _ := context.GetPrincipal(r)
+	filter, err := parseDocumentFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.documentService.GetPatientDocuments(r.Context(), principal, mux.Vars(r)["patientId"], filter)
+	if err != nil {
+		writeBreakGlassError(w, err, "failed to get documents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeBreakGlassError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrJustificationRequired):
+		http.Error(w, "a justification of at least 20 characters is required", http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrInvalidCursor):
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "patient not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrProfileNotFound):
+		http.Error(w, "profile not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrAccessDenied):
+		http.Error(w, "access denied", http.StatusForbidden)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *BreakGlassHandler) RegisterRoutes(router *mux.Router) {
+	breakGlass := router.PathPrefix("/break-glass").Subrouter()
+	breakGlass.Use(middleware.Auth(h.userService), middleware.RequireSession(), middleware.RequireRole(models.RoleClinician))
+
+	breakGlass.HandleFunc("", h.Open).Methods(http.MethodPost)
+	breakGlass.HandleFunc("/{patientId}/documents", h.ListDocuments).Methods(http.MethodGet)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+)
+
+// DelegationHandler manages the delegations between accounts. Acting on
+// behalf of a grantor happens on the record routes through the X-Act-As
+// header.
+type DelegationHandler struct {
+	delegationService *delegation.Service
+	userService       *user.UserService
+}
+
+func NewDelegationHandler(delegationService *delegation.Service, userService *user.UserService) *DelegationHandler {
+	return &DelegationHandler{
+		delegationService: delegationService,
+		userService:       userService,
+	}
+}
+
+func (h *DelegationHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	var req models.DelegationInvite
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	principal, _ := context.GetPrincipal(r)
+	invited, err := h.delegationService.Invite(r.Context(), principal, req)
+	if err != nil {
+		writeDelegationError(w, err, "failed to invite delegate")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(invited); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *DelegationHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var req models.DelegationAcceptance
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	accepted, err := h.delegationService.Accept(r.Context(), context.GetUserID(r), req.Token)
+	if err != nil {
+		writeDelegationError(w, err, "failed to accept delegation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(accepted); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *DelegationHandler) ListGranted(w http.ResponseWriter, r *http.Request) {
+	delegations, err := h.delegationService.ListGranted(r.Context(), context.GetUserID(r))
+	if err != nil {
+		writeDelegationError(w, err, "failed to get delegations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(delegations); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *DelegationHandler) ListReceived(w http.ResponseWriter, r *http.Request) {
+	delegations, err := h.delegationService.ListReceived(r.Context(), context.GetUserID(r))
+	if err != nil {
+		writeDelegationError(w, err, "failed to get delegations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(delegations); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *DelegationHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := h.delegationService.Revoke(r.Context(), mux.Vars(r)["id"], context.GetUserID(r)); err != nil {
+		writeDelegationError(w, err, "failed to revoke delegation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDelegationError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrDelegationNotFound):
+		http.Error(w, "delegation not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrInvalidDelegation):
+		http.Error(w, "delegations cannot go to yourself or end in the past", http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrDelegationExists):
+		http.Error(w, "a delegation from this user already exists", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrAccessDenied):
+		http.Error(w, "access denied", http.StatusForbidden)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *DelegationHandler) RegisterRoutes(router *mux.Router) {
+	delegations := router.PathPrefix("/delegations").Subrouter()
+	delegations.Use(middleware.Auth(h.userService), middleware.RequireSession())
+
+	delegations.HandleFunc("", h.Invite).Methods(http.MethodPost)
+	delegations.HandleFunc("", h.ListGranted).Methods(http.MethodGet)
+	delegations.HandleFunc("/received", h.ListReceived).Methods(http.MethodGet)
+	delegations.HandleFunc("/accept", h.Accept).Methods(http.MethodPost)
+	delegations.HandleFunc("/{id}", h.Revoke).Methods(http.MethodDelete)
+}
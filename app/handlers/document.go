@@ -3,7 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 
@@ -11,32 +17,40 @@ import (
 	"github.com/gruzdev-dev/meddoc/app/models"
 	"github.com/gruzdev-dev/meddoc/app/server/context"
 	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
 	"github.com/gruzdev-dev/meddoc/app/services/document"
 	"github.com/gruzdev-dev/meddoc/app/services/user"
 )
 
+const maxSearchQueryLength = 500
+
 type DocumentHandler struct {
-	documentService *document.Service
-	userService     *user.UserService
+	documentService   *document.Service
+	userService       *user.UserService
+	delegationService *delegation.Service
 }
 
-func NewDocumentHandler(documentService *document.Service, userService *user.UserService) *DocumentHandler {
+func NewDocumentHandler(documentService *document.Service, userService *user.UserService, delegationService *delegation.Service) *DocumentHandler {
 	return &DocumentHandler{
-		documentService: documentService,
-		userService:     userService,
+		documentService:   documentService,
+		userService:       userService,
+		delegationService: delegationService,
 	}
 }
 
 func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request) {
 	var doc models.DocumentCreation
-	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeRequest(w, r, &doc) {
 		return
 	}
 
-	userID := context.GetUserID(r)
-	createdDoc, err := h.documentService.CreateDocument(r.Context(), doc, userID)
+	principal, _ := context.GetPrincipal(r)
+	createdDoc, err := h.documentService.CreateDocument(r.Context(), doc, principal)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrProfileNotFound) {
+			http.Error(w, "profile not found", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to create document", http.StatusInternalServerError)
 		return
 	}
@@ -52,9 +66,9 @@ func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request)
 func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	userID := context.GetUserID(r)
+	principal, _ := context.GetPrincipal(r)
 
-	doc, err := h.documentService.GetDocument(r.Context(), id, userID)
+	doc, err := h.documentService.GetDocument(r.Context(), id, principal)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrAccessDenied) {
 			http.Error(w, "access denied", http.StatusForbidden)
@@ -72,26 +86,142 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *DocumentHandler) GetUserDocuments(w http.ResponseWriter, r *http.Request) {
-	userID := context.GetUserID(r)
-	docs, err := h.documentService.GetUserDocuments(r.Context(), userID)
+	principal, _ := context.GetPrincipal(r)
+	filter, err := parseDocumentFilter(r.URL.Query())
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.documentService.GetUserDocuments(r.Context(), principal, filter)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrProfileNotFound) {
+			http.Error(w, "profile not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, apperrors.ErrInvalidCursor) {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to get documents", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(docs); err != nil {
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *DocumentHandler) SearchDocuments(w http.ResponseWriter, r *http.Request) {
+	principal, _ := context.GetPrincipal(r)
+	query := r.URL.Query()
+	search := models.DocumentSearch{
+		Query:     query.Get("q"),
+		ProfileID: query.Get("profile_id"),
+	}
+	if utf8.RuneCountInString(search.Query) > maxSearchQueryLength {
+		http.Error(w, fmt.Sprintf("q must be at most %d characters", maxSearchQueryLength), http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		search.Limit = limit
+	}
+
+	results, err := h.documentService.SearchDocuments(r.Context(), principal, search)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrEmptySearch) {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, apperrors.ErrProfileNotFound) {
+			http.Error(w, "profile not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to search documents", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
+// parseDocumentFilter reads the query parameters of document listings. sort
+// names a field, prefixed with a minus for descending order.
+func parseDocumentFilter(query url.Values) (models.DocumentFilter, error) {
+	filter := models.DocumentFilter{
+		ProfileID: query.Get("profile_id"),
+		Category:  query.Get("category"),
+		Cursor:    query.Get("cursor"),
+	}
+
+	if value := query.Get("sort"); value != "" {
+		field, descending := strings.CutPrefix(value, "-")
+		switch field {
+		case models.DocumentSortDate, models.DocumentSortPriority, models.DocumentSortCreatedAt, models.DocumentSortUpdatedAt:
+		default:
+			return models.DocumentFilter{}, fmt.Errorf("sort must be one of date, priority, created_at, updated_at, optionally prefixed with -")
+		}
+		filter.Sort = field
+		filter.Descending = descending
+	}
+
+	for name, target := range map[string]**int{"min_priority": &filter.MinPriority, "max_priority": &filter.MaxPriority} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return models.DocumentFilter{}, fmt.Errorf("%s must be a non-negative number", name)
+		}
+		*target = &n
+	}
+
+	for name, target := range map[string]*string{"date_from": &filter.DateFrom, "date_to": &filter.DateTo} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return models.DocumentFilter{}, fmt.Errorf("%s must be a date in YYYY-MM-DD format", name)
+		}
+		*target = value
+	}
+
+	if value := query.Get("has_file"); value != "" {
+		hasFile, err := strconv.ParseBool(value)
+		if err != nil {
+			return models.DocumentFilter{}, fmt.Errorf("has_file must be true or false")
+		}
+		filter.HasFile = &hasFile
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return models.DocumentFilter{}, fmt.Errorf("limit must be a positive number")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
 func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	userID := context.GetUserID(r)
+	principal, _ := context.GetPrincipal(r)
 
-	if err := h.documentService.DeleteDocument(r.Context(), id, userID); err != nil {
+	if err := h.documentService.DeleteDocument(r.Context(), id, principal); err != nil {
 		if errors.Is(err, apperrors.ErrAccessDenied) {
 			http.Error(w, "access denied", http.StatusForbidden)
 			return
@@ -110,15 +240,14 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request)
 func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	userID := context.GetUserID(r)
+	principal, _ := context.GetPrincipal(r)
 
 	var update models.DocumentUpdate
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeRequest(w, r, &update) {
 		return
 	}
 
-	updatedDoc, err := h.documentService.UpdateDocument(r.Context(), id, update, userID)
+	updatedDoc, err := h.documentService.UpdateDocument(r.Context(), id, update, principal)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrAccessDenied) {
 			http.Error(w, "access denied", http.StatusForbidden)
@@ -128,6 +257,10 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "document not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, apperrors.ErrProfileNotFound) {
+			http.Error(w, "profile not found", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to update document", http.StatusInternalServerError)
 		return
 	}
@@ -140,13 +273,65 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+func (h *DocumentHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	principal, _ := context.GetPrincipal(r)
+
+	var data models.CommentCreation
+	if !decodeRequest(w, r, &data) {
+		return
+	}
+
+	comment, err := h.documentService.AddComment(r.Context(), mux.Vars(r)["id"], data, principal)
+	if err != nil {
+		writeCommentError(w, err, "failed to add comment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(comment); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *DocumentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	principal, _ := context.GetPrincipal(r)
+
+	comments, err := h.documentService.GetComments(r.Context(), mux.Vars(r)["id"], principal)
+	if err != nil {
+		writeCommentError(w, err, "failed to get comments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(comments); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeCommentError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrAccessDenied):
+		http.Error(w, "access denied", http.StatusForbidden)
+	case errors.Is(err, apperrors.ErrDocumentNotFound):
+		http.Error(w, "document not found", http.StatusNotFound)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
 func (h *DocumentHandler) RegisterRoutes(router *mux.Router) {
 	docs := router.PathPrefix("/documents").Subrouter()
-	docs.Use(middleware.Auth(h.userService))
+	docs.Use(middleware.Auth(h.userService), middleware.ActAs(h.delegationService), middleware.RequireScope(models.ScopeDocumentsRead, models.ScopeDocumentsWrite))
 
 	docs.HandleFunc("", h.CreateDocument).Methods(http.MethodPost)
 	docs.HandleFunc("", h.GetUserDocuments).Methods(http.MethodGet)
+	docs.HandleFunc("/search", h.SearchDocuments).Methods(http.MethodGet)
 	docs.HandleFunc("/{id}", h.GetDocument).Methods(http.MethodGet)
 	docs.HandleFunc("/{id}", h.UpdateDocument).Methods(http.MethodPatch)
 	docs.HandleFunc("/{id}", h.DeleteDocument).Methods(http.MethodDelete)
+	docs.HandleFunc("/{id}/comments", h.AddComment).Methods(http.MethodPost)
+	docs.HandleFunc("/{id}/comments", h.GetComments).Methods(http.MethodGet)
 }
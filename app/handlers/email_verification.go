@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var verification models.EmailVerification
+	if !decodeRequest(w, r, &verification) {
+		return
+	}
+
+	if err := h.userService.VerifyEmail(r.Context(), verification.Token); err != nil {
+		http.Error(w, "invalid or expired verification token", http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	var resend models.ResendVerification
+	if !decodeRequest(w, r, &resend) {
+		return
+	}
+
+	if err := h.userService.ResendVerificationEmail(r.Context(), resend.Email); err != nil {
+		logger.Error("failed to resend verification email", err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
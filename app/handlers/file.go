@@ -14,6 +14,7 @@ import (
 	"github.com/gruzdev-dev/meddoc/app/models"
 	"github.com/gruzdev-dev/meddoc/app/server/context"
 	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
 	"github.com/gruzdev-dev/meddoc/app/services/file"
 	"github.com/gruzdev-dev/meddoc/app/services/user"
 	"github.com/gruzdev-dev/meddoc/pkg/logger"
@@ -34,14 +35,16 @@ var allowedExts = map[string]struct{}{
 }
 
 type FileHandler struct {
-	fileService *file.Service
-	userService *user.UserService
+	fileService       *file.Service
+	userService       *user.UserService
+	delegationService *delegation.Service
 }
 
-func NewFileHandler(fileService *file.Service, userService *user.UserService) *FileHandler {
+func NewFileHandler(fileService *file.Service, userService *user.UserService, delegationService *delegation.Service) *FileHandler {
 	return &FileHandler{
-		fileService: fileService,
-		userService: userService,
+		fileService:       fileService,
+		userService:       userService,
+		delegationService: delegationService,
 	}
 }
 
@@ -84,16 +87,21 @@ func (h *FileHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 
 	multiReader := io.MultiReader(bytes.NewReader(buffer), fileReader)
 
-	userID := context.GetUserID(r)
+	principal, _ := context.GetPrincipal(r)
 	logger.Info("uploading file", map[string]any{
-		"user_id": userID,
+		"user_id": principal.UserID,
 	})
 
 	metadata := models.FileMetadata{
-		Size: header.Size,
+		Size:      header.Size,
+		ProfileID: r.FormValue("profile_id"),
 	}
 
-	uploadedFile, err := h.fileService.UploadFile(r.Context(), multiReader, metadata, userID)
+	uploadedFile, err := h.fileService.UploadFile(r.Context(), multiReader, metadata, principal)
+	if err == errors.ErrProfileNotFound {
+		http.Error(w, "profile not found", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		logger.Error("failed to upload file", err)
 		http.Error(w, "failed to upload file", http.StatusInternalServerError)
@@ -122,9 +130,9 @@ func isValidExtension(ext string) bool {
 func (h *FileHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	userID := context.GetUserID(r)
+	principal, _ := context.GetPrincipal(r)
 
-	reader, err := h.fileService.DownloadFile(r.Context(), id, userID)
+	reader, err := h.fileService.DownloadFile(r.Context(), id, principal)
 	if err != nil {
 		logger.Error("failed to download file", err)
 		switch err {
@@ -152,7 +160,7 @@ func (h *FileHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
 
 func (h *FileHandler) RegisterRoutes(router *mux.Router) {
 	files := router.PathPrefix("/files").Subrouter()
-	files.Use(middleware.Auth(h.userService))
+	files.Use(middleware.Auth(h.userService), middleware.ActAs(h.delegationService), middleware.RequireScope(models.ScopeFilesRead, models.ScopeFilesWrite))
 
 	files.HandleFunc("/upload", h.UploadFile).Methods(http.MethodPost)
 	files.HandleFunc("/{id}", h.DownloadFile).Methods(http.MethodGet)
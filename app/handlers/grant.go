@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
+	"github.com/gruzdev-dev/meddoc/app/services/grant"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+)
+
+type GrantHandler struct {
+	grantService      *grant.Service
+	userService       *user.UserService
+	delegationService *delegation.Service
+}
+
+func NewGrantHandler(grantService *grant.Service, userService *user.UserService, delegationService *delegation.Service) *GrantHandler {
+	return &GrantHandler{
+		grantService:      grantService,
+		userService:       userService,
+		delegationService: delegationService,
+	}
+}
+
+func (h *GrantHandler) ShareDocument(w http.ResponseWriter, r *http.Request) {
+	var req models.GrantCreation
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	principal, _ := context.GetPrincipal(r)
+	grant, err := h.grantService.ShareDocument(r.Context(), mux.Vars(r)["id"], req, principal)
+	if err != nil {
+		writeGrantError(w, err, "failed to share document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(grant); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *GrantHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	principal, _ := context.GetPrincipal(r)
+	grants, err := h.grantService.ListGrants(r.Context(), mux.Vars(r)["id"], principal)
+	if err != nil {
+		writeGrantError(w, err, "failed to get grants")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(grants); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *GrantHandler) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	principal, _ := context.GetPrincipal(r)
+
+	if err := h.grantService.RevokeGrant(r.Context(), vars["id"], vars["grantId"], principal); err != nil {
+		writeGrantError(w, err, "failed to revoke grant")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *GrantHandler) SharedWithMe(w http.ResponseWriter, r *http.Request) {
+	shared, err := h.grantService.SharedWithUser(r.Context(), context.GetUserID(r))
+	if err != nil {
+		http.Error(w, "failed to get shared documents", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(shared); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeGrantError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, apperrors.ErrDocumentNotFound):
+		http.Error(w, "document not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrAccessDenied):
+		http.Error(w, "access denied", http.StatusForbidden)
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "user not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrGrantNotFound):
+		http.Error(w, "grant not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrInvalidGrant):
+		http.Error(w, "documents cannot be shared with their owner or until a time in the past", http.StatusBadRequest)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+func (h *GrantHandler) RegisterRoutes(router *mux.Router) {
+	requireAuth := middleware.Auth(h.userService)
+	actAs := middleware.ActAs(h.delegationService)
+	requireScope := middleware.RequireScope(models.ScopeDocumentsRead, models.ScopeDocumentsWrite)
+
+	grants := router.PathPrefix("/documents/{id}/grants").Subrouter()
+	grants.Use(requireAuth, actAs, requireScope)
+	grants.HandleFunc("", h.ShareDocument).Methods(http.MethodPost)
+	grants.HandleFunc("", h.ListGrants).Methods(http.MethodGet)
+	grants.HandleFunc("/{grantId}", h.RevokeGrant).Methods(http.MethodDelete)
+
+	shared := router.PathPrefix("/shared/documents").Subrouter()
+	shared.Use(requireAuth, actAs, requireScope)
+	shared.HandleFunc("", h.SharedWithMe).Methods(http.MethodGet)
+}
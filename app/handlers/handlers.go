@@ -2,22 +2,45 @@ package handlers
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/gruzdev-dev/meddoc/app/services/account"
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
 	"github.com/gruzdev-dev/meddoc/app/services/document"
 	"github.com/gruzdev-dev/meddoc/app/services/file"
+	"github.com/gruzdev-dev/meddoc/app/services/grant"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc"
+	"github.com/gruzdev-dev/meddoc/app/services/profile"
+	"github.com/gruzdev-dev/meddoc/app/services/sharelink"
 	"github.com/gruzdev-dev/meddoc/app/services/user"
 )
 
 type Handlers struct {
-	userHandler     *UserHandler
-	documentHandler *DocumentHandler
-	fileHandler     *FileHandler
+	userHandler       *UserHandler
+	documentHandler   *DocumentHandler
+	fileHandler       *FileHandler
+	accountHandler    *AccountHandler
+	grantHandler      *GrantHandler
+	shareLinkHandler  *ShareLinkHandler
+	oidcHandler       *OIDCHandler
+	profileHandler    *PatientProfileHandler
+	delegationHandler *DelegationHandler
+	auditHandler      *AuditHandler
+	breakGlassHandler *BreakGlassHandler
 }
 
-func NewHandlers(userService *user.UserService, documentService *document.Service, fileService *file.Service) *Handlers {
+func NewHandlers(userService *user.UserService, documentService *document.Service, fileService *file.Service, accountService *account.Service, grantService *grant.Service, shareLinkService *sharelink.Service, oidcService *oidc.Service, profileService *profile.Service, delegationService *delegation.Service, auditService *audit.Service) *Handlers {
 	return &Handlers{
-		userHandler:     NewUserHandler(userService),
-		documentHandler: NewDocumentHandler(documentService, userService),
-		fileHandler:     NewFileHandler(fileService, userService),
+		userHandler:       NewUserHandler(userService),
+		documentHandler:   NewDocumentHandler(documentService, userService, delegationService),
+		fileHandler:       NewFileHandler(fileService, userService, delegationService),
+		accountHandler:    NewAccountHandler(accountService, userService),
+		grantHandler:      NewGrantHandler(grantService, userService, delegationService),
+		shareLinkHandler:  NewShareLinkHandler(shareLinkService, userService),
+		oidcHandler:       NewOIDCHandler(oidcService),
+		profileHandler:    NewPatientProfileHandler(profileService, userService, delegationService),
+		delegationHandler: NewDelegationHandler(delegationService, userService),
+		auditHandler:      NewAuditHandler(auditService, userService),
+		breakGlassHandler: NewBreakGlassHandler(documentService, userService),
 	}
 }
 
@@ -25,4 +48,12 @@ func (h *Handlers) RegisterRoutes(router *mux.Router) {
 	h.userHandler.RegisterRoutes(router)
 	h.documentHandler.RegisterRoutes(router)
 	h.fileHandler.RegisterRoutes(router)
+	h.accountHandler.RegisterRoutes(router)
+	h.grantHandler.RegisterRoutes(router)
+	h.shareLinkHandler.RegisterRoutes(router)
+	h.oidcHandler.RegisterRoutes(router)
+	h.profileHandler.RegisterRoutes(router)
+	h.delegationHandler.RegisterRoutes(router)
+	h.auditHandler.RegisterRoutes(router)
+	h.breakGlassHandler.RegisterRoutes(router)
 }
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+)
+
+func (h *UserHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var verification models.MFAVerification
+	if !decodeRequest(w, r, &verification) {
+		return
+	}
+
+	tokens, err := h.userService.VerifyMFA(r.Context(), verification.MFAToken, verification.Code, clientInfo(r))
+	if err != nil {
+		if writeLoginThrottled(w, err) {
+			return
+		}
+		http.Error(w, "invalid two-factor code", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *UserHandler) StartTOTPEnrollment(w http.ResponseWriter, r *http.Request) {
+	userID := context.GetUserID(r)
+	enrollment, err := h.userService.StartTOTPEnrollment(r.Context(), userID)
+	if err != nil {
+		writeMFAError(w, err, "failed to start two-factor enrollment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(enrollment); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *UserHandler) ConfirmTOTPEnrollment(w http.ResponseWriter, r *http.Request) {
+	var code models.MFACode
+	if !decodeRequest(w, r, &code) {
+		return
+	}
+
+	userID := context.GetUserID(r)
+	codes, err := h.userService.ConfirmTOTPEnrollment(r.Context(), userID, code.Code)
+	if err != nil {
+		writeMFAError(w, err, "failed to confirm two-factor enrollment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(codes); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *UserHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var code models.MFACode
+	if !decodeRequest(w, r, &code) {
+		return
+	}
+
+	userID := context.GetUserID(r)
+	if err := h.userService.DisableTOTP(r.Context(), userID, code.Code); err != nil {
+		writeMFAError(w, err, "failed to disable two-factor authentication")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var code models.MFACode
+	if !decodeRequest(w, r, &code) {
+		return
+	}
+
+	userID := context.GetUserID(r)
+	codes, err := h.userService.RegenerateRecoveryCodes(r.Context(), userID, code.Code)
+	if err != nil {
+		writeMFAError(w, err, "failed to regenerate recovery codes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(codes); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeMFAError(w http.ResponseWriter, err error, fallback string) {
+	if writeLoginThrottled(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidMFACode):
+		http.Error(w, "invalid two-factor code", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrMFAAlreadyEnabled):
+		http.Error(w, "two-factor authentication already enabled", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrMFANotEnabled):
+		http.Error(w, "two-factor authentication not enabled", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrMFAEnrollmentNotStarted):
+		http.Error(w, "two-factor enrollment not started", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrMFAUnavailable):
+		http.Error(w, "two-factor authentication is not available", http.StatusNotImplemented)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
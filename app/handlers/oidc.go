@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// oidcStateCookie ties a sign-in to the browser that started it, so a
+// callback URL from someone else's sign-in cannot log a victim into the
+// attacker's account.
+const oidcStateCookie = "meddoc_oidc_state"
+
+type OIDCHandler struct {
+	oidcService *oidc.Service
+}
+
+func NewOIDCHandler(oidcService *oidc.Service) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+
+	start, err := h.oidcService.StartLogin(r.Context(), provider)
+	if err != nil {
+		writeOIDCError(w, err, provider, "failed to start sign-in")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    start.State,
+		Path:     strings.TrimSuffix(r.URL.Path, "/login"),
+		Expires:  start.ExpiresAt,
+		MaxAge:   int(time.Until(start.ExpiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Lax lets the cookie through on the provider's top-level redirect
+		// back to us.
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, start.AuthURL, http.StatusFound)
+}
+
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		http.Error(w, "sign-in was not completed at the identity provider", http.StatusUnauthorized)
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		http.Error(w, "invalid or expired sign-in attempt", http.StatusUnauthorized)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     strings.TrimSuffix(r.URL.Path, "/callback"),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	result, err := h.oidcService.CompleteLogin(r.Context(), provider, state, query.Get("code"), clientInfo(r))
+	if err != nil {
+		writeOIDCError(w, err, provider, "failed to sign in")
+		return
+	}
+
+	var response any = result.Tokens
+	if result.Challenge != nil {
+		response = result.Challenge
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeOIDCError(w http.ResponseWriter, err error, provider, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrOIDCProviderNotFound):
+		http.Error(w, "identity provider not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrInvalidOIDCLogin), errors.Is(err, apperrors.ErrInvalidIDToken):
+		http.Error(w, "invalid or expired sign-in attempt", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrIdentityEmailUnverified):
+		http.Error(w, "identity provider did not verify the email address", http.StatusForbidden)
+	case errors.Is(err, apperrors.ErrUserExists):
+		http.Error(w, "account is linked to another user", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrOIDCProviderUnavailable):
+		logger.Error("identity provider unavailable", err, "provider", provider)
+		http.Error(w, "identity provider unavailable", http.StatusBadGateway)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *OIDCHandler) RegisterRoutes(router *mux.Router) {
+	auth := router.PathPrefix("/auth/oidc/{provider}").Subrouter()
+	auth.HandleFunc("/login", h.Login).Methods(http.MethodGet)
+	auth.HandleFunc("/callback", h.Callback).Methods(http.MethodGet)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var forgot models.ForgotPassword
+	if !decodeRequest(w, r, &forgot) {
+		return
+	}
+
+	// The response never depends on whether the account exists or the mail
+	// could be sent, so failures are only logged.
+	if err := h.userService.ForgotPassword(r.Context(), forgot.Email); err != nil {
+		logger.Error("failed to send password reset", err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var reset models.PasswordReset
+	if !decodeRequest(w, r, &reset) {
+		return
+	}
+
+	if err := h.userService.ResetPassword(r.Context(), reset.Token, reset.Password); err != nil {
+		if errors.Is(err, apperrors.ErrInvalidResetToken) {
+			http.Error(w, "invalid or expired reset token", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to reset password", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
+	"github.com/gruzdev-dev/meddoc/app/services/profile"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+)
+
+// PatientProfileHandler manages the patient profiles of an account, as
+// opposed to the account holder's own user profile.
+type PatientProfileHandler struct {
+	profileService    *profile.Service
+	userService       *user.UserService
+	delegationService *delegation.Service
+}
+
+func NewPatientProfileHandler(profileService *profile.Service, userService *user.UserService, delegationService *delegation.Service) *PatientProfileHandler {
+	return &PatientProfileHandler{
+		profileService:    profileService,
+		userService:       userService,
+		delegationService: delegationService,
+	}
+}
+
+func (h *PatientProfileHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	var req models.ProfileCreation
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	created, err := h.profileService.CreateProfile(r.Context(), context.GetUserID(r), req)
+	if err != nil {
+		writePatientProfileError(w, err, "failed to create profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *PatientProfileHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.profileService.ListProfiles(r.Context(), context.GetUserID(r))
+	if err != nil {
+		writePatientProfileError(w, err, "failed to get profiles")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(profiles); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *PatientProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	found, err := h.profileService.GetProfile(r.Context(), mux.Vars(r)["id"], context.GetUserID(r))
+	if err != nil {
+		writePatientProfileError(w, err, "failed to get profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(found); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *PatientProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var update models.ProfileUpdate
+	if !decodeRequest(w, r, &update) {
+		return
+	}
+
+	updated, err := h.profileService.UpdateProfile(r.Context(), mux.Vars(r)["id"], context.GetUserID(r), update)
+	if err != nil {
+		writePatientProfileError(w, err, "failed to update profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *PatientProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	if err := h.profileService.DeleteProfile(r.Context(), mux.Vars(r)["id"], context.GetUserID(r)); err != nil {
+		writePatientProfileError(w, err, "failed to delete profile")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePatientProfileError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrProfileNotFound):
+		http.Error(w, "profile not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrDefaultProfile):
+		http.Error(w, "the default profile cannot be deleted or given another relationship", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrProfileInUse):
+		http.Error(w, "profile still has documents or files", http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *PatientProfileHandler) RegisterRoutes(router *mux.Router) {
+	profiles := router.PathPrefix("/profiles").Subrouter()
+	profiles.Use(middleware.Auth(h.userService), middleware.ActAs(h.delegationService), middleware.RequireScope(models.ScopeDocumentsRead, models.ScopeDocumentsWrite))
+
+	profiles.HandleFunc("", h.CreateProfile).Methods(http.MethodPost)
+	profiles.HandleFunc("", h.ListProfiles).Methods(http.MethodGet)
+	profiles.HandleFunc("/{id}", h.GetProfile).Methods(http.MethodGet)
+	profiles.HandleFunc("/{id}", h.UpdateProfile).Methods(http.MethodPatch)
+	profiles.HandleFunc("/{id}", h.DeleteProfile).Methods(http.MethodDelete)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+)
+
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userService.GetProfile(r.Context(), context.GetUserID(r))
+	if err != nil {
+		writeProfileError(w, err, "failed to get profile")
+		return
+	}
+
+	writeProfile(w, user)
+}
+
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var update models.UserUpdate
+	if !decodeRequest(w, r, &update) {
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), context.GetUserID(r), update)
+	if err != nil {
+		writeProfileError(w, err, "failed to update profile")
+		return
+	}
+
+	writeProfile(w, user)
+}
+
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	principal, ok := context.GetPrincipal(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var change models.PasswordChange
+	if !decodeRequest(w, r, &change) {
+		return
+	}
+
+	tokens, err := h.userService.ChangePassword(r.Context(), principal, change.CurrentPassword, change.NewPassword, clientInfo(r))
+	if err != nil {
+		writeProfileError(w, err, "failed to change password")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *UserHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	var change models.EmailChange
+	if !decodeRequest(w, r, &change) {
+		return
+	}
+
+	err := h.userService.RequestEmailChange(r.Context(), context.GetUserID(r), change.NewEmail, change.Password)
+	if err != nil {
+		writeProfileError(w, err, "failed to request email change")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func writeProfile(w http.ResponseWriter, user *models.User) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeProfileError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "user not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrInvalidCredentials):
+		http.Error(w, "invalid password", http.StatusForbidden)
+	case errors.Is(err, apperrors.ErrUserExists):
+		http.Error(w, "email already in use", http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
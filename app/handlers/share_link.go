@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/sharelink"
+	"github.com/gruzdev-dev/meddoc/app/services/user"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// sharePINHeader carries the PIN of a protected share link, so it does not
+// end up in URLs and access logs.
+const sharePINHeader = "X-Share-PIN"
+
+type ShareLinkHandler struct {
+	shareLinkService *sharelink.Service
+	userService      *user.UserService
+}
+
+func NewShareLinkHandler(shareLinkService *sharelink.Service, userService *user.UserService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareLinkService: shareLinkService,
+		userService:      userService,
+	}
+}
+
+func (h *ShareLinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	var req models.ShareLinkCreation
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	principal, _ := context.GetPrincipal(r)
+	link, err := h.shareLinkService.CreateLink(r.Context(), req, principal)
+	if err != nil {
+		writeShareLinkError(w, err, "failed to create share link")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(link); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *ShareLinkHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.shareLinkService.ListLinks(r.Context(), context.GetUserID(r))
+	if err != nil {
+		http.Error(w, "failed to get share links", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(links); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *ShareLinkHandler) RevokeLink(w http.ResponseWriter, r *http.Request) {
+	if err := h.shareLinkService.RevokeLink(r.Context(), mux.Vars(r)["id"], context.GetUserID(r)); err != nil {
+		writeShareLinkError(w, err, "failed to revoke share link")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShareLinkHandler) OpenLink(w http.ResponseWriter, r *http.Request) {
+	bundle, err := h.shareLinkService.Open(r.Context(), mux.Vars(r)["token"], r.Header.Get(sharePINHeader))
+	if err != nil {
+		writeShareLinkError(w, err, "failed to open share link")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(bundle); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *ShareLinkHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reader, err := h.shareLinkService.OpenFile(r.Context(), vars["token"], r.Header.Get(sharePINHeader), vars["fileId"])
+	if err != nil {
+		writeShareLinkError(w, err, "failed to download file")
+		return
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logger.Error("failed to close reader", err)
+		}
+	}()
+
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := io.Copy(w, reader); err != nil {
+		logger.Error("failed to send file", err)
+		http.Error(w, "failed to send file", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeShareLinkError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrShareLinkNotFound):
+		http.Error(w, "share link not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrShareLinkExpired):
+		http.Error(w, "share link expired or revoked", http.StatusGone)
+	case errors.Is(err, apperrors.ErrInvalidPIN):
+		http.Error(w, "invalid PIN", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrInvalidShareLink):
+		http.Error(w, "share links must expire in the future and within the allowed maximum", http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrDocumentNotFound):
+		http.Error(w, "document not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrNotFound):
+		http.Error(w, "file not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrAccessDenied):
+		http.Error(w, "access denied", http.StatusForbidden)
+	case errors.Is(err, apperrors.ErrShareLinksUnavailable):
+		http.Error(w, "share links are not available", http.StatusNotImplemented)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *ShareLinkHandler) RegisterRoutes(router *mux.Router) {
+	links := router.PathPrefix("/share-links").Subrouter()
+	links.Use(middleware.Auth(h.userService), middleware.RequireSession())
+	links.HandleFunc("", h.CreateLink).Methods(http.MethodPost)
+	links.HandleFunc("", h.ListLinks).Methods(http.MethodGet)
+	links.HandleFunc("/{id}", h.RevokeLink).Methods(http.MethodDelete)
+
+	router.HandleFunc("/share/{token}", h.OpenLink).Methods(http.MethodGet)
+	router.HandleFunc("/share/{token}/files/{fileId}", h.DownloadFile).Methods(http.MethodGet)
+}
@@ -3,11 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/server/middleware"
 	userservice "github.com/gruzdev-dev/meddoc/app/services/user"
 )
 
@@ -23,8 +27,7 @@ func NewUserHandler(userService *userservice.UserService) *UserHandler {
 
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var reg models.UserRegistration
-	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeRequest(w, r, &reg) {
 		return
 	}
 
@@ -48,32 +51,64 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var login models.UserLogin
-	if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeRequest(w, r, &login) {
 		return
 	}
 
-	tokens, err := h.userService.Login(r.Context(), login.Email, login.Password)
+	result, err := h.userService.Login(r.Context(), login.Email, login.Password, clientInfo(r))
 	if err != nil {
+		if writeLoginThrottled(w, err) {
+			return
+		}
+		if errors.Is(err, apperrors.ErrEmailNotVerified) {
+			http.Error(w, "email not verified", http.StatusForbidden)
+			return
+		}
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
+	var response any = result.Tokens
+	if result.Challenge != nil {
+		response = result.Challenge
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
+// writeLoginThrottled answers a refused login or two-factor attempt with 423
+// or 429 and a Retry-After header, and reports whether err was one.
+func writeLoginThrottled(w http.ResponseWriter, err error) bool {
+	var throttled *apperrors.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	if errors.Is(err, apperrors.ErrAccountLocked) {
+		http.Error(w, "account temporarily locked", http.StatusLocked)
+		return true
+	}
+	http.Error(w, "too many login attempts", http.StatusTooManyRequests)
+	return true
+}
+
+// clientInfo describes the client a sign-in or token refresh came from.
+func clientInfo(r *http.Request) models.ClientInfo {
+	return models.ClientInfo{IP: context.GetClientIP(r), UserAgent: r.UserAgent()}
+}
+
 func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	var refresh models.RefreshToken
-	if err := json.NewDecoder(r.Body).Decode(&refresh); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeRequest(w, r, &refresh) {
 		return
 	}
 
-	tokens, err := h.userService.RefreshToken(r.Context(), refresh.RefreshToken)
+	tokens, err := h.userService.RefreshToken(r.Context(), refresh.RefreshToken, clientInfo(r))
 	if err != nil {
 		http.Error(w, "invalid refresh token", http.StatusUnauthorized)
 		return
@@ -86,9 +121,81 @@ func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := context.GetPrincipal(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.userService.Logout(r.Context(), principal); err != nil {
+		http.Error(w, "failed to logout", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	principal, ok := context.GetPrincipal(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.userService.LogoutAll(r.Context(), principal); err != nil {
+		http.Error(w, "failed to logout", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(h.userService.JWKS()); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *UserHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/.well-known/jwks.json", h.JWKS).Methods(http.MethodGet)
+
 	auth := router.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/register", h.Register).Methods(http.MethodPost)
 	auth.HandleFunc("/login", h.Login).Methods(http.MethodPost)
 	auth.HandleFunc("/refresh", h.Refresh).Methods(http.MethodPost)
+	auth.HandleFunc("/password/forgot", h.ForgotPassword).Methods(http.MethodPost)
+	auth.HandleFunc("/password/reset", h.ResetPassword).Methods(http.MethodPost)
+	auth.HandleFunc("/email/verify", h.VerifyEmail).Methods(http.MethodPost)
+	auth.HandleFunc("/email/resend", h.ResendVerificationEmail).Methods(http.MethodPost)
+
+	requireAuth := middleware.Auth(h.userService)
+	requireSession := middleware.RequireSession()
+	auth.Handle("/logout", requireAuth(requireSession(http.HandlerFunc(h.Logout)))).Methods(http.MethodPost)
+	auth.Handle("/logout-all", requireAuth(requireSession(http.HandlerFunc(h.LogoutAll)))).Methods(http.MethodPost)
+	auth.HandleFunc("/mfa/verify", h.VerifyMFA).Methods(http.MethodPost)
+
+	me := router.PathPrefix("/users/me").Subrouter()
+	me.Use(requireAuth, requireSession)
+	me.HandleFunc("", h.GetProfile).Methods(http.MethodGet)
+	me.HandleFunc("", h.UpdateProfile).Methods(http.MethodPatch)
+	me.HandleFunc("/password", h.ChangePassword).Methods(http.MethodPost)
+	me.HandleFunc("/email", h.RequestEmailChange).Methods(http.MethodPost)
+	me.HandleFunc("/mfa/totp", h.StartTOTPEnrollment).Methods(http.MethodPost)
+	me.HandleFunc("/mfa/totp/confirm", h.ConfirmTOTPEnrollment).Methods(http.MethodPost)
+	me.HandleFunc("/mfa/totp", h.DisableTOTP).Methods(http.MethodDelete)
+	me.HandleFunc("/mfa/recovery-codes", h.RegenerateRecoveryCodes).Methods(http.MethodPost)
+	me.HandleFunc("/tokens", h.CreateAccessToken).Methods(http.MethodPost)
+	me.HandleFunc("/tokens", h.ListAccessTokens).Methods(http.MethodGet)
+	me.HandleFunc("/tokens/{id}", h.RevokeAccessToken).Methods(http.MethodDelete)
+	me.HandleFunc("/sessions", h.ListSessions).Methods(http.MethodGet)
+	me.HandleFunc("/sessions/{id}", h.RevokeSession).Methods(http.MethodDelete)
+
+	admin := router.PathPrefix("/admin/users").Subrouter()
+	admin.Use(requireAuth, requireSession, middleware.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/{id}", h.GetUser).Methods(http.MethodGet)
+	admin.HandleFunc("/{id}/roles", h.SetRoles).Methods(http.MethodPut)
 }
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+	"github.com/gruzdev-dev/meddoc/pkg/validation"
+)
+
+type validationErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields validation.Errors `json:"fields"`
+}
+
+// decodeRequest decodes the JSON body into v and checks its binding tags. On
+// failure it writes the error response and returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	var fields validation.Errors
+	if errors.As(validation.Struct(v), &fields) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		if err := json.NewEncoder(w).Encode(validationErrorResponse{
+			Error:  "validation failed",
+			Fields: fields,
+		}); err != nil {
+			logger.Error("failed to encode response", err)
+		}
+		return false
+	}
+
+	return true
+}
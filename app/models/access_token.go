@@ -0,0 +1,58 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	ScopeDocumentsRead  = "documents:read"
+	ScopeDocumentsWrite = "documents:write"
+	ScopeFilesRead      = "files:read"
+	ScopeFilesWrite     = "files:write"
+)
+
+func IsValidScope(scope string) bool {
+	switch scope {
+	case ScopeDocumentsRead, ScopeDocumentsWrite, ScopeFilesRead, ScopeFilesWrite:
+		return true
+	}
+	return false
+}
+
+// PersonalAccessToken is a long-lived credential for scripts. Only the hash
+// of the token is stored; the token itself is returned once, on creation.
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Token      string     `json:"token,omitempty"`
+}
+
+func (t *PersonalAccessToken) Active(now time.Time) bool {
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+type PersonalAccessTokenCreation struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// ExpiresAt is optional; tokens without it stay valid until revoked.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// IsAccessToken reports whether the principal authenticated with a personal
+// access token rather than a session.
+func (p Principal) IsAccessToken() bool {
+	return p.AccessTokenID != ""
+}
+
+// HasScope reports whether the principal may act within scope. Sessions are
+// not limited to scopes.
+func (p Principal) HasScope(scope string) bool {
+	return !p.IsAccessToken() || slices.Contains(p.Scopes, scope)
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// AccountDeletionUnconfirmed deletions wait for the emailed link of an
+	// account without a password to be opened. They are never carried out.
+	AccountDeletionUnconfirmed = "unconfirmed"
+	AccountDeletionPending     = "pending"
+	AccountDeletionInProgress  = "in_progress"
+	AccountDeletionCompleted   = "completed"
+	AccountDeletionCancelled   = "cancelled"
+)
+
+// AccountDeletion tracks the erasure of a user's account and data. Once
+// completed it is kept, without the email address, as the deletion receipt.
+type AccountDeletion struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// Email receives the receipt. It is cleared when the deletion completes.
+	Email        string    `json:"-"`
+	Status       string    `json:"status"`
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	// LeaseUntil is when a worker that started the deletion is presumed
+	// dead, so another one may resume it.
+	LeaseUntil time.Time `json:"-"`
+	// TokenHash is the hash of the confirmation token mailed for an
+	// unconfirmed deletion, which can be confirmed until ConfirmBy.
+	TokenHash        string     `json:"-"`
+	ConfirmBy        time.Time  `json:"-"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	DocumentsDeleted int64      `json:"documents_deleted"`
+	FilesDeleted     int64      `json:"files_deleted"`
+}
+
+// AccountDeletionRequest confirms a deletion with the current password.
+// Accounts without a password leave it empty and confirm by email instead.
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+type AccountDeletionConfirmation struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Audited actions. The part before the dot names the kind of resource.
+const (
+	AuditDocumentCreate  = "document.create"
+	AuditDocumentRead    = "document.read"
+	AuditDocumentList    = "document.list"
+	AuditDocumentSearch  = "document.search"
+	AuditDocumentUpdate  = "document.update"
+	AuditDocumentDelete  = "document.delete"
+	AuditDocumentComment = "document.comment"
+	AuditBreakGlass      = "document.break_glass"
+	AuditFileUpload      = "file.upload"
+	AuditFileDownload    = "file.download"
+	AuditFileDelete      = "file.delete"
+	AuditLogin           = "auth.login"
+	AuditLogout          = "auth.logout"
+	AuditLogoutAll       = "auth.logout_all"
+	AuditPasswordChange  = "auth.password_change"
+	AuditPasswordReset   = "auth.password_reset"
+)
+
+const (
+	AuditSuccess = "success"
+	// AuditDenied means the actor was not allowed to do what they tried,
+	// including failed sign-ins.
+	AuditDenied = "denied"
+	// AuditFailure means the operation could not be carried out, for
+	// example because the resource does not exist.
+	AuditFailure = "failure"
+)
+
+// AuditEvent records one operation on medical data or one step of signing
+// in. Events are only ever added, never changed.
+type AuditEvent struct {
+	ID string `json:"id"`
+	// Seq numbers events in the order they were added, starting at 1.
+	Seq  int64     `json:"seq"`
+	Time time.Time `json:"time"`
+	// ActorID is the user who acted: the delegate for delegated requests,
+	// and empty for sign-ins that failed, access through share links and
+	// operations the system carried out on its own.
+	ActorID string `json:"actor_id,omitempty"`
+	// OwnerID is the user whose data or account was acted on.
+	OwnerID    string `json:"owner_id,omitempty"`
+	Action     string `json:"action"`
+	ResourceID string `json:"resource_id,omitempty"`
+	Outcome    string `json:"outcome"`
+	RequestID  string `json:"request_id,omitempty"`
+	IP         string `json:"ip,omitempty"`
+	// BreakGlassID is set when the actor got in through emergency access.
+	BreakGlassID string `json:"break_glass_id,omitempty"`
+	// PrevHash is the Hash of the event before this one, empty for the
+	// first event. Hash covers PrevHash and every other field except ID, so
+	// changing or removing an event breaks the chain after it.
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash"`
+}
+
+// AuditCheckpoint is a signed statement of the audit chain's head at some
+// point in time. It proves events up to Seq existed with these contents even
+// to someone who could rewrite the whole chain.
+type AuditCheckpoint struct {
+	ID   string    `json:"id"`
+	Seq  int64     `json:"seq"`
+	Hash string    `json:"hash"`
+	Time time.Time `json:"time"`
+	// KeyID names the key ring key Signature was made with.
+	KeyID     string `json:"key_id"`
+	Signature string `json:"signature"`
+}
+
+// AuditFilter narrows down audit events. Zero fields match every event.
+type AuditFilter struct {
+	ActorID    string
+	OwnerID    string
+	Action     string
+	ResourceID string
+	Outcome    string
+	RequestID  string
+	// ExcludeActorID leaves out the events of one actor, such as an
+	// owner's own operations on their data.
+	ExcludeActorID string
+	// BreakGlass keeps only events of emergency access.
+	BreakGlass bool
+	// From is inclusive and To exclusive.
+	From  *time.Time
+	To    *time.Time
+	Limit int
+}
@@ -5,12 +5,21 @@ import (
 )
 
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email" binding:"required,email"`
-	Name      string    `json:"name" binding:"required"`
-	Password  string    `json:"-" binding:"required,min=8"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       string `json:"id"`
+	Email    string `json:"email" binding:"required,email"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"-" binding:"required,min=8"`
+	// EmailVerified is set once the user opened the verification link sent
+	// to Email.
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles"`
+	// TokensValidAfter invalidates every token issued before it.
+	TokensValidAfter time.Time   `json:"-"`
+	MFA              MFASettings `json:"-"`
+	// Identities are the OpenID Connect accounts the user can sign in with.
+	Identities []ExternalIdentity `json:"-"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
 }
 
 type UserRegistration struct {
@@ -19,11 +28,33 @@ type UserRegistration struct {
 	Name     string `json:"name" binding:"required"`
 }
 
+type UserUpdate struct {
+	Name *string `json:"name,omitempty" binding:"min=1,max=100"`
+}
+
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type EmailChange struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
 type UserLogin struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
 
+type EmailVerification struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerification struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -33,3 +64,28 @@ type TokenPair struct {
 type RefreshToken struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+// ClientInfo describes where a request came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Principal is the identity an authenticated request acts with.
+type Principal struct {
+	UserID    string
+	SessionID string
+	TokenID   string
+	ExpiresAt time.Time
+	// Roles come from the access token, so role changes apply once the
+	// token is refreshed.
+	Roles []string
+	// AccessTokenID and Scopes are set for requests made with a personal
+	// access token.
+	AccessTokenID string
+	Scopes        []string
+	// ActorID is the delegate who sent the request when it acts on behalf
+	// of UserID, and Delegation the permission they were given.
+	ActorID    string
+	Delegation string
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// BreakGlassAccess lets a clinician read the documents of a patient who has
+// not shared them, for use in emergencies. It needs a written justification,
+// ends after a fixed time and the patient is told as soon as it is opened.
+type BreakGlassAccess struct {
+	ID            string    `json:"id"`
+	ClinicianID   string    `json:"clinician_id"`
+	PatientID     string    `json:"patient_id"`
+	Justification string    `json:"justification"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type BreakGlassRequest struct {
+	PatientID     string `json:"patient_id" binding:"required"`
+	Justification string `json:"justification" binding:"required,min=20,max=2000"`
+}
+
+// CanBreakGlass reports whether the principal may open or use break-glass
+// access: only clinicians signed in themselves, not acting for someone else
+// or through an access token.
+func (p Principal) CanBreakGlass() bool {
+	return slices.Contains(p.Roles, RoleClinician) && !p.IsDelegated() && !p.IsAccessToken()
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a note left on a document by its owner or by a user the
+// document was shared with at the comment level or above.
+type Comment struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id"`
+	OwnerID    string    `json:"-"`
+	AuthorID   string    `json:"author_id"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CommentCreation struct {
+	Text string `json:"text" binding:"required,max=5000"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// Delegation permissions. A read delegate can look at the grantor's records;
+// a manage delegate can also change them.
+const (
+	DelegationRead   = "read"
+	DelegationManage = "manage"
+)
+
+const (
+	DelegationPending = "pending"
+	DelegationActive  = "active"
+)
+
+// Delegation lets another user act on behalf of the grantor across their
+// whole record, for example an adult child caring for a parent. It starts as
+// an invitation mailed to Email and becomes active once the user registered
+// with that address accepts it.
+type Delegation struct {
+	ID         string `json:"id"`
+	GrantorID  string `json:"grantor_id"`
+	DelegateID string `json:"delegate_id,omitempty"`
+	Email      string `json:"email"`
+	Permission string `json:"permission"`
+	Status     string `json:"status"`
+	TokenHash  string `json:"-"`
+	// InviteExpiresAt is when a pending invitation can no longer be
+	// accepted.
+	InviteExpiresAt *time.Time `json:"invite_expires_at,omitempty"`
+	// ExpiresAt optionally ends the delegation itself.
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// Active reports whether the delegate may act for the grantor at now.
+func (d *Delegation) Active(now time.Time) bool {
+	return d.Status == DelegationActive && (d.ExpiresAt == nil || d.ExpiresAt.After(now))
+}
+
+type DelegationInvite struct {
+	Email      string     `json:"email" binding:"required,email"`
+	Permission string     `json:"permission" binding:"required,oneof=read manage"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+type DelegationAcceptance struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// IsDelegated reports whether a delegate is acting on behalf of UserID.
+func (p Principal) IsDelegated() bool {
+	return p.ActorID != ""
+}
+
+// Actor returns the user who actually made the request: the delegate for
+// delegated requests, UserID otherwise.
+func (p Principal) Actor() string {
+	if p.IsDelegated() {
+		return p.ActorID
+	}
+	return p.UserID
+}
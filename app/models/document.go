@@ -14,26 +14,104 @@ type Document struct {
 	Priority    int               `json:"priority,omitempty"`
 	Content     map[string]string `json:"content,omitempty"`
 	UserID      string            `json:"-" binding:"required"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ProfileID   string            `json:"profile_id"`
+	// CreatedBy is the user who created the document and UpdatedBy the one
+	// who last changed it. They differ from the owner when a delegate or a
+	// grantee acted.
+	CreatedBy string    `json:"created_by,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type DocumentCreation struct {
-	Title       string            `json:"title"`
-	Description string            `json:"description,omitempty"`
-	Date        string            `json:"date,omitempty"`
-	File        string            `json:"file,omitempty"`
-	Category    string            `json:"category,omitempty"`
-	Priority    int               `json:"priority,omitempty"`
-	Content     map[string]string `json:"content,omitempty"`
+	Title       string            `json:"title" binding:"required,max=255"`
+	Description string            `json:"description,omitempty" binding:"max=10000"`
+	Date        string            `json:"date,omitempty" binding:"omitempty,date"`
+	File        string            `json:"file,omitempty" binding:"max=255"`
+	Category    string            `json:"category,omitempty" binding:"max=100"`
+	Priority    int               `json:"priority,omitempty" binding:"min=0"`
+	Content     map[string]string `json:"content,omitempty" binding:"max=100"`
+	ProfileID   string            `json:"profile_id,omitempty" binding:"max=64"`
 }
 
 type DocumentUpdate struct {
-	Title       *string           `json:"title,omitempty"`
-	Description *string           `json:"description,omitempty"`
-	Date        *string           `json:"date,omitempty"`
-	File        *string           `json:"file,omitempty"`
-	Category    *string           `json:"category,omitempty"`
-	Priority    *int              `json:"priority,omitempty"`
-	Content     map[string]string `json:"content,omitempty"`
+	Title       *string           `json:"title,omitempty" binding:"min=1,max=255"`
+	Description *string           `json:"description,omitempty" binding:"max=10000"`
+	Date        *string           `json:"date,omitempty" binding:"omitempty,date"`
+	File        *string           `json:"file,omitempty" binding:"max=255"`
+	Category    *string           `json:"category,omitempty" binding:"max=100"`
+	Priority    *int              `json:"priority,omitempty" binding:"min=0"`
+	Content     map[string]string `json:"content,omitempty" binding:"max=100"`
+	ProfileID   *string           `json:"profile_id,omitempty" binding:"min=1,max=64"`
+}
+
+// Fields documents can be sorted by.
+const (
+	DocumentSortDate      = "date"
+	DocumentSortPriority  = "priority"
+	DocumentSortCreatedAt = "created_at"
+	DocumentSortUpdatedAt = "updated_at"
+)
+
+// DocumentFilter narrows down the documents of an account and picks the page
+// to return. Zero fields match every document.
+type DocumentFilter struct {
+	ProfileID string
+	Category  string
+	// MinPriority and MaxPriority bound the priority, both inclusive.
+	MinPriority *int
+	MaxPriority *int
+	// DateFrom and DateTo bound the date, both inclusive, in YYYY-MM-DD
+	// format. Documents without a date are left out once either is set.
+	DateFrom string
+	DateTo   string
+	// HasFile keeps only documents with a file attached when true and only
+	// those without one when false.
+	HasFile *bool
+
+	// Sort is one of the DocumentSort fields, created_at when empty. Ties
+	// are broken by ID, so the order is stable across pages.
+	Sort       string
+	Descending bool
+	// Cursor is the NextCursor of the previous page, empty for the first
+	// page. It is only valid with the sort it was returned for.
+	Cursor string
+	Limit  int
+}
+
+// DocumentPage is one page of the documents matching a filter.
+type DocumentPage struct {
+	Documents []*Document `json:"documents"`
+	// NextCursor fetches the page after this one; it is empty on the last
+	// page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total counts the documents matching the filter on all pages.
+	Total int64 `json:"total"`
+}
+
+// DocumentSearch is a full-text query over the documents of an account.
+// Query follows MongoDB's text search syntax: words match in any form,
+// "quoted phrases" must appear as they are and -words must not appear.
+type DocumentSearch struct {
+	Query     string
+	ProfileID string
+	Limit     int
+}
+
+// DocumentSearchResult is a document that matched a search, with Score
+// ranking it against the other results.
+type DocumentSearchResult struct {
+	Document   *Document   `json:"document"`
+	Score      float64     `json:"score"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight is an excerpt of one field of a document with the words that
+// matched the query wrapped in <mark> tags. The rest of the snippet is
+// HTML-escaped.
+type Highlight struct {
+	// Field is title, description or content.<key>.
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
 }
@@ -3,18 +3,23 @@ package models
 type FileRecord struct {
 	ID          string
 	UserID      string
+	ProfileID   string
 	StorageType string // "gridfs" or "local"
 }
 
 type FileResponse struct {
-	ID string `json:"id"`
+	ID        string `json:"id"`
+	ProfileID string `json:"profile_id"`
 }
 
 type FileMetadata struct {
 	Size int64 `json:"size"`
+	// ProfileID defaults to the default profile of the account.
+	ProfileID string `json:"profile_id,omitempty"`
 }
 
 type FileCreation struct {
 	UserID      string
+	ProfileID   string
 	StorageType string // "gridfs" or "local"
 }
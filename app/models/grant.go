@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// Grant permissions, from weakest to strongest. Each includes the ones
+// before it.
+const (
+	GrantRead    = "read"
+	GrantComment = "comment"
+	GrantEdit    = "edit"
+)
+
+var grantRanks = map[string]int{
+	GrantRead:    1,
+	GrantComment: 2,
+	GrantEdit:    3,
+}
+
+func IsValidGrantPermission(permission string) bool {
+	_, ok := grantRanks[permission]
+	return ok
+}
+
+// GrantAllows reports whether a grant with permission includes required.
+func GrantAllows(permission, required string) bool {
+	rank, ok := grantRanks[required]
+	return ok && grantRanks[permission] >= rank
+}
+
+// Grant shares one document with another registered user. There is at most
+// one grant per document and grantee.
+type Grant struct {
+	ID         string     `json:"id"`
+	DocumentID string     `json:"document_id"`
+	OwnerID    string     `json:"-"`
+	GranteeID  string     `json:"grantee_id"`
+	Permission string     `json:"permission"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (g *Grant) Active(now time.Time) bool {
+	return g.ExpiresAt == nil || g.ExpiresAt.After(now)
+}
+
+type GrantCreation struct {
+	Email      string     `json:"email" binding:"required,email"`
+	Permission string     `json:"permission" binding:"required,oneof=read comment edit"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// SharedDocument is a document another user has shared with the caller.
+type SharedDocument struct {
+	Document   *Document  `json:"document"`
+	Permission string     `json:"permission"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
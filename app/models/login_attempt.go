@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempts counts recent failed logins for one key, an account or a
+// client IP.
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
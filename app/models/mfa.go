@@ -0,0 +1,46 @@
+package models
+
+type MFASettings struct {
+	Enabled bool
+	// Secret and PendingSecret hold the TOTP secret encrypted at rest.
+	// PendingSecret is set while an enrollment waits for its first code.
+	Secret        string
+	PendingSecret string
+	// RecoveryCodes holds SHA-256 hashes of the unused recovery codes.
+	RecoveryCodes []string
+	// LastUsedStep is the TOTP time step of the last accepted code, so a code
+	// cannot be replayed within its validity window.
+	LastUsedStep int64
+}
+
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCodePNG  []byte `json:"qr_code_png"`
+}
+
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type MFAVerification struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFACode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// LoginResult holds either a token pair or, for accounts with two-factor
+// authentication, the challenge that has to be answered to get one.
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge *MFAChallenge
+}
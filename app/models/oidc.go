@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ExternalIdentity links a user to their account at an OpenID Connect
+// provider.
+type ExternalIdentity struct {
+	Provider string
+	Subject  string
+}
+
+// ExternalLogin is what an OpenID Connect provider asserted about a user that
+// signed in with it.
+type ExternalLogin struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCLogin is a sign-in that was sent to a provider and waits for it to
+// redirect back.
+type OIDCLogin struct {
+	// StateHash is the SHA-256 hash of the state parameter; the state itself
+	// is never stored.
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// OIDCLoginStart is where the client has to go to sign in with a provider.
+type OIDCLoginStart struct {
+	AuthURL   string
+	State     string
+	ExpiresAt time.Time
+}
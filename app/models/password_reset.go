@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+type PasswordResetToken struct {
+	// TokenHash is the SHA-256 hash of the token sent to the user; the token
+	// itself is never stored.
+	TokenHash string
+	UserID    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type PasswordReset struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
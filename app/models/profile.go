@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// Relationships of a profile to the account holder.
+const (
+	RelationshipSelf    = "self"
+	RelationshipChild   = "child"
+	RelationshipPartner = "partner"
+	RelationshipParent  = "parent"
+	RelationshipOther   = "other"
+)
+
+// Profile is a patient whose records an account manages, such as the
+// account holder or one of their children. Every account has one default
+// profile for the account holder, which documents and files belong to
+// unless another profile is given.
+type Profile struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"-"`
+	Name         string    `json:"name"`
+	DateOfBirth  string    `json:"date_of_birth,omitempty"`
+	Relationship string    `json:"relationship"`
+	Default      bool      `json:"default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ProfileCreation struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	DateOfBirth  string `json:"date_of_birth,omitempty" binding:"omitempty,date"`
+	Relationship string `json:"relationship" binding:"required,oneof=child partner parent other"`
+}
+
+type ProfileUpdate struct {
+	Name         *string `json:"name,omitempty" binding:"min=1,max=100"`
+	DateOfBirth  *string `json:"date_of_birth,omitempty" binding:"omitempty,date"`
+	Relationship *string `json:"relationship,omitempty" binding:"oneof=child partner parent other"`
+}
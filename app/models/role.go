@@ -0,0 +1,34 @@
+package models
+
+import "slices"
+
+const (
+	RolePatient   = "patient"
+	RoleClinician = "clinician"
+	RoleAdmin     = "admin"
+)
+
+// DefaultRoles are given to new accounts and assumed for accounts stored
+// before roles existed.
+var DefaultRoles = []string{RolePatient}
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RolePatient, RoleClinician, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+type RoleAssignment struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
+func (p Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
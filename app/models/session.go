@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a sign-in on one device. It lives as long as its refresh token
+// keeps being rotated.
+type Session struct {
+	ID      string `json:"id"`
+	UserID  string `json:"-"`
+	TokenID string `json:"-"`
+	// AccessTokenID is the ID of the newest access token issued for the
+	// session, so revoking the session can revoke it too.
+	AccessTokenID string     `json:"-"`
+	UserAgent     string     `json:"user_agent,omitempty"`
+	IP            string     `json:"ip,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"-"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SessionRotation is what changes when a session's refresh token is used.
+type SessionRotation struct {
+	TokenID       string
+	AccessTokenID string
+	ExpiresAt     time.Time
+	Client        ClientInfo
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareLink gives anyone holding its token read access to a set of
+// documents and their files, without an account.
+type ShareLink struct {
+	ID          string   `json:"id"`
+	OwnerID     string   `json:"-"`
+	DocumentIDs []string `json:"document_ids"`
+	// PINHash is the bcrypt hash of the optional PIN.
+	PINHash      string    `json:"-"`
+	PINProtected bool      `json:"pin_protected"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// MaxUses limits how often the link can be opened, and each of its files
+	// downloaded; 0 means unlimited.
+	MaxUses           int        `json:"max_uses,omitempty"`
+	Views             int        `json:"views"`
+	Downloads         int        `json:"downloads"`
+	FailedPINAttempts int        `json:"-"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	// Token and URL are derived from the ID and only filled in for the
+	// owner.
+	Token string `json:"token,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type ShareLinkCreation struct {
+	DocumentIDs []string  `json:"document_ids" binding:"required,min=1,max=50"`
+	ExpiresAt   time.Time `json:"expires_at" binding:"required"`
+	PIN         string    `json:"pin,omitempty" binding:"omitempty,min=4,max=12"`
+	MaxUses     int       `json:"max_uses,omitempty" binding:"min=0"`
+}
+
+// SharedBundle is what the holder of a share link sees.
+type SharedBundle struct {
+	ExpiresAt time.Time   `json:"expires_at"`
+	Documents []*Document `json:"documents"`
+}
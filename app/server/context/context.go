@@ -3,11 +3,19 @@ package context
 import (
 	"context"
 	"net/http"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "sub"
+const (
+	UserIDKey    contextKey = "sub"
+	PrincipalKey contextKey = "principal"
+	ClientIPKey  contextKey = "client_ip"
+	ActorIDKey   contextKey = "actor"
+	RequestIDKey contextKey = "request_id"
+)
 
 func WithUserID(r *http.Request, userID string) *http.Request {
 	ctx := context.WithValue(r.Context(), UserIDKey, userID)
@@ -20,3 +28,48 @@ func GetUserID(r *http.Request) string {
 	}
 	return ""
 }
+
+func WithPrincipal(r *http.Request, principal models.Principal) *http.Request {
+	ctx := context.WithValue(r.Context(), PrincipalKey, principal)
+	ctx = context.WithValue(ctx, UserIDKey, principal.UserID)
+	ctx = context.WithValue(ctx, ActorIDKey, principal.Actor())
+	return r.WithContext(ctx)
+}
+
+func GetPrincipal(r *http.Request) (models.Principal, bool) {
+	principal, ok := r.Context().Value(PrincipalKey).(models.Principal)
+	return principal, ok
+}
+
+// GetActorID returns the user who sent the request. It differs from
+// GetUserID when a delegate acts on behalf of another account.
+func GetActorID(r *http.Request) string {
+	if actorID, ok := r.Context().Value(ActorIDKey).(string); ok {
+		return actorID
+	}
+	return ""
+}
+
+func WithClientIP(r *http.Request, ip string) *http.Request {
+	ctx := context.WithValue(r.Context(), ClientIPKey, ip)
+	return r.WithContext(ctx)
+}
+
+func GetClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ClientIPKey).(string); ok {
+		return ip
+	}
+	return ""
+}
+
+func WithRequestID(r *http.Request, requestID string) *http.Request {
+	ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+	return r.WithContext(ctx)
+}
+
+func GetRequestID(r *http.Request) string {
+	if requestID, ok := r.Context().Value(RequestIDKey).(string); ok {
+		return requestID
+	}
+	return ""
+}
@@ -4,20 +4,29 @@ import (
 	"compress/gzip"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
 	"runtime/debug"
 	"strings"
 	"time"
 
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
 	appctx "github.com/gruzdev-dev/meddoc/app/server/context"
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
 	"github.com/gruzdev-dev/meddoc/app/services/user"
+	"github.com/gruzdev-dev/meddoc/config"
 	"github.com/gruzdev-dev/meddoc/pkg/logger"
 )
 
 const (
 	requestIDHeader = "X-Request-ID"
 	requestIDLength = 16
+	actAsHeader     = "X-Act-As"
 )
 
 type responseWriter struct {
@@ -130,7 +139,7 @@ func RequestID() func(http.Handler) http.Handler {
 				requestID = generateRequestID()
 			}
 			w.Header().Set(requestIDHeader, requestID)
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, appctx.WithRequestID(r, requestID))
 		})
 	}
 }
@@ -144,6 +153,95 @@ func generateRequestID() string {
 	return base64.URLEncoding.EncodeToString(b)
 }
 
+// ClientIP stores the client address in the request context. Proxy headers
+// are only honoured when trustProxyHeaders is set, since clients can forge
+// them. Of X-Forwarded-For only the entries appended by trustedProxies and by
+// the proxy the request came through are believed.
+func ClientIP(trustProxyHeaders bool, trustedProxies []string) func(http.Handler) http.Handler {
+	var trusted []netip.Prefix
+	for _, proxy := range trustedProxies {
+		if prefix, err := config.ParsePrefix(proxy); err == nil {
+			trusted = append(trusted, prefix)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, appctx.WithClientIP(r, clientIP(r, trustProxyHeaders, trusted)))
+		})
+	}
+}
+
+// AuditOrigin attributes the audit events of a request to its request ID
+// and client IP. It has to run after RequestID and ClientIP.
+func AuditOrigin() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := audit.WithOrigin(r.Context(), audit.Origin{
+				RequestID: appctx.GetRequestID(r),
+				IP:        appctx.GetClientIP(r),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func clientIP(r *http.Request, trustProxyHeaders bool, trustedProxies []netip.Prefix) string {
+	if trustProxyHeaders {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			if ip := forwardedClient(strings.Join(forwarded, ","), trustedProxies); ip != "" {
+				return ip
+			}
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedClient returns the rightmost X-Forwarded-For entry that is not a
+// trusted proxy. Entries to the left of it were sent by the client and may be
+// forged. When every entry is a trusted proxy the leftmost one is returned.
+func forwardedClient(forwarded string, trustedProxies []netip.Prefix) string {
+	entries := strings.Split(forwarded, ",")
+	leftmost := ""
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := strings.TrimSpace(entries[i])
+		if entry == "" {
+			continue
+		}
+		leftmost = entry
+		if !isTrustedProxy(entry, trustedProxies) {
+			return entry
+		}
+	}
+	return leftmost
+}
+
+func isTrustedProxy(entry string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		addrPort, err := netip.ParseAddrPort(entry)
+		if err != nil {
+			return false
+		}
+		addr = addrPort.Addr()
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 func Auth(userService *user.UserService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -159,13 +257,129 @@ func Auth(userService *user.UserService) func(http.Handler) http.Handler {
 				return
 			}
 
-			userID, err := userService.ValidateToken(tokenString)
+			principal, err := userService.Authenticate(r.Context(), tokenString)
 			if err != nil {
 				http.Error(w, "invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			next.ServeHTTP(w, appctx.WithUserID(r, userID))
+			next.ServeHTTP(w, appctx.WithPrincipal(r, *principal))
+		})
+	}
+}
+
+// RequireRole only lets requests through whose principal has one of roles.
+// It has to run after Auth.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := appctx.GetPrincipal(r)
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !principal.HasRole(roles...) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope limits requests made with a personal access token to tokens
+// with the read scope for GET and HEAD requests and the write scope for all
+// other methods. Sessions pass unchecked. It has to run after Auth.
+func RequireScope(read, write string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := appctx.GetPrincipal(r)
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			scope := write
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				scope = read
+			}
+			if !principal.HasScope(scope) {
+				http.Error(w, "token lacks scope "+scope, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession turns away personal access tokens and delegated requests,
+// for routes that manage the account itself. It has to run after Auth.
+func RequireSession() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := appctx.GetPrincipal(r)
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if principal.IsAccessToken() {
+				http.Error(w, "personal access tokens cannot be used here", http.StatusForbidden)
+				return
+			}
+			if r.Header.Get(actAsHeader) != "" {
+				http.Error(w, "delegates cannot act on behalf of others here", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ActAs lets a delegate act on behalf of the user named in the X-Act-As
+// header. The request continues with the grantor as its user and the
+// delegate as its actor; read delegates are limited to GET and HEAD
+// requests. Requests without the header pass unchanged. It has to run
+// after Auth.
+func ActAs(delegations *delegation.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			grantorID := r.Header.Get(actAsHeader)
+			if grantorID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, ok := appctx.GetPrincipal(r)
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			acting, err := delegations.ActAs(r.Context(), principal, grantorID)
+			if errors.Is(err, apperrors.ErrAccessDenied) {
+				http.Error(w, "no delegation from this user", http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				logger.Error("failed to check delegation", err)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			if acting.Delegation == models.DelegationRead && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				http.Error(w, "delegation only allows reading", http.StatusForbidden)
+				return
+			}
+
+			logger.Info("delegated request",
+				"user_id", acting.UserID,
+				"actor_id", acting.ActorID,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			next.ServeHTTP(w, appctx.WithPrincipal(r, acting))
 		})
 	}
 }
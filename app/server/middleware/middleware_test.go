@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.7/32")}
+
+	tests := []struct {
+		name              string
+		forwarded         []string
+		realIP            string
+		trustProxyHeaders bool
+		trustedProxies    []netip.Prefix
+		want              string
+	}{
+		{
+			name:      "headers not trusted",
+			forwarded: []string{"198.51.100.1"},
+			want:      "203.0.113.9",
+		},
+		{
+			name:              "single proxy takes the rightmost entry",
+			forwarded:         []string{"1.2.3.4, 198.51.100.1"},
+			trustProxyHeaders: true,
+			want:              "198.51.100.1",
+		},
+		{
+			name:              "skips trusted proxies",
+			forwarded:         []string{"1.2.3.4, 198.51.100.1, 10.1.2.3", "192.0.2.7"},
+			trustProxyHeaders: true,
+			trustedProxies:    trusted,
+			want:              "198.51.100.1",
+		},
+		{
+			name:              "every entry is a trusted proxy",
+			forwarded:         []string{"10.0.0.1, 10.0.0.2"},
+			trustProxyHeaders: true,
+			trustedProxies:    trusted,
+			want:              "10.0.0.1",
+		},
+		{
+			name:              "X-Real-IP without X-Forwarded-For",
+			realIP:            "198.51.100.2",
+			trustProxyHeaders: true,
+			want:              "198.51.100.2",
+		},
+		{
+			name:              "no headers",
+			trustProxyHeaders: true,
+			want:              "203.0.113.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "203.0.113.9:51234"
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			assert.Equal(t, tt.want, clientIP(r, tt.trustProxyHeaders, tt.trustedProxies))
+		})
+	}
+}
@@ -33,6 +33,8 @@ func (s *Server) Start() error {
 	router := mux.NewRouter()
 
 	router.Use(middleware.RequestID())
+	router.Use(middleware.ClientIP(s.cfg.Server.TrustProxyHeaders, s.cfg.Server.TrustedProxies))
+	router.Use(middleware.AuditOrigin())
 	router.Use(middleware.Logging())
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Compression())
@@ -0,0 +1,363 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type Dependencies struct {
+	Deletions   DeletionRepository
+	Users       Users
+	Documents   Documents
+	Grants      Grants
+	Files       Files
+	Profiles    Profiles
+	Delegations Delegations
+	ShareLinks  ShareLinks
+	Mailer      Mailer
+}
+
+type Config struct {
+	// GracePeriod is how long a requested deletion can still be cancelled.
+	GracePeriod time.Duration
+	// PollInterval is how often Run looks for deletions that are due.
+	PollInterval time.Duration
+	// Lease is how long a deletion may run before another worker resumes it.
+	Lease time.Duration
+	// ConfirmationURL is the page deletion confirmation links for accounts
+	// without a password point to; the token is added as the "token" query
+	// parameter. ConfirmationTTL is how long such a link works.
+	ConfirmationURL string
+	ConfirmationTTL time.Duration
+}
+
+// Service erases accounts together with their documents and files. Deletions
+// are scheduled first and carried out by Run once the grace period is over;
+// every step can be repeated, so a deletion interrupted halfway is resumed
+// when its lease runs out.
+type Service struct {
+	deletions       DeletionRepository
+	users           Users
+	documents       Documents
+	grants          Grants
+	files           Files
+	profiles        Profiles
+	delegations     Delegations
+	shareLinks      ShareLinks
+	mailer          Mailer
+	gracePeriod     time.Duration
+	pollInterval    time.Duration
+	lease           time.Duration
+	confirmationURL string
+	confirmationTTL time.Duration
+}
+
+func NewService(deps Dependencies, cfg Config) *Service {
+	return &Service{
+		deletions:       deps.Deletions,
+		users:           deps.Users,
+		documents:       deps.Documents,
+		grants:          deps.Grants,
+		files:           deps.Files,
+		profiles:        deps.Profiles,
+		delegations:     deps.Delegations,
+		shareLinks:      deps.ShareLinks,
+		mailer:          deps.Mailer,
+		gracePeriod:     cfg.GracePeriod,
+		pollInterval:    cfg.PollInterval,
+		lease:           cfg.Lease,
+		confirmationURL: cfg.ConfirmationURL,
+		confirmationTTL: cfg.ConfirmationTTL,
+	}
+}
+
+// RequestDeletion schedules the deletion of the user's account after the
+// password has been confirmed. Accounts without a password, created through
+// single sign-on, are mailed a link to ConfirmDeletion instead, and nothing
+// is scheduled until it is opened.
+func (s *Service) RequestDeletion(ctx context.Context, userID, password string) (*models.AccountDeletion, error) {
+	user, err := s.users.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Password != "" {
+		if _, err := s.users.VerifyPassword(ctx, userID, password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkNoActiveDeletion(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if user.Password == "" {
+		return s.requestConfirmation(ctx, user)
+	}
+
+	now := time.Now()
+	deletion := &models.AccountDeletion{
+		UserID:       user.ID,
+		Email:        user.Email,
+		Status:       models.AccountDeletionPending,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.gracePeriod),
+	}
+	if err := s.deletions.Create(ctx, deletion); err != nil {
+		return nil, err
+	}
+
+	s.sendDeletionNotice(ctx, user, deletion)
+	return deletion, nil
+}
+
+// ConfirmDeletion schedules the deletion the user requested without a
+// password, using the token from the mailed link.
+func (s *Service) ConfirmDeletion(ctx context.Context, userID, token string) (*models.AccountDeletion, error) {
+	if err := s.checkNoActiveDeletion(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deletion, err := s.deletions.Confirm(ctx, userID, hashConfirmationToken(token), now, now.Add(s.gracePeriod))
+	if errors.Is(err, apperrors.ErrDeletionNotFound) {
+		return nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendDeletionNotice(ctx, user, deletion)
+	return deletion, nil
+}
+
+func (s *Service) checkNoActiveDeletion(ctx context.Context, userID string) error {
+	_, err := s.deletions.GetActiveByUserID(ctx, userID)
+	if err == nil {
+		return apperrors.ErrDeletionPending
+	}
+	if !errors.Is(err, apperrors.ErrDeletionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// requestConfirmation mails a link that confirms the deletion of an account
+// without a password. Only someone who can read the account's email and is
+// signed in can confirm it.
+func (s *Service) requestConfirmation(ctx context.Context, user *models.User) (*models.AccountDeletion, error) {
+	token, err := newConfirmationToken()
+	if err != nil {
+		return nil, err
+	}
+	link, err := withToken(s.confirmationURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deletion := &models.AccountDeletion{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Status:      models.AccountDeletionUnconfirmed,
+		RequestedAt: now,
+		TokenHash:   hashConfirmationToken(token),
+		ConfirmBy:   now.Add(s.confirmationTTL),
+	}
+	if err := s.deletions.Create(ctx, deletion); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm the deletion of your MedDoc account",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nOpen the link below while signed in to confirm that your MedDoc account and all of its "+
+				"documents and files should be deleted. It expires in %s.\n\n%s\n\n"+
+				"If you did not ask for this, you can ignore this email and your account stays as it is.\n",
+			user.Name, s.confirmationTTL, link,
+		),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deletion, nil
+}
+
+func (s *Service) sendDeletionNotice(ctx context.Context, user *models.User, deletion *models.AccountDeletion) {
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your MedDoc account will be deleted",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nYour MedDoc account and all of its documents and files will be deleted on %s.\n\n"+
+				"Sign in and cancel the deletion before then if you want to keep your account.\n",
+			user.Name, deletion.ScheduledFor.UTC().Format(time.RFC1123),
+		),
+	}); err != nil {
+		logger.Error("failed to send account deletion notice", err, "user_id", user.ID)
+	}
+}
+
+func (s *Service) GetDeletion(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	return s.deletions.GetActiveByUserID(ctx, userID)
+}
+
+// CancelDeletion cancels a scheduled deletion. Deletions that have already
+// started cannot be cancelled.
+func (s *Service) CancelDeletion(ctx context.Context, userID string) error {
+	return s.deletions.Cancel(ctx, userID)
+}
+
+// Run processes due deletions every poll interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("failed to process account deletions", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue carries out every deletion that is due, including ones an
+// earlier worker left unfinished.
+func (s *Service) ProcessDue(ctx context.Context) error {
+	for {
+		now := time.Now()
+		deletion, err := s.deletions.ClaimDue(ctx, now, now.Add(s.lease))
+		if errors.Is(err, apperrors.ErrDeletionNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.erase(ctx, deletion); err != nil {
+			return fmt.Errorf("failed to delete account %s: %w", deletion.UserID, err)
+		}
+	}
+}
+
+// erase removes the user's files, documents, grants, profiles, delegations,
+// share links and account, in that order, and mails the receipt. Files go first because
+// they are only found through their records, which are removed one by one
+// after the stored bytes.
+func (s *Service) erase(ctx context.Context, deletion *models.AccountDeletion) error {
+	files, err := s.files.ListUserFiles(ctx, deletion.UserID)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := s.files.Remove(ctx, file); err != nil {
+			return err
+		}
+		if err := s.deletions.AddProgress(ctx, deletion.ID, 0, 1); err != nil {
+			return err
+		}
+	}
+
+	documents, err := s.documents.DeleteUserDocuments(ctx, deletion.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.deletions.AddProgress(ctx, deletion.ID, documents, 0); err != nil {
+		return err
+	}
+
+	if err := s.grants.DeleteUserGrants(ctx, deletion.UserID); err != nil {
+		return err
+	}
+
+	if err := s.profiles.DeleteUserProfiles(ctx, deletion.UserID); err != nil {
+		return err
+	}
+
+	if err := s.delegations.DeleteUserDelegations(ctx, deletion.UserID); err != nil {
+		return err
+	}
+
+	if err := s.shareLinks.DeleteUserLinks(ctx, deletion.UserID); err != nil {
+		return err
+	}
+
+	if err := s.users.DeleteUser(ctx, deletion.UserID); err != nil {
+		return err
+	}
+
+	receipt, err := s.deletions.Complete(ctx, deletion.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	logger.Info("account deleted",
+		"deletion_id", receipt.ID,
+		"user_id", receipt.UserID,
+		"documents", receipt.DocumentsDeleted,
+		"files", receipt.FilesDeleted,
+	)
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      deletion.Email,
+		Subject: "Your MedDoc account has been deleted",
+		Body: fmt.Sprintf(
+			"Your MedDoc account has been deleted.\n\n"+
+				"Receipt: %s\nRequested: %s\nCompleted: %s\nDocuments deleted: %d\nFiles deleted: %d\n",
+			receipt.ID,
+			receipt.RequestedAt.UTC().Format(time.RFC3339),
+			receipt.CompletedAt.UTC().Format(time.RFC3339),
+			receipt.DocumentsDeleted,
+			receipt.FilesDeleted,
+		),
+	}); err != nil {
+		logger.Error("failed to send account deletion receipt", err, "deletion_id", receipt.ID)
+	}
+
+	return nil
+}
+
+func newConfirmationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashConfirmationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func withToken(rawURL, token string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
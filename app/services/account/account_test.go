@@ -0,0 +1,239 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type mocks struct {
+	deletions   *MockDeletionRepository
+	users       *MockUsers
+	documents   *MockDocuments
+	grants      *MockGrants
+	files       *MockFiles
+	profiles    *MockProfiles
+	delegations *MockDelegations
+	shareLinks  *MockShareLinks
+	mailer      *MockMailer
+}
+
+func newTestService(t *testing.T) (*Service, mocks) {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		deletions:   NewMockDeletionRepository(ctrl),
+		users:       NewMockUsers(ctrl),
+		documents:   NewMockDocuments(ctrl),
+		grants:      NewMockGrants(ctrl),
+		files:       NewMockFiles(ctrl),
+		profiles:    NewMockProfiles(ctrl),
+		delegations: NewMockDelegations(ctrl),
+		shareLinks:  NewMockShareLinks(ctrl),
+		mailer:      NewMockMailer(ctrl),
+	}
+	service := NewService(Dependencies{
+		Deletions:   m.deletions,
+		Users:       m.users,
+		Documents:   m.documents,
+		Grants:      m.grants,
+		Files:       m.files,
+		Profiles:    m.profiles,
+		Delegations: m.delegations,
+		ShareLinks:  m.shareLinks,
+		Mailer:      m.mailer,
+	}, Config{
+		GracePeriod:     24 * time.Hour,
+		PollInterval:    time.Minute,
+		Lease:           time.Minute,
+		ConfirmationURL: "https://meddoc.example/confirm-deletion",
+		ConfirmationTTL: time.Hour,
+	})
+	return service, m
+}
+
+func TestService_RequestDeletion(t *testing.T) {
+	user := &models.User{ID: "user-123", Email: "test@example.com", Name: "Test User", Password: "hash"}
+
+	t.Run("schedules deletion after grace period", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.users.EXPECT().GetProfile(gomock.Any(), "user-123").Return(user, nil)
+		m.users.EXPECT().VerifyPassword(gomock.Any(), "user-123", "password123").Return(user, nil)
+		m.deletions.EXPECT().GetActiveByUserID(gomock.Any(), "user-123").Return(nil, errors.ErrDeletionNotFound)
+		m.deletions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+		deletion, err := service.RequestDeletion(context.Background(), "user-123", "password123")
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountDeletionPending, deletion.Status)
+		assert.Equal(t, "test@example.com", deletion.Email)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), deletion.ScheduledFor, time.Second)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.users.EXPECT().GetProfile(gomock.Any(), "user-123").Return(user, nil)
+		m.users.EXPECT().VerifyPassword(gomock.Any(), "user-123", "wrong").Return(nil, errors.ErrInvalidCredentials)
+
+		_, err := service.RequestDeletion(context.Background(), "user-123", "wrong")
+		assert.ErrorIs(t, err, errors.ErrInvalidCredentials)
+	})
+
+	t.Run("already scheduled", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.users.EXPECT().GetProfile(gomock.Any(), "user-123").Return(user, nil)
+		m.users.EXPECT().VerifyPassword(gomock.Any(), "user-123", "password123").Return(user, nil)
+		m.deletions.EXPECT().GetActiveByUserID(gomock.Any(), "user-123").Return(&models.AccountDeletion{ID: "deletion-1"}, nil)
+
+		_, err := service.RequestDeletion(context.Background(), "user-123", "password123")
+		assert.ErrorIs(t, err, errors.ErrDeletionPending)
+	})
+
+	t.Run("federated account confirms by email", func(t *testing.T) {
+		federated := &models.User{ID: "user-456", Email: "sso@example.com", Name: "SSO User"}
+		service, m := newTestService(t)
+		m.users.EXPECT().GetProfile(gomock.Any(), "user-456").Return(federated, nil)
+		m.deletions.EXPECT().GetActiveByUserID(gomock.Any(), "user-456").Return(nil, errors.ErrDeletionNotFound)
+		var created *models.AccountDeletion
+		m.deletions.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, deletion *models.AccountDeletion) error {
+				created = deletion
+				return nil
+			})
+		var token string
+		m.mailer.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+				assert.Equal(t, "sso@example.com", msg.To)
+				_, after, found := strings.Cut(msg.Body, "https://meddoc.example/confirm-deletion?token=")
+				require.True(t, found)
+				token = strings.Fields(after)[0]
+				return nil
+			})
+
+		deletion, err := service.RequestDeletion(context.Background(), "user-456", "")
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountDeletionUnconfirmed, deletion.Status)
+		assert.Equal(t, hashConfirmationToken(token), created.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), created.ConfirmBy, time.Second)
+		assert.Zero(t, created.ScheduledFor)
+	})
+}
+
+func TestService_ConfirmDeletion(t *testing.T) {
+	federated := &models.User{ID: "user-456", Email: "sso@example.com", Name: "SSO User"}
+
+	t.Run("schedules the deletion", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.deletions.EXPECT().GetActiveByUserID(gomock.Any(), "user-456").Return(nil, errors.ErrDeletionNotFound)
+		m.deletions.EXPECT().
+			Confirm(gomock.Any(), "user-456", hashConfirmationToken("token-1"), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, now, scheduledFor time.Time) (*models.AccountDeletion, error) {
+				assert.Equal(t, now.Add(24*time.Hour), scheduledFor)
+				return &models.AccountDeletion{ID: "deletion-1", UserID: "user-456", Status: models.AccountDeletionPending, ScheduledFor: scheduledFor}, nil
+			})
+		m.users.EXPECT().GetProfile(gomock.Any(), "user-456").Return(federated, nil)
+		m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+		deletion, err := service.ConfirmDeletion(context.Background(), "user-456", "token-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountDeletionPending, deletion.Status)
+	})
+
+	t.Run("unknown or expired token", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.deletions.EXPECT().GetActiveByUserID(gomock.Any(), "user-456").Return(nil, errors.ErrDeletionNotFound)
+		m.deletions.EXPECT().
+			Confirm(gomock.Any(), "user-456", hashConfirmationToken("wrong"), gomock.Any(), gomock.Any()).
+			Return(nil, errors.ErrDeletionNotFound)
+
+		_, err := service.ConfirmDeletion(context.Background(), "user-456", "wrong")
+		assert.ErrorIs(t, err, errors.ErrInvalidToken)
+	})
+
+	t.Run("already scheduled", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.deletions.EXPECT().GetActiveByUserID(gomock.Any(), "user-456").Return(&models.AccountDeletion{ID: "deletion-1"}, nil)
+
+		_, err := service.ConfirmDeletion(context.Background(), "user-456", "token-1")
+		assert.ErrorIs(t, err, errors.ErrDeletionPending)
+	})
+}
+
+func TestService_ProcessDue(t *testing.T) {
+	deletion := &models.AccountDeletion{
+		ID:     "deletion-1",
+		UserID: "user-123",
+		Email:  "test@example.com",
+		Status: models.AccountDeletionInProgress,
+	}
+	files := []*models.FileRecord{
+		{ID: "file-1", UserID: "user-123", StorageType: "local"},
+		{ID: "file-2", UserID: "user-123", StorageType: "gridfs"},
+	}
+
+	t.Run("erases everything and mails a receipt", func(t *testing.T) {
+		service, m := newTestService(t)
+		completedAt := time.Now()
+		gomock.InOrder(
+			m.deletions.EXPECT().ClaimDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(deletion, nil),
+			m.files.EXPECT().ListUserFiles(gomock.Any(), "user-123").Return(files, nil),
+			m.files.EXPECT().Remove(gomock.Any(), files[0]).Return(nil),
+			m.deletions.EXPECT().AddProgress(gomock.Any(), "deletion-1", int64(0), int64(1)).Return(nil),
+			m.files.EXPECT().Remove(gomock.Any(), files[1]).Return(nil),
+			m.deletions.EXPECT().AddProgress(gomock.Any(), "deletion-1", int64(0), int64(1)).Return(nil),
+			m.documents.EXPECT().DeleteUserDocuments(gomock.Any(), "user-123").Return(int64(3), nil),
+			m.deletions.EXPECT().AddProgress(gomock.Any(), "deletion-1", int64(3), int64(0)).Return(nil),
+			m.grants.EXPECT().DeleteUserGrants(gomock.Any(), "user-123").Return(nil),
+			m.profiles.EXPECT().DeleteUserProfiles(gomock.Any(), "user-123").Return(nil),
+			m.delegations.EXPECT().DeleteUserDelegations(gomock.Any(), "user-123").Return(nil),
+			m.shareLinks.EXPECT().DeleteUserLinks(gomock.Any(), "user-123").Return(nil),
+			m.users.EXPECT().DeleteUser(gomock.Any(), "user-123").Return(nil),
+			m.deletions.EXPECT().Complete(gomock.Any(), "deletion-1", gomock.Any()).Return(&models.AccountDeletion{
+				ID:               "deletion-1",
+				UserID:           "user-123",
+				Status:           models.AccountDeletionCompleted,
+				CompletedAt:      &completedAt,
+				DocumentsDeleted: 3,
+				FilesDeleted:     2,
+			}, nil),
+			m.mailer.EXPECT().
+				Send(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+					assert.Equal(t, "test@example.com", msg.To)
+					assert.Contains(t, msg.Body, "deletion-1")
+					assert.Contains(t, msg.Body, "Documents deleted: 3")
+					assert.Contains(t, msg.Body, "Files deleted: 2")
+					return nil
+				}),
+			m.deletions.EXPECT().ClaimDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.ErrDeletionNotFound),
+		)
+
+		assert.NoError(t, service.ProcessDue(context.Background()))
+	})
+
+	t.Run("stops at a failed step", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.deletions.EXPECT().ClaimDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(deletion, nil)
+		m.files.EXPECT().ListUserFiles(gomock.Any(), "user-123").Return(files, nil)
+		m.files.EXPECT().Remove(gomock.Any(), files[0]).Return(assert.AnError)
+
+		assert.ErrorIs(t, service.ProcessDue(context.Background()), assert.AnError)
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.deletions.EXPECT().ClaimDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.ErrDeletionNotFound)
+
+		assert.NoError(t, service.ProcessDue(context.Background()))
+	})
+}
@@ -0,0 +1,55 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type DeletionRepository interface {
+	Create(ctx context.Context, deletion *models.AccountDeletion) error
+	GetActiveByUserID(ctx context.Context, userID string) (*models.AccountDeletion, error)
+	Confirm(ctx context.Context, userID, tokenHash string, now, scheduledFor time.Time) (*models.AccountDeletion, error)
+	Cancel(ctx context.Context, userID string) error
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*models.AccountDeletion, error)
+	AddProgress(ctx context.Context, id string, documents, files int64) error
+	Complete(ctx context.Context, id string, completedAt time.Time) (*models.AccountDeletion, error)
+}
+
+type Users interface {
+	GetProfile(ctx context.Context, userID string) (*models.User, error)
+	VerifyPassword(ctx context.Context, userID, password string) (*models.User, error)
+	DeleteUser(ctx context.Context, userID string) error
+}
+
+type Documents interface {
+	DeleteUserDocuments(ctx context.Context, userID string) (int64, error)
+}
+
+// Grants removes the document grants the user gave and received.
+type Grants interface {
+	DeleteUserGrants(ctx context.Context, userID string) error
+}
+
+type Files interface {
+	ListUserFiles(ctx context.Context, userID string) ([]*models.FileRecord, error)
+	Remove(ctx context.Context, file *models.FileRecord) error
+}
+
+type Profiles interface {
+	DeleteUserProfiles(ctx context.Context, userID string) error
+}
+
+type Delegations interface {
+	DeleteUserDelegations(ctx context.Context, userID string) error
+}
+
+type ShareLinks interface {
+	DeleteUserLinks(ctx context.Context, userID string) error
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+}
@@ -0,0 +1,482 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/account/interfaces.go
+
+// Package account is a generated GoMock package.
+package account
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/gruzdev-dev/meddoc/app/models"
+	mailer "github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// MockDeletionRepository is a mock of DeletionRepository interface.
+type MockDeletionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeletionRepositoryMockRecorder
+}
+
+// MockDeletionRepositoryMockRecorder is the mock recorder for MockDeletionRepository.
+type MockDeletionRepositoryMockRecorder struct {
+	mock *MockDeletionRepository
+}
+
+// NewMockDeletionRepository creates a new mock instance.
+func NewMockDeletionRepository(ctrl *gomock.Controller) *MockDeletionRepository {
+	mock := &MockDeletionRepository{ctrl: ctrl}
+	mock.recorder = &MockDeletionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeletionRepository) EXPECT() *MockDeletionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddProgress mocks base method.
+func (m *MockDeletionRepository) AddProgress(ctx context.Context, id string, documents, files int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProgress", ctx, id, documents, files)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddProgress indicates an expected call of AddProgress.
+func (mr *MockDeletionRepositoryMockRecorder) AddProgress(ctx, id, documents, files interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProgress", reflect.TypeOf((*MockDeletionRepository)(nil).AddProgress), ctx, id, documents, files)
+}
+
+// Cancel mocks base method.
+func (m *MockDeletionRepository) Cancel(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockDeletionRepositoryMockRecorder) Cancel(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockDeletionRepository)(nil).Cancel), ctx, userID)
+}
+
+// ClaimDue mocks base method.
+func (m *MockDeletionRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*models.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", ctx, now, leaseUntil)
+	ret0, _ := ret[0].(*models.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *MockDeletionRepositoryMockRecorder) ClaimDue(ctx, now, leaseUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*MockDeletionRepository)(nil).ClaimDue), ctx, now, leaseUntil)
+}
+
+// Complete mocks base method.
+func (m *MockDeletionRepository) Complete(ctx context.Context, id string, completedAt time.Time) (*models.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, id, completedAt)
+	ret0, _ := ret[0].(*models.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockDeletionRepositoryMockRecorder) Complete(ctx, id, completedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockDeletionRepository)(nil).Complete), ctx, id, completedAt)
+}
+
+// Confirm mocks base method.
+func (m *MockDeletionRepository) Confirm(ctx context.Context, userID, tokenHash string, now, scheduledFor time.Time) (*models.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, userID, tokenHash, now, scheduledFor)
+	ret0, _ := ret[0].(*models.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockDeletionRepositoryMockRecorder) Confirm(ctx, userID, tokenHash, now, scheduledFor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockDeletionRepository)(nil).Confirm), ctx, userID, tokenHash, now, scheduledFor)
+}
+
+// Create mocks base method.
+func (m *MockDeletionRepository) Create(ctx context.Context, deletion *models.AccountDeletion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, deletion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDeletionRepositoryMockRecorder) Create(ctx, deletion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeletionRepository)(nil).Create), ctx, deletion)
+}
+
+// GetActiveByUserID mocks base method.
+func (m *MockDeletionRepository) GetActiveByUserID(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveByUserID", ctx, userID)
+	ret0, _ := ret[0].(*models.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveByUserID indicates an expected call of GetActiveByUserID.
+func (mr *MockDeletionRepositoryMockRecorder) GetActiveByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveByUserID", reflect.TypeOf((*MockDeletionRepository)(nil).GetActiveByUserID), ctx, userID)
+}
+
+// MockUsers is a mock of Users interface.
+type MockUsers struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersMockRecorder
+}
+
+// MockUsersMockRecorder is the mock recorder for MockUsers.
+type MockUsersMockRecorder struct {
+	mock *MockUsers
+}
+
+// NewMockUsers creates a new mock instance.
+func NewMockUsers(ctrl *gomock.Controller) *MockUsers {
+	mock := &MockUsers{ctrl: ctrl}
+	mock.recorder = &MockUsersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
+	return m.recorder
+}
+
+// DeleteUser mocks base method.
+func (m *MockUsers) DeleteUser(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUsersMockRecorder) DeleteUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUsers)(nil).DeleteUser), ctx, userID)
+}
+
+// GetProfile mocks base method.
+func (m *MockUsers) GetProfile(ctx context.Context, userID string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUsersMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUsers)(nil).GetProfile), ctx, userID)
+}
+
+// VerifyPassword mocks base method.
+func (m *MockUsers) VerifyPassword(ctx context.Context, userID, password string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPassword", ctx, userID, password)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPassword indicates an expected call of VerifyPassword.
+func (mr *MockUsersMockRecorder) VerifyPassword(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPassword", reflect.TypeOf((*MockUsers)(nil).VerifyPassword), ctx, userID, password)
+}
+
+// MockDocuments is a mock of Documents interface.
+type MockDocuments struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentsMockRecorder
+}
+
+// MockDocumentsMockRecorder is the mock recorder for MockDocuments.
+type MockDocumentsMockRecorder struct {
+	mock *MockDocuments
+}
+
+// NewMockDocuments creates a new mock instance.
+func NewMockDocuments(ctrl *gomock.Controller) *MockDocuments {
+	mock := &MockDocuments{ctrl: ctrl}
+	mock.recorder = &MockDocumentsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocuments) EXPECT() *MockDocumentsMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserDocuments mocks base method.
+func (m *MockDocuments) DeleteUserDocuments(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserDocuments", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserDocuments indicates an expected call of DeleteUserDocuments.
+func (mr *MockDocumentsMockRecorder) DeleteUserDocuments(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserDocuments", reflect.TypeOf((*MockDocuments)(nil).DeleteUserDocuments), ctx, userID)
+}
+
+// MockGrants is a mock of Grants interface.
+type MockGrants struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantsMockRecorder
+}
+
+// MockGrantsMockRecorder is the mock recorder for MockGrants.
+type MockGrantsMockRecorder struct {
+	mock *MockGrants
+}
+
+// NewMockGrants creates a new mock instance.
+func NewMockGrants(ctrl *gomock.Controller) *MockGrants {
+	mock := &MockGrants{ctrl: ctrl}
+	mock.recorder = &MockGrantsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrants) EXPECT() *MockGrantsMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserGrants mocks base method.
+func (m *MockGrants) DeleteUserGrants(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserGrants", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserGrants indicates an expected call of DeleteUserGrants.
+func (mr *MockGrantsMockRecorder) DeleteUserGrants(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserGrants", reflect.TypeOf((*MockGrants)(nil).DeleteUserGrants), ctx, userID)
+}
+
+// MockFiles is a mock of Files interface.
+type MockFiles struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilesMockRecorder
+}
+
+// MockFilesMockRecorder is the mock recorder for MockFiles.
+type MockFilesMockRecorder struct {
+	mock *MockFiles
+}
+
+// NewMockFiles creates a new mock instance.
+func NewMockFiles(ctrl *gomock.Controller) *MockFiles {
+	mock := &MockFiles{ctrl: ctrl}
+	mock.recorder = &MockFilesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFiles) EXPECT() *MockFilesMockRecorder {
+	return m.recorder
+}
+
+// ListUserFiles mocks base method.
+func (m *MockFiles) ListUserFiles(ctx context.Context, userID string) ([]*models.FileRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserFiles", ctx, userID)
+	ret0, _ := ret[0].([]*models.FileRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserFiles indicates an expected call of ListUserFiles.
+func (mr *MockFilesMockRecorder) ListUserFiles(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserFiles", reflect.TypeOf((*MockFiles)(nil).ListUserFiles), ctx, userID)
+}
+
+// Remove mocks base method.
+func (m *MockFiles) Remove(ctx context.Context, file *models.FileRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, file)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockFilesMockRecorder) Remove(ctx, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockFiles)(nil).Remove), ctx, file)
+}
+
+// MockProfiles is a mock of Profiles interface.
+type MockProfiles struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfilesMockRecorder
+}
+
+// MockProfilesMockRecorder is the mock recorder for MockProfiles.
+type MockProfilesMockRecorder struct {
+	mock *MockProfiles
+}
+
+// NewMockProfiles creates a new mock instance.
+func NewMockProfiles(ctrl *gomock.Controller) *MockProfiles {
+	mock := &MockProfiles{ctrl: ctrl}
+	mock.recorder = &MockProfilesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfiles) EXPECT() *MockProfilesMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserProfiles mocks base method.
+func (m *MockProfiles) DeleteUserProfiles(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserProfiles", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserProfiles indicates an expected call of DeleteUserProfiles.
+func (mr *MockProfilesMockRecorder) DeleteUserProfiles(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserProfiles", reflect.TypeOf((*MockProfiles)(nil).DeleteUserProfiles), ctx, userID)
+}
+
+// MockDelegations is a mock of Delegations interface.
+type MockDelegations struct {
+	ctrl     *gomock.Controller
+	recorder *MockDelegationsMockRecorder
+}
+
+// MockDelegationsMockRecorder is the mock recorder for MockDelegations.
+type MockDelegationsMockRecorder struct {
+	mock *MockDelegations
+}
+
+// NewMockDelegations creates a new mock instance.
+func NewMockDelegations(ctrl *gomock.Controller) *MockDelegations {
+	mock := &MockDelegations{ctrl: ctrl}
+	mock.recorder = &MockDelegationsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDelegations) EXPECT() *MockDelegationsMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserDelegations mocks base method.
+func (m *MockDelegations) DeleteUserDelegations(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserDelegations", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserDelegations indicates an expected call of DeleteUserDelegations.
+func (mr *MockDelegationsMockRecorder) DeleteUserDelegations(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserDelegations", reflect.TypeOf((*MockDelegations)(nil).DeleteUserDelegations), ctx, userID)
+}
+
+// MockShareLinks is a mock of ShareLinks interface.
+type MockShareLinks struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareLinksMockRecorder
+}
+
+// MockShareLinksMockRecorder is the mock recorder for MockShareLinks.
+type MockShareLinksMockRecorder struct {
+	mock *MockShareLinks
+}
+
+// NewMockShareLinks creates a new mock instance.
+func NewMockShareLinks(ctrl *gomock.Controller) *MockShareLinks {
+	mock := &MockShareLinks{ctrl: ctrl}
+	mock.recorder = &MockShareLinksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareLinks) EXPECT() *MockShareLinksMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserLinks mocks base method.
+func (m *MockShareLinks) DeleteUserLinks(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserLinks", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserLinks indicates an expected call of DeleteUserLinks.
+func (mr *MockShareLinksMockRecorder) DeleteUserLinks(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserLinks", reflect.TypeOf((*MockShareLinks)(nil).DeleteUserLinks), ctx, userID)
+}
+
+// MockMailer is a mock of Mailer interface.
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+}
+
+// MockMailerMockRecorder is the mock recorder for MockMailer.
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+// NewMockMailer creates a new mock instance.
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailerMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), ctx, msg)
+}
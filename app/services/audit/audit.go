@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// deniedErrors are the errors that mean an actor was refused rather than an
+// operation having failed.
+var deniedErrors = []error{
+	apperrors.ErrAccessDenied,
+	apperrors.ErrInvalidCredentials,
+	apperrors.ErrInvalidMFACode,
+	apperrors.ErrInvalidToken,
+	apperrors.ErrInvalidResetToken,
+	apperrors.ErrEmailNotVerified,
+	apperrors.ErrAccountLocked,
+	apperrors.ErrTooManyAttempts,
+}
+
+// Origin identifies the request an audited operation was part of.
+type Origin struct {
+	RequestID string
+	IP        string
+}
+
+type originKey struct{}
+
+// WithOrigin returns a context whose audit events are attributed to origin.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+func originFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
+
+type Dependencies struct {
+	Events      AuditRepository
+	Checkpoints CheckpointRepository
+	// Keys sign checkpoints with their active key and verify them with the
+	// key named in each checkpoint.
+	Keys *keyring.KeyRing
+}
+
+type Config struct {
+	// CheckpointInterval is how often Run signs the head of the chain.
+	CheckpointInterval time.Duration
+}
+
+// Service keeps the audit log of who read or changed medical data and who
+// signed in. Events form a hash chain that is signed at checkpoints, so
+// changes made to the log outside the application can be detected.
+type Service struct {
+	events             AuditRepository
+	checkpoints        CheckpointRepository
+	keys               *keyring.KeyRing
+	checkpointInterval time.Duration
+
+	// mu serializes appends from this instance; head is the last event it
+	// knows of, nil until loaded.
+	mu   sync.Mutex
+	head *models.AuditEvent
+}
+
+func NewService(deps Dependencies, cfg Config) *Service {
+	return &Service{
+		events:             deps.Events,
+		checkpoints:        deps.Checkpoints,
+		keys:               deps.Keys,
+		checkpointInterval: cfg.CheckpointInterval,
+	}
+}
+
+// Record adds an event for an operation that ended with err. The outcome,
+// time and request origin are filled in here. The event is written even if
+// ctx was cancelled; a failure to write it is logged and does not fail the
+// operation.
+func (s *Service) Record(ctx context.Context, event models.AuditEvent, err error) {
+	origin := originFrom(ctx)
+	// MongoDB keeps milliseconds, and the hash has to match what is read back.
+	event.Time = time.Now().UTC().Truncate(time.Millisecond)
+	event.Outcome = outcome(err)
+	event.RequestID = origin.RequestID
+	event.IP = origin.IP
+
+	if err := s.append(context.WithoutCancel(ctx), &event); err != nil {
+		logger.Error("failed to record audit event", err,
+			"action", event.Action,
+			"actor_id", event.ActorID,
+			"resource_id", event.ResourceID,
+			"request_id", event.RequestID,
+		)
+	}
+}
+
+// Query returns the events matching filter, newest first. It is meant for
+// administrators.
+func (s *Service) Query(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	filter.Limit = limit(filter.Limit)
+	return s.events.Find(ctx, filter)
+}
+
+// AccessToUserData returns what others did with the data and account of
+// userID, newest first. The user's own operations are left out.
+func (s *Service) AccessToUserData(ctx context.Context, userID string, filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	filter.OwnerID = userID
+	filter.ExcludeActorID = userID
+	filter.Limit = limit(filter.Limit)
+	return s.events.Find(ctx, filter)
+}
+
+func outcome(err error) string {
+	if err == nil {
+		return models.AuditSuccess
+	}
+	for _, denied := range deniedErrors {
+		if errors.Is(err, denied) {
+			return models.AuditDenied
+		}
+	}
+	return models.AuditFailure
+}
+
+func limit(n int) int {
+	if n <= 0 {
+		return defaultLimit
+	}
+	return min(n, maxLimit)
+}
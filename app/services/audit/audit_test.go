@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestService_Record(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedOutcome string
+	}{
+		{name: "success", err: nil, expectedOutcome: models.AuditSuccess},
+		{name: "access denied", err: apperrors.ErrAccessDenied, expectedOutcome: models.AuditDenied},
+		{name: "wrapped denial", err: fmt.Errorf("login: %w", apperrors.ErrInvalidCredentials), expectedOutcome: models.AuditDenied},
+		{name: "missing resource", err: apperrors.ErrDocumentNotFound, expectedOutcome: models.AuditFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockEvents := NewMockAuditRepository(ctrl)
+			service := NewService(Dependencies{Events: mockEvents}, Config{})
+
+			ctx := WithOrigin(context.Background(), Origin{RequestID: "req-1", IP: "10.0.0.1"})
+			mockEvents.EXPECT().Last(gomock.Any()).Return(nil, apperrors.ErrAuditEventNotFound)
+			mockEvents.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+					assert.Equal(t, models.AuditDocumentRead, event.Action)
+					assert.Equal(t, "user-1", event.ActorID)
+					assert.Equal(t, tt.expectedOutcome, event.Outcome)
+					assert.Equal(t, "req-1", event.RequestID)
+					assert.Equal(t, "10.0.0.1", event.IP)
+					assert.WithinDuration(t, time.Now(), event.Time, time.Minute)
+					return nil
+				})
+
+			service.Record(ctx, models.AuditEvent{Action: models.AuditDocumentRead, ActorID: "user-1"}, tt.err)
+		})
+	}
+}
+
+func TestService_RecordOutlivesCancelledRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEvents := NewMockAuditRepository(ctrl)
+	service := NewService(Dependencies{Events: mockEvents}, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mockEvents.EXPECT().Last(gomock.Any()).Return(nil, apperrors.ErrAuditEventNotFound)
+	mockEvents.EXPECT().
+		Insert(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *models.AuditEvent) error {
+			assert.NoError(t, ctx.Err())
+			return nil
+		})
+
+	service.Record(ctx, models.AuditEvent{Action: models.AuditLogout}, nil)
+}
+
+func TestService_Query(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        models.AuditFilter
+		expectedLimit int
+	}{
+		{name: "default limit", filter: models.AuditFilter{}, expectedLimit: defaultLimit},
+		{name: "requested limit", filter: models.AuditFilter{Limit: 20}, expectedLimit: 20},
+		{name: "limit is capped", filter: models.AuditFilter{Limit: 5000}, expectedLimit: maxLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockEvents := NewMockAuditRepository(ctrl)
+			service := NewService(Dependencies{Events: mockEvents}, Config{})
+
+			expected := tt.filter
+			expected.Limit = tt.expectedLimit
+			mockEvents.EXPECT().Find(gomock.Any(), expected).Return([]*models.AuditEvent{}, nil)
+
+			_, err := service.Query(context.Background(), tt.filter)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_AccessToUserData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEvents := NewMockAuditRepository(ctrl)
+	service := NewService(Dependencies{Events: mockEvents}, Config{})
+
+	events := []*models.AuditEvent{{ID: "event-1", ActorID: "delegate-1", OwnerID: "user-1"}}
+	mockEvents.EXPECT().
+		Find(gomock.Any(), models.AuditFilter{
+			OwnerID:        "user-1",
+			ExcludeActorID: "user-1",
+			Action:         models.AuditDocumentRead,
+			Limit:          defaultLimit,
+		}).
+		Return(events, nil)
+
+	result, err := service.AccessToUserData(context.Background(), "user-1", models.AuditFilter{
+		OwnerID: "someone-else",
+		Action:  models.AuditDocumentRead,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, events, result)
+}
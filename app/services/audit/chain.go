@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// maxAppendAttempts bounds how often an append is retried when other
+// instances keep taking the next sequence number.
+const maxAppendAttempts = 5
+
+// append links event to the head of the chain and stores it. The unique
+// sequence number makes concurrent writers on other instances fail instead
+// of forking the chain; they reload the head and try again.
+func (s *Service) append(ctx context.Context, event *models.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range maxAppendAttempts {
+		if s.head == nil {
+			head, err := s.events.Last(ctx)
+			if errors.Is(err, apperrors.ErrAuditEventNotFound) {
+				head = &models.AuditEvent{}
+			} else if err != nil {
+				return err
+			}
+			s.head = head
+		}
+
+		event.Seq = s.head.Seq + 1
+		event.PrevHash = s.head.Hash
+		event.Hash = eventHash(event)
+
+		err := s.events.Insert(ctx, event)
+		if err != nil {
+			s.head = nil
+			if errors.Is(err, apperrors.ErrAuditSeqTaken) {
+				continue
+			}
+			return err
+		}
+
+		head := *event
+		s.head = &head
+		return nil
+	}
+	return apperrors.ErrAuditSeqTaken
+}
+
+// eventHash chains event to PrevHash. Fields are encoded as a JSON array so
+// that no two different events encode the same way.
+func eventHash(event *models.AuditEvent) string {
+	fields, _ := json.Marshal([]any{
+		event.Seq,
+		event.Time.UTC().Format(time.RFC3339Nano),
+		event.ActorID,
+		event.OwnerID,
+		event.Action,
+		event.ResourceID,
+		event.Outcome,
+		event.RequestID,
+		event.IP,
+		event.BreakGlassID,
+	})
+
+	h := sha256.New()
+	h.Write([]byte(event.PrevHash))
+	h.Write([]byte{'\n'})
+	h.Write(fields)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Run signs a checkpoint every checkpoint interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.Checkpoint(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("failed to sign audit checkpoint", err)
+		}
+	}
+}
+
+// Checkpoint signs the current head of the chain. It returns nil when the
+// head has been signed already or there are no events yet.
+func (s *Service) Checkpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	head, err := s.events.Last(ctx)
+	if errors.Is(err, apperrors.ErrAuditEventNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := s.checkpoints.Last(ctx)
+	if err != nil && !errors.Is(err, apperrors.ErrAuditCheckpointNotFound) {
+		return nil, err
+	}
+	if last != nil && last.Seq >= head.Seq {
+		return nil, nil
+	}
+
+	key := s.keys.SigningKey()
+	checkpoint := &models.AuditCheckpoint{
+		Seq:   head.Seq,
+		Hash:  head.Hash,
+		Time:  time.Now().UTC().Truncate(time.Millisecond),
+		KeyID: key.ID,
+	}
+	signature, err := jwt.GetSigningMethod(key.Algorithm).Sign(checkpointPayload(checkpoint), key.Private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+	checkpoint.Signature = base64.RawURLEncoding.EncodeToString(signature)
+
+	// Another instance may have signed the same head in the meantime.
+	if err := s.checkpoints.Insert(ctx, checkpoint); err != nil {
+		if errors.Is(err, apperrors.ErrAuditSeqTaken) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (s *Service) verifyCheckpoint(checkpoint *models.AuditCheckpoint) error {
+	key, err := s.keys.Key(checkpoint.KeyID)
+	if err != nil {
+		return fmt.Errorf("unknown signing key %q", checkpoint.KeyID)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(checkpoint.Signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+	return jwt.GetSigningMethod(key.Algorithm).Verify(checkpointPayload(checkpoint), signature, key.Public)
+}
+
+func checkpointPayload(checkpoint *models.AuditCheckpoint) string {
+	return fmt.Sprintf("meddoc-audit-checkpoint\n%d\n%s\n%s",
+		checkpoint.Seq, checkpoint.Hash, checkpoint.Time.UTC().Format(time.RFC3339Nano))
+}
@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+)
+
+func TestService_RecordChainsEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEvents := NewMockAuditRepository(ctrl)
+	service := NewService(Dependencies{Events: mockEvents}, Config{})
+
+	var stored []*models.AuditEvent
+	mockEvents.EXPECT().Last(gomock.Any()).Return(&models.AuditEvent{Seq: 4, Hash: "head-hash"}, nil)
+	mockEvents.EXPECT().
+		Insert(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+			stored = append(stored, event)
+			return nil
+		}).
+		Times(2)
+
+	service.Record(context.Background(), models.AuditEvent{Action: models.AuditLogin}, nil)
+	service.Record(context.Background(), models.AuditEvent{Action: models.AuditLogout}, nil)
+
+	require.Len(t, stored, 2)
+	assert.Equal(t, int64(5), stored[0].Seq)
+	assert.Equal(t, "head-hash", stored[0].PrevHash)
+	assert.Equal(t, eventHash(stored[0]), stored[0].Hash)
+	assert.Equal(t, int64(6), stored[1].Seq)
+	assert.Equal(t, stored[0].Hash, stored[1].PrevHash)
+	assert.Equal(t, eventHash(stored[1]), stored[1].Hash)
+}
+
+func TestService_RecordRetriesWhenSeqIsTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEvents := NewMockAuditRepository(ctrl)
+	service := NewService(Dependencies{Events: mockEvents}, Config{})
+
+	gomock.InOrder(
+		mockEvents.EXPECT().Last(gomock.Any()).Return(&models.AuditEvent{Seq: 4, Hash: "hash-4"}, nil),
+		mockEvents.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(apperrors.ErrAuditSeqTaken),
+		mockEvents.EXPECT().Last(gomock.Any()).Return(&models.AuditEvent{Seq: 5, Hash: "hash-5"}, nil),
+		mockEvents.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+				assert.Equal(t, int64(6), event.Seq)
+				assert.Equal(t, "hash-5", event.PrevHash)
+				return nil
+			}),
+	)
+
+	service.Record(context.Background(), models.AuditEvent{Action: models.AuditLogin}, nil)
+}
+
+func TestEventHash(t *testing.T) {
+	event := &models.AuditEvent{
+		Seq:        3,
+		Time:       time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		ActorID:    "user-1",
+		Action:     models.AuditDocumentRead,
+		ResourceID: "doc-1",
+		Outcome:    models.AuditSuccess,
+		PrevHash:   "prev",
+	}
+	hash := eventHash(event)
+	assert.Len(t, hash, 64)
+
+	local := *event
+	local.Time = event.Time.In(time.FixedZone("UTC+3", 3*60*60))
+	assert.Equal(t, hash, eventHash(&local), "the time zone does not change the hash")
+
+	changed := *event
+	changed.ActorID = "user-2"
+	assert.NotEqual(t, hash, eventHash(&changed))
+
+	// Moving text between fields must change the hash as well.
+	shifted := *event
+	shifted.ActorID, shifted.OwnerID = "user", "-1"
+	assert.NotEqual(t, hash, eventHash(&shifted))
+
+	relinked := *event
+	relinked.PrevHash = "other"
+	assert.NotEqual(t, hash, eventHash(&relinked))
+}
+
+func TestService_Checkpoint(t *testing.T) {
+	keys := keyring.NewHMAC([]byte("test-secret"))
+	head := &models.AuditEvent{Seq: 7, Hash: "hash-7"}
+
+	tests := []struct {
+		name       string
+		mockSetup  func(events *MockAuditRepository, checkpoints *MockCheckpointRepository)
+		expectSign bool
+	}{
+		{
+			name: "signs a new head",
+			mockSetup: func(events *MockAuditRepository, checkpoints *MockCheckpointRepository) {
+				events.EXPECT().Last(gomock.Any()).Return(head, nil)
+				checkpoints.EXPECT().Last(gomock.Any()).Return(&models.AuditCheckpoint{Seq: 5}, nil)
+				checkpoints.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectSign: true,
+		},
+		{
+			name: "first checkpoint",
+			mockSetup: func(events *MockAuditRepository, checkpoints *MockCheckpointRepository) {
+				events.EXPECT().Last(gomock.Any()).Return(head, nil)
+				checkpoints.EXPECT().Last(gomock.Any()).Return(nil, apperrors.ErrAuditCheckpointNotFound)
+				checkpoints.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectSign: true,
+		},
+		{
+			name: "head already signed",
+			mockSetup: func(events *MockAuditRepository, checkpoints *MockCheckpointRepository) {
+				events.EXPECT().Last(gomock.Any()).Return(head, nil)
+				checkpoints.EXPECT().Last(gomock.Any()).Return(&models.AuditCheckpoint{Seq: 7}, nil)
+			},
+		},
+		{
+			name: "signed by another instance meanwhile",
+			mockSetup: func(events *MockAuditRepository, checkpoints *MockCheckpointRepository) {
+				events.EXPECT().Last(gomock.Any()).Return(head, nil)
+				checkpoints.EXPECT().Last(gomock.Any()).Return(&models.AuditCheckpoint{Seq: 5}, nil)
+				checkpoints.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(apperrors.ErrAuditSeqTaken)
+			},
+		},
+		{
+			name: "no events yet",
+			mockSetup: func(events *MockAuditRepository, checkpoints *MockCheckpointRepository) {
+				events.EXPECT().Last(gomock.Any()).Return(nil, apperrors.ErrAuditEventNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockEvents := NewMockAuditRepository(ctrl)
+			mockCheckpoints := NewMockCheckpointRepository(ctrl)
+			service := NewService(Dependencies{Events: mockEvents, Checkpoints: mockCheckpoints, Keys: keys}, Config{})
+			tt.mockSetup(mockEvents, mockCheckpoints)
+
+			checkpoint, err := service.Checkpoint(context.Background())
+			require.NoError(t, err)
+			if !tt.expectSign {
+				assert.Nil(t, checkpoint)
+				return
+			}
+			require.NotNil(t, checkpoint)
+			assert.Equal(t, int64(7), checkpoint.Seq)
+			assert.Equal(t, "hash-7", checkpoint.Hash)
+			assert.Equal(t, "default", checkpoint.KeyID)
+			assert.NoError(t, service.verifyCheckpoint(checkpoint))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type AuditRepository interface {
+	Insert(ctx context.Context, event *models.AuditEvent) error
+	Last(ctx context.Context) (*models.AuditEvent, error)
+	Walk(ctx context.Context, fn func(*models.AuditEvent) error) error
+	Find(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error)
+}
+
+type CheckpointRepository interface {
+	Insert(ctx context.Context, checkpoint *models.AuditCheckpoint) error
+	Last(ctx context.Context) (*models.AuditCheckpoint, error)
+	All(ctx context.Context) ([]*models.AuditCheckpoint, error)
+}
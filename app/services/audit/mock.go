@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/audit/interfaces.go
+
+// Package audit is a generated GoMock package.
+package audit
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockAuditRepository) Find(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, filter)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockAuditRepositoryMockRecorder) Find(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockAuditRepository)(nil).Find), ctx, filter)
+}
+
+// Insert mocks base method.
+func (m *MockAuditRepository) Insert(ctx context.Context, event *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockAuditRepositoryMockRecorder) Insert(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockAuditRepository)(nil).Insert), ctx, event)
+}
+
+// Last mocks base method.
+func (m *MockAuditRepository) Last(ctx context.Context) (*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Last", ctx)
+	ret0, _ := ret[0].(*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Last indicates an expected call of Last.
+func (mr *MockAuditRepositoryMockRecorder) Last(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockAuditRepository)(nil).Last), ctx)
+}
+
+// Walk mocks base method.
+func (m *MockAuditRepository) Walk(ctx context.Context, fn func(*models.AuditEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Walk", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Walk indicates an expected call of Walk.
+func (mr *MockAuditRepositoryMockRecorder) Walk(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Walk", reflect.TypeOf((*MockAuditRepository)(nil).Walk), ctx, fn)
+}
+
+// MockCheckpointRepository is a mock of CheckpointRepository interface.
+type MockCheckpointRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckpointRepositoryMockRecorder
+}
+
+// MockCheckpointRepositoryMockRecorder is the mock recorder for MockCheckpointRepository.
+type MockCheckpointRepositoryMockRecorder struct {
+	mock *MockCheckpointRepository
+}
+
+// NewMockCheckpointRepository creates a new mock instance.
+func NewMockCheckpointRepository(ctrl *gomock.Controller) *MockCheckpointRepository {
+	mock := &MockCheckpointRepository{ctrl: ctrl}
+	mock.recorder = &MockCheckpointRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCheckpointRepository) EXPECT() *MockCheckpointRepositoryMockRecorder {
+	return m.recorder
+}
+
+// All mocks base method.
+func (m *MockCheckpointRepository) All(ctx context.Context) ([]*models.AuditCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All", ctx)
+	ret0, _ := ret[0].([]*models.AuditCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// All indicates an expected call of All.
+func (mr *MockCheckpointRepositoryMockRecorder) All(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockCheckpointRepository)(nil).All), ctx)
+}
+
+// Insert mocks base method.
+func (m *MockCheckpointRepository) Insert(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCheckpointRepositoryMockRecorder) Insert(ctx, checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCheckpointRepository)(nil).Insert), ctx, checkpoint)
+}
+
+// Last mocks base method.
+func (m *MockCheckpointRepository) Last(ctx context.Context) (*models.AuditCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Last", ctx)
+	ret0, _ := ret[0].(*models.AuditCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Last indicates an expected call of Last.
+func (mr *MockCheckpointRepositoryMockRecorder) Last(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockCheckpointRepository)(nil).Last), ctx)
+}
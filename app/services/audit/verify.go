@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// ChainError reports the first place where the audit chain is not intact.
+type ChainError struct {
+	Seq    int64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at seq %d: %s", e.Seq, e.Reason)
+}
+
+// Report summarizes a verified chain.
+type Report struct {
+	Events      int64
+	Checkpoints int
+	// Head is the last event of the chain, nil when it is empty.
+	Head *models.AuditEvent
+}
+
+// Verify walks the whole chain, recomputing every hash, and checks it against
+// the signed checkpoints. It returns a *ChainError for the first event that
+// was changed, removed or inserted out of order, for a checkpoint with a bad
+// signature, and for a chain cut short before its last checkpoint.
+func (s *Service) Verify(ctx context.Context) (*Report, error) {
+	checkpoints, err := s.checkpoints.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, checkpoint := range checkpoints {
+		if err := s.verifyCheckpoint(checkpoint); err != nil {
+			return nil, &ChainError{Seq: checkpoint.Seq, Reason: fmt.Sprintf("checkpoint signature is invalid: %v", err)}
+		}
+	}
+
+	report := &Report{Checkpoints: len(checkpoints)}
+	prev := &models.AuditEvent{}
+	next := 0
+	err = s.events.Walk(ctx, func(event *models.AuditEvent) error {
+		switch {
+		case event.Seq != prev.Seq+1:
+			return &ChainError{Seq: prev.Seq + 1, Reason: fmt.Sprintf("expected event %d, found event %d", prev.Seq+1, event.Seq)}
+		case event.PrevHash != prev.Hash:
+			return &ChainError{Seq: event.Seq, Reason: "event does not link to the event before it"}
+		case eventHash(event) != event.Hash:
+			return &ChainError{Seq: event.Seq, Reason: "event does not match its hash"}
+		}
+		if next < len(checkpoints) && checkpoints[next].Seq == event.Seq {
+			if checkpoints[next].Hash != event.Hash {
+				return &ChainError{Seq: event.Seq, Reason: "event does not match its signed checkpoint"}
+			}
+			next++
+		}
+
+		prev = event
+		report.Events++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if next < len(checkpoints) {
+		return nil, &ChainError{
+			Seq:    prev.Seq + 1,
+			Reason: fmt.Sprintf("chain ends at event %d but a checkpoint covers event %d", prev.Seq, checkpoints[next].Seq),
+		}
+	}
+
+	if report.Events > 0 {
+		report.Head = prev
+	}
+	return report, nil
+}
@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+)
+
+// testChain returns n correctly chained events.
+func testChain(n int) []*models.AuditEvent {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	events := make([]*models.AuditEvent, 0, n)
+	prev := ""
+	for i := range n {
+		event := &models.AuditEvent{
+			Seq:        int64(i + 1),
+			Time:       start.Add(time.Duration(i) * time.Minute),
+			ActorID:    "user-1",
+			OwnerID:    "user-1",
+			Action:     models.AuditDocumentRead,
+			ResourceID: "doc-1",
+			Outcome:    models.AuditSuccess,
+			PrevHash:   prev,
+		}
+		event.Hash = eventHash(event)
+		prev = event.Hash
+		events = append(events, event)
+	}
+	return events
+}
+
+func signedCheckpoint(t *testing.T, keys *keyring.KeyRing, event *models.AuditEvent) *models.AuditCheckpoint {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	events := NewMockAuditRepository(ctrl)
+	checkpoints := NewMockCheckpointRepository(ctrl)
+	signer := NewService(Dependencies{Events: events, Checkpoints: checkpoints, Keys: keys}, Config{})
+
+	var signed *models.AuditCheckpoint
+	events.EXPECT().Last(gomock.Any()).Return(event, nil)
+	checkpoints.EXPECT().Last(gomock.Any()).Return(&models.AuditCheckpoint{}, nil)
+	checkpoints.EXPECT().
+		Insert(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, checkpoint *models.AuditCheckpoint) error {
+			signed = checkpoint
+			return nil
+		})
+	_, err := signer.Checkpoint(context.Background())
+	require.NoError(t, err)
+	return signed
+}
+
+func TestService_Verify(t *testing.T) {
+	keys := keyring.NewHMAC([]byte("test-secret"))
+
+	tests := []struct {
+		name string
+		// tamper changes a chain of five events with checkpoints at
+		// events 2 and 4.
+		tamper        func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint)
+		expectedSeq   int64
+		expectedError string
+	}{
+		{
+			name: "intact chain",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				return events, checkpoints
+			},
+		},
+		{
+			name: "changed event",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				events[2].ActorID = "someone-else"
+				return events, checkpoints
+			},
+			expectedSeq:   3,
+			expectedError: "does not match its hash",
+		},
+		{
+			name: "removed event",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				return append(events[:2], events[3:]...), checkpoints
+			},
+			expectedSeq:   3,
+			expectedError: "expected event 3, found event 4",
+		},
+		{
+			name: "removed event with renumbered and rehashed tail",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				events = append(events[:2], events[3:]...)
+				for i := 2; i < len(events); i++ {
+					events[i].Seq = int64(i + 1)
+					events[i].PrevHash = events[i-1].Hash
+					events[i].Hash = eventHash(events[i])
+				}
+				return events, checkpoints
+			},
+			expectedSeq:   4,
+			expectedError: "does not match its signed checkpoint",
+		},
+		{
+			name: "relinked event",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				events[3].PrevHash = events[1].Hash
+				events[3].Hash = eventHash(events[3])
+				return events, checkpoints
+			},
+			expectedSeq:   4,
+			expectedError: "does not link",
+		},
+		{
+			name: "truncated chain",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				return events[:3], checkpoints
+			},
+			expectedSeq:   4,
+			expectedError: "chain ends at event 3 but a checkpoint covers event 4",
+		},
+		{
+			name: "forged checkpoint",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				checkpoints[1].Hash = events[2].Hash
+				return events, checkpoints
+			},
+			expectedSeq:   4,
+			expectedError: "checkpoint signature is invalid",
+		},
+		{
+			name: "checkpoint signed with an unknown key",
+			tamper: func(events []*models.AuditEvent, checkpoints []*models.AuditCheckpoint) ([]*models.AuditEvent, []*models.AuditCheckpoint) {
+				checkpoints[0].KeyID = "2019-01"
+				return events, checkpoints
+			},
+			expectedSeq:   2,
+			expectedError: `unknown signing key "2019-01"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := testChain(5)
+			checkpoints := []*models.AuditCheckpoint{
+				signedCheckpoint(t, keys, chain[1]),
+				signedCheckpoint(t, keys, chain[3]),
+			}
+			events, checkpoints := tt.tamper(chain, checkpoints)
+
+			ctrl := gomock.NewController(t)
+			mockEvents := NewMockAuditRepository(ctrl)
+			mockCheckpoints := NewMockCheckpointRepository(ctrl)
+			service := NewService(Dependencies{Events: mockEvents, Checkpoints: mockCheckpoints, Keys: keys}, Config{})
+
+			mockCheckpoints.EXPECT().All(gomock.Any()).Return(checkpoints, nil)
+			mockEvents.EXPECT().
+				Walk(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, fn func(*models.AuditEvent) error) error {
+					for _, event := range events {
+						if err := fn(event); err != nil {
+							return err
+						}
+					}
+					return nil
+				}).
+				AnyTimes()
+
+			report, err := service.Verify(context.Background())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				assert.Equal(t, int64(5), report.Events)
+				assert.Equal(t, 2, report.Checkpoints)
+				assert.Equal(t, chain[4].Hash, report.Head.Hash)
+				return
+			}
+
+			var chainErr *ChainError
+			require.ErrorAs(t, err, &chainErr)
+			assert.Equal(t, tt.expectedSeq, chainErr.Seq)
+			assert.Contains(t, chainErr.Reason, tt.expectedError)
+		})
+	}
+}
+
+func TestService_VerifyEmptyChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEvents := NewMockAuditRepository(ctrl)
+	mockCheckpoints := NewMockCheckpointRepository(ctrl)
+	service := NewService(Dependencies{Events: mockEvents, Checkpoints: mockCheckpoints, Keys: keyring.NewHMAC([]byte("test-secret"))}, Config{})
+
+	mockCheckpoints.EXPECT().All(gomock.Any()).Return([]*models.AuditCheckpoint{}, nil)
+	mockEvents.EXPECT().Walk(gomock.Any(), gomock.Any()).Return(nil)
+
+	report, err := service.Verify(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), report.Events)
+	assert.Nil(t, report.Head)
+}
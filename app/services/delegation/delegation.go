@@ -0,0 +1,226 @@
+package delegation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type Dependencies struct {
+	Delegations DelegationRepository
+	Users       Users
+	Mailer      Mailer
+}
+
+type Config struct {
+	// URL is the page invitations point to; the token is added as the
+	// "token" query parameter.
+	URL       string
+	InviteTTL time.Duration
+}
+
+// Service manages delegations between accounts. A delegate acts for the
+// grantor with a principal that carries the grantor as UserID and the
+// delegate as ActorID, so ownership checks apply to the grantor's records
+// while the delegate stays recorded as the one who acted.
+type Service struct {
+	delegations DelegationRepository
+	users       Users
+	mailer      Mailer
+	url         string
+	inviteTTL   time.Duration
+}
+
+func NewService(deps Dependencies, cfg Config) *Service {
+	return &Service{
+		delegations: deps.Delegations,
+		users:       deps.Users,
+		mailer:      deps.Mailer,
+		url:         cfg.URL,
+		inviteTTL:   cfg.InviteTTL,
+	}
+}
+
+func NewServiceFromConfig(deps Dependencies, cfg *config.Config) *Service {
+	return NewService(deps, Config{
+		URL:       cfg.Sharing.Delegations.URL,
+		InviteTTL: cfg.Sharing.Delegations.InviteTTL,
+	})
+}
+
+// Invite mails an invitation to act on behalf of the principal's account.
+// Delegates cannot pass on access they were delegated.
+func (s *Service) Invite(ctx context.Context, principal models.Principal, data models.DelegationInvite) (*models.Delegation, error) {
+	if principal.IsDelegated() {
+		return nil, apperrors.ErrAccessDenied
+	}
+
+	now := time.Now()
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(now) {
+		return nil, apperrors.ErrInvalidDelegation
+	}
+
+	grantor, err := s.users.GetProfile(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(grantor.Email, data.Email) {
+		return nil, apperrors.ErrInvalidDelegation
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, err
+	}
+	link, err := withToken(s.url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	inviteExpiresAt := now.Add(s.inviteTTL)
+	delegation := &models.Delegation{
+		GrantorID:       principal.UserID,
+		Email:           data.Email,
+		Permission:      data.Permission,
+		Status:          models.DelegationPending,
+		TokenHash:       hashInviteToken(token),
+		InviteExpiresAt: &inviteExpiresAt,
+		ExpiresAt:       data.ExpiresAt,
+		CreatedAt:       now,
+	}
+	if err := s.delegations.Create(ctx, delegation); err != nil {
+		return nil, err
+	}
+
+	access := "view your medical records"
+	if data.Permission == models.DelegationManage {
+		access = "view and manage your medical records"
+	}
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      data.Email,
+		Subject: fmt.Sprintf("%s invited you to MedDoc", grantor.Name),
+		Body: fmt.Sprintf(
+			"Hello,\n\n%s (%s) invited you to %s on their behalf. "+
+				"Sign in with this email address and open the link below to accept. It expires in %s.\n\n%s\n\n"+
+				"If you do not know the sender, you can ignore this email.\n",
+			grantor.Name, grantor.Email, access, s.inviteTTL, link,
+		),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// Accept makes userID the delegate of a pending invitation. The invitation
+// has to have been sent to the user's email address.
+func (s *Service) Accept(ctx context.Context, userID, token string) (*models.Delegation, error) {
+	delegation, err := s.delegations.GetInvitation(ctx, hashInviteToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, delegation.Email) || delegation.GrantorID == userID {
+		return nil, apperrors.ErrDelegationNotFound
+	}
+
+	now := time.Now()
+	if err := s.delegations.Accept(ctx, delegation.ID, userID, now); err != nil {
+		return nil, err
+	}
+
+	delegation.DelegateID = userID
+	delegation.Status = models.DelegationActive
+	delegation.AcceptedAt = &now
+	delegation.InviteExpiresAt = nil
+	return delegation, nil
+}
+
+// ListGranted returns the delegations and invitations the user gave.
+func (s *Service) ListGranted(ctx context.Context, userID string) ([]*models.Delegation, error) {
+	return s.delegations.GetByGrantorID(ctx, userID)
+}
+
+// ListReceived returns the delegations the user holds.
+func (s *Service) ListReceived(ctx context.Context, userID string) ([]*models.Delegation, error) {
+	return s.delegations.GetByDelegateID(ctx, userID)
+}
+
+// Revoke ends a delegation. Grantors and delegates may both end it.
+func (s *Service) Revoke(ctx context.Context, id, userID string) error {
+	return s.delegations.Delete(ctx, id, userID)
+}
+
+// ActAs returns the principal the delegate acts with on behalf of
+// grantorID. It keeps the delegate's session and token but none of their
+// roles, which belong to the delegate rather than the grantor.
+func (s *Service) ActAs(ctx context.Context, principal models.Principal, grantorID string) (models.Principal, error) {
+	if principal.IsDelegated() || grantorID == principal.UserID {
+		return models.Principal{}, apperrors.ErrAccessDenied
+	}
+
+	delegation, err := s.delegations.GetActive(ctx, grantorID, principal.UserID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrDelegationNotFound) {
+			return models.Principal{}, apperrors.ErrAccessDenied
+		}
+		return models.Principal{}, err
+	}
+	if !delegation.Active(time.Now()) {
+		return models.Principal{}, apperrors.ErrAccessDenied
+	}
+
+	acting := principal
+	acting.UserID = grantorID
+	acting.ActorID = principal.UserID
+	acting.Delegation = delegation.Permission
+	acting.Roles = nil
+	return acting, nil
+}
+
+// DeleteUserDelegations removes every delegation the user gave or holds,
+// for account erasure.
+func (s *Service) DeleteUserDelegations(ctx context.Context, userID string) error {
+	return s.delegations.DeleteAllForUser(ctx, userID)
+}
+
+func newInviteToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func withToken(rawURL, token string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
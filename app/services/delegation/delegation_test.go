@@ -0,0 +1,232 @@
+package delegation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type mocks struct {
+	delegations *MockDelegationRepository
+	users       *MockUsers
+	mailer      *MockMailer
+}
+
+func newTestService(t *testing.T) (*Service, mocks) {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		delegations: NewMockDelegationRepository(ctrl),
+		users:       NewMockUsers(ctrl),
+		mailer:      NewMockMailer(ctrl),
+	}
+	service := NewService(Dependencies{
+		Delegations: m.delegations,
+		Users:       m.users,
+		Mailer:      m.mailer,
+	}, Config{
+		URL:       "http://localhost/accept-delegation",
+		InviteTTL: 24 * time.Hour,
+	})
+	return service, m
+}
+
+func TestService_Invite(t *testing.T) {
+	grantor := &models.User{ID: "user-123", Email: "parent@example.com", Name: "Pat Parent"}
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		principal     models.Principal
+		data          models.DelegationInvite
+		setupMocks    func(m mocks)
+		expectedError error
+	}{
+		{
+			name:      "mails an invitation",
+			principal: models.Principal{UserID: "user-123"},
+			data:      models.DelegationInvite{Email: "child@example.com", Permission: models.DelegationManage},
+			setupMocks: func(m mocks) {
+				m.users.EXPECT().GetProfile(gomock.Any(), "user-123").Return(grantor, nil)
+				m.delegations.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, delegation *models.Delegation) error {
+						assert.Equal(t, "user-123", delegation.GrantorID)
+						assert.Equal(t, models.DelegationPending, delegation.Status)
+						assert.NotEmpty(t, delegation.TokenHash)
+						require.NotNil(t, delegation.InviteExpiresAt)
+						delegation.ID = "delegation-1"
+						return nil
+					})
+				m.mailer.EXPECT().
+					Send(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+						assert.Equal(t, "child@example.com", msg.To)
+						assert.Contains(t, msg.Body, "http://localhost/accept-delegation?token=")
+						return nil
+					})
+			},
+		},
+		{
+			name:      "own email address",
+			principal: models.Principal{UserID: "user-123"},
+			data:      models.DelegationInvite{Email: "Parent@Example.com", Permission: models.DelegationRead},
+			setupMocks: func(m mocks) {
+				m.users.EXPECT().GetProfile(gomock.Any(), "user-123").Return(grantor, nil)
+			},
+			expectedError: errors.ErrInvalidDelegation,
+		},
+		{
+			name:          "expiry in the past",
+			principal:     models.Principal{UserID: "user-123"},
+			data:          models.DelegationInvite{Email: "child@example.com", Permission: models.DelegationRead, ExpiresAt: &past},
+			setupMocks:    func(m mocks) {},
+			expectedError: errors.ErrInvalidDelegation,
+		},
+		{
+			name:          "delegates cannot invite",
+			principal:     models.Principal{UserID: "user-123", ActorID: "user-456"},
+			data:          models.DelegationInvite{Email: "child@example.com", Permission: models.DelegationRead},
+			setupMocks:    func(m mocks) {},
+			expectedError: errors.ErrAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t)
+			tt.setupMocks(m)
+
+			delegation, err := service.Invite(context.Background(), tt.principal, tt.data)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "delegation-1", delegation.ID)
+		})
+	}
+}
+
+func TestService_Accept(t *testing.T) {
+	invitation := func() *models.Delegation {
+		return &models.Delegation{ID: "delegation-1", GrantorID: "user-123", Email: "child@example.com", Status: models.DelegationPending}
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(m mocks)
+		expectedError error
+	}{
+		{
+			name: "accepts an invitation sent to the user",
+			setupMocks: func(m mocks) {
+				m.delegations.EXPECT().GetInvitation(gomock.Any(), hashInviteToken("token")).Return(invitation(), nil)
+				m.users.EXPECT().GetProfile(gomock.Any(), "user-456").Return(&models.User{ID: "user-456", Email: "Child@example.com"}, nil)
+				m.delegations.EXPECT().Accept(gomock.Any(), "delegation-1", "user-456", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "invitation sent to someone else",
+			setupMocks: func(m mocks) {
+				m.delegations.EXPECT().GetInvitation(gomock.Any(), hashInviteToken("token")).Return(invitation(), nil)
+				m.users.EXPECT().GetProfile(gomock.Any(), "user-456").Return(&models.User{ID: "user-456", Email: "other@example.com"}, nil)
+			},
+			expectedError: errors.ErrDelegationNotFound,
+		},
+		{
+			name: "unknown or expired token",
+			setupMocks: func(m mocks) {
+				m.delegations.EXPECT().GetInvitation(gomock.Any(), hashInviteToken("token")).Return(nil, errors.ErrDelegationNotFound)
+			},
+			expectedError: errors.ErrDelegationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t)
+			tt.setupMocks(m)
+
+			delegation, err := service.Accept(context.Background(), "user-456", "token")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-456", delegation.DelegateID)
+			assert.Equal(t, models.DelegationActive, delegation.Status)
+		})
+	}
+}
+
+func TestService_ActAs(t *testing.T) {
+	delegate := models.Principal{UserID: "user-456", SessionID: "session-1", Roles: []string{models.RoleAdmin}}
+	expired := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		principal     models.Principal
+		setupMocks    func(m mocks)
+		expectedError error
+	}{
+		{
+			name:      "active delegation",
+			principal: delegate,
+			setupMocks: func(m mocks) {
+				m.delegations.EXPECT().GetActive(gomock.Any(), "user-123", "user-456").
+					Return(&models.Delegation{Status: models.DelegationActive, Permission: models.DelegationRead}, nil)
+			},
+		},
+		{
+			name:      "no delegation",
+			principal: delegate,
+			setupMocks: func(m mocks) {
+				m.delegations.EXPECT().GetActive(gomock.Any(), "user-123", "user-456").Return(nil, errors.ErrDelegationNotFound)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "expired delegation",
+			principal: delegate,
+			setupMocks: func(m mocks) {
+				m.delegations.EXPECT().GetActive(gomock.Any(), "user-123", "user-456").
+					Return(&models.Delegation{Status: models.DelegationActive, ExpiresAt: &expired}, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:          "already acting for someone",
+			principal:     models.Principal{UserID: "user-789", ActorID: "user-456"},
+			setupMocks:    func(m mocks) {},
+			expectedError: errors.ErrAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t)
+			tt.setupMocks(m)
+
+			acting, err := service.ActAs(context.Background(), tt.principal, "user-123")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-123", acting.UserID)
+			assert.Equal(t, "user-456", acting.ActorID)
+			assert.Equal(t, "user-456", acting.Actor())
+			assert.Equal(t, models.DelegationRead, acting.Delegation)
+			assert.Equal(t, "session-1", acting.SessionID)
+			assert.Empty(t, acting.Roles)
+		})
+	}
+}
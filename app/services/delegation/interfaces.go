@@ -0,0 +1,29 @@
+package delegation
+
+import (
+	"context"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type DelegationRepository interface {
+	Create(ctx context.Context, delegation *models.Delegation) error
+	GetInvitation(ctx context.Context, tokenHash string) (*models.Delegation, error)
+	Accept(ctx context.Context, id, delegateID string, acceptedAt time.Time) error
+	GetActive(ctx context.Context, grantorID, delegateID string) (*models.Delegation, error)
+	GetByGrantorID(ctx context.Context, grantorID string) ([]*models.Delegation, error)
+	GetByDelegateID(ctx context.Context, delegateID string) ([]*models.Delegation, error)
+	Delete(ctx context.Context, id, userID string) error
+	DeleteAllForUser(ctx context.Context, userID string) error
+}
+
+// Users looks up the accounts taking part in a delegation.
+type Users interface {
+	GetProfile(ctx context.Context, userID string) (*models.User, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+}
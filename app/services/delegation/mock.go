@@ -0,0 +1,229 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/delegation/interfaces.go
+
+// Package delegation is a generated GoMock package.
+package delegation
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/gruzdev-dev/meddoc/app/models"
+	mailer "github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// MockDelegationRepository is a mock of DelegationRepository interface.
+type MockDelegationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDelegationRepositoryMockRecorder
+}
+
+// MockDelegationRepositoryMockRecorder is the mock recorder for MockDelegationRepository.
+type MockDelegationRepositoryMockRecorder struct {
+	mock *MockDelegationRepository
+}
+
+// NewMockDelegationRepository creates a new mock instance.
+func NewMockDelegationRepository(ctrl *gomock.Controller) *MockDelegationRepository {
+	mock := &MockDelegationRepository{ctrl: ctrl}
+	mock.recorder = &MockDelegationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDelegationRepository) EXPECT() *MockDelegationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockDelegationRepository) Accept(ctx context.Context, id, delegateID string, acceptedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, id, delegateID, acceptedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockDelegationRepositoryMockRecorder) Accept(ctx, id, delegateID, acceptedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockDelegationRepository)(nil).Accept), ctx, id, delegateID, acceptedAt)
+}
+
+// Create mocks base method.
+func (m *MockDelegationRepository) Create(ctx context.Context, delegation *models.Delegation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, delegation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDelegationRepositoryMockRecorder) Create(ctx, delegation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDelegationRepository)(nil).Create), ctx, delegation)
+}
+
+// Delete mocks base method.
+func (m *MockDelegationRepository) Delete(ctx context.Context, id, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDelegationRepositoryMockRecorder) Delete(ctx, id, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDelegationRepository)(nil).Delete), ctx, id, userID)
+}
+
+// DeleteAllForUser mocks base method.
+func (m *MockDelegationRepository) DeleteAllForUser(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllForUser indicates an expected call of DeleteAllForUser.
+func (mr *MockDelegationRepositoryMockRecorder) DeleteAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllForUser", reflect.TypeOf((*MockDelegationRepository)(nil).DeleteAllForUser), ctx, userID)
+}
+
+// GetActive mocks base method.
+func (m *MockDelegationRepository) GetActive(ctx context.Context, grantorID, delegateID string) (*models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", ctx, grantorID, delegateID)
+	ret0, _ := ret[0].(*models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActive indicates an expected call of GetActive.
+func (mr *MockDelegationRepositoryMockRecorder) GetActive(ctx, grantorID, delegateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockDelegationRepository)(nil).GetActive), ctx, grantorID, delegateID)
+}
+
+// GetByDelegateID mocks base method.
+func (m *MockDelegationRepository) GetByDelegateID(ctx context.Context, delegateID string) ([]*models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByDelegateID", ctx, delegateID)
+	ret0, _ := ret[0].([]*models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByDelegateID indicates an expected call of GetByDelegateID.
+func (mr *MockDelegationRepositoryMockRecorder) GetByDelegateID(ctx, delegateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDelegateID", reflect.TypeOf((*MockDelegationRepository)(nil).GetByDelegateID), ctx, delegateID)
+}
+
+// GetByGrantorID mocks base method.
+func (m *MockDelegationRepository) GetByGrantorID(ctx context.Context, grantorID string) ([]*models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByGrantorID", ctx, grantorID)
+	ret0, _ := ret[0].([]*models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByGrantorID indicates an expected call of GetByGrantorID.
+func (mr *MockDelegationRepositoryMockRecorder) GetByGrantorID(ctx, grantorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByGrantorID", reflect.TypeOf((*MockDelegationRepository)(nil).GetByGrantorID), ctx, grantorID)
+}
+
+// GetInvitation mocks base method.
+func (m *MockDelegationRepository) GetInvitation(ctx context.Context, tokenHash string) (*models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitation", ctx, tokenHash)
+	ret0, _ := ret[0].(*models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitation indicates an expected call of GetInvitation.
+func (mr *MockDelegationRepositoryMockRecorder) GetInvitation(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitation", reflect.TypeOf((*MockDelegationRepository)(nil).GetInvitation), ctx, tokenHash)
+}
+
+// MockUsers is a mock of Users interface.
+type MockUsers struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersMockRecorder
+}
+
+// MockUsersMockRecorder is the mock recorder for MockUsers.
+type MockUsersMockRecorder struct {
+	mock *MockUsers
+}
+
+// NewMockUsers creates a new mock instance.
+func NewMockUsers(ctrl *gomock.Controller) *MockUsers {
+	mock := &MockUsers{ctrl: ctrl}
+	mock.recorder = &MockUsersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
+	return m.recorder
+}
+
+// GetProfile mocks base method.
+func (m *MockUsers) GetProfile(ctx context.Context, userID string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUsersMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUsers)(nil).GetProfile), ctx, userID)
+}
+
+// MockMailer is a mock of Mailer interface.
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+}
+
+// MockMailerMockRecorder is the mock recorder for MockMailer.
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+// NewMockMailer creates a new mock instance.
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailerMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), ctx, msg)
+}
@@ -0,0 +1,117 @@
+package document
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// minJustificationLength keeps clinicians from opening emergency access
+// with a token word; whitespace does not count.
+const minJustificationLength = 20
+
+// BreakGlass opens read access to every document of a patient for a
+// clinician who has not been granted them. It lasts the configured time and
+// the patient is mailed right away. Only clinicians signed in themselves may
+// break glass, not delegates acting for them or personal access tokens.
+func (s *Service) BreakGlass(ctx context.Context, principal models.Principal, req models.BreakGlassRequest) (_ *models.BreakGlassAccess, err error) {
+	event := models.AuditEvent{Action: models.AuditBreakGlass, ActorID: principal.Actor(), OwnerID: req.PatientID}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	if !principal.CanBreakGlass() {
+		return nil, errors.ErrAccessDenied
+	}
+	justification := strings.TrimSpace(req.Justification)
+	if utf8.RuneCountInString(justification) < minJustificationLength {
+		return nil, errors.ErrJustificationRequired
+	}
+
+	patient, err := s.users.GetProfile(ctx, req.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	clinician, err := s.users.GetProfile(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	access := &models.BreakGlassAccess{
+		ClinicianID:   principal.UserID,
+		PatientID:     patient.ID,
+		Justification: justification,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.breakGlassTTL),
+	}
+	if err := s.breakGlass.Create(ctx, access); err != nil {
+		return nil, err
+	}
+	event.ResourceID = access.ID
+	event.BreakGlassID = access.ID
+
+	// The access stays open when the mail fails: holding up an emergency
+	// would be worse, and the opening is in the audit log either way.
+	if err := s.notifyBreakGlass(ctx, patient, clinician, access); err != nil {
+		logger.Error("failed to notify patient of break-glass access", err,
+			"break_glass_id", access.ID,
+			"patient_id", patient.ID,
+		)
+	}
+	return access, nil
+}
+
+// GetPatientDocuments lists the documents of a patient to a clinician with
+// open break-glass access to them, a page at a time like GetUserDocuments.
+func (s *Service) GetPatientDocuments(ctx context.Context, principal models.Principal, patientID string, filter models.DocumentFilter) (_ *models.DocumentPage, err error) {
+	event := auditEvent(models.AuditDocumentList, "", principal)
+	event.OwnerID = patientID
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	access, err := s.activeBreakGlass(ctx, principal, patientID)
+	if err != nil {
+		return nil, err
+	}
+	event.BreakGlassID = access.ID
+	return s.listDocuments(ctx, patientID, filter)
+}
+
+// activeBreakGlass returns the open break-glass access of the principal to
+// the documents of patientID, or ErrAccessDenied.
+func (s *Service) activeBreakGlass(ctx context.Context, principal models.Principal, patientID string) (*models.BreakGlassAccess, error) {
+	if !principal.CanBreakGlass() {
+		return nil, errors.ErrAccessDenied
+	}
+
+	access, err := s.breakGlass.GetActive(ctx, principal.UserID, patientID, time.Now())
+	if stderrors.Is(err, errors.ErrBreakGlassNotFound) {
+		return nil, errors.ErrAccessDenied
+	}
+	if err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+func (s *Service) notifyBreakGlass(ctx context.Context, patient, clinician *models.User, access *models.BreakGlassAccess) error {
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      patient.Email,
+		Subject: "Emergency access to your MedDoc records",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\n%s (%s) opened emergency access to your medical documents at %s. "+
+				"The access ends at %s.\n\nReason given:\n%s\n\n"+
+				"Every document they open is listed in your audit log. If you did not expect this, "+
+				"please contact us.\n",
+			patient.Name, clinician.Name, clinician.Email,
+			access.CreatedAt.UTC().Format(time.RFC1123), access.ExpiresAt.UTC().Format(time.RFC1123),
+			access.Justification,
+		),
+	})
+}
@@ -0,0 +1,255 @@
+package document
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+type breakGlassMocks struct {
+	docs       *MockDocumentRepository
+	grants     *policy.MockGrants
+	breakGlass *MockBreakGlassRepository
+	users      *MockUsers
+	mailer     *MockMailer
+	audit      *MockAuditor
+}
+
+func newBreakGlassService(t *testing.T) (*Service, breakGlassMocks) {
+	ctrl := gomock.NewController(t)
+	mocks := breakGlassMocks{
+		docs:       NewMockDocumentRepository(ctrl),
+		grants:     policy.NewMockGrants(ctrl),
+		breakGlass: NewMockBreakGlassRepository(ctrl),
+		users:      NewMockUsers(ctrl),
+		mailer:     NewMockMailer(ctrl),
+		audit:      NewMockAuditor(ctrl),
+	}
+	service := NewService(Dependencies{
+		Documents:  mocks.docs,
+		Files:      NewMockFileDeleter(ctrl),
+		Profiles:   NewMockProfileResolver(ctrl),
+		Policy:     policy.New(policy.DefaultPermissions, mocks.grants),
+		Audit:      mocks.audit,
+		BreakGlass: mocks.breakGlass,
+		Users:      mocks.users,
+		Mailer:     mocks.mailer,
+	}, Config{BreakGlassTTL: time.Hour})
+	return service, mocks
+}
+
+func TestService_BreakGlass(t *testing.T) {
+	clinician := models.Principal{UserID: "clinician-1", SessionID: "session-1", Roles: []string{models.RolePatient, models.RoleClinician}}
+	patient := &models.User{ID: "patient-1", Email: "patient@example.com", Name: "Pat Patient"}
+	justification := "Unconscious patient in A&E, need medication history"
+
+	tests := []struct {
+		name          string
+		principal     models.Principal
+		justification string
+		mockSetup     func(m breakGlassMocks)
+		expectedError error
+	}{
+		{
+			name:          "clinician opens access",
+			principal:     clinician,
+			justification: justification,
+			mockSetup: func(m breakGlassMocks) {
+				m.users.EXPECT().GetProfile(gomock.Any(), "patient-1").Return(patient, nil)
+				m.users.EXPECT().GetProfile(gomock.Any(), "clinician-1").Return(&models.User{ID: "clinician-1", Name: "Dr Casey"}, nil)
+				m.breakGlass.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, access *models.BreakGlassAccess) error {
+						assert.Equal(t, "clinician-1", access.ClinicianID)
+						assert.Equal(t, "patient-1", access.PatientID)
+						assert.WithinDuration(t, time.Now().Add(time.Hour), access.ExpiresAt, time.Minute)
+						access.ID = "bg-1"
+						return nil
+					})
+				m.mailer.EXPECT().
+					Send(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+						assert.Equal(t, "patient@example.com", msg.To)
+						assert.Contains(t, msg.Body, "Dr Casey")
+						assert.Contains(t, msg.Body, justification)
+						return nil
+					})
+				m.audit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+					Action:       models.AuditBreakGlass,
+					ActorID:      "clinician-1",
+					OwnerID:      "patient-1",
+					ResourceID:   "bg-1",
+					BreakGlassID: "bg-1",
+				}, nil)
+			},
+		},
+		{
+			name:          "failed notification keeps the access open",
+			principal:     clinician,
+			justification: justification,
+			mockSetup: func(m breakGlassMocks) {
+				m.users.EXPECT().GetProfile(gomock.Any(), "patient-1").Return(patient, nil)
+				m.users.EXPECT().GetProfile(gomock.Any(), "clinician-1").Return(&models.User{ID: "clinician-1"}, nil)
+				m.breakGlass.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(assert.AnError)
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), nil)
+			},
+		},
+		{
+			name:          "not a clinician",
+			principal:     models.Principal{UserID: "user-1", Roles: []string{models.RolePatient}},
+			justification: justification,
+			mockSetup: func(m breakGlassMocks) {
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrAccessDenied)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:          "delegate acting for a clinician",
+			principal:     models.Principal{UserID: "clinician-1", ActorID: "delegate-1", Delegation: models.DelegationManage, Roles: []string{models.RoleClinician}},
+			justification: justification,
+			mockSetup: func(m breakGlassMocks) {
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrAccessDenied)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:          "personal access token",
+			principal:     models.Principal{UserID: "clinician-1", AccessTokenID: "token-1", Roles: []string{models.RoleClinician}},
+			justification: justification,
+			mockSetup: func(m breakGlassMocks) {
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrAccessDenied)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:          "justification padded with whitespace",
+			principal:     clinician,
+			justification: "emergency" + "                    ",
+			mockSetup: func(m breakGlassMocks) {
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrJustificationRequired)
+			},
+			expectedError: errors.ErrJustificationRequired,
+		},
+		{
+			name:          "unknown patient",
+			principal:     clinician,
+			justification: justification,
+			mockSetup: func(m breakGlassMocks) {
+				m.users.EXPECT().GetProfile(gomock.Any(), "patient-1").Return(nil, errors.ErrUserNotFound)
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrUserNotFound)
+			},
+			expectedError: errors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mocks := newBreakGlassService(t)
+			tt.mockSetup(mocks)
+
+			access, err := service.BreakGlass(context.Background(), tt.principal, models.BreakGlassRequest{
+				PatientID:     "patient-1",
+				Justification: tt.justification,
+			})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, access)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, justification, access.Justification)
+		})
+	}
+}
+
+func TestService_ReadWithBreakGlass(t *testing.T) {
+	clinician := models.Principal{UserID: "clinician-1", SessionID: "session-1", Roles: []string{models.RoleClinician}}
+	doc := &models.Document{ID: "doc-1", UserID: "patient-1"}
+	access := &models.BreakGlassAccess{ID: "bg-1", ClinicianID: "clinician-1", PatientID: "patient-1"}
+
+	t.Run("reads with open access are flagged", func(t *testing.T) {
+		service, m := newBreakGlassService(t)
+		m.docs.EXPECT().GetByID(gomock.Any(), "doc-1").Return(doc, nil)
+		m.grants.EXPECT().DocumentPermission(gomock.Any(), "doc-1", "clinician-1").Return("", nil)
+		m.breakGlass.EXPECT().GetActive(gomock.Any(), "clinician-1", "patient-1", gomock.Any()).Return(access, nil)
+		m.audit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+			Action:       models.AuditDocumentRead,
+			ResourceID:   "doc-1",
+			ActorID:      "clinician-1",
+			OwnerID:      "patient-1",
+			BreakGlassID: "bg-1",
+		}, nil)
+
+		got, err := service.GetDocument(context.Background(), "doc-1", clinician)
+		require.NoError(t, err)
+		assert.Equal(t, doc, got)
+	})
+
+	t.Run("no open access", func(t *testing.T) {
+		service, m := newBreakGlassService(t)
+		m.docs.EXPECT().GetByID(gomock.Any(), "doc-1").Return(doc, nil)
+		m.grants.EXPECT().DocumentPermission(gomock.Any(), "doc-1", "clinician-1").Return("", nil)
+		m.breakGlass.EXPECT().GetActive(gomock.Any(), "clinician-1", "patient-1", gomock.Any()).Return(nil, errors.ErrBreakGlassNotFound)
+		m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrAccessDenied)
+
+		_, err := service.GetDocument(context.Background(), "doc-1", clinician)
+		assert.ErrorIs(t, err, errors.ErrAccessDenied)
+	})
+
+	t.Run("break glass does not allow changes", func(t *testing.T) {
+		service, m := newBreakGlassService(t)
+		m.docs.EXPECT().GetByID(gomock.Any(), "doc-1").Return(doc, nil)
+		m.grants.EXPECT().DocumentPermission(gomock.Any(), "doc-1", "clinician-1").Return("", nil)
+		m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrAccessDenied)
+
+		title := "Changed"
+		_, err := service.UpdateDocument(context.Background(), "doc-1", models.DocumentUpdate{Title: &title}, clinician)
+		assert.ErrorIs(t, err, errors.ErrAccessDenied)
+	})
+}
+
+func TestService_GetPatientDocuments(t *testing.T) {
+	clinician := models.Principal{UserID: "clinician-1", SessionID: "session-1", Roles: []string{models.RoleClinician}}
+	page := &models.DocumentPage{Documents: []*models.Document{{ID: "doc-1", UserID: "patient-1"}}, Total: 1}
+
+	t.Run("with open access", func(t *testing.T) {
+		service, m := newBreakGlassService(t)
+		m.breakGlass.EXPECT().
+			GetActive(gomock.Any(), "clinician-1", "patient-1", gomock.Any()).
+			Return(&models.BreakGlassAccess{ID: "bg-1"}, nil)
+		m.docs.EXPECT().
+			GetByUserID(gomock.Any(), "patient-1", models.DocumentFilter{Sort: models.DocumentSortDate, Limit: 50}).
+			Return(page, nil)
+		m.audit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+			Action:       models.AuditDocumentList,
+			ActorID:      "clinician-1",
+			OwnerID:      "patient-1",
+			BreakGlassID: "bg-1",
+		}, nil)
+
+		got, err := service.GetPatientDocuments(context.Background(), clinician, "patient-1", models.DocumentFilter{Sort: models.DocumentSortDate})
+		require.NoError(t, err)
+		assert.Equal(t, page, got)
+	})
+
+	t.Run("without access", func(t *testing.T) {
+		service, m := newBreakGlassService(t)
+		m.breakGlass.EXPECT().
+			GetActive(gomock.Any(), "clinician-1", "patient-1", gomock.Any()).
+			Return(nil, errors.ErrBreakGlassNotFound)
+		m.audit.EXPECT().Record(gomock.Any(), gomock.Any(), errors.ErrAccessDenied)
+
+		_, err := service.GetPatientDocuments(context.Background(), clinician, "patient-1", models.DocumentFilter{})
+		assert.ErrorIs(t, err, errors.ErrAccessDenied)
+	})
+}
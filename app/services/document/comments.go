@@ -0,0 +1,46 @@
+package document
+
+import (
+	"context"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+// AddComment leaves a comment on a document. The owner and users with a
+// comment or edit grant may comment; read grants and break-glass access
+// only let users read the comments.
+func (s *Service) AddComment(ctx context.Context, documentID string, data models.CommentCreation, principal models.Principal) (_ *models.Comment, err error) {
+	event := auditEvent(models.AuditDocumentComment, documentID, principal)
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	doc, err := s.authorizedDocument(ctx, documentID, principal, policy.ActionComment, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		DocumentID: doc.ID,
+		OwnerID:    doc.UserID,
+		AuthorID:   principal.Actor(),
+		Text:       data.Text,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetComments returns the comments on a document, oldest first, to anyone
+// who may read the document. It is audited as a read of the document.
+func (s *Service) GetComments(ctx context.Context, documentID string, principal models.Principal) (_ []*models.Comment, err error) {
+	event := auditEvent(models.AuditDocumentRead, documentID, principal)
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	if _, err := s.authorizedDocument(ctx, documentID, principal, policy.ActionRead, &event); err != nil {
+		return nil, err
+	}
+	return s.comments.GetByDocumentID(ctx, documentID)
+}
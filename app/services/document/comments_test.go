@@ -0,0 +1,162 @@
+package document
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+func TestService_AddComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockDocumentRepository(ctrl)
+	mockComments := NewMockCommentRepository(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Comments:  mockComments,
+		Files:     NewMockFileDeleter(ctrl),
+		Profiles:  NewMockProfileResolver(ctrl),
+		Policy:    policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
+
+	doc := &models.Document{ID: "doc-123", UserID: "user-123"}
+
+	tests := []struct {
+		name          string
+		principal     models.Principal
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:      "owner",
+			principal: models.Principal{UserID: "user-123"},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockComments.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:      "comment grant",
+			principal: models.Principal{UserID: "grantee"},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "grantee").Return(models.GrantComment, nil)
+				mockComments.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:      "edit grant",
+			principal: models.Principal{UserID: "grantee"},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "grantee").Return(models.GrantEdit, nil)
+				mockComments.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:      "read grant may not comment",
+			principal: models.Principal{UserID: "grantee"},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "grantee").Return(models.GrantRead, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "document not found",
+			principal: models.Principal{UserID: "user-123"},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(nil, errors.ErrDocumentNotFound)
+			},
+			expectedError: errors.ErrDocumentNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			comment, err := service.AddComment(context.Background(), "doc-123", models.CommentCreation{Text: "Please repeat in 3 months"}, tt.principal)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, comment)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "doc-123", comment.DocumentID)
+			assert.Equal(t, "user-123", comment.OwnerID)
+			assert.Equal(t, tt.principal.UserID, comment.AuthorID)
+			assert.Equal(t, "Please repeat in 3 months", comment.Text)
+			assert.False(t, comment.CreatedAt.IsZero())
+		})
+	}
+}
+
+func TestService_GetComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockDocumentRepository(ctrl)
+	mockComments := NewMockCommentRepository(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Comments:  mockComments,
+		Files:     NewMockFileDeleter(ctrl),
+		Profiles:  NewMockProfileResolver(ctrl),
+		Policy:    policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
+
+	doc := &models.Document{ID: "doc-123", UserID: "user-123"}
+	comments := []*models.Comment{{ID: "comment-1", DocumentID: "doc-123", AuthorID: "grantee", Text: "Noted"}}
+
+	t.Run("read grant may read comments", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+		mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "grantee").Return(models.GrantRead, nil)
+		mockComments.EXPECT().GetByDocumentID(gomock.Any(), "doc-123").Return(comments, nil)
+
+		got, err := service.GetComments(context.Background(), "doc-123", models.Principal{UserID: "grantee"})
+		require.NoError(t, err)
+		assert.Equal(t, comments, got)
+	})
+
+	t.Run("without a grant", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+		mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "stranger").Return("", nil)
+
+		_, err := service.GetComments(context.Background(), "doc-123", models.Principal{UserID: "stranger"})
+		assert.ErrorIs(t, err, errors.ErrAccessDenied)
+	})
+}
+
+func TestService_DeleteUserDocuments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockDocumentRepository(ctrl)
+	mockComments := NewMockCommentRepository(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Comments:  mockComments,
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
+
+	gomock.InOrder(
+		mockComments.EXPECT().DeleteByUserID(gomock.Any(), "user-123").Return(nil),
+		mockRepo.EXPECT().DeleteByUserID(gomock.Any(), "user-123").Return(int64(2), nil),
+	)
+
+	deleted, err := service.DeleteUserDocuments(context.Background(), "user-123")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+}
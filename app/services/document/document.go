@@ -2,23 +2,84 @@ package document
 
 import (
 	"context"
+	stderrors "errors"
 	"time"
 
 	"github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
 )
 
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type Dependencies struct {
+	Documents  DocumentRepository
+	Comments   CommentRepository
+	Grants     GrantRepository
+	ShareLinks ShareLinkRepository
+	Files      FileDeleter
+	Profiles   ProfileResolver
+	Policy     Authorizer
+	Audit      Auditor
+	BreakGlass BreakGlassRepository
+	Users      Users
+	Mailer     Mailer
+}
+
+type Config struct {
+	// BreakGlassTTL is how long emergency access to a patient's documents
+	// lasts.
+	BreakGlassTTL time.Duration
+}
+
 type Service struct {
-	repo DocumentRepository
+	repo          DocumentRepository
+	comments      CommentRepository
+	grants        GrantRepository
+	shareLinks    ShareLinkRepository
+	files         FileDeleter
+	profiles      ProfileResolver
+	policy        Authorizer
+	audit         Auditor
+	breakGlass    BreakGlassRepository
+	users         Users
+	mailer        Mailer
+	breakGlassTTL time.Duration
 }
 
-func NewService(repo DocumentRepository) *Service {
+func NewService(deps Dependencies, cfg Config) *Service {
 	return &Service{
-		repo: repo,
+		repo:          deps.Documents,
+		comments:      deps.Comments,
+		grants:        deps.Grants,
+		shareLinks:    deps.ShareLinks,
+		files:         deps.Files,
+		profiles:      deps.Profiles,
+		policy:        deps.Policy,
+		audit:         deps.Audit,
+		breakGlass:    deps.BreakGlass,
+		users:         deps.Users,
+		mailer:        deps.Mailer,
+		breakGlassTTL: cfg.BreakGlassTTL,
 	}
 }
 
-func (s *Service) CreateDocument(ctx context.Context, data models.DocumentCreation, userID string) (*models.Document, error) {
+// CreateDocument files a new document of the principal's user under the
+// profile given in data, or under the default profile of the user.
+func (s *Service) CreateDocument(ctx context.Context, data models.DocumentCreation, principal models.Principal) (_ *models.Document, err error) {
+	event := auditEvent(models.AuditDocumentCreate, "", principal)
+	event.OwnerID = principal.UserID
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	profile, err := s.profiles.Resolve(ctx, principal.UserID, data.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
 	doc := &models.Document{
 		Title:       data.Title,
 		Description: data.Description,
@@ -27,56 +88,177 @@ func (s *Service) CreateDocument(ctx context.Context, data models.DocumentCreati
 		Category:    data.Category,
 		Priority:    data.Priority,
 		Content:     data.Content,
-		UserID:      userID,
+		UserID:      principal.UserID,
+		ProfileID:   profile.ID,
+		CreatedBy:   principal.Actor(),
+		UpdatedBy:   principal.Actor(),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 	if err := s.repo.Create(ctx, doc); err != nil {
 		return nil, err
 	}
+	event.ResourceID = doc.ID
 	return doc, nil
 }
 
-func (s *Service) GetDocument(ctx context.Context, id string, userID string) (*models.Document, error) {
-	doc, err := s.repo.GetByID(ctx, id)
+func (s *Service) GetDocument(ctx context.Context, id string, principal models.Principal) (_ *models.Document, err error) {
+	event := auditEvent(models.AuditDocumentRead, id, principal)
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	return s.authorizedDocument(ctx, id, principal, policy.ActionRead, &event)
+}
+
+// GetUserDocuments returns the page of the documents of the principal's user
+// that filter selects. A profile in the filter has to belong to the user.
+func (s *Service) GetUserDocuments(ctx context.Context, principal models.Principal, filter models.DocumentFilter) (_ *models.DocumentPage, err error) {
+	event := auditEvent(models.AuditDocumentList, "", principal)
+	event.OwnerID = principal.UserID
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	return s.listDocuments(ctx, principal.UserID, filter)
+}
+
+// listDocuments returns a page of the documents of userID, of at most
+// maxPageSize documents.
+func (s *Service) listDocuments(ctx context.Context, userID string, filter models.DocumentFilter) (*models.DocumentPage, error) {
+	if filter.ProfileID != "" {
+		if _, err := s.profiles.Resolve(ctx, userID, filter.ProfileID); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	filter.Limit = min(filter.Limit, maxPageSize)
+	return s.repo.GetByUserID(ctx, userID, filter)
+}
+
+// DeleteDocument deletes a document together with its comments, the grants
+// on it and its place in share links. Those go first, so a deletion that
+// fails halfway can simply be repeated. The document's file is removed as
+// well unless another document of the owner still uses it.
+func (s *Service) DeleteDocument(ctx context.Context, id string, principal models.Principal) (err error) {
+	event := auditEvent(models.AuditDocumentDelete, id, principal)
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	doc, err := s.authorizedDocument(ctx, id, principal, policy.ActionDelete, &event)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if doc.UserID != userID {
-		return nil, errors.ErrAccessDenied
+	if err := s.comments.DeleteByDocumentID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.grants.DeleteByDocumentID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.shareLinks.RemoveDocument(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
 	}
 
-	return doc, nil
+	s.deleteUnreferencedFile(ctx, doc)
+	return nil
 }
 
-func (s *Service) GetUserDocuments(ctx context.Context, userID string) ([]*models.Document, error) {
-	return s.repo.GetByUserID(ctx, userID)
+// DeleteUserDocuments deletes every document of the user and returns how many
+// were removed, together with the comments on them and the ones the user
+// left on documents of others. Their files are left to the caller. It is
+// recorded as a deletion by the system rather than by a user.
+func (s *Service) DeleteUserDocuments(ctx context.Context, userID string) (_ int64, err error) {
+	event := models.AuditEvent{Action: models.AuditDocumentDelete, OwnerID: userID}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	if err := s.comments.DeleteByUserID(ctx, userID); err != nil {
+		return 0, err
+	}
+	return s.repo.DeleteByUserID(ctx, userID)
 }
 
-func (s *Service) DeleteDocument(ctx context.Context, id string, userID string) error {
-	doc, err := s.repo.GetByID(ctx, id)
+// deleteUnreferencedFile removes the file of a deleted document unless
+// another document of the user still points at it. Whoever may delete the
+// document may delete its file, so the file is removed on behalf of the
+// owner rather than the caller. The document is already gone at this point,
+// so failures are only logged.
+func (s *Service) deleteUnreferencedFile(ctx context.Context, doc *models.Document) {
+	if doc.File == "" {
+		return
+	}
+
+	count, err := s.repo.CountByFile(ctx, doc.UserID, doc.File)
 	if err != nil {
-		return err
+		logger.Error("failed to check document file references", err, "document_id", doc.ID)
+		return
+	}
+	if count > 0 {
+		return
 	}
 
-	if doc.UserID != userID {
-		return errors.ErrAccessDenied
+	if err := s.files.DeleteOwnedFile(ctx, doc.File, doc.UserID); err != nil {
+		logger.Error("failed to delete document file", err, "document_id", doc.ID, "file", doc.File)
 	}
+}
+
+// UpdateDocument changes a document. Only its owner may move it to another
+// of their profiles.
+func (s *Service) UpdateDocument(ctx context.Context, id string, update models.DocumentUpdate, principal models.Principal) (_ *models.Document, err error) {
+	event := auditEvent(models.AuditDocumentUpdate, id, principal)
+	defer func() { s.audit.Record(ctx, event, err) }()
 
-	return s.repo.Delete(ctx, id)
+	doc, err := s.authorizedDocument(ctx, id, principal, policy.ActionUpdate, &event)
+	if err != nil {
+		return nil, err
+	}
+	if update.ProfileID != nil {
+		if principal.UserID != doc.UserID {
+			return nil, errors.ErrAccessDenied
+		}
+		if _, err := s.profiles.Resolve(ctx, doc.UserID, *update.ProfileID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, id, update, principal.Actor()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) UpdateDocument(ctx context.Context, id string, update models.DocumentUpdate, userID string) (*models.Document, error) {
+// authorizedDocument loads a document and checks that the principal may
+// perform action on it. The owner is noted in event as soon as the document
+// is found, so refused attempts are attributed to it too. A clinician the
+// policy refuses may still read the document through break-glass access,
+// which is then noted in event as well.
+func (s *Service) authorizedDocument(ctx context.Context, id string, principal models.Principal, action policy.Action, event *models.AuditEvent) (*models.Document, error) {
 	doc, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if doc.UserID != userID {
-		return nil, errors.ErrAccessDenied
+	event.OwnerID = doc.UserID
+
+	resource := policy.Resource{Type: policy.ResourceDocument, ID: doc.ID, OwnerID: doc.UserID}
+	err = s.policy.Authorize(ctx, principal, action, resource)
+	if err == nil {
+		return doc, nil
 	}
-	if err := s.repo.Update(ctx, id, update); err != nil {
+	if action != policy.ActionRead || !stderrors.Is(err, errors.ErrAccessDenied) {
 		return nil, err
 	}
-	return s.repo.GetByID(ctx, id)
+
+	access, err := s.activeBreakGlass(ctx, principal, doc.UserID)
+	if err != nil {
+		return nil, err
+	}
+	event.BreakGlassID = access.ID
+	return doc, nil
+}
+
+func auditEvent(action, resourceID string, principal models.Principal) models.AuditEvent {
+	return models.AuditEvent{
+		Action:     action,
+		ResourceID: resourceID,
+		ActorID:    principal.Actor(),
+	}
 }
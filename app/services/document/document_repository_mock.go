@@ -7,9 +7,12 @@ package document
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/gruzdev-dev/meddoc/app/models"
+	policy "github.com/gruzdev-dev/meddoc/app/services/policy"
+	mailer "github.com/gruzdev-dev/meddoc/pkg/mailer"
 )
 
 // MockDocumentRepository is a mock of DocumentRepository interface.
@@ -35,6 +38,21 @@ func (m *MockDocumentRepository) EXPECT() *MockDocumentRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountByFile mocks base method.
+func (m *MockDocumentRepository) CountByFile(ctx context.Context, userID, file string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByFile", ctx, userID, file)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByFile indicates an expected call of CountByFile.
+func (mr *MockDocumentRepositoryMockRecorder) CountByFile(ctx, userID, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByFile", reflect.TypeOf((*MockDocumentRepository)(nil).CountByFile), ctx, userID, file)
+}
+
 // Create mocks base method.
 func (m *MockDocumentRepository) Create(ctx context.Context, doc *models.Document) error {
 	m.ctrl.T.Helper()
@@ -63,6 +81,21 @@ func (mr *MockDocumentRepositoryMockRecorder) Delete(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDocumentRepository)(nil).Delete), ctx, id)
 }
 
+// DeleteByUserID mocks base method.
+func (m *MockDocumentRepository) DeleteByUserID(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockDocumentRepositoryMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockDocumentRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
 // GetByID mocks base method.
 func (m *MockDocumentRepository) GetByID(ctx context.Context, id string) (*models.Document, error) {
 	m.ctrl.T.Helper()
@@ -79,30 +112,473 @@ func (mr *MockDocumentRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomo
 }
 
 // GetByUserID mocks base method.
-func (m *MockDocumentRepository) GetByUserID(ctx context.Context, userID string) ([]*models.Document, error) {
+func (m *MockDocumentRepository) GetByUserID(ctx context.Context, userID string, filter models.DocumentFilter) (*models.DocumentPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
-	ret0, _ := ret[0].([]*models.Document)
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID, filter)
+	ret0, _ := ret[0].(*models.DocumentPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByUserID indicates an expected call of GetByUserID.
-func (mr *MockDocumentRepositoryMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+func (mr *MockDocumentRepositoryMockRecorder) GetByUserID(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockDocumentRepository)(nil).GetByUserID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockDocumentRepository)(nil).GetByUserID), ctx, userID, filter)
+}
+
+// Search mocks base method.
+func (m *MockDocumentRepository) Search(ctx context.Context, userID string, search models.DocumentSearch) ([]*models.DocumentSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, userID, search)
+	ret0, _ := ret[0].([]*models.DocumentSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockDocumentRepositoryMockRecorder) Search(ctx, userID, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockDocumentRepository)(nil).Search), ctx, userID, search)
 }
 
 // Update mocks base method.
-func (m *MockDocumentRepository) Update(ctx context.Context, id string, update models.DocumentUpdate) error {
+func (m *MockDocumentRepository) Update(ctx context.Context, id string, update models.DocumentUpdate, updatedBy string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, update)
+	ret := m.ctrl.Call(m, "Update", ctx, id, update, updatedBy)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockDocumentRepositoryMockRecorder) Update(ctx, id, update interface{}) *gomock.Call {
+func (mr *MockDocumentRepositoryMockRecorder) Update(ctx, id, update, updatedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDocumentRepository)(nil).Update), ctx, id, update, updatedBy)
+}
+
+// MockCommentRepository is a mock of CommentRepository interface.
+type MockCommentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentRepositoryMockRecorder
+}
+
+// MockCommentRepositoryMockRecorder is the mock recorder for MockCommentRepository.
+type MockCommentRepositoryMockRecorder struct {
+	mock *MockCommentRepository
+}
+
+// NewMockCommentRepository creates a new mock instance.
+func NewMockCommentRepository(ctrl *gomock.Controller) *MockCommentRepository {
+	mock := &MockCommentRepository{ctrl: ctrl}
+	mock.recorder = &MockCommentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentRepository) EXPECT() *MockCommentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommentRepositoryMockRecorder) Create(ctx, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCommentRepository)(nil).Create), ctx, comment)
+}
+
+// DeleteByDocumentID mocks base method.
+func (m *MockCommentRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByDocumentID", ctx, documentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByDocumentID indicates an expected call of DeleteByDocumentID.
+func (mr *MockCommentRepositoryMockRecorder) DeleteByDocumentID(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByDocumentID", reflect.TypeOf((*MockCommentRepository)(nil).DeleteByDocumentID), ctx, documentID)
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockCommentRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockCommentRepositoryMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockCommentRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
+// GetByDocumentID mocks base method.
+func (m *MockCommentRepository) GetByDocumentID(ctx context.Context, documentID string) ([]*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByDocumentID", ctx, documentID)
+	ret0, _ := ret[0].([]*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByDocumentID indicates an expected call of GetByDocumentID.
+func (mr *MockCommentRepositoryMockRecorder) GetByDocumentID(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDocumentID", reflect.TypeOf((*MockCommentRepository)(nil).GetByDocumentID), ctx, documentID)
+}
+
+// MockFileDeleter is a mock of FileDeleter interface.
+type MockFileDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileDeleterMockRecorder
+}
+
+// MockFileDeleterMockRecorder is the mock recorder for MockFileDeleter.
+type MockFileDeleterMockRecorder struct {
+	mock *MockFileDeleter
+}
+
+// NewMockFileDeleter creates a new mock instance.
+func NewMockFileDeleter(ctrl *gomock.Controller) *MockFileDeleter {
+	mock := &MockFileDeleter{ctrl: ctrl}
+	mock.recorder = &MockFileDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileDeleter) EXPECT() *MockFileDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteOwnedFile mocks base method.
+func (m *MockFileDeleter) DeleteOwnedFile(ctx context.Context, id, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOwnedFile", ctx, id, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOwnedFile indicates an expected call of DeleteOwnedFile.
+func (mr *MockFileDeleterMockRecorder) DeleteOwnedFile(ctx, id, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOwnedFile", reflect.TypeOf((*MockFileDeleter)(nil).DeleteOwnedFile), ctx, id, ownerID)
+}
+
+// MockGrantRepository is a mock of GrantRepository interface.
+type MockGrantRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantRepositoryMockRecorder
+}
+
+// MockGrantRepositoryMockRecorder is the mock recorder for MockGrantRepository.
+type MockGrantRepositoryMockRecorder struct {
+	mock *MockGrantRepository
+}
+
+// NewMockGrantRepository creates a new mock instance.
+func NewMockGrantRepository(ctrl *gomock.Controller) *MockGrantRepository {
+	mock := &MockGrantRepository{ctrl: ctrl}
+	mock.recorder = &MockGrantRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrantRepository) EXPECT() *MockGrantRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByDocumentID mocks base method.
+func (m *MockGrantRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByDocumentID", ctx, documentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByDocumentID indicates an expected call of DeleteByDocumentID.
+func (mr *MockGrantRepositoryMockRecorder) DeleteByDocumentID(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByDocumentID", reflect.TypeOf((*MockGrantRepository)(nil).DeleteByDocumentID), ctx, documentID)
+}
+
+// MockShareLinkRepository is a mock of ShareLinkRepository interface.
+type MockShareLinkRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareLinkRepositoryMockRecorder
+}
+
+// MockShareLinkRepositoryMockRecorder is the mock recorder for MockShareLinkRepository.
+type MockShareLinkRepositoryMockRecorder struct {
+	mock *MockShareLinkRepository
+}
+
+// NewMockShareLinkRepository creates a new mock instance.
+func NewMockShareLinkRepository(ctrl *gomock.Controller) *MockShareLinkRepository {
+	mock := &MockShareLinkRepository{ctrl: ctrl}
+	mock.recorder = &MockShareLinkRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareLinkRepository) EXPECT() *MockShareLinkRepositoryMockRecorder {
+	return m.recorder
+}
+
+// RemoveDocument mocks base method.
+func (m *MockShareLinkRepository) RemoveDocument(ctx context.Context, documentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDocument", ctx, documentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDocument indicates an expected call of RemoveDocument.
+func (mr *MockShareLinkRepositoryMockRecorder) RemoveDocument(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDocument", reflect.TypeOf((*MockShareLinkRepository)(nil).RemoveDocument), ctx, documentID)
+}
+
+// MockProfileResolver is a mock of ProfileResolver interface.
+type MockProfileResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileResolverMockRecorder
+}
+
+// MockProfileResolverMockRecorder is the mock recorder for MockProfileResolver.
+type MockProfileResolverMockRecorder struct {
+	mock *MockProfileResolver
+}
+
+// NewMockProfileResolver creates a new mock instance.
+func NewMockProfileResolver(ctrl *gomock.Controller) *MockProfileResolver {
+	mock := &MockProfileResolver{ctrl: ctrl}
+	mock.recorder = &MockProfileResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileResolver) EXPECT() *MockProfileResolverMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockProfileResolver) Resolve(ctx context.Context, userID, profileID string) (*models.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, userID, profileID)
+	ret0, _ := ret[0].(*models.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockProfileResolverMockRecorder) Resolve(ctx, userID, profileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockProfileResolver)(nil).Resolve), ctx, userID, profileID)
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockAuthorizer) Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, principal, action, resource)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAuthorizerMockRecorder) Authorize(ctx, principal, action, resource interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthorizer)(nil).Authorize), ctx, principal, action, resource)
+}
+
+// MockAuditor is a mock of Auditor interface.
+type MockAuditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditorMockRecorder
+}
+
+// MockAuditorMockRecorder is the mock recorder for MockAuditor.
+type MockAuditorMockRecorder struct {
+	mock *MockAuditor
+}
+
+// NewMockAuditor creates a new mock instance.
+func NewMockAuditor(ctrl *gomock.Controller) *MockAuditor {
+	mock := &MockAuditor{ctrl: ctrl}
+	mock.recorder = &MockAuditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditor) EXPECT() *MockAuditorMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditor) Record(ctx context.Context, event models.AuditEvent, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, event, err)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditorMockRecorder) Record(ctx, event, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditor)(nil).Record), ctx, event, err)
+}
+
+// MockBreakGlassRepository is a mock of BreakGlassRepository interface.
+type MockBreakGlassRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreakGlassRepositoryMockRecorder
+}
+
+// MockBreakGlassRepositoryMockRecorder is the mock recorder for MockBreakGlassRepository.
+type MockBreakGlassRepositoryMockRecorder struct {
+	mock *MockBreakGlassRepository
+}
+
+// NewMockBreakGlassRepository creates a new mock instance.
+func NewMockBreakGlassRepository(ctrl *gomock.Controller) *MockBreakGlassRepository {
+	mock := &MockBreakGlassRepository{ctrl: ctrl}
+	mock.recorder = &MockBreakGlassRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreakGlassRepository) EXPECT() *MockBreakGlassRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockBreakGlassRepository) Create(ctx context.Context, access *models.BreakGlassAccess) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBreakGlassRepositoryMockRecorder) Create(ctx, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBreakGlassRepository)(nil).Create), ctx, access)
+}
+
+// GetActive mocks base method.
+func (m *MockBreakGlassRepository) GetActive(ctx context.Context, clinicianID, patientID string, now time.Time) (*models.BreakGlassAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", ctx, clinicianID, patientID, now)
+	ret0, _ := ret[0].(*models.BreakGlassAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActive indicates an expected call of GetActive.
+func (mr *MockBreakGlassRepositoryMockRecorder) GetActive(ctx, clinicianID, patientID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockBreakGlassRepository)(nil).GetActive), ctx, clinicianID, patientID, now)
+}
+
+// MockUsers is a mock of Users interface.
+type MockUsers struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersMockRecorder
+}
+
+// MockUsersMockRecorder is the mock recorder for MockUsers.
+type MockUsersMockRecorder struct {
+	mock *MockUsers
+}
+
+// NewMockUsers creates a new mock instance.
+func NewMockUsers(ctrl *gomock.Controller) *MockUsers {
+	mock := &MockUsers{ctrl: ctrl}
+	mock.recorder = &MockUsersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
+	return m.recorder
+}
+
+// GetProfile mocks base method.
+func (m *MockUsers) GetProfile(ctx context.Context, userID string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUsersMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUsers)(nil).GetProfile), ctx, userID)
+}
+
+// MockMailer is a mock of Mailer interface.
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+}
+
+// MockMailerMockRecorder is the mock recorder for MockMailer.
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+// NewMockMailer creates a new mock instance.
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailerMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDocumentRepository)(nil).Update), ctx, id, update)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), ctx, msg)
 }
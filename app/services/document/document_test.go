@@ -10,6 +10,7 @@ import (
 
 	"github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
 )
 
 func TestService_CreateDocument(t *testing.T) {
@@ -17,12 +18,22 @@ func TestService_CreateDocument(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockDocumentRepository(ctrl)
-	service := NewService(mockRepo)
+	mockProfiles := NewMockProfileResolver(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Files:     NewMockFileDeleter(ctrl),
+		Profiles:  mockProfiles,
+		Policy:    policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
+
+	defaultProfile := &models.Profile{ID: "profile-1", UserID: "user-123", Default: true}
 
 	tests := []struct {
 		name          string
 		creation      models.DocumentCreation
-		userID        string
+		principal     models.Principal
 		mockSetup     func()
 		expectedError error
 	}{
@@ -37,8 +48,11 @@ func TestService_CreateDocument(t *testing.T) {
 				Priority:    1,
 				Content:     map[string]string{"key": "value"},
 			},
-			userID: "user-123",
+			principal: models.Principal{UserID: "user-123"},
 			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "").
+					Return(defaultProfile, nil)
 				mockRepo.EXPECT().
 					Create(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, doc *models.Document) error {
@@ -50,6 +64,63 @@ func TestService_CreateDocument(t *testing.T) {
 						assert.Equal(t, 1, doc.Priority)
 						assert.Equal(t, map[string]string{"key": "value"}, doc.Content)
 						assert.Equal(t, "user-123", doc.UserID)
+						assert.Equal(t, "profile-1", doc.ProfileID)
+						assert.Equal(t, "user-123", doc.CreatedBy)
+						return nil
+					})
+			},
+			expectedError: nil,
+		},
+		{
+			name: "filed under another profile",
+			creation: models.DocumentCreation{
+				Title:     "Vaccination card",
+				ProfileID: "profile-2",
+			},
+			principal: models.Principal{UserID: "user-123"},
+			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-2").
+					Return(&models.Profile{ID: "profile-2", UserID: "user-123"}, nil)
+				mockRepo.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, doc *models.Document) error {
+						assert.Equal(t, "profile-2", doc.ProfileID)
+						return nil
+					})
+			},
+			expectedError: nil,
+		},
+		{
+			name: "profile of another account",
+			creation: models.DocumentCreation{
+				Title:     "Vaccination card",
+				ProfileID: "profile-9",
+			},
+			principal: models.Principal{UserID: "user-123"},
+			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-9").
+					Return(nil, errors.ErrProfileNotFound)
+			},
+			expectedError: errors.ErrProfileNotFound,
+		},
+		{
+			name: "created by a delegate",
+			creation: models.DocumentCreation{
+				Title: "Blood test",
+			},
+			principal: models.Principal{UserID: "user-123", ActorID: "user-456"},
+			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "").
+					Return(defaultProfile, nil)
+				mockRepo.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, doc *models.Document) error {
+						assert.Equal(t, "user-123", doc.UserID)
+						assert.Equal(t, "user-456", doc.CreatedBy)
+						assert.Equal(t, "user-456", doc.UpdatedBy)
 						return nil
 					})
 			},
@@ -60,8 +131,11 @@ func TestService_CreateDocument(t *testing.T) {
 			creation: models.DocumentCreation{
 				Title: "Test Document",
 			},
-			userID: "user-123",
+			principal: models.Principal{UserID: "user-123"},
 			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "").
+					Return(defaultProfile, nil)
 				mockRepo.EXPECT().
 					Create(gomock.Any(), gomock.Any()).
 					Return(errors.ErrInternal)
@@ -73,7 +147,7 @@ func TestService_CreateDocument(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			doc, err := service.CreateDocument(context.Background(), tt.creation, tt.userID)
+			doc, err := service.CreateDocument(context.Background(), tt.creation, tt.principal)
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 				assert.Nil(t, doc)
@@ -87,7 +161,7 @@ func TestService_CreateDocument(t *testing.T) {
 				assert.Equal(t, tt.creation.Category, doc.Category)
 				assert.Equal(t, tt.creation.Priority, doc.Priority)
 				assert.Equal(t, tt.creation.Content, doc.Content)
-				assert.Equal(t, tt.userID, doc.UserID)
+				assert.Equal(t, tt.principal.UserID, doc.UserID)
 			}
 		})
 	}
@@ -98,7 +172,15 @@ func TestService_GetDocument(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockDocumentRepository(ctrl)
-	service := NewService(mockRepo)
+	mockProfiles := NewMockProfileResolver(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Files:     NewMockFileDeleter(ctrl),
+		Profiles:  mockProfiles,
+		Policy:    policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
 
 	existingDoc := &models.Document{
 		ID:          "doc-123",
@@ -146,15 +228,32 @@ func TestService_GetDocument(t *testing.T) {
 				mockRepo.EXPECT().
 					GetByID(gomock.Any(), "doc-123").
 					Return(existingDoc, nil)
+				mockGrants.EXPECT().
+					DocumentPermission(gomock.Any(), "doc-123", "other-user").
+					Return("", nil)
 			},
 			expectedError: errors.ErrAccessDenied,
 		},
+		{
+			name:   "shared with the user",
+			docID:  "doc-123",
+			userID: "grantee",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(existingDoc, nil)
+				mockGrants.EXPECT().
+					DocumentPermission(gomock.Any(), "doc-123", "grantee").
+					Return(models.GrantRead, nil)
+			},
+			expectedError: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			doc, err := service.GetDocument(context.Background(), tt.docID, tt.userID)
+			doc, err := service.GetDocument(context.Background(), tt.docID, models.Principal{UserID: tt.userID})
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 				assert.Nil(t, doc)
@@ -175,30 +274,43 @@ func TestService_GetUserDocuments(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockDocumentRepository(ctrl)
-	service := NewService(mockRepo)
+	mockProfiles := NewMockProfileResolver(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Files:     NewMockFileDeleter(ctrl),
+		Profiles:  mockProfiles,
+		Policy:    policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
 
-	userDocs := []*models.Document{
-		{
-			ID:          "doc-1",
-			Title:       "Document 1",
-			Description: "Description 1",
-			UserID:      "user-123",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			ID:          "doc-2",
-			Title:       "Document 2",
-			Description: "Description 2",
-			UserID:      "user-123",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+	userDocs := &models.DocumentPage{
+		Documents: []*models.Document{
+			{
+				ID:          "doc-1",
+				Title:       "Document 1",
+				Description: "Description 1",
+				UserID:      "user-123",
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			},
+			{
+				ID:          "doc-2",
+				Title:       "Document 2",
+				Description: "Description 2",
+				UserID:      "user-123",
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			},
 		},
+		NextCursor: "next",
+		Total:      5,
 	}
 
 	tests := []struct {
 		name          string
 		userID        string
+		filter        models.DocumentFilter
 		mockSetup     func()
 		expectedError error
 	}{
@@ -207,17 +319,75 @@ func TestService_GetUserDocuments(t *testing.T) {
 			userID: "user-123",
 			mockSetup: func() {
 				mockRepo.EXPECT().
-					GetByUserID(gomock.Any(), "user-123").
+					GetByUserID(gomock.Any(), "user-123", models.DocumentFilter{Limit: 50}).
+					Return(userDocs, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "filtered by profile",
+			userID: "user-123",
+			filter: models.DocumentFilter{ProfileID: "profile-2"},
+			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-2").
+					Return(&models.Profile{ID: "profile-2", UserID: "user-123"}, nil)
+				mockRepo.EXPECT().
+					GetByUserID(gomock.Any(), "user-123", models.DocumentFilter{ProfileID: "profile-2", Limit: 50}).
 					Return(userDocs, nil)
 			},
 			expectedError: nil,
 		},
+		{
+			name:   "sorted and paged",
+			userID: "user-123",
+			filter: models.DocumentFilter{Sort: models.DocumentSortPriority, Descending: true, Cursor: "abc", Limit: 2},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByUserID(gomock.Any(), "user-123", models.DocumentFilter{Sort: models.DocumentSortPriority, Descending: true, Cursor: "abc", Limit: 2}).
+					Return(userDocs, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "page size capped",
+			userID: "user-123",
+			filter: models.DocumentFilter{Limit: 10000},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByUserID(gomock.Any(), "user-123", models.DocumentFilter{Limit: 200}).
+					Return(userDocs, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "profile of another account",
+			userID: "user-123",
+			filter: models.DocumentFilter{ProfileID: "profile-9"},
+			mockSetup: func() {
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-9").
+					Return(nil, errors.ErrProfileNotFound)
+			},
+			expectedError: errors.ErrProfileNotFound,
+		},
+		{
+			name:   "invalid cursor",
+			userID: "user-123",
+			filter: models.DocumentFilter{Cursor: "garbage"},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByUserID(gomock.Any(), "user-123", models.DocumentFilter{Cursor: "garbage", Limit: 50}).
+					Return(nil, errors.ErrInvalidCursor)
+			},
+			expectedError: errors.ErrInvalidCursor,
+		},
 		{
 			name:   "repository error",
 			userID: "user-123",
 			mockSetup: func() {
 				mockRepo.EXPECT().
-					GetByUserID(gomock.Any(), "user-123").
+					GetByUserID(gomock.Any(), "user-123", models.DocumentFilter{Limit: 50}).
 					Return(nil, errors.ErrInternal)
 			},
 			expectedError: errors.ErrInternal,
@@ -227,18 +397,13 @@ func TestService_GetUserDocuments(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			docs, err := service.GetUserDocuments(context.Background(), tt.userID)
+			page, err := service.GetUserDocuments(context.Background(), models.Principal{UserID: tt.userID}, tt.filter)
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Nil(t, docs)
+				assert.Nil(t, page)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, docs)
-				assert.Len(t, docs, 2)
-				assert.Equal(t, userDocs[0].ID, docs[0].ID)
-				assert.Equal(t, userDocs[0].Title, docs[0].Title)
-				assert.Equal(t, userDocs[1].ID, docs[1].ID)
-				assert.Equal(t, userDocs[1].Title, docs[1].Title)
+				assert.Equal(t, userDocs, page)
 			}
 		})
 	}
@@ -249,18 +414,44 @@ func TestService_DeleteDocument(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockDocumentRepository(ctrl)
-	service := NewService(mockRepo)
+	mockComments := NewMockCommentRepository(ctrl)
+	mockDocumentGrants := NewMockGrantRepository(ctrl)
+	mockLinks := NewMockShareLinkRepository(ctrl)
+	mockFiles := NewMockFileDeleter(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents:  mockRepo,
+		Comments:   mockComments,
+		Grants:     mockDocumentGrants,
+		ShareLinks: mockLinks,
+		Files:      mockFiles,
+		Profiles:   NewMockProfileResolver(ctrl),
+		Policy:     policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:      ignoreAudit(ctrl),
+	}, Config{})
+	expectCleanup := func(docID string) {
+		mockComments.EXPECT().DeleteByDocumentID(gomock.Any(), docID).Return(nil)
+		mockDocumentGrants.EXPECT().DeleteByDocumentID(gomock.Any(), docID).Return(nil)
+		mockLinks.EXPECT().RemoveDocument(gomock.Any(), docID).Return(nil)
+	}
 
 	existingDoc := &models.Document{
 		ID:     "doc-123",
 		Title:  "Test Document",
 		UserID: "user-123",
 	}
+	docWithFile := &models.Document{
+		ID:     "doc-456",
+		Title:  "Scan",
+		File:   "file-123.pdf",
+		UserID: "user-123",
+	}
 
 	tests := []struct {
 		name          string
 		docID         string
 		userID        string
+		roles         []string
 		mockSetup     func()
 		expectedError error
 	}{
@@ -272,12 +463,75 @@ func TestService_DeleteDocument(t *testing.T) {
 				mockRepo.EXPECT().
 					GetByID(gomock.Any(), "doc-123").
 					Return(existingDoc, nil)
+				expectCleanup("doc-123")
 				mockRepo.EXPECT().
 					Delete(gomock.Any(), "doc-123").
 					Return(nil)
 			},
 			expectedError: nil,
 		},
+		{
+			name:   "deletes unreferenced file",
+			docID:  "doc-456",
+			userID: "user-123",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-456").Return(docWithFile, nil)
+				expectCleanup("doc-456")
+				mockRepo.EXPECT().Delete(gomock.Any(), "doc-456").Return(nil)
+				mockRepo.EXPECT().CountByFile(gomock.Any(), "user-123", "file-123.pdf").Return(int64(0), nil)
+				mockFiles.EXPECT().DeleteOwnedFile(gomock.Any(), "file-123.pdf", "user-123").Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "admin deletes the file on behalf of the owner",
+			docID:  "doc-456",
+			userID: "admin-1",
+			roles:  []string{models.RoleAdmin},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-456").Return(docWithFile, nil)
+				expectCleanup("doc-456")
+				mockRepo.EXPECT().Delete(gomock.Any(), "doc-456").Return(nil)
+				mockRepo.EXPECT().CountByFile(gomock.Any(), "user-123", "file-123.pdf").Return(int64(0), nil)
+				mockFiles.EXPECT().DeleteOwnedFile(gomock.Any(), "file-123.pdf", "user-123").Return(nil)
+			},
+		},
+		{
+			name:   "grants are deleted before the document",
+			docID:  "doc-123",
+			userID: "user-123",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(existingDoc, nil)
+				mockComments.EXPECT().DeleteByDocumentID(gomock.Any(), "doc-123").Return(nil)
+				mockDocumentGrants.EXPECT().DeleteByDocumentID(gomock.Any(), "doc-123").Return(assert.AnError)
+			},
+			expectedError: assert.AnError,
+		},
+		{
+			name:   "keeps file referenced by another document",
+			docID:  "doc-456",
+			userID: "user-123",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-456").Return(docWithFile, nil)
+				expectCleanup("doc-456")
+				mockRepo.EXPECT().Delete(gomock.Any(), "doc-456").Return(nil)
+				mockRepo.EXPECT().CountByFile(gomock.Any(), "user-123", "file-123.pdf").Return(int64(1), nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "file deletion failure does not fail the request",
+			docID:  "doc-456",
+			userID: "user-123",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetByID(gomock.Any(), "doc-456").Return(docWithFile, nil)
+				expectCleanup("doc-456")
+				mockRepo.EXPECT().Delete(gomock.Any(), "doc-456").Return(nil)
+				mockRepo.EXPECT().CountByFile(gomock.Any(), "user-123", "file-123.pdf").Return(int64(0), nil)
+				mockFiles.EXPECT().DeleteOwnedFile(gomock.Any(), "file-123.pdf", "user-123").Return(assert.AnError)
+			},
+			expectedError: nil,
+		},
 		{
 			name:   "document not found",
 			docID:  "nonexistent",
@@ -305,7 +559,7 @@ func TestService_DeleteDocument(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			err := service.DeleteDocument(context.Background(), tt.docID, tt.userID)
+			err := service.DeleteDocument(context.Background(), tt.docID, models.Principal{UserID: tt.userID, Roles: tt.roles})
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
@@ -320,7 +574,15 @@ func TestService_UpdateDocument(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockDocumentRepository(ctrl)
-	service := NewService(mockRepo)
+	mockProfiles := NewMockProfileResolver(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(Dependencies{
+		Documents: mockRepo,
+		Files:     NewMockFileDeleter(ctrl),
+		Profiles:  mockProfiles,
+		Policy:    policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:     ignoreAudit(ctrl),
+	}, Config{})
 
 	existingDoc := &models.Document{
 		ID:          "doc-123",
@@ -361,7 +623,7 @@ func TestService_UpdateDocument(t *testing.T) {
 					GetByID(gomock.Any(), "doc-123").
 					Return(existingDoc, nil)
 				mockRepo.EXPECT().
-					Update(gomock.Any(), "doc-123", gomock.Any()).
+					Update(gomock.Any(), "doc-123", gomock.Any(), gomock.Any()).
 					Return(nil)
 				mockRepo.EXPECT().
 					GetByID(gomock.Any(), "doc-123").
@@ -394,15 +656,116 @@ func TestService_UpdateDocument(t *testing.T) {
 				mockRepo.EXPECT().
 					GetByID(gomock.Any(), "doc-123").
 					Return(existingDoc, nil)
+				mockGrants.EXPECT().
+					DocumentPermission(gomock.Any(), "doc-123", "other-user").
+					Return("", nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:   "read grant may not update",
+			docID:  "doc-123",
+			userID: "grantee",
+			update: models.DocumentUpdate{
+				Title: stringPtr("New Title"),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(existingDoc, nil)
+				mockGrants.EXPECT().
+					DocumentPermission(gomock.Any(), "doc-123", "grantee").
+					Return(models.GrantComment, nil)
 			},
 			expectedError: errors.ErrAccessDenied,
 		},
+		{
+			name:   "owner moves document to another profile",
+			docID:  "doc-123",
+			userID: "user-123",
+			update: models.DocumentUpdate{
+				ProfileID: stringPtr("profile-2"),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(existingDoc, nil)
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-2").
+					Return(&models.Profile{ID: "profile-2", UserID: "user-123"}, nil)
+				mockRepo.EXPECT().
+					Update(gomock.Any(), "doc-123", gomock.Any(), gomock.Any()).
+					Return(nil)
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(updatedDoc, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "move to a profile of another account",
+			docID:  "doc-123",
+			userID: "user-123",
+			update: models.DocumentUpdate{
+				ProfileID: stringPtr("profile-9"),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(existingDoc, nil)
+				mockProfiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-9").
+					Return(nil, errors.ErrProfileNotFound)
+			},
+			expectedError: errors.ErrProfileNotFound,
+		},
+		{
+			name:   "edit grant may not move the document",
+			docID:  "doc-123",
+			userID: "grantee",
+			update: models.DocumentUpdate{
+				ProfileID: stringPtr("profile-2"),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(existingDoc, nil)
+				mockGrants.EXPECT().
+					DocumentPermission(gomock.Any(), "doc-123", "grantee").
+					Return(models.GrantEdit, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:   "edit grant may update",
+			docID:  "doc-123",
+			userID: "grantee",
+			update: models.DocumentUpdate{
+				Title:       stringPtr("Updated Title"),
+				Description: stringPtr("Updated Description"),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(existingDoc, nil)
+				mockGrants.EXPECT().
+					DocumentPermission(gomock.Any(), "doc-123", "grantee").
+					Return(models.GrantEdit, nil)
+				mockRepo.EXPECT().
+					Update(gomock.Any(), "doc-123", gomock.Any(), gomock.Any()).
+					Return(nil)
+				mockRepo.EXPECT().
+					GetByID(gomock.Any(), "doc-123").
+					Return(updatedDoc, nil)
+			},
+			expectedError: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			doc, err := service.UpdateDocument(context.Background(), tt.docID, tt.update, tt.userID)
+			doc, err := service.UpdateDocument(context.Background(), tt.docID, tt.update, models.Principal{UserID: tt.userID})
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 				assert.Nil(t, doc)
@@ -421,3 +784,78 @@ func TestService_UpdateDocument(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestService_RecordsAuditEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockDocumentRepository(ctrl)
+	mockComments := NewMockCommentRepository(ctrl)
+	mockDocumentGrants := NewMockGrantRepository(ctrl)
+	mockLinks := NewMockShareLinkRepository(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	mockAudit := NewMockAuditor(ctrl)
+	service := NewService(Dependencies{
+		Documents:  mockRepo,
+		Comments:   mockComments,
+		Grants:     mockDocumentGrants,
+		ShareLinks: mockLinks,
+		Files:      NewMockFileDeleter(ctrl),
+		Profiles:   NewMockProfileResolver(ctrl),
+		Policy:     policy.New(policy.DefaultPermissions, mockGrants),
+		Audit:      mockAudit,
+	}, Config{})
+
+	doc := &models.Document{ID: "doc-123", UserID: "user-123"}
+
+	t.Run("read by owner", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+			Action:     models.AuditDocumentRead,
+			ResourceID: "doc-123",
+			ActorID:    "user-123",
+			OwnerID:    "user-123",
+		}, nil)
+
+		_, err := service.GetDocument(context.Background(), "doc-123", models.Principal{UserID: "user-123"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("denied read names the owner", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+		mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "other-user").Return("", nil)
+		mockAudit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+			Action:     models.AuditDocumentRead,
+			ResourceID: "doc-123",
+			ActorID:    "other-user",
+			OwnerID:    "user-123",
+		}, errors.ErrAccessDenied)
+
+		_, err := service.GetDocument(context.Background(), "doc-123", models.Principal{UserID: "other-user"})
+		assert.ErrorIs(t, err, errors.ErrAccessDenied)
+	})
+
+	t.Run("delegate is the actor", func(t *testing.T) {
+		mockRepo.EXPECT().Delete(gomock.Any(), "doc-123").Return(nil)
+		mockRepo.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+		mockComments.EXPECT().DeleteByDocumentID(gomock.Any(), "doc-123").Return(nil)
+		mockDocumentGrants.EXPECT().DeleteByDocumentID(gomock.Any(), "doc-123").Return(nil)
+		mockLinks.EXPECT().RemoveDocument(gomock.Any(), "doc-123").Return(nil)
+		mockAudit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+			Action:     models.AuditDocumentDelete,
+			ResourceID: "doc-123",
+			ActorID:    "delegate-1",
+			OwnerID:    "user-123",
+		}, nil)
+
+		principal := models.Principal{UserID: "user-123", ActorID: "delegate-1", Delegation: models.DelegationManage}
+		assert.NoError(t, service.DeleteDocument(context.Background(), "doc-123", principal))
+	})
+}
+
+// ignoreAudit returns an auditor for tests that do not check audit events.
+func ignoreAudit(ctrl *gomock.Controller) *MockAuditor {
+	audit := NewMockAuditor(ctrl)
+	audit.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	return audit
+}
@@ -2,14 +2,72 @@ package document
 
 import (
 	"context"
+	"time"
 
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
 )
 
 type DocumentRepository interface {
 	Create(ctx context.Context, doc *models.Document) error
 	GetByID(ctx context.Context, id string) (*models.Document, error)
-	GetByUserID(ctx context.Context, userID string) ([]*models.Document, error)
+	GetByUserID(ctx context.Context, userID string, filter models.DocumentFilter) (*models.DocumentPage, error)
+	Search(ctx context.Context, userID string, search models.DocumentSearch) ([]*models.DocumentSearchResult, error)
 	Delete(ctx context.Context, id string) error
-	Update(ctx context.Context, id string, update models.DocumentUpdate) error
+	Update(ctx context.Context, id string, update models.DocumentUpdate, updatedBy string) error
+	CountByFile(ctx context.Context, userID, file string) (int64, error)
+	DeleteByUserID(ctx context.Context, userID string) (int64, error)
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	GetByDocumentID(ctx context.Context, documentID string) ([]*models.Comment, error)
+	DeleteByDocumentID(ctx context.Context, documentID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// FileDeleter removes the file a deleted document referenced.
+type FileDeleter interface {
+	DeleteOwnedFile(ctx context.Context, id, ownerID string) error
+}
+
+// GrantRepository removes the grants on a deleted document.
+type GrantRepository interface {
+	DeleteByDocumentID(ctx context.Context, documentID string) error
+}
+
+// ShareLinkRepository takes a deleted document out of the links it was
+// shared through.
+type ShareLinkRepository interface {
+	RemoveDocument(ctx context.Context, documentID string) error
+}
+
+// ProfileResolver finds the profile of a user that documents are filed
+// under, the default profile for an empty profileID.
+type ProfileResolver interface {
+	Resolve(ctx context.Context, userID, profileID string) (*models.Profile, error)
+}
+
+type Authorizer interface {
+	Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error
+}
+
+// Auditor records who read or changed which document.
+type Auditor interface {
+	Record(ctx context.Context, event models.AuditEvent, err error)
+}
+
+type BreakGlassRepository interface {
+	Create(ctx context.Context, access *models.BreakGlassAccess) error
+	GetActive(ctx context.Context, clinicianID, patientID string, now time.Time) (*models.BreakGlassAccess, error)
+}
+
+// Users looks up the patient and clinician of a break-glass access.
+type Users interface {
+	GetProfile(ctx context.Context, userID string) (*models.User, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
 }
@@ -0,0 +1,73 @@
+package document
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/fulltext"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchDocuments finds the documents of the principal's user that match
+// the query, most relevant first, and highlights where each one matched. A
+// profile in the search has to belong to the user.
+func (s *Service) SearchDocuments(ctx context.Context, principal models.Principal, search models.DocumentSearch) (_ []*models.DocumentSearchResult, err error) {
+	event := auditEvent(models.AuditDocumentSearch, "", principal)
+	event.OwnerID = principal.UserID
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return nil, errors.ErrEmptySearch
+	}
+	if search.ProfileID != "" {
+		if _, err := s.profiles.Resolve(ctx, principal.UserID, search.ProfileID); err != nil {
+			return nil, err
+		}
+	}
+	if search.Limit <= 0 {
+		search.Limit = defaultSearchLimit
+	}
+	search.Limit = min(search.Limit, maxSearchLimit)
+
+	results, err := s.repo.Search(ctx, principal.UserID, search)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := fulltext.Terms(search.Query)
+	for _, result := range results {
+		result.Highlights = highlights(result.Document, terms)
+	}
+	return results, nil
+}
+
+// highlights returns a snippet of every field of doc that contains one of
+// terms. Content fields come in the order of their keys.
+func highlights(doc *models.Document, terms []string) []models.Highlight {
+	found := []models.Highlight{}
+	add := func(field, text string) {
+		if snippet, ok := fulltext.Highlight(text, terms); ok {
+			found = append(found, models.Highlight{Field: field, Snippet: snippet})
+		}
+	}
+
+	add("title", doc.Title)
+	add("description", doc.Description)
+	keys := make([]string, 0, len(doc.Content))
+	for key := range doc.Content {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		add("content."+key, doc.Content[key])
+	}
+	return found
+}
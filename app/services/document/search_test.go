@@ -0,0 +1,126 @@
+package document
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+func TestService_SearchDocuments(t *testing.T) {
+	principal := models.Principal{UserID: "user-123"}
+	found := func() []*models.DocumentSearchResult {
+		return []*models.DocumentSearchResult{{
+			Document: &models.Document{
+				ID:          "doc-1",
+				Title:       "Cardiology report",
+				Description: "Follow-up after the ECG",
+				Content: map[string]string{
+					"summary":    "No signs of arrhythmia",
+					"conclusion": "Reports of the cardiologist attached",
+				},
+			},
+			Score: 11.5,
+		}}
+	}
+
+	tests := []struct {
+		name          string
+		search        models.DocumentSearch
+		mockSetup     func(repo *MockDocumentRepository, profiles *MockProfileResolver)
+		expected      []models.Highlight
+		expectedError error
+	}{
+		{
+			name:   "highlights every matching field",
+			search: models.DocumentSearch{Query: "  cardiology reports "},
+			mockSetup: func(repo *MockDocumentRepository, _ *MockProfileResolver) {
+				repo.EXPECT().
+					Search(gomock.Any(), "user-123", models.DocumentSearch{Query: "cardiology reports", Limit: 20}).
+					Return(found(), nil)
+			},
+			expected: []models.Highlight{
+				{Field: "title", Snippet: "<mark>Cardiology</mark> <mark>report</mark>"},
+				{Field: "content.conclusion", Snippet: "<mark>Reports</mark> of the cardiologist attached"},
+			},
+		},
+		{
+			name:   "within a profile and capped",
+			search: models.DocumentSearch{Query: "ecg", ProfileID: "profile-2", Limit: 1000},
+			mockSetup: func(repo *MockDocumentRepository, profiles *MockProfileResolver) {
+				profiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-2").
+					Return(&models.Profile{ID: "profile-2", UserID: "user-123"}, nil)
+				repo.EXPECT().
+					Search(gomock.Any(), "user-123", models.DocumentSearch{Query: "ecg", ProfileID: "profile-2", Limit: 100}).
+					Return(found(), nil)
+			},
+			expected: []models.Highlight{
+				{Field: "description", Snippet: "Follow-up after the <mark>ECG</mark>"},
+			},
+		},
+		{
+			name:          "empty query",
+			search:        models.DocumentSearch{Query: "   "},
+			mockSetup:     func(*MockDocumentRepository, *MockProfileResolver) {},
+			expectedError: errors.ErrEmptySearch,
+		},
+		{
+			name:   "profile of another account",
+			search: models.DocumentSearch{Query: "ecg", ProfileID: "profile-9"},
+			mockSetup: func(_ *MockDocumentRepository, profiles *MockProfileResolver) {
+				profiles.EXPECT().
+					Resolve(gomock.Any(), "user-123", "profile-9").
+					Return(nil, errors.ErrProfileNotFound)
+			},
+			expectedError: errors.ErrProfileNotFound,
+		},
+		{
+			name:   "repository error",
+			search: models.DocumentSearch{Query: "ecg"},
+			mockSetup: func(repo *MockDocumentRepository, _ *MockProfileResolver) {
+				repo.EXPECT().Search(gomock.Any(), "user-123", gomock.Any()).Return(nil, errors.ErrInternal)
+			},
+			expectedError: errors.ErrInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := NewMockDocumentRepository(ctrl)
+			profiles := NewMockProfileResolver(ctrl)
+			audit := NewMockAuditor(ctrl)
+			service := NewService(Dependencies{
+				Documents: repo,
+				Files:     NewMockFileDeleter(ctrl),
+				Profiles:  profiles,
+				Policy:    policy.New(policy.DefaultPermissions, policy.NewMockGrants(ctrl)),
+				Audit:     audit,
+			}, Config{})
+			tt.mockSetup(repo, profiles)
+			audit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+				Action:  models.AuditDocumentSearch,
+				ActorID: "user-123",
+				OwnerID: "user-123",
+			}, tt.expectedError)
+
+			results, err := service.SearchDocuments(context.Background(), principal, tt.search)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, results)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "doc-1", results[0].Document.ID)
+			assert.Equal(t, tt.expected, results[0].Highlights)
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"time"
 
 	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
 )
 
 const (
@@ -19,17 +21,36 @@ type Service struct {
 	repo         FileRepository
 	localStorage Storage
 	gridStorage  Storage
+	profiles     ProfileResolver
+	policy       Authorizer
+	audit        Auditor
+	breakGlass   BreakGlassRepository
 }
 
-func NewService(repo FileRepository, localStorage, gridStorage Storage) *Service {
+func NewService(repo FileRepository, localStorage, gridStorage Storage, profiles ProfileResolver, policy Authorizer, audit Auditor, breakGlass BreakGlassRepository) *Service {
 	return &Service{
 		repo:         repo,
 		localStorage: localStorage,
 		gridStorage:  gridStorage,
+		profiles:     profiles,
+		policy:       policy,
+		audit:        audit,
+		breakGlass:   breakGlass,
 	}
 }
 
-func (s *Service) UploadFile(ctx context.Context, reader io.Reader, metadata models.FileMetadata, userID string) (*models.FileResponse, error) {
+// UploadFile stores a file of the principal's user under the profile given
+// in metadata, or under the default profile of the user.
+func (s *Service) UploadFile(ctx context.Context, reader io.Reader, metadata models.FileMetadata, principal models.Principal) (_ *models.FileResponse, err error) {
+	event := models.AuditEvent{Action: models.AuditFileUpload, ActorID: principal.Actor(), OwnerID: principal.UserID}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	userID := principal.UserID
+	profile, err := s.profiles.Resolve(ctx, userID, metadata.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
 	storageType := "local"
 	if metadata.Size >= smallFileThreshold {
 		storageType = "gridfs"
@@ -37,6 +58,7 @@ func (s *Service) UploadFile(ctx context.Context, reader io.Reader, metadata mod
 
 	fileCreation := &models.FileCreation{
 		UserID:      userID,
+		ProfileID:   profile.ID,
 		StorageType: storageType,
 	}
 
@@ -44,6 +66,7 @@ func (s *Service) UploadFile(ctx context.Context, reader io.Reader, metadata mod
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file record: %w", err)
 	}
+	event.ResourceID = fileRecord.ID
 
 	var err2 error
 	if storageType == "local" {
@@ -57,29 +80,49 @@ func (s *Service) UploadFile(ctx context.Context, reader io.Reader, metadata mod
 	}
 
 	return &models.FileResponse{
-		ID: fileRecord.ID,
+		ID:        fileRecord.ID,
+		ProfileID: fileRecord.ProfileID,
 	}, nil
 }
 
-func (s *Service) DownloadFile(ctx context.Context, id string, userID string) (io.ReadCloser, error) {
-	gridFSID := id
-	if ext := filepath.Ext(id); ext != "" {
-		gridFSID = id[:len(id)-len(ext)]
+func (s *Service) DownloadFile(ctx context.Context, id string, principal models.Principal) (_ io.ReadCloser, err error) {
+	event := models.AuditEvent{Action: models.AuditFileDownload, ActorID: principal.Actor(), ResourceID: trimExt(id)}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	file, err := s.authorizedFile(ctx, id, principal, policy.ActionRead, &event)
+	if err != nil {
+		return nil, err
 	}
 
-	file, err := s.repo.GetByID(ctx, gridFSID)
+	return s.download(ctx, id, file)
+}
+
+// Open returns the content of a file of ownerID without checking a
+// principal. It is meant for callers that authorized the access on their
+// own, such as share links, and is recorded without an actor.
+func (s *Service) Open(ctx context.Context, id, ownerID string) (_ io.ReadCloser, err error) {
+	event := models.AuditEvent{Action: models.AuditFileDownload, OwnerID: ownerID, ResourceID: trimExt(id)}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	file, err := s.repo.GetByID(ctx, trimExt(id))
 	if err != nil {
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, apperrors.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get file: %w", err)
 	}
-
-	if file.UserID != userID {
-		return nil, apperrors.ErrAccessDenied
+	if file.UserID != ownerID {
+		return nil, apperrors.ErrNotFound
 	}
 
-	var reader io.ReadCloser
+	return s.download(ctx, id, file)
+}
+
+func (s *Service) download(ctx context.Context, id string, file *models.FileRecord) (io.ReadCloser, error) {
+	var (
+		reader io.ReadCloser
+		err    error
+	)
 	switch file.StorageType {
 	case "gridfs":
 		reader, err = s.gridStorage.Download(ctx, id)
@@ -95,3 +138,107 @@ func (s *Service) DownloadFile(ctx context.Context, id string, userID string) (i
 
 	return reader, nil
 }
+
+// DeleteFile deletes a file of the user. id may carry an extension, as in
+// DownloadFile.
+func (s *Service) DeleteFile(ctx context.Context, id string, principal models.Principal) (err error) {
+	event := models.AuditEvent{Action: models.AuditFileDelete, ActorID: principal.Actor(), ResourceID: trimExt(id)}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	file, err := s.authorizedFile(ctx, id, principal, policy.ActionDelete, &event)
+	if err != nil {
+		return err
+	}
+
+	return s.Remove(ctx, file)
+}
+
+// DeleteOwnedFile deletes a file of ownerID without checking a principal.
+// It is meant for callers that authorized the deletion on their own, such as
+// the removal of a document's file along with the document, and is recorded
+// without an actor.
+func (s *Service) DeleteOwnedFile(ctx context.Context, id, ownerID string) (err error) {
+	event := models.AuditEvent{Action: models.AuditFileDelete, OwnerID: ownerID, ResourceID: trimExt(id)}
+	defer func() { s.audit.Record(ctx, event, err) }()
+
+	file, err := s.repo.GetByID(ctx, trimExt(id))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to get file: %w", err)
+	}
+	if file.UserID != ownerID {
+		return apperrors.ErrNotFound
+	}
+
+	return s.Remove(ctx, file)
+}
+
+func (s *Service) ListUserFiles(ctx context.Context, userID string) ([]*models.FileRecord, error) {
+	return s.repo.GetByUserID(ctx, userID)
+}
+
+// Remove deletes the stored bytes of a file and then its record. The record
+// goes last so an interrupted removal is found and finished by a later call.
+func (s *Service) Remove(ctx context.Context, file *models.FileRecord) error {
+	var err error
+	switch file.StorageType {
+	case "gridfs":
+		err = s.gridStorage.Delete(ctx, file.ID)
+	case "local":
+		err = s.localStorage.Delete(ctx, file.ID)
+	default:
+		return fmt.Errorf("unknown storage type: %s", file.StorageType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, file.ID); err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return fmt.Errorf("failed to delete file record: %w", err)
+	}
+	return nil
+}
+
+// authorizedFile loads the record of a file and checks that the principal may
+// perform action on it. The owner is noted in event as soon as the file is
+// found, so refused attempts are attributed to it too. Clinicians the policy
+// refuses may still read the file through break-glass access to the owner's
+// records, which is then noted in event as well.
+func (s *Service) authorizedFile(ctx context.Context, id string, principal models.Principal, action policy.Action, event *models.AuditEvent) (*models.FileRecord, error) {
+	file, err := s.repo.GetByID(ctx, trimExt(id))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	event.OwnerID = file.UserID
+
+	resource := policy.Resource{Type: policy.ResourceFile, ID: file.ID, OwnerID: file.UserID}
+	err = s.policy.Authorize(ctx, principal, action, resource)
+	if err == nil {
+		return file, nil
+	}
+	if action != policy.ActionRead || !errors.Is(err, apperrors.ErrAccessDenied) || !principal.CanBreakGlass() {
+		return nil, err
+	}
+
+	access, err := s.breakGlass.GetActive(ctx, principal.UserID, file.UserID, time.Now())
+	if errors.Is(err, apperrors.ErrBreakGlassNotFound) {
+		return nil, apperrors.ErrAccessDenied
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get break-glass access: %w", err)
+	}
+	event.BreakGlassID = access.ID
+	return file, nil
+}
+
+func trimExt(id string) string {
+	if ext := filepath.Ext(id); ext != "" {
+		return id[:len(id)-len(ext)]
+	}
+	return id
+}
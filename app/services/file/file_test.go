@@ -10,7 +10,9 @@ import (
 	"github.com/golang/mock/gomock"
 	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestService_UploadFile(t *testing.T) {
@@ -21,11 +23,16 @@ func TestService_UploadFile(t *testing.T) {
 	mockLocalStorage := NewMockStorage(ctrl)
 	mockGridStorage := NewMockStorage(ctrl)
 
-	service := NewService(mockRepo, mockLocalStorage, mockGridStorage)
+	mockProfiles := NewMockProfileResolver(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(mockRepo, mockLocalStorage, mockGridStorage, mockProfiles, policy.New(policy.DefaultPermissions, mockGrants), ignoreAudit(ctrl), NewMockBreakGlassRepository(ctrl))
+
+	defaultProfile := &models.Profile{ID: "profile123", UserID: "user123", Default: true}
 
 	tests := []struct {
 		name           string
 		fileSize       int64
+		profileID      string
 		expectedError  error
 		setupMocks     func()
 		expectedResult *models.FileResponse
@@ -34,48 +41,58 @@ func TestService_UploadFile(t *testing.T) {
 			name:     "successful small file upload",
 			fileSize: 500 * 1024, // 500KB
 			setupMocks: func() {
+				mockProfiles.EXPECT().Resolve(gomock.Any(), "user123", "").Return(defaultProfile, nil)
 				fileCreation := &models.FileCreation{
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "local",
 				}
 				fileRecord := &models.FileRecord{
 					ID:          "file123",
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "local",
 				}
 				mockRepo.EXPECT().Create(gomock.Any(), fileCreation).Return(fileRecord, nil)
 				mockLocalStorage.EXPECT().Upload(gomock.Any(), "file123", gomock.Any()).Return(nil)
 			},
 			expectedResult: &models.FileResponse{
-				ID: "file123",
+				ID:        "file123",
+				ProfileID: "profile123",
 			},
 		},
 		{
 			name:     "successful large file upload",
 			fileSize: 2 * 1024 * 1024, // 2MB
 			setupMocks: func() {
+				mockProfiles.EXPECT().Resolve(gomock.Any(), "user123", "").Return(defaultProfile, nil)
 				fileCreation := &models.FileCreation{
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "gridfs",
 				}
 				fileRecord := &models.FileRecord{
 					ID:          "file123",
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "gridfs",
 				}
 				mockRepo.EXPECT().Create(gomock.Any(), fileCreation).Return(fileRecord, nil)
 				mockGridStorage.EXPECT().Upload(gomock.Any(), "file123", gomock.Any()).Return(nil)
 			},
 			expectedResult: &models.FileResponse{
-				ID: "file123",
+				ID:        "file123",
+				ProfileID: "profile123",
 			},
 		},
 		{
 			name:     "repository error",
 			fileSize: 500 * 1024,
 			setupMocks: func() {
+				mockProfiles.EXPECT().Resolve(gomock.Any(), "user123", "").Return(defaultProfile, nil)
 				fileCreation := &models.FileCreation{
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "local",
 				}
 				mockRepo.EXPECT().Create(gomock.Any(), fileCreation).Return(nil, errors.New("db error"))
@@ -86,13 +103,16 @@ func TestService_UploadFile(t *testing.T) {
 			name:     "storage error",
 			fileSize: 500 * 1024,
 			setupMocks: func() {
+				mockProfiles.EXPECT().Resolve(gomock.Any(), "user123", "").Return(defaultProfile, nil)
 				fileCreation := &models.FileCreation{
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "local",
 				}
 				fileRecord := &models.FileRecord{
 					ID:          "file123",
 					UserID:      "user123",
+					ProfileID:   "profile123",
 					StorageType: "local",
 				}
 				mockRepo.EXPECT().Create(gomock.Any(), fileCreation).Return(fileRecord, nil)
@@ -100,6 +120,15 @@ func TestService_UploadFile(t *testing.T) {
 			},
 			expectedError: errors.New("failed to upload file: storage error"),
 		},
+		{
+			name:      "profile of another account",
+			fileSize:  500 * 1024,
+			profileID: "other-profile",
+			setupMocks: func() {
+				mockProfiles.EXPECT().Resolve(gomock.Any(), "user123", "other-profile").Return(nil, apperrors.ErrProfileNotFound)
+			},
+			expectedError: apperrors.ErrProfileNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -107,11 +136,12 @@ func TestService_UploadFile(t *testing.T) {
 			tt.setupMocks()
 
 			metadata := models.FileMetadata{
-				Size: tt.fileSize,
+				Size:      tt.fileSize,
+				ProfileID: tt.profileID,
 			}
 			reader := strings.NewReader("test content")
 
-			result, err := service.UploadFile(context.Background(), reader, metadata, "user123")
+			result, err := service.UploadFile(context.Background(), reader, metadata, models.Principal{UserID: "user123"})
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -133,7 +163,8 @@ func TestService_DownloadFile(t *testing.T) {
 	mockLocalStorage := NewMockStorage(ctrl)
 	mockGridStorage := NewMockStorage(ctrl)
 
-	service := NewService(mockRepo, mockLocalStorage, mockGridStorage)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(mockRepo, mockLocalStorage, mockGridStorage, NewMockProfileResolver(ctrl), policy.New(policy.DefaultPermissions, mockGrants), ignoreAudit(ctrl), NewMockBreakGlassRepository(ctrl))
 
 	tests := []struct {
 		name          string
@@ -193,9 +224,26 @@ func TestService_DownloadFile(t *testing.T) {
 					StorageType: "local",
 				}
 				mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+				mockGrants.EXPECT().FilePermission(gomock.Any(), "user123", "file123", "user456").Return("", nil)
 			},
 			expectedError: apperrors.ErrAccessDenied,
 		},
+		{
+			name:   "file of a shared document",
+			fileID: "file123.pdf",
+			userID: "user456",
+			setupMocks: func() {
+				fileRecord := &models.FileRecord{
+					ID:          "file123",
+					UserID:      "user123",
+					StorageType: "local",
+				}
+				mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+				mockGrants.EXPECT().FilePermission(gomock.Any(), "user123", "file123", "user456").Return(models.GrantRead, nil)
+				mockLocalStorage.EXPECT().Download(gomock.Any(), "file123.pdf").Return(io.NopCloser(strings.NewReader("shared content")), nil)
+			},
+			expectedData: "shared content",
+		},
 		{
 			name:   "storage error",
 			fileID: "file123",
@@ -217,7 +265,7 @@ func TestService_DownloadFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMocks()
 
-			reader, err := service.DownloadFile(context.Background(), tt.fileID, tt.userID)
+			reader, err := service.DownloadFile(context.Background(), tt.fileID, models.Principal{UserID: tt.userID})
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -241,3 +289,241 @@ func TestService_DownloadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestService_DownloadFile_BreakGlass(t *testing.T) {
+	clinician := models.Principal{UserID: "clinician-1", Roles: []string{models.RoleClinician}}
+	fileRecord := &models.FileRecord{ID: "file123", UserID: "patient-1", StorageType: "local"}
+
+	tests := []struct {
+		name          string
+		principal     models.Principal
+		setupMocks    func(breakGlass *MockBreakGlassRepository, grants *policy.MockGrants, storage *MockStorage)
+		expectedEvent models.AuditEvent
+		expectedError error
+	}{
+		{
+			name:      "open access",
+			principal: clinician,
+			setupMocks: func(breakGlass *MockBreakGlassRepository, grants *policy.MockGrants, storage *MockStorage) {
+				grants.EXPECT().FilePermission(gomock.Any(), "patient-1", "file123", "clinician-1").Return("", nil)
+				breakGlass.EXPECT().GetActive(gomock.Any(), "clinician-1", "patient-1", gomock.Any()).
+					Return(&models.BreakGlassAccess{ID: "bg-1"}, nil)
+				storage.EXPECT().Download(gomock.Any(), "file123").Return(io.NopCloser(strings.NewReader("scan")), nil)
+			},
+			expectedEvent: models.AuditEvent{
+				Action:       models.AuditFileDownload,
+				ResourceID:   "file123",
+				ActorID:      "clinician-1",
+				OwnerID:      "patient-1",
+				BreakGlassID: "bg-1",
+			},
+		},
+		{
+			name:      "no open access",
+			principal: clinician,
+			setupMocks: func(breakGlass *MockBreakGlassRepository, grants *policy.MockGrants, _ *MockStorage) {
+				grants.EXPECT().FilePermission(gomock.Any(), "patient-1", "file123", "clinician-1").Return("", nil)
+				breakGlass.EXPECT().GetActive(gomock.Any(), "clinician-1", "patient-1", gomock.Any()).
+					Return(nil, apperrors.ErrBreakGlassNotFound)
+			},
+			expectedEvent: models.AuditEvent{
+				Action:     models.AuditFileDownload,
+				ResourceID: "file123",
+				ActorID:    "clinician-1",
+				OwnerID:    "patient-1",
+			},
+			expectedError: apperrors.ErrAccessDenied,
+		},
+		{
+			name:      "not a clinician",
+			principal: models.Principal{UserID: "user-2", Roles: []string{models.RolePatient}},
+			setupMocks: func(_ *MockBreakGlassRepository, grants *policy.MockGrants, _ *MockStorage) {
+				grants.EXPECT().FilePermission(gomock.Any(), "patient-1", "file123", "user-2").Return("", nil)
+			},
+			expectedEvent: models.AuditEvent{
+				Action:     models.AuditFileDownload,
+				ResourceID: "file123",
+				ActorID:    "user-2",
+				OwnerID:    "patient-1",
+			},
+			expectedError: apperrors.ErrAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockFileRepository(ctrl)
+			mockLocalStorage := NewMockStorage(ctrl)
+			mockGrants := policy.NewMockGrants(ctrl)
+			mockBreakGlass := NewMockBreakGlassRepository(ctrl)
+			mockAudit := NewMockAuditor(ctrl)
+			service := NewService(mockRepo, mockLocalStorage, NewMockStorage(ctrl), NewMockProfileResolver(ctrl), policy.New(policy.DefaultPermissions, mockGrants), mockAudit, mockBreakGlass)
+
+			mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+			tt.setupMocks(mockBreakGlass, mockGrants, mockLocalStorage)
+			mockAudit.EXPECT().Record(gomock.Any(), tt.expectedEvent, tt.expectedError)
+
+			reader, err := service.DownloadFile(context.Background(), "file123", tt.principal)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, reader)
+				return
+			}
+			require.NoError(t, err)
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, "scan", string(data))
+		})
+	}
+}
+
+func TestService_DeleteFile_BreakGlass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockFileRepository(ctrl)
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(mockRepo, NewMockStorage(ctrl), NewMockStorage(ctrl), NewMockProfileResolver(ctrl), policy.New(policy.DefaultPermissions, mockGrants), ignoreAudit(ctrl), NewMockBreakGlassRepository(ctrl))
+
+	mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(&models.FileRecord{ID: "file123", UserID: "patient-1"}, nil)
+
+	// Break-glass access only ever lets clinicians read.
+	err := service.DeleteFile(context.Background(), "file123", models.Principal{UserID: "clinician-1", Roles: []string{models.RoleClinician}})
+	assert.ErrorIs(t, err, apperrors.ErrAccessDenied)
+}
+
+func TestService_DeleteFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockFileRepository(ctrl)
+	mockLocalStorage := NewMockStorage(ctrl)
+	mockGridStorage := NewMockStorage(ctrl)
+
+	mockGrants := policy.NewMockGrants(ctrl)
+	service := NewService(mockRepo, mockLocalStorage, mockGridStorage, NewMockProfileResolver(ctrl), policy.New(policy.DefaultPermissions, mockGrants), ignoreAudit(ctrl), NewMockBreakGlassRepository(ctrl))
+
+	tests := []struct {
+		name          string
+		fileID        string
+		userID        string
+		expectedError error
+		setupMocks    func()
+	}{
+		{
+			name:   "successful local file delete",
+			fileID: "file123.pdf",
+			userID: "user123",
+			setupMocks: func() {
+				fileRecord := &models.FileRecord{
+					ID:          "file123",
+					UserID:      "user123",
+					StorageType: "local",
+				}
+				mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+				mockLocalStorage.EXPECT().Delete(gomock.Any(), "file123").Return(nil)
+				mockRepo.EXPECT().Delete(gomock.Any(), "file123").Return(nil)
+			},
+		},
+		{
+			name:   "successful gridfs file delete",
+			fileID: "file123",
+			userID: "user123",
+			setupMocks: func() {
+				fileRecord := &models.FileRecord{
+					ID:          "file123",
+					UserID:      "user123",
+					StorageType: "gridfs",
+				}
+				mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+				mockGridStorage.EXPECT().Delete(gomock.Any(), "file123").Return(nil)
+				mockRepo.EXPECT().Delete(gomock.Any(), "file123").Return(nil)
+			},
+		},
+		{
+			name:   "access denied",
+			fileID: "file123",
+			userID: "user456",
+			setupMocks: func() {
+				fileRecord := &models.FileRecord{
+					ID:          "file123",
+					UserID:      "user123",
+					StorageType: "local",
+				}
+				mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+			},
+			expectedError: apperrors.ErrAccessDenied,
+		},
+		{
+			name:   "storage error keeps the record",
+			fileID: "file123",
+			userID: "user123",
+			setupMocks: func() {
+				fileRecord := &models.FileRecord{
+					ID:          "file123",
+					UserID:      "user123",
+					StorageType: "local",
+				}
+				mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+				mockLocalStorage.EXPECT().Delete(gomock.Any(), "file123").Return(errors.New("storage error"))
+			},
+			expectedError: errors.New("failed to delete file: storage error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			err := service.DeleteFile(context.Background(), tt.fileID, models.Principal{UserID: tt.userID})
+
+			if tt.expectedError != nil {
+				if errors.Is(tt.expectedError, apperrors.ErrAccessDenied) {
+					assert.ErrorIs(t, err, tt.expectedError)
+				} else {
+					assert.ErrorContains(t, err, tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_DeleteOwnedFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockFileRepository(ctrl)
+	mockLocalStorage := NewMockStorage(ctrl)
+	mockAudit := NewMockAuditor(ctrl)
+	service := NewService(mockRepo, mockLocalStorage, NewMockStorage(ctrl), NewMockProfileResolver(ctrl), policy.New(policy.DefaultPermissions, policy.NewMockGrants(ctrl)), mockAudit, NewMockBreakGlassRepository(ctrl))
+
+	fileRecord := &models.FileRecord{ID: "file123", UserID: "user123", StorageType: "local"}
+
+	t.Run("file of the owner", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+		mockLocalStorage.EXPECT().Delete(gomock.Any(), "file123").Return(nil)
+		mockRepo.EXPECT().Delete(gomock.Any(), "file123").Return(nil)
+		mockAudit.EXPECT().Record(gomock.Any(), models.AuditEvent{
+			Action:     models.AuditFileDelete,
+			ResourceID: "file123",
+			OwnerID:    "user123",
+		}, nil)
+
+		assert.NoError(t, service.DeleteOwnedFile(context.Background(), "file123.pdf", "user123"))
+	})
+
+	t.Run("file of someone else", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "file123").Return(fileRecord, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any(), apperrors.ErrNotFound)
+
+		err := service.DeleteOwnedFile(context.Background(), "file123", "user456")
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+// ignoreAudit returns an auditor for tests that do not check audit events.
+func ignoreAudit(ctrl *gomock.Controller) *MockAuditor {
+	audit := NewMockAuditor(ctrl)
+	audit.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	return audit
+}
@@ -3,16 +3,42 @@ package file
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
 )
 
 type Storage interface {
 	Upload(ctx context.Context, id string, reader io.Reader) error
 	Download(ctx context.Context, id string) (io.ReadCloser, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type FileRepository interface {
 	Create(ctx context.Context, file *models.FileCreation) (*models.FileRecord, error)
 	GetByID(ctx context.Context, id string) (*models.FileRecord, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.FileRecord, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ProfileResolver finds the profile of a user that files are stored under,
+// the default profile for an empty profileID.
+type ProfileResolver interface {
+	Resolve(ctx context.Context, userID, profileID string) (*models.Profile, error)
+}
+
+// BreakGlassRepository finds the open emergency access of a clinician to the
+// files of a patient.
+type BreakGlassRepository interface {
+	GetActive(ctx context.Context, clinicianID, patientID string, now time.Time) (*models.BreakGlassAccess, error)
+}
+
+type Authorizer interface {
+	Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error
+}
+
+// Auditor records who uploaded, read or deleted which file.
+type Auditor interface {
+	Record(ctx context.Context, event models.AuditEvent, err error)
 }
@@ -8,9 +8,11 @@ import (
 	context "context"
 	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/gruzdev-dev/meddoc/app/models"
+	policy "github.com/gruzdev-dev/meddoc/app/services/policy"
 )
 
 // MockStorage is a mock of Storage interface.
@@ -36,6 +38,20 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockStorage) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockStorageMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorage)(nil).Delete), ctx, id)
+}
+
 // Download mocks base method.
 func (m *MockStorage) Download(ctx context.Context, id string) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +119,20 @@ func (mr *MockFileRepositoryMockRecorder) Create(ctx, file interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFileRepository)(nil).Create), ctx, file)
 }
 
+// Delete mocks base method.
+func (m *MockFileRepository) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFileRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFileRepository)(nil).Delete), ctx, id)
+}
+
 // GetByID mocks base method.
 func (m *MockFileRepository) GetByID(ctx context.Context, id string) (*models.FileRecord, error) {
 	m.ctrl.T.Helper()
@@ -117,3 +147,166 @@ func (mr *MockFileRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFileRepository)(nil).GetByID), ctx, id)
 }
+
+// GetByUserID mocks base method.
+func (m *MockFileRepository) GetByUserID(ctx context.Context, userID string) ([]*models.FileRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.FileRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockFileRepositoryMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockFileRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// MockProfileResolver is a mock of ProfileResolver interface.
+type MockProfileResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileResolverMockRecorder
+}
+
+// MockProfileResolverMockRecorder is the mock recorder for MockProfileResolver.
+type MockProfileResolverMockRecorder struct {
+	mock *MockProfileResolver
+}
+
+// NewMockProfileResolver creates a new mock instance.
+func NewMockProfileResolver(ctrl *gomock.Controller) *MockProfileResolver {
+	mock := &MockProfileResolver{ctrl: ctrl}
+	mock.recorder = &MockProfileResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileResolver) EXPECT() *MockProfileResolverMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockProfileResolver) Resolve(ctx context.Context, userID, profileID string) (*models.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, userID, profileID)
+	ret0, _ := ret[0].(*models.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockProfileResolverMockRecorder) Resolve(ctx, userID, profileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockProfileResolver)(nil).Resolve), ctx, userID, profileID)
+}
+
+// MockBreakGlassRepository is a mock of BreakGlassRepository interface.
+type MockBreakGlassRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreakGlassRepositoryMockRecorder
+}
+
+// MockBreakGlassRepositoryMockRecorder is the mock recorder for MockBreakGlassRepository.
+type MockBreakGlassRepositoryMockRecorder struct {
+	mock *MockBreakGlassRepository
+}
+
+// NewMockBreakGlassRepository creates a new mock instance.
+func NewMockBreakGlassRepository(ctrl *gomock.Controller) *MockBreakGlassRepository {
+	mock := &MockBreakGlassRepository{ctrl: ctrl}
+	mock.recorder = &MockBreakGlassRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreakGlassRepository) EXPECT() *MockBreakGlassRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetActive mocks base method.
+func (m *MockBreakGlassRepository) GetActive(ctx context.Context, clinicianID, patientID string, now time.Time) (*models.BreakGlassAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", ctx, clinicianID, patientID, now)
+	ret0, _ := ret[0].(*models.BreakGlassAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActive indicates an expected call of GetActive.
+func (mr *MockBreakGlassRepositoryMockRecorder) GetActive(ctx, clinicianID, patientID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockBreakGlassRepository)(nil).GetActive), ctx, clinicianID, patientID, now)
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockAuthorizer) Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, principal, action, resource)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAuthorizerMockRecorder) Authorize(ctx, principal, action, resource interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthorizer)(nil).Authorize), ctx, principal, action, resource)
+}
+
+// MockAuditor is a mock of Auditor interface.
+type MockAuditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditorMockRecorder
+}
+
+// MockAuditorMockRecorder is the mock recorder for MockAuditor.
+type MockAuditorMockRecorder struct {
+	mock *MockAuditor
+}
+
+// NewMockAuditor creates a new mock instance.
+func NewMockAuditor(ctrl *gomock.Controller) *MockAuditor {
+	mock := &MockAuditor{ctrl: ctrl}
+	mock.recorder = &MockAuditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditor) EXPECT() *MockAuditorMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditor) Record(ctx context.Context, event models.AuditEvent, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, event, err)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditorMockRecorder) Record(ctx, event, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditor)(nil).Record), ctx, event, err)
+}
@@ -0,0 +1,143 @@
+package grant
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+// Service shares documents with other registered users. Access through a
+// grant is enforced by the policy, which looks grants up on its own.
+type Service struct {
+	grants    GrantRepository
+	documents DocumentRepository
+	users     UserRepository
+	policy    Authorizer
+}
+
+func NewService(grants GrantRepository, documents DocumentRepository, users UserRepository, policy Authorizer) *Service {
+	return &Service{
+		grants:    grants,
+		documents: documents,
+		users:     users,
+		policy:    policy,
+	}
+}
+
+// ShareDocument grants the user registered with data.Email access to a
+// document. Sharing a document with the same user again replaces the
+// permission and expiry of the earlier grant.
+func (s *Service) ShareDocument(ctx context.Context, documentID string, data models.GrantCreation, principal models.Principal) (*models.Grant, error) {
+	doc, err := s.shareableDocument(ctx, documentID, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.IsValidGrantPermission(data.Permission) {
+		return nil, apperrors.ErrInvalidGrant
+	}
+	now := time.Now()
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(now) {
+		return nil, apperrors.ErrInvalidGrant
+	}
+
+	grantee, err := s.users.GetByEmail(ctx, data.Email)
+	if err != nil {
+		return nil, err
+	}
+	if grantee.ID == doc.UserID {
+		return nil, apperrors.ErrInvalidGrant
+	}
+
+	grant := &models.Grant{
+		DocumentID: doc.ID,
+		OwnerID:    doc.UserID,
+		GranteeID:  grantee.ID,
+		Permission: data.Permission,
+		ExpiresAt:  data.ExpiresAt,
+		CreatedAt:  now,
+	}
+	if err := s.grants.Upsert(ctx, grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// ListGrants returns the active grants of a document.
+func (s *Service) ListGrants(ctx context.Context, documentID string, principal models.Principal) ([]*models.Grant, error) {
+	if _, err := s.shareableDocument(ctx, documentID, principal); err != nil {
+		return nil, err
+	}
+	return s.grants.GetByDocumentID(ctx, documentID)
+}
+
+// RevokeGrant removes a grant. Access ends with the next request of the
+// grantee.
+func (s *Service) RevokeGrant(ctx context.Context, documentID, grantID string, principal models.Principal) error {
+	if _, err := s.shareableDocument(ctx, documentID, principal); err != nil {
+		return err
+	}
+	return s.grants.Delete(ctx, grantID, documentID)
+}
+
+// SharedWithUser lists the documents other users currently share with
+// userID. Grants whose document was deleted are skipped.
+func (s *Service) SharedWithUser(ctx context.Context, userID string) ([]*models.SharedDocument, error) {
+	grants, err := s.grants.GetByGranteeID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		return []*models.SharedDocument{}, nil
+	}
+
+	ids := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		ids = append(ids, grant.DocumentID)
+	}
+	docs, err := s.documents.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Document, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+	}
+
+	shared := make([]*models.SharedDocument, 0, len(grants))
+	for _, grant := range grants {
+		doc, ok := byID[grant.DocumentID]
+		if !ok || doc.UserID != grant.OwnerID {
+			continue
+		}
+		shared = append(shared, &models.SharedDocument{
+			Document:   doc,
+			Permission: grant.Permission,
+			ExpiresAt:  grant.ExpiresAt,
+		})
+	}
+	return shared, nil
+}
+
+// DeleteUserGrants removes the grants a user gave and received.
+func (s *Service) DeleteUserGrants(ctx context.Context, userID string) error {
+	return s.grants.DeleteByUserID(ctx, userID)
+}
+
+// shareableDocument loads a document and checks that the principal may
+// manage its grants.
+func (s *Service) shareableDocument(ctx context.Context, documentID string, principal models.Principal) (*models.Document, error) {
+	doc, err := s.documents.GetByID(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := policy.Resource{Type: policy.ResourceDocument, ID: doc.ID, OwnerID: doc.UserID}
+	if err := s.policy.Authorize(ctx, principal, policy.ActionShare, resource); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,197 @@
+package grant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+func TestService_ShareDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGrants := NewMockGrantRepository(ctrl)
+	mockDocuments := NewMockDocumentRepository(ctrl)
+	mockUsers := NewMockUserRepository(ctrl)
+	service := NewService(mockGrants, mockDocuments, mockUsers, policy.New(policy.DefaultPermissions, policy.NewMockGrants(ctrl)))
+
+	doc := &models.Document{ID: "doc-123", UserID: "owner"}
+	grantee := &models.User{ID: "grantee", Email: "doctor@example.com"}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		userID        string
+		data          models.GrantCreation
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name:   "successful share",
+			userID: "owner",
+			data:   models.GrantCreation{Email: "doctor@example.com", Permission: models.GrantEdit, ExpiresAt: &future},
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockUsers.EXPECT().GetByEmail(gomock.Any(), "doctor@example.com").Return(grantee, nil)
+				mockGrants.EXPECT().Upsert(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, grant *models.Grant) error {
+					assert.Equal(t, "doc-123", grant.DocumentID)
+					assert.Equal(t, "owner", grant.OwnerID)
+					assert.Equal(t, "grantee", grant.GranteeID)
+					assert.Equal(t, models.GrantEdit, grant.Permission)
+					assert.Equal(t, &future, grant.ExpiresAt)
+					grant.ID = "grant-1"
+					return nil
+				})
+			},
+		},
+		{
+			name:   "only the owner may share",
+			userID: "grantee",
+			data:   models.GrantCreation{Email: "doctor@example.com", Permission: models.GrantRead},
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:   "document not found",
+			userID: "owner",
+			data:   models.GrantCreation{Email: "doctor@example.com", Permission: models.GrantRead},
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(nil, errors.ErrDocumentNotFound)
+			},
+			expectedError: errors.ErrDocumentNotFound,
+		},
+		{
+			name:   "expiry in the past",
+			userID: "owner",
+			data:   models.GrantCreation{Email: "doctor@example.com", Permission: models.GrantRead, ExpiresAt: &past},
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+			},
+			expectedError: errors.ErrInvalidGrant,
+		},
+		{
+			name:   "unknown grantee",
+			userID: "owner",
+			data:   models.GrantCreation{Email: "nobody@example.com", Permission: models.GrantRead},
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockUsers.EXPECT().GetByEmail(gomock.Any(), "nobody@example.com").Return(nil, errors.ErrUserNotFound)
+			},
+			expectedError: errors.ErrUserNotFound,
+		},
+		{
+			name:   "sharing with oneself",
+			userID: "owner",
+			data:   models.GrantCreation{Email: "owner@example.com", Permission: models.GrantRead},
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockUsers.EXPECT().GetByEmail(gomock.Any(), "owner@example.com").Return(&models.User{ID: "owner"}, nil)
+			},
+			expectedError: errors.ErrInvalidGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			grant, err := service.ShareDocument(context.Background(), "doc-123", tt.data, models.Principal{UserID: tt.userID})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, grant)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "grant-1", grant.ID)
+			}
+		})
+	}
+}
+
+func TestService_RevokeGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGrants := NewMockGrantRepository(ctrl)
+	mockDocuments := NewMockDocumentRepository(ctrl)
+	service := NewService(mockGrants, mockDocuments, NewMockUserRepository(ctrl), policy.New(policy.DefaultPermissions, policy.NewMockGrants(ctrl)))
+
+	doc := &models.Document{ID: "doc-123", UserID: "owner"}
+
+	tests := []struct {
+		name          string
+		userID        string
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name:   "successful revoke",
+			userID: "owner",
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockGrants.EXPECT().Delete(gomock.Any(), "grant-1", "doc-123").Return(nil)
+			},
+		},
+		{
+			name:   "grant not found",
+			userID: "owner",
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+				mockGrants.EXPECT().Delete(gomock.Any(), "grant-1", "doc-123").Return(errors.ErrGrantNotFound)
+			},
+			expectedError: errors.ErrGrantNotFound,
+		},
+		{
+			name:   "grantee may not revoke",
+			userID: "grantee",
+			setupMocks: func() {
+				mockDocuments.EXPECT().GetByID(gomock.Any(), "doc-123").Return(doc, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			err := service.RevokeGrant(context.Background(), "doc-123", "grant-1", models.Principal{UserID: tt.userID})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_SharedWithUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGrants := NewMockGrantRepository(ctrl)
+	mockDocuments := NewMockDocumentRepository(ctrl)
+	service := NewService(mockGrants, mockDocuments, NewMockUserRepository(ctrl), policy.New(policy.DefaultPermissions, policy.NewMockGrants(ctrl)))
+
+	mockGrants.EXPECT().GetByGranteeID(gomock.Any(), "grantee").Return([]*models.Grant{
+		{DocumentID: "doc-1", OwnerID: "owner", GranteeID: "grantee", Permission: models.GrantRead},
+		{DocumentID: "doc-2", OwnerID: "owner", GranteeID: "grantee", Permission: models.GrantEdit},
+	}, nil)
+	mockDocuments.EXPECT().GetByIDs(gomock.Any(), []string{"doc-1", "doc-2"}).Return([]*models.Document{
+		{ID: "doc-2", Title: "Blood test", UserID: "owner"},
+	}, nil)
+
+	shared, err := service.SharedWithUser(context.Background(), "grantee")
+	assert.NoError(t, err)
+	if assert.Len(t, shared, 1) {
+		assert.Equal(t, "doc-2", shared[0].Document.ID)
+		assert.Equal(t, models.GrantEdit, shared[0].Permission)
+	}
+}
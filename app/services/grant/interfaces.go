@@ -0,0 +1,30 @@
+package grant
+
+import (
+	"context"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+type GrantRepository interface {
+	Upsert(ctx context.Context, grant *models.Grant) error
+	GetByDocumentID(ctx context.Context, documentID string) ([]*models.Grant, error)
+	GetByGranteeID(ctx context.Context, granteeID string) ([]*models.Grant, error)
+	Delete(ctx context.Context, id, documentID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type DocumentRepository interface {
+	GetByID(ctx context.Context, id string) (*models.Document, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Document, error)
+}
+
+// UserRepository finds the grantee a document is shared with.
+type UserRepository interface {
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+type Authorizer interface {
+	Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error
+}
@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/grant/interfaces.go
+
+// Package grant is a generated GoMock package.
+package grant
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/gruzdev-dev/meddoc/app/models"
+	policy "github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+// MockGrantRepository is a mock of GrantRepository interface.
+type MockGrantRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantRepositoryMockRecorder
+}
+
+// MockGrantRepositoryMockRecorder is the mock recorder for MockGrantRepository.
+type MockGrantRepositoryMockRecorder struct {
+	mock *MockGrantRepository
+}
+
+// NewMockGrantRepository creates a new mock instance.
+func NewMockGrantRepository(ctrl *gomock.Controller) *MockGrantRepository {
+	mock := &MockGrantRepository{ctrl: ctrl}
+	mock.recorder = &MockGrantRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrantRepository) EXPECT() *MockGrantRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockGrantRepository) Delete(ctx context.Context, id, documentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, documentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGrantRepositoryMockRecorder) Delete(ctx, id, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGrantRepository)(nil).Delete), ctx, id, documentID)
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockGrantRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockGrantRepositoryMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockGrantRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
+// GetByDocumentID mocks base method.
+func (m *MockGrantRepository) GetByDocumentID(ctx context.Context, documentID string) ([]*models.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByDocumentID", ctx, documentID)
+	ret0, _ := ret[0].([]*models.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByDocumentID indicates an expected call of GetByDocumentID.
+func (mr *MockGrantRepositoryMockRecorder) GetByDocumentID(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDocumentID", reflect.TypeOf((*MockGrantRepository)(nil).GetByDocumentID), ctx, documentID)
+}
+
+// GetByGranteeID mocks base method.
+func (m *MockGrantRepository) GetByGranteeID(ctx context.Context, granteeID string) ([]*models.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByGranteeID", ctx, granteeID)
+	ret0, _ := ret[0].([]*models.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByGranteeID indicates an expected call of GetByGranteeID.
+func (mr *MockGrantRepositoryMockRecorder) GetByGranteeID(ctx, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByGranteeID", reflect.TypeOf((*MockGrantRepository)(nil).GetByGranteeID), ctx, granteeID)
+}
+
+// Upsert mocks base method.
+func (m *MockGrantRepository) Upsert(ctx context.Context, grant *models.Grant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockGrantRepositoryMockRecorder) Upsert(ctx, grant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockGrantRepository)(nil).Upsert), ctx, grant)
+}
+
+// MockDocumentRepository is a mock of DocumentRepository interface.
+type MockDocumentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentRepositoryMockRecorder
+}
+
+// MockDocumentRepositoryMockRecorder is the mock recorder for MockDocumentRepository.
+type MockDocumentRepositoryMockRecorder struct {
+	mock *MockDocumentRepository
+}
+
+// NewMockDocumentRepository creates a new mock instance.
+func NewMockDocumentRepository(ctrl *gomock.Controller) *MockDocumentRepository {
+	mock := &MockDocumentRepository{ctrl: ctrl}
+	mock.recorder = &MockDocumentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentRepository) EXPECT() *MockDocumentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByID mocks base method.
+func (m *MockDocumentRepository) GetByID(ctx context.Context, id string) (*models.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockDocumentRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockDocumentRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByIDs mocks base method.
+func (m *MockDocumentRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*models.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockDocumentRepositoryMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockDocumentRepository)(nil).GetByIDs), ctx, ids)
+}
+
+// MockUserRepository is a mock of UserRepository interface.
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance.
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByEmail mocks base method.
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUserRepositoryMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserRepository)(nil).GetByEmail), ctx, email)
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockAuthorizer) Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, principal, action, resource)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAuthorizerMockRecorder) Authorize(ctx, principal, action, resource interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthorizer)(nil).Authorize), ctx, principal, action, resource)
+}
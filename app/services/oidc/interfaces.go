@@ -0,0 +1,17 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type LoginRepository interface {
+	Create(ctx context.Context, login *models.OIDCLogin) error
+	Consume(ctx context.Context, stateHash string) (*models.OIDCLogin, error)
+}
+
+// Users signs in the users providers authenticated.
+type Users interface {
+	LoginWithIdentity(ctx context.Context, login models.ExternalLogin, client models.ClientInfo) (*models.LoginResult, error)
+}
@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/oidc/interfaces.go
+
+// Package oidc is a generated GoMock package.
+package oidc
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// MockLoginRepository is a mock of LoginRepository interface.
+type MockLoginRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginRepositoryMockRecorder
+}
+
+// MockLoginRepositoryMockRecorder is the mock recorder for MockLoginRepository.
+type MockLoginRepositoryMockRecorder struct {
+	mock *MockLoginRepository
+}
+
+// NewMockLoginRepository creates a new mock instance.
+func NewMockLoginRepository(ctrl *gomock.Controller) *MockLoginRepository {
+	mock := &MockLoginRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginRepository) EXPECT() *MockLoginRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockLoginRepository) Consume(ctx context.Context, stateHash string) (*models.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, stateHash)
+	ret0, _ := ret[0].(*models.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockLoginRepositoryMockRecorder) Consume(ctx, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockLoginRepository)(nil).Consume), ctx, stateHash)
+}
+
+// Create mocks base method.
+func (m *MockLoginRepository) Create(ctx context.Context, login *models.OIDCLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockLoginRepositoryMockRecorder) Create(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLoginRepository)(nil).Create), ctx, login)
+}
+
+// MockUsers is a mock of Users interface.
+type MockUsers struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersMockRecorder
+}
+
+// MockUsersMockRecorder is the mock recorder for MockUsers.
+type MockUsersMockRecorder struct {
+	mock *MockUsers
+}
+
+// NewMockUsers creates a new mock instance.
+func NewMockUsers(ctrl *gomock.Controller) *MockUsers {
+	mock := &MockUsers{ctrl: ctrl}
+	mock.recorder = &MockUsersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
+	return m.recorder
+}
+
+// LoginWithIdentity mocks base method.
+func (m *MockUsers) LoginWithIdentity(ctx context.Context, login models.ExternalLogin, client models.ClientInfo) (*models.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithIdentity", ctx, login, client)
+	ret0, _ := ret[0].(*models.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithIdentity indicates an expected call of LoginWithIdentity.
+func (mr *MockUsersMockRecorder) LoginWithIdentity(ctx, login, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithIdentity", reflect.TypeOf((*MockUsers)(nil).LoginWithIdentity), ctx, login, client)
+}
@@ -0,0 +1,171 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/config"
+)
+
+type Dependencies struct {
+	Logins LoginRepository
+	Users  Users
+}
+
+type Config struct {
+	Providers []ProviderConfig
+	// LoginTTL is how long a user has to finish signing in at a provider.
+	LoginTTL time.Duration
+	// HTTPClient talks to the providers. http.DefaultClient with a timeout
+	// is used when it is nil.
+	HTTPClient *http.Client
+}
+
+// Service signs users in with OpenID Connect providers using the
+// authorization code flow with PKCE. The state, nonce and code verifier of a
+// started sign-in are kept server-side until the provider redirects back.
+type Service struct {
+	logins    LoginRepository
+	users     Users
+	providers map[string]*provider
+	loginTTL  time.Duration
+}
+
+func NewService(deps Dependencies, cfg Config) *Service {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	providers := make(map[string]*provider, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		providers[p.Name] = newProvider(p, client)
+	}
+
+	return &Service{
+		logins:    deps.Logins,
+		users:     deps.Users,
+		providers: providers,
+		loginTTL:  cfg.LoginTTL,
+	}
+}
+
+func NewServiceFromConfig(deps Dependencies, cfg *config.Config) *Service {
+	providers := make([]ProviderConfig, 0, len(cfg.Auth.OIDC.Providers))
+	for _, p := range cfg.Auth.OIDC.Providers {
+		providers = append(providers, ProviderConfig{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+
+	return NewService(deps, Config{
+		Providers: providers,
+		LoginTTL:  cfg.Auth.OIDC.LoginTTL,
+	})
+}
+
+// StartLogin begins signing in with a provider and returns the URL the user
+// has to be sent to. The returned state has to come back with the callback.
+func (s *Service) StartLogin(ctx context.Context, providerName string) (*models.OIDCLoginStart, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, apperrors.ErrOIDCProviderNotFound
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	authURL, err := p.authCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	login := &models.OIDCLogin{
+		StateHash:    hashState(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(s.loginTTL),
+		CreatedAt:    now,
+	}
+	if err := s.logins.Create(ctx, login); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCLoginStart{AuthURL: authURL, State: state, ExpiresAt: login.ExpiresAt}, nil
+}
+
+// CompleteLogin redeems the code the provider redirected back with, verifies
+// the ID token and signs in the user it identifies. Every state can be used
+// once.
+func (s *Service) CompleteLogin(ctx context.Context, providerName, state, code string, client models.ClientInfo) (*models.LoginResult, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, apperrors.ErrOIDCProviderNotFound
+	}
+	if state == "" || code == "" {
+		return nil, apperrors.ErrInvalidOIDCLogin
+	}
+
+	login, err := s.logins.Consume(ctx, hashState(state))
+	if err != nil {
+		return nil, err
+	}
+	if login.Provider != providerName {
+		return nil, apperrors.ErrInvalidOIDCLogin
+	}
+
+	rawIDToken, err := p.exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.verify(ctx, rawIDToken, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.users.LoginWithIdentity(ctx, models.ExternalLogin{
+		Provider:      providerName,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, client)
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,233 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc/oidctest"
+)
+
+const redirectURL = "https://meddoc.example/api/v1/auth/oidc/corp/callback"
+
+var testUser = oidctest.User{
+	Subject:       "sub-123",
+	Email:         "test@example.com",
+	EmailVerified: true,
+	Name:          "Test User",
+}
+
+type mocks struct {
+	logins *MockLoginRepository
+	users  *MockUsers
+}
+
+func newTestService(t *testing.T, issuer string) (*Service, mocks) {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		logins: NewMockLoginRepository(ctrl),
+		users:  NewMockUsers(ctrl),
+	}
+	service := NewService(Dependencies{Logins: m.logins, Users: m.users}, Config{
+		Providers: []ProviderConfig{{
+			Name:         "corp",
+			Issuer:       issuer,
+			ClientID:     "meddoc",
+			ClientSecret: "secret",
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"openid", "email"},
+		}},
+		LoginTTL: time.Minute,
+	})
+	return service, m
+}
+
+// authorize starts a login and follows it to the provider, returning the
+// stored login and the state and code the provider redirected back with.
+func authorize(t *testing.T, service *Service, m mocks) (*models.OIDCLogin, string, string) {
+	var stored *models.OIDCLogin
+	m.logins.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, login *models.OIDCLogin) error {
+		stored = login
+		return nil
+	})
+
+	start, err := service.StartLogin(context.Background(), "corp")
+	require.NoError(t, err)
+	assert.Equal(t, hashState(start.State), stored.StateHash)
+	assert.Equal(t, "corp", stored.Provider)
+
+	authURL, err := url.Parse(start.AuthURL)
+	require.NoError(t, err)
+	assert.Equal(t, "openid email", authURL.Query().Get("scope"))
+	assert.Equal(t, stored.Nonce, authURL.Query().Get("nonce"))
+	assert.NotEqual(t, stored.CodeVerifier, authURL.Query().Get("code_challenge"))
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(start.AuthURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := resp.Location()
+	require.NoError(t, err)
+	assert.Equal(t, start.State, callback.Query().Get("state"))
+	return stored, callback.Query().Get("state"), callback.Query().Get("code")
+}
+
+func TestService_CompleteLogin(t *testing.T) {
+	tests := []struct {
+		name          string
+		modifyClaims  func(jwt.MapClaims)
+		modifyLogin   func(*models.OIDCLogin)
+		expectedError error
+	}{
+		{
+			name: "successful login",
+		},
+		{
+			name:          "nonce mismatch",
+			modifyClaims:  func(c jwt.MapClaims) { c["nonce"] = "other" },
+			expectedError: errors.ErrInvalidIDToken,
+		},
+		{
+			name:          "wrong audience",
+			modifyClaims:  func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+			expectedError: errors.ErrInvalidIDToken,
+		},
+		{
+			name: "issued to another party",
+			modifyClaims: func(c jwt.MapClaims) {
+				c["aud"] = []string{"meddoc", "someone-else"}
+				c["azp"] = "someone-else"
+			},
+			expectedError: errors.ErrInvalidIDToken,
+		},
+		{
+			name:          "wrong issuer",
+			modifyClaims:  func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+			expectedError: errors.ErrInvalidIDToken,
+		},
+		{
+			name:          "expired token",
+			modifyClaims:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			expectedError: errors.ErrInvalidIDToken,
+		},
+		{
+			name:          "wrong code verifier",
+			modifyLogin:   func(l *models.OIDCLogin) { l.CodeVerifier = "tampered" },
+			expectedError: errors.ErrInvalidOIDCLogin,
+		},
+		{
+			name:          "login started for another provider",
+			modifyLogin:   func(l *models.OIDCLogin) { l.Provider = "other" },
+			expectedError: errors.ErrInvalidOIDCLogin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := oidctest.NewProvider("meddoc", "secret")
+			defer provider.Close()
+			provider.SignIn(testUser)
+			provider.ModifyClaims(tt.modifyClaims)
+
+			service, m := newTestService(t, provider.Issuer())
+			login, state, code := authorize(t, service, m)
+			if tt.modifyLogin != nil {
+				tt.modifyLogin(login)
+			}
+			m.logins.EXPECT().Consume(gomock.Any(), hashState(state)).Return(login, nil)
+
+			if tt.expectedError == nil {
+				m.users.EXPECT().LoginWithIdentity(gomock.Any(), models.ExternalLogin{
+					Provider:      "corp",
+					Subject:       "sub-123",
+					Email:         "test@example.com",
+					EmailVerified: true,
+					Name:          "Test User",
+				}, gomock.Any()).Return(&models.LoginResult{Tokens: &models.TokenPair{AccessToken: "access"}}, nil)
+			}
+
+			result, err := service.CompleteLogin(context.Background(), "corp", state, code, models.ClientInfo{})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "access", result.Tokens.AccessToken)
+		})
+	}
+}
+
+func TestService_CompleteLoginErrors(t *testing.T) {
+	provider := oidctest.NewProvider("meddoc", "secret")
+	defer provider.Close()
+	provider.SignIn(testUser)
+
+	t.Run("unknown provider", func(t *testing.T) {
+		service, _ := newTestService(t, provider.Issuer())
+
+		_, err := service.StartLogin(context.Background(), "other")
+		assert.ErrorIs(t, err, errors.ErrOIDCProviderNotFound)
+		_, err = service.CompleteLogin(context.Background(), "other", "state", "code", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrOIDCProviderNotFound)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		service, m := newTestService(t, provider.Issuer())
+		m.logins.EXPECT().Consume(gomock.Any(), hashState("state")).Return(nil, errors.ErrInvalidOIDCLogin)
+
+		_, err := service.CompleteLogin(context.Background(), "corp", "state", "code", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidOIDCLogin)
+	})
+
+	t.Run("code used twice", func(t *testing.T) {
+		service, m := newTestService(t, provider.Issuer())
+		login, state, code := authorize(t, service, m)
+		m.logins.EXPECT().Consume(gomock.Any(), hashState(state)).Return(login, nil).Times(2)
+		m.users.EXPECT().LoginWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Return(&models.LoginResult{}, nil)
+
+		_, err := service.CompleteLogin(context.Background(), "corp", state, code, models.ClientInfo{})
+		require.NoError(t, err)
+		_, err = service.CompleteLogin(context.Background(), "corp", state, code, models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidOIDCLogin)
+	})
+
+	t.Run("rotated signing key", func(t *testing.T) {
+		service, m := newTestService(t, provider.Issuer())
+		m.users.EXPECT().LoginWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Return(&models.LoginResult{}, nil).Times(2)
+
+		login, state, code := authorize(t, service, m)
+		m.logins.EXPECT().Consume(gomock.Any(), hashState(state)).Return(login, nil)
+		_, err := service.CompleteLogin(context.Background(), "corp", state, code, models.ClientInfo{})
+		require.NoError(t, err)
+
+		// The cached JWKS is only refetched once keysRefreshInterval passed.
+		service.providers["corp"].keysFetched = time.Now().Add(-keysRefreshInterval)
+		provider.RotateKey()
+		login, state, code = authorize(t, service, m)
+		m.logins.EXPECT().Consume(gomock.Any(), hashState(state)).Return(login, nil)
+		_, err = service.CompleteLogin(context.Background(), "corp", state, code, models.ClientInfo{})
+		require.NoError(t, err)
+	})
+
+	t.Run("provider unavailable", func(t *testing.T) {
+		down := oidctest.NewProvider("meddoc", "secret")
+		down.Close()
+		service, _ := newTestService(t, down.Issuer())
+
+		_, err := service.StartLogin(context.Background(), "corp")
+		assert.ErrorIs(t, err, errors.ErrOIDCProviderUnavailable)
+	})
+}
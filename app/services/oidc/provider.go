@@ -0,0 +1,391 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+)
+
+const (
+	// discoveryTTL is how long provider metadata is reused before it is
+	// fetched again.
+	discoveryTTL = time.Hour
+	// keysRefreshInterval limits how often an unknown key ID makes us fetch
+	// the provider's JWKS again.
+	keysRefreshInterval = time.Minute
+	maxResponseSize     = 1 << 20
+	clockSkew           = time.Minute
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type ProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+type signingKey struct {
+	algorithm string
+	public    any
+}
+
+// claimBool accepts booleans some providers send as strings.
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	*b = claimBool(string(data) == "true" || string(data) == `"true"`)
+	return nil
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string    `json:"nonce"`
+	AuthorizedParty string    `json:"azp"`
+	Email           string    `json:"email"`
+	EmailVerified   claimBool `json:"email_verified"`
+	Name            string    `json:"name"`
+}
+
+// provider talks to one OpenID Connect provider. Its metadata and signing
+// keys are fetched on first use and cached.
+type provider struct {
+	cfg    ProviderConfig
+	client *http.Client
+
+	mu              sync.Mutex
+	metadata        *metadata
+	metadataFetched time.Time
+	keys            map[string]signingKey
+	keysFetched     time.Time
+}
+
+func newProvider(cfg ProviderConfig, client *http.Client) *provider {
+	return &provider{cfg: cfg, client: client}
+}
+
+// authCodeURL returns the authorization request for the code flow with PKCE.
+func (p *provider) authCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint", apperrors.ErrOIDCProviderUnavailable)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// exchange redeems an authorization code and returns the raw ID token.
+func (p *provider) exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", apperrors.ErrOIDCProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: invalid token response: %v", apperrors.ErrOIDCProviderUnavailable, err)
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		// The code was used, expired or issued to someone else.
+		return "", fmt.Errorf("%w: %s %s", apperrors.ErrInvalidOIDCLogin, body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token endpoint returned %d", apperrors.ErrOIDCProviderUnavailable, resp.StatusCode)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: no ID token in response", apperrors.ErrInvalidIDToken)
+	}
+	return body.IDToken, nil
+}
+
+// verify checks the ID token's signature against the provider's JWKS and its
+// issuer, audience, lifetime and nonce.
+func (p *provider) verify(ctx context.Context, rawIDToken, nonce string) (*idTokenClaims, error) {
+	if _, err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.signingKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.algorithm != "" && key.algorithm != token.Method.Alg() {
+			return nil, fmt.Errorf("key %q is not for %s", kid, token.Method.Alg())
+		}
+		return key.public, nil
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if errors.Is(err, apperrors.ErrOIDCProviderUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", apperrors.ErrInvalidIDToken)
+	}
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: issued to another party", apperrors.ErrInvalidIDToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", apperrors.ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+func (p *provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil && time.Since(p.metadataFetched) < discoveryTTL {
+		return p.metadata, nil
+	}
+
+	var meta metadata
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, err
+	}
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: discovery document is for issuer %q", apperrors.ErrOIDCProviderUnavailable, meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete discovery document", apperrors.ErrOIDCProviderUnavailable)
+	}
+
+	p.metadata = &meta
+	p.metadataFetched = time.Now()
+	return p.metadata, nil
+}
+
+// signingKey looks up a key by ID. Unknown IDs refetch the JWKS, at most once
+// per keysRefreshInterval, so key rotations at the provider are picked up.
+// Tokens without a key ID are accepted when the JWKS holds a single key.
+func (p *provider) signingKey(ctx context.Context, kid string) (signingKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetched) < keysRefreshInterval {
+		return signingKey{}, fmt.Errorf("unknown key %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &set); err != nil {
+		return signingKey{}, err
+	}
+
+	keys := make(map[string]signingKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := parseJWK(jwk)
+		if err != nil {
+			// Keys of types we do not support cannot have signed a token
+			// we accept, so they are skipped.
+			continue
+		}
+		keys[jwk.KeyID] = signingKey{algorithm: jwk.Algorithm, public: public}
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return signingKey{}, fmt.Errorf("unknown key %q", kid)
+}
+
+func (p *provider) lookupKey(kid string) (signingKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *provider) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", apperrors.ErrOIDCProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", apperrors.ErrOIDCProviderUnavailable, endpoint, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: invalid response from %s: %v", apperrors.ErrOIDCProviderUnavailable, endpoint, err)
+	}
+	return nil
+}
+
+func parseJWK(jwk jsonWebKey) (any, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, err
+		}
+		return key, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,12 @@
+package policy
+
+import (
+	"context"
+)
+
+// Grants looks up what owners have shared with a user. Both methods return
+// "" when nothing is shared.
+type Grants interface {
+	DocumentPermission(ctx context.Context, documentID, granteeID string) (string, error)
+	FilePermission(ctx context.Context, ownerID, fileID, granteeID string) (string, error)
+}
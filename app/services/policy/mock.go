@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/policy/interfaces.go
+
+// Package policy is a generated GoMock package.
+package policy
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGrants is a mock of Grants interface.
+type MockGrants struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantsMockRecorder
+}
+
+// MockGrantsMockRecorder is the mock recorder for MockGrants.
+type MockGrantsMockRecorder struct {
+	mock *MockGrants
+}
+
+// NewMockGrants creates a new mock instance.
+func NewMockGrants(ctrl *gomock.Controller) *MockGrants {
+	mock := &MockGrants{ctrl: ctrl}
+	mock.recorder = &MockGrantsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrants) EXPECT() *MockGrantsMockRecorder {
+	return m.recorder
+}
+
+// DocumentPermission mocks base method.
+func (m *MockGrants) DocumentPermission(ctx context.Context, documentID, granteeID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DocumentPermission", ctx, documentID, granteeID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DocumentPermission indicates an expected call of DocumentPermission.
+func (mr *MockGrantsMockRecorder) DocumentPermission(ctx, documentID, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DocumentPermission", reflect.TypeOf((*MockGrants)(nil).DocumentPermission), ctx, documentID, granteeID)
+}
+
+// FilePermission mocks base method.
+func (m *MockGrants) FilePermission(ctx context.Context, ownerID, fileID, granteeID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilePermission", ctx, ownerID, fileID, granteeID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilePermission indicates an expected call of FilePermission.
+func (mr *MockGrantsMockRecorder) FilePermission(ctx, ownerID, fileID, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilePermission", reflect.TypeOf((*MockGrants)(nil).FilePermission), ctx, ownerID, fileID, granteeID)
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"context"
+	"slices"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type Action string
+
+const (
+	ActionRead    Action = "read"
+	ActionComment Action = "comment"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	// ActionShare manages the grants of a document. Only owners and roles
+	// given it explicitly may share.
+	ActionShare Action = "share"
+)
+
+// grantActions maps the actions a document grant can allow to the grant
+// permission they need. Grants on a document extend to reading its file.
+var grantActions = map[string]map[Action]string{
+	ResourceDocument: {
+		ActionRead:    models.GrantRead,
+		ActionComment: models.GrantComment,
+		ActionUpdate:  models.GrantEdit,
+	},
+	ResourceFile: {
+		ActionRead: models.GrantRead,
+	},
+}
+
+const (
+	ResourceDocument = "document"
+	ResourceFile     = "file"
+)
+
+// Resource is what an action is performed on. OwnerID is the user the
+// resource belongs to.
+type Resource struct {
+	Type    string
+	ID      string
+	OwnerID string
+}
+
+// Permissions lists, per role and resource type, the actions a role may
+// perform on resources owned by other users.
+type Permissions map[string]map[string][]Action
+
+// DefaultPermissions let administrators delete documents and files, for
+// example to handle erasure requests, without being able to read them.
+var DefaultPermissions = Permissions{
+	models.RoleAdmin: {
+		ResourceDocument: {ActionDelete},
+		ResourceFile:     {ActionDelete},
+	},
+}
+
+// Policy decides whether a principal may perform an action on a resource.
+// Owners may do anything with their own resources; everything else has to
+// be granted to one of the principal's roles, or shared with the principal
+// through a document grant.
+type Policy struct {
+	permissions Permissions
+	grants      Grants
+}
+
+func New(permissions Permissions, grants Grants) *Policy {
+	return &Policy{
+		permissions: permissions,
+		grants:      grants,
+	}
+}
+
+// Authorize returns ErrAccessDenied unless the principal may perform action
+// on resource.
+func (p *Policy) Authorize(ctx context.Context, principal models.Principal, action Action, resource Resource) error {
+	if principal.UserID == "" {
+		return apperrors.ErrAccessDenied
+	}
+	if resource.OwnerID == principal.UserID {
+		return nil
+	}
+
+	for _, role := range principal.Roles {
+		if slices.Contains(p.permissions[role][resource.Type], action) {
+			return nil
+		}
+	}
+
+	return p.authorizeGrant(ctx, principal, action, resource)
+}
+
+func (p *Policy) authorizeGrant(ctx context.Context, principal models.Principal, action Action, resource Resource) error {
+	required, ok := grantActions[resource.Type][action]
+	if !ok {
+		return apperrors.ErrAccessDenied
+	}
+
+	var (
+		permission string
+		err        error
+	)
+	switch resource.Type {
+	case ResourceDocument:
+		permission, err = p.grants.DocumentPermission(ctx, resource.ID, principal.UserID)
+	case ResourceFile:
+		permission, err = p.grants.FilePermission(ctx, resource.OwnerID, resource.ID, principal.UserID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !models.GrantAllows(permission, required) {
+		return apperrors.ErrAccessDenied
+	}
+	return nil
+}
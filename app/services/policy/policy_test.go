@@ -0,0 +1,169 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGrants := NewMockGrants(ctrl)
+	policy := New(DefaultPermissions, mockGrants)
+	document := Resource{Type: ResourceDocument, ID: "doc-123", OwnerID: "user-123"}
+	file := Resource{Type: ResourceFile, ID: "file-123", OwnerID: "user-123"}
+
+	tests := []struct {
+		name          string
+		principal     models.Principal
+		action        Action
+		resource      Resource
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name:      "owner may read",
+			principal: models.Principal{UserID: "user-123", Roles: []string{models.RolePatient}},
+			action:    ActionRead,
+			resource:  document,
+		},
+		{
+			name:      "owner without roles may delete",
+			principal: models.Principal{UserID: "user-123"},
+			action:    ActionDelete,
+			resource:  document,
+		},
+		{
+			name:      "other patient may not read",
+			principal: models.Principal{UserID: "user-456", Roles: []string{models.RolePatient}},
+			action:    ActionRead,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "user-456").Return("", nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "clinician needs a grant",
+			principal: models.Principal{UserID: "user-456", Roles: []string{models.RoleClinician}},
+			action:    ActionRead,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "user-456").Return("", nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "comment grant may read",
+			principal: models.Principal{UserID: "user-456", Roles: []string{models.RoleClinician}},
+			action:    ActionRead,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "user-456").Return(models.GrantComment, nil)
+			},
+		},
+		{
+			name:      "read grant may not comment",
+			principal: models.Principal{UserID: "user-456"},
+			action:    ActionComment,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "user-456").Return(models.GrantRead, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "edit grant may update",
+			principal: models.Principal{UserID: "user-456"},
+			action:    ActionUpdate,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "user-456").Return(models.GrantEdit, nil)
+			},
+		},
+		{
+			name:          "grants never allow deleting",
+			principal:     models.Principal{UserID: "user-456"},
+			action:        ActionDelete,
+			resource:      document,
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:          "grants never allow sharing",
+			principal:     models.Principal{UserID: "user-456"},
+			action:        ActionShare,
+			resource:      document,
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "grant on a document allows reading its file",
+			principal: models.Principal{UserID: "user-456"},
+			action:    ActionRead,
+			resource:  file,
+			setupMocks: func() {
+				mockGrants.EXPECT().FilePermission(gomock.Any(), "user-123", "file-123", "user-456").Return(models.GrantRead, nil)
+			},
+		},
+		{
+			name:          "grants never allow deleting files",
+			principal:     models.Principal{UserID: "user-456"},
+			action:        ActionDelete,
+			resource:      file,
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "grant lookup failure",
+			principal: models.Principal{UserID: "user-456"},
+			action:    ActionRead,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "user-456").Return("", errors.ErrInternal)
+			},
+			expectedError: errors.ErrInternal,
+		},
+		{
+			name:      "admin may delete",
+			principal: models.Principal{UserID: "admin-1", Roles: []string{models.RolePatient, models.RoleAdmin}},
+			action:    ActionDelete,
+			resource:  document,
+		},
+		{
+			name:      "admin may not read",
+			principal: models.Principal{UserID: "admin-1", Roles: []string{models.RoleAdmin}},
+			action:    ActionRead,
+			resource:  document,
+			setupMocks: func() {
+				mockGrants.EXPECT().DocumentPermission(gomock.Any(), "doc-123", "admin-1").Return("", nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:          "anonymous",
+			principal:     models.Principal{},
+			action:        ActionRead,
+			resource:      Resource{Type: ResourceFile, ID: "file-123"},
+			expectedError: errors.ErrAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMocks != nil {
+				tt.setupMocks()
+			}
+			err := policy.Authorize(context.Background(), tt.principal, tt.action, tt.resource)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
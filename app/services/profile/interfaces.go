@@ -0,0 +1,28 @@
+package profile
+
+import (
+	"context"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type ProfileRepository interface {
+	Create(ctx context.Context, profile *models.Profile) error
+	CreateDefault(ctx context.Context, profile *models.Profile) (*models.Profile, error)
+	GetDefault(ctx context.Context, userID string) (*models.Profile, error)
+	GetByID(ctx context.Context, id, userID string) (*models.Profile, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.Profile, error)
+	Update(ctx context.Context, id, userID string, update models.ProfileUpdate) error
+	Delete(ctx context.Context, id, userID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// RecordCounter counts the documents or files filed under a profile.
+type RecordCounter interface {
+	CountByProfile(ctx context.Context, userID, profileID string) (int64, error)
+}
+
+// Users provides the account holder's name for the default profile.
+type Users interface {
+	GetProfile(ctx context.Context, userID string) (*models.User, error)
+}
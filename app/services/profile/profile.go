@@ -0,0 +1,152 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type Dependencies struct {
+	Profiles  ProfileRepository
+	Documents RecordCounter
+	Files     RecordCounter
+	Users     Users
+}
+
+// Service manages the patient profiles of accounts. The default profile of
+// the account holder is created on first use, so accounts never have to be
+// set up for it.
+type Service struct {
+	profiles  ProfileRepository
+	documents RecordCounter
+	files     RecordCounter
+	users     Users
+}
+
+func NewService(deps Dependencies) *Service {
+	return &Service{
+		profiles:  deps.Profiles,
+		documents: deps.Documents,
+		files:     deps.Files,
+		users:     deps.Users,
+	}
+}
+
+// ListProfiles returns the profiles of the user, the default profile first.
+func (s *Service) ListProfiles(ctx context.Context, userID string) ([]*models.Profile, error) {
+	if _, err := s.DefaultProfile(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.profiles.GetByUserID(ctx, userID)
+}
+
+func (s *Service) CreateProfile(ctx context.Context, userID string, data models.ProfileCreation) (*models.Profile, error) {
+	now := time.Now()
+	profile := &models.Profile{
+		UserID:       userID,
+		Name:         data.Name,
+		DateOfBirth:  data.DateOfBirth,
+		Relationship: data.Relationship,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.profiles.Create(ctx, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *Service) GetProfile(ctx context.Context, id, userID string) (*models.Profile, error) {
+	return s.profiles.GetByID(ctx, id, userID)
+}
+
+// UpdateProfile changes a profile of the user. The default profile always
+// stays the account holder's own.
+func (s *Service) UpdateProfile(ctx context.Context, id, userID string, update models.ProfileUpdate) (*models.Profile, error) {
+	profile, err := s.profiles.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Default && update.Relationship != nil {
+		return nil, apperrors.ErrDefaultProfile
+	}
+
+	if err := s.profiles.Update(ctx, id, userID, update); err != nil {
+		return nil, err
+	}
+	return s.profiles.GetByID(ctx, id, userID)
+}
+
+// DeleteProfile removes a profile that has no documents or files left.
+// Records are never deleted along with their profile; they have to be moved
+// or deleted first.
+func (s *Service) DeleteProfile(ctx context.Context, id, userID string) error {
+	profile, err := s.profiles.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if profile.Default {
+		return apperrors.ErrDefaultProfile
+	}
+
+	for _, records := range []RecordCounter{s.documents, s.files} {
+		count, err := records.CountByProfile(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.ErrProfileInUse
+		}
+	}
+
+	return s.profiles.Delete(ctx, id, userID)
+}
+
+// Resolve returns the profile of the user that records should be filed
+// under: the one with profileID, or the default profile if it is empty.
+func (s *Service) Resolve(ctx context.Context, userID, profileID string) (*models.Profile, error) {
+	if profileID == "" {
+		return s.DefaultProfile(ctx, userID)
+	}
+	return s.profiles.GetByID(ctx, profileID, userID)
+}
+
+// DefaultProfile returns the profile of the account holder, creating it on
+// first use.
+func (s *Service) DefaultProfile(ctx context.Context, userID string) (*models.Profile, error) {
+	profile, err := s.profiles.GetDefault(ctx, userID)
+	if !errors.Is(err, apperrors.ErrProfileNotFound) {
+		return profile, err
+	}
+
+	user, err := s.users.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return s.profiles.CreateDefault(ctx, &models.Profile{
+		UserID:       userID,
+		Name:         defaultProfileName(user),
+		Relationship: models.RelationshipSelf,
+		Default:      true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+}
+
+// DeleteUserProfiles removes every profile of the user. It is meant for
+// account deletion, after the documents and files are gone.
+func (s *Service) DeleteUserProfiles(ctx context.Context, userID string) error {
+	return s.profiles.DeleteByUserID(ctx, userID)
+}
+
+// defaultProfileName names the default profile after the account holder.
+func defaultProfileName(user *models.User) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Email
+}
@@ -0,0 +1,193 @@
+package profile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type mocks struct {
+	profiles  *MockProfileRepository
+	documents *MockRecordCounter
+	files     *MockRecordCounter
+	users     *MockUsers
+}
+
+func newTestService(t *testing.T) (*Service, mocks) {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		profiles:  NewMockProfileRepository(ctrl),
+		documents: NewMockRecordCounter(ctrl),
+		files:     NewMockRecordCounter(ctrl),
+		users:     NewMockUsers(ctrl),
+	}
+	service := NewService(Dependencies{
+		Profiles:  m.profiles,
+		Documents: m.documents,
+		Files:     m.files,
+		Users:     m.users,
+	})
+	return service, m
+}
+
+func TestService_Resolve(t *testing.T) {
+	defaultProfile := &models.Profile{ID: "profile-1", UserID: "user-123", Default: true}
+
+	tests := []struct {
+		name          string
+		profileID     string
+		setupMocks    func(m mocks)
+		expectedID    string
+		expectedError error
+	}{
+		{
+			name: "existing default profile",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetDefault(gomock.Any(), "user-123").Return(defaultProfile, nil)
+			},
+			expectedID: "profile-1",
+		},
+		{
+			name: "creates the default profile on first use",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetDefault(gomock.Any(), "user-123").Return(nil, errors.ErrProfileNotFound)
+				m.users.EXPECT().GetProfile(gomock.Any(), "user-123").Return(&models.User{ID: "user-123", Email: "parent@example.com"}, nil)
+				m.profiles.EXPECT().
+					CreateDefault(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, profile *models.Profile) (*models.Profile, error) {
+						assert.Equal(t, "parent@example.com", profile.Name)
+						assert.Equal(t, models.RelationshipSelf, profile.Relationship)
+						assert.True(t, profile.Default)
+						return defaultProfile, nil
+					})
+			},
+			expectedID: "profile-1",
+		},
+		{
+			name:      "profile of the user",
+			profileID: "profile-2",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(&models.Profile{ID: "profile-2", UserID: "user-123"}, nil)
+			},
+			expectedID: "profile-2",
+		},
+		{
+			name:      "profile of another account",
+			profileID: "profile-9",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-9", "user-123").Return(nil, errors.ErrProfileNotFound)
+			},
+			expectedError: errors.ErrProfileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t)
+			tt.setupMocks(m)
+
+			profile, err := service.Resolve(context.Background(), "user-123", tt.profileID)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, profile.ID)
+		})
+	}
+}
+
+func TestService_UpdateProfile(t *testing.T) {
+	relationship := models.RelationshipChild
+
+	t.Run("default profile keeps its relationship", func(t *testing.T) {
+		service, m := newTestService(t)
+		m.profiles.EXPECT().GetByID(gomock.Any(), "profile-1", "user-123").Return(&models.Profile{ID: "profile-1", Default: true}, nil)
+
+		_, err := service.UpdateProfile(context.Background(), "profile-1", "user-123", models.ProfileUpdate{Relationship: &relationship})
+		assert.ErrorIs(t, err, errors.ErrDefaultProfile)
+	})
+
+	t.Run("updates a profile", func(t *testing.T) {
+		service, m := newTestService(t)
+		update := models.ProfileUpdate{Relationship: &relationship}
+		m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(&models.Profile{ID: "profile-2"}, nil)
+		m.profiles.EXPECT().Update(gomock.Any(), "profile-2", "user-123", update).Return(nil)
+		m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(&models.Profile{ID: "profile-2", Relationship: relationship}, nil)
+
+		profile, err := service.UpdateProfile(context.Background(), "profile-2", "user-123", update)
+		require.NoError(t, err)
+		assert.Equal(t, relationship, profile.Relationship)
+	})
+}
+
+func TestService_DeleteProfile(t *testing.T) {
+	child := &models.Profile{ID: "profile-2", UserID: "user-123", Relationship: models.RelationshipChild}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(m mocks)
+		expectedError error
+	}{
+		{
+			name: "deletes an empty profile",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(child, nil)
+				m.documents.EXPECT().CountByProfile(gomock.Any(), "user-123", "profile-2").Return(int64(0), nil)
+				m.files.EXPECT().CountByProfile(gomock.Any(), "user-123", "profile-2").Return(int64(0), nil)
+				m.profiles.EXPECT().Delete(gomock.Any(), "profile-2", "user-123").Return(nil)
+			},
+		},
+		{
+			name: "profile with documents",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(child, nil)
+				m.documents.EXPECT().CountByProfile(gomock.Any(), "user-123", "profile-2").Return(int64(2), nil)
+			},
+			expectedError: errors.ErrProfileInUse,
+		},
+		{
+			name: "profile with files",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(child, nil)
+				m.documents.EXPECT().CountByProfile(gomock.Any(), "user-123", "profile-2").Return(int64(0), nil)
+				m.files.EXPECT().CountByProfile(gomock.Any(), "user-123", "profile-2").Return(int64(1), nil)
+			},
+			expectedError: errors.ErrProfileInUse,
+		},
+		{
+			name: "default profile",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(&models.Profile{ID: "profile-2", Default: true}, nil)
+			},
+			expectedError: errors.ErrDefaultProfile,
+		},
+		{
+			name: "profile of another account",
+			setupMocks: func(m mocks) {
+				m.profiles.EXPECT().GetByID(gomock.Any(), "profile-2", "user-123").Return(nil, errors.ErrProfileNotFound)
+			},
+			expectedError: errors.ErrProfileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t)
+			tt.setupMocks(m)
+
+			err := service.DeleteProfile(context.Background(), "profile-2", "user-123")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package sharelink
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+type LinkRepository interface {
+	Create(ctx context.Context, link *models.ShareLink) error
+	GetByID(ctx context.Context, id string) (*models.ShareLink, error)
+	GetByOwnerID(ctx context.Context, ownerID string) ([]*models.ShareLink, error)
+	Revoke(ctx context.Context, id, ownerID string) error
+	RecordView(ctx context.Context, id string, now time.Time) error
+	RecordDownload(ctx context.Context, id string, limit int, now time.Time) error
+	AddFailedPINAttempt(ctx context.Context, id string) (int, error)
+	DeleteByOwnerID(ctx context.Context, ownerID string) error
+}
+
+type DocumentRepository interface {
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Document, error)
+}
+
+// Files streams the files attached to shared documents.
+type Files interface {
+	Open(ctx context.Context, id, ownerID string) (io.ReadCloser, error)
+}
+
+type Authorizer interface {
+	Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error
+}
@@ -0,0 +1,267 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app/services/sharelink/interfaces.go
+
+// Package sharelink is a generated GoMock package.
+package sharelink
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/gruzdev-dev/meddoc/app/models"
+	policy "github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+// MockLinkRepository is a mock of LinkRepository interface.
+type MockLinkRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkRepositoryMockRecorder
+}
+
+// MockLinkRepositoryMockRecorder is the mock recorder for MockLinkRepository.
+type MockLinkRepositoryMockRecorder struct {
+	mock *MockLinkRepository
+}
+
+// NewMockLinkRepository creates a new mock instance.
+func NewMockLinkRepository(ctrl *gomock.Controller) *MockLinkRepository {
+	mock := &MockLinkRepository{ctrl: ctrl}
+	mock.recorder = &MockLinkRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkRepository) EXPECT() *MockLinkRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFailedPINAttempt mocks base method.
+func (m *MockLinkRepository) AddFailedPINAttempt(ctx context.Context, id string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailedPINAttempt", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFailedPINAttempt indicates an expected call of AddFailedPINAttempt.
+func (mr *MockLinkRepositoryMockRecorder) AddFailedPINAttempt(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailedPINAttempt", reflect.TypeOf((*MockLinkRepository)(nil).AddFailedPINAttempt), ctx, id)
+}
+
+// Create mocks base method.
+func (m *MockLinkRepository) Create(ctx context.Context, link *models.ShareLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockLinkRepositoryMockRecorder) Create(ctx, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLinkRepository)(nil).Create), ctx, link)
+}
+
+// DeleteByOwnerID mocks base method.
+func (m *MockLinkRepository) DeleteByOwnerID(ctx context.Context, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByOwnerID", ctx, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByOwnerID indicates an expected call of DeleteByOwnerID.
+func (mr *MockLinkRepositoryMockRecorder) DeleteByOwnerID(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByOwnerID", reflect.TypeOf((*MockLinkRepository)(nil).DeleteByOwnerID), ctx, ownerID)
+}
+
+// GetByID mocks base method.
+func (m *MockLinkRepository) GetByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockLinkRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockLinkRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByOwnerID mocks base method.
+func (m *MockLinkRepository) GetByOwnerID(ctx context.Context, ownerID string) ([]*models.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOwnerID", ctx, ownerID)
+	ret0, _ := ret[0].([]*models.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOwnerID indicates an expected call of GetByOwnerID.
+func (mr *MockLinkRepositoryMockRecorder) GetByOwnerID(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOwnerID", reflect.TypeOf((*MockLinkRepository)(nil).GetByOwnerID), ctx, ownerID)
+}
+
+// RecordDownload mocks base method.
+func (m *MockLinkRepository) RecordDownload(ctx context.Context, id string, limit int, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDownload", ctx, id, limit, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDownload indicates an expected call of RecordDownload.
+func (mr *MockLinkRepositoryMockRecorder) RecordDownload(ctx, id, limit, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDownload", reflect.TypeOf((*MockLinkRepository)(nil).RecordDownload), ctx, id, limit, now)
+}
+
+// RecordView mocks base method.
+func (m *MockLinkRepository) RecordView(ctx context.Context, id string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordView", ctx, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordView indicates an expected call of RecordView.
+func (mr *MockLinkRepositoryMockRecorder) RecordView(ctx, id, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordView", reflect.TypeOf((*MockLinkRepository)(nil).RecordView), ctx, id, now)
+}
+
+// Revoke mocks base method.
+func (m *MockLinkRepository) Revoke(ctx context.Context, id, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockLinkRepositoryMockRecorder) Revoke(ctx, id, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockLinkRepository)(nil).Revoke), ctx, id, ownerID)
+}
+
+// MockDocumentRepository is a mock of DocumentRepository interface.
+type MockDocumentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentRepositoryMockRecorder
+}
+
+// MockDocumentRepositoryMockRecorder is the mock recorder for MockDocumentRepository.
+type MockDocumentRepositoryMockRecorder struct {
+	mock *MockDocumentRepository
+}
+
+// NewMockDocumentRepository creates a new mock instance.
+func NewMockDocumentRepository(ctrl *gomock.Controller) *MockDocumentRepository {
+	mock := &MockDocumentRepository{ctrl: ctrl}
+	mock.recorder = &MockDocumentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentRepository) EXPECT() *MockDocumentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByIDs mocks base method.
+func (m *MockDocumentRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*models.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockDocumentRepositoryMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockDocumentRepository)(nil).GetByIDs), ctx, ids)
+}
+
+// MockFiles is a mock of Files interface.
+type MockFiles struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilesMockRecorder
+}
+
+// MockFilesMockRecorder is the mock recorder for MockFiles.
+type MockFilesMockRecorder struct {
+	mock *MockFiles
+}
+
+// NewMockFiles creates a new mock instance.
+func NewMockFiles(ctrl *gomock.Controller) *MockFiles {
+	mock := &MockFiles{ctrl: ctrl}
+	mock.recorder = &MockFilesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFiles) EXPECT() *MockFilesMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockFiles) Open(ctx context.Context, id, ownerID string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, id, ownerID)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockFilesMockRecorder) Open(ctx, id, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockFiles)(nil).Open), ctx, id, ownerID)
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockAuthorizer) Authorize(ctx context.Context, principal models.Principal, action policy.Action, resource policy.Resource) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, principal, action, resource)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAuthorizerMockRecorder) Authorize(ctx, principal, action, resource interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthorizer)(nil).Authorize), ctx, principal, action, resource)
+}
@@ -0,0 +1,320 @@
+package sharelink
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+type Dependencies struct {
+	Links     LinkRepository
+	Documents DocumentRepository
+	Files     Files
+	Policy    Authorizer
+}
+
+type Config struct {
+	// Key signs link tokens. Share links are unavailable when it is empty.
+	Key []byte
+	// URL is the page share links point to; the token is added as the
+	// "token" query parameter.
+	URL            string
+	MaxTTL         time.Duration
+	MaxPINAttempts int
+}
+
+// Service hands out public links to documents. A link token is the link ID
+// followed by an HMAC of it, so tokens cannot be derived from the guessable
+// IDs. Whether a link is still usable is decided by its stored state, which
+// lets owners revoke it.
+type Service struct {
+	links          LinkRepository
+	documents      DocumentRepository
+	files          Files
+	policy         Authorizer
+	key            []byte
+	url            string
+	maxTTL         time.Duration
+	maxPINAttempts int
+}
+
+func NewService(deps Dependencies, cfg Config) *Service {
+	return &Service{
+		links:          deps.Links,
+		documents:      deps.Documents,
+		files:          deps.Files,
+		policy:         deps.Policy,
+		key:            cfg.Key,
+		url:            cfg.URL,
+		maxTTL:         cfg.MaxTTL,
+		maxPINAttempts: cfg.MaxPINAttempts,
+	}
+}
+
+func NewServiceFromConfig(deps Dependencies, cfg *config.Config) (*Service, error) {
+	var key []byte
+	if cfg.Sharing.Links.Key != "" {
+		var err error
+		key, err = base64.StdEncoding.DecodeString(cfg.Sharing.Links.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid share link key: %w", err)
+		}
+	}
+
+	return NewService(deps, Config{
+		Key:            key,
+		URL:            cfg.Sharing.Links.URL,
+		MaxTTL:         cfg.Sharing.Links.MaxTTL,
+		MaxPINAttempts: cfg.Sharing.Links.MaxPINAttempts,
+	}), nil
+}
+
+// CreateLink creates a link to documents the principal may share.
+func (s *Service) CreateLink(ctx context.Context, data models.ShareLinkCreation, principal models.Principal) (*models.ShareLink, error) {
+	if len(s.key) == 0 {
+		return nil, apperrors.ErrShareLinksUnavailable
+	}
+
+	now := time.Now()
+	if !data.ExpiresAt.After(now) || data.ExpiresAt.After(now.Add(s.maxTTL)) {
+		return nil, apperrors.ErrInvalidShareLink
+	}
+
+	ids := slices.Clone(data.DocumentIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	docs, err := s.documents.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != len(ids) {
+		return nil, apperrors.ErrDocumentNotFound
+	}
+	for _, doc := range docs {
+		resource := policy.Resource{Type: policy.ResourceDocument, ID: doc.ID, OwnerID: doc.UserID}
+		if err := s.policy.Authorize(ctx, principal, policy.ActionShare, resource); err != nil {
+			return nil, err
+		}
+	}
+
+	link := &models.ShareLink{
+		OwnerID:     principal.UserID,
+		DocumentIDs: ids,
+		ExpiresAt:   data.ExpiresAt,
+		MaxUses:     data.MaxUses,
+		CreatedAt:   now,
+	}
+	if data.PIN != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(data.PIN), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		link.PINHash = string(hash)
+		link.PINProtected = true
+	}
+
+	if err := s.links.Create(ctx, link); err != nil {
+		return nil, err
+	}
+	if err := s.addToken(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListLinks returns the links of the user that have not expired yet,
+// including revoked ones.
+func (s *Service) ListLinks(ctx context.Context, ownerID string) ([]*models.ShareLink, error) {
+	links, err := s.links.GetByOwnerID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if err := s.addToken(link); err != nil {
+			return nil, err
+		}
+	}
+	return links, nil
+}
+
+func (s *Service) RevokeLink(ctx context.Context, id, ownerID string) error {
+	return s.links.Revoke(ctx, id, ownerID)
+}
+
+// DeleteUserLinks removes every link the user created, for account erasure.
+func (s *Service) DeleteUserLinks(ctx context.Context, userID string) error {
+	return s.links.DeleteByOwnerID(ctx, userID)
+}
+
+// Open returns the documents behind a link and counts the view. Documents
+// deleted since the link was created are left out.
+func (s *Service) Open(ctx context.Context, token, pin string) (*models.SharedBundle, error) {
+	link, err := s.usableLink(ctx, token, pin)
+	if err != nil {
+		return nil, err
+	}
+	if link.MaxUses > 0 && link.Views >= link.MaxUses {
+		return nil, apperrors.ErrShareLinkExpired
+	}
+
+	if err := s.links.RecordView(ctx, link.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	docs, err := s.linkedDocuments(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SharedBundle{
+		ExpiresAt: link.ExpiresAt,
+		Documents: docs,
+	}, nil
+}
+
+// OpenFile streams a file attached to one of the link's documents.
+// Downloads are counted apart from views: every use MaxUses allows covers
+// one download of each of the link's files, so opening a link once and
+// fetching its files works, but files cannot be fetched without limit.
+func (s *Service) OpenFile(ctx context.Context, token, pin, fileID string) (io.ReadCloser, error) {
+	link, err := s.usableLink(ctx, token, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.linkedDocuments(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		owner string
+		files int
+	)
+	for _, doc := range docs {
+		if doc.File == "" {
+			continue
+		}
+		files++
+		if trimExt(doc.File) == trimExt(fileID) {
+			owner = doc.UserID
+		}
+	}
+	if owner == "" {
+		return nil, apperrors.ErrNotFound
+	}
+
+	if err := s.links.RecordDownload(ctx, link.ID, link.MaxUses*files, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.files.Open(ctx, fileID, owner)
+}
+
+// usableLink resolves a token to a link that is neither revoked nor expired
+// and checks its PIN. Too many wrong PINs revoke the link. Uses are counted
+// and limited by RecordView and RecordDownload, atomically.
+func (s *Service) usableLink(ctx context.Context, token, pin string) (*models.ShareLink, error) {
+	if len(s.key) == 0 {
+		return nil, apperrors.ErrShareLinksUnavailable
+	}
+
+	id, ok := s.verifyToken(token)
+	if !ok {
+		return nil, apperrors.ErrShareLinkNotFound
+	}
+	link, err := s.links.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if link.RevokedAt != nil || !link.ExpiresAt.After(time.Now()) {
+		return nil, apperrors.ErrShareLinkExpired
+	}
+
+	if link.PINHash == "" {
+		return link, nil
+	}
+	if pin == "" {
+		return nil, apperrors.ErrInvalidPIN
+	}
+	if bcrypt.CompareHashAndPassword([]byte(link.PINHash), []byte(pin)) == nil {
+		return link, nil
+	}
+
+	attempts, err := s.links.AddFailedPINAttempt(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+	if attempts >= s.maxPINAttempts {
+		if err := s.links.Revoke(ctx, link.ID, link.OwnerID); err != nil && !errors.Is(err, apperrors.ErrShareLinkNotFound) {
+			return nil, err
+		}
+		logger.Info("share link revoked after wrong PINs", "link_id", link.ID, "attempts", attempts)
+		return nil, apperrors.ErrShareLinkExpired
+	}
+	return nil, apperrors.ErrInvalidPIN
+}
+
+// linkedDocuments returns the link's documents that still belong to its
+// owner.
+func (s *Service) linkedDocuments(ctx context.Context, link *models.ShareLink) ([]*models.Document, error) {
+	docs, err := s.documents.GetByIDs(ctx, link.DocumentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]*models.Document, 0, len(docs))
+	for _, doc := range docs {
+		if doc.UserID == link.OwnerID {
+			owned = append(owned, doc)
+		}
+	}
+	return owned, nil
+}
+
+func (s *Service) addToken(link *models.ShareLink) error {
+	link.Token = link.ID + "." + s.sign(link.ID)
+
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	query.Set("token", link.Token)
+	u.RawQuery = query.Encode()
+	link.URL = u.String()
+	return nil
+}
+
+func (s *Service) sign(id string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) verifyToken(token string) (string, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, hmac.Equal([]byte(signature), []byte(s.sign(id)))
+}
+
+func trimExt(id string) string {
+	return strings.TrimSuffix(id, filepath.Ext(id))
+}
@@ -0,0 +1,314 @@
+package sharelink
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+)
+
+const linkID = "64b7f0c2a1b2c3d4e5f60718"
+
+type mocks struct {
+	links     *MockLinkRepository
+	documents *MockDocumentRepository
+	files     *MockFiles
+}
+
+func newTestService(t *testing.T, key []byte) (*Service, mocks) {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		links:     NewMockLinkRepository(ctrl),
+		documents: NewMockDocumentRepository(ctrl),
+		files:     NewMockFiles(ctrl),
+	}
+	service := NewService(Dependencies{
+		Links:     m.links,
+		Documents: m.documents,
+		Files:     m.files,
+		Policy:    policy.New(policy.DefaultPermissions, policy.NewMockGrants(ctrl)),
+	}, Config{
+		Key:            key,
+		URL:            "https://meddoc.example/share",
+		MaxTTL:         24 * time.Hour,
+		MaxPINAttempts: 3,
+	})
+	return service, m
+}
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestService_CreateLink(t *testing.T) {
+	docs := []*models.Document{
+		{ID: "doc-1", UserID: "owner"},
+		{ID: "doc-2", UserID: "owner"},
+	}
+	owner := models.Principal{UserID: "owner"}
+
+	t.Run("creates a PIN-protected link", func(t *testing.T) {
+		service, m := newTestService(t, testKey)
+		m.documents.EXPECT().GetByIDs(gomock.Any(), []string{"doc-1", "doc-2"}).Return(docs, nil)
+		m.links.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, link *models.ShareLink) error {
+			assert.Equal(t, "owner", link.OwnerID)
+			assert.Equal(t, []string{"doc-1", "doc-2"}, link.DocumentIDs)
+			assert.Equal(t, 2, link.MaxUses)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(link.PINHash), []byte("1234")))
+			link.ID = linkID
+			return nil
+		})
+
+		link, err := service.CreateLink(context.Background(), models.ShareLinkCreation{
+			DocumentIDs: []string{"doc-2", "doc-1", "doc-2"},
+			ExpiresAt:   time.Now().Add(time.Hour),
+			PIN:         "1234",
+			MaxUses:     2,
+		}, owner)
+		require.NoError(t, err)
+		assert.True(t, link.PINProtected)
+		assert.True(t, strings.HasPrefix(link.Token, linkID+"."))
+		assert.Equal(t, "https://meddoc.example/share?token="+link.Token, link.URL)
+	})
+
+	tests := []struct {
+		name          string
+		key           []byte
+		principal     models.Principal
+		expiresAt     time.Time
+		setupMocks    func(m mocks)
+		expectedError error
+	}{
+		{
+			name:          "without a key",
+			principal:     owner,
+			expiresAt:     time.Now().Add(time.Hour),
+			expectedError: errors.ErrShareLinksUnavailable,
+		},
+		{
+			name:          "expiry beyond the maximum",
+			key:           testKey,
+			principal:     owner,
+			expiresAt:     time.Now().Add(48 * time.Hour),
+			expectedError: errors.ErrInvalidShareLink,
+		},
+		{
+			name:      "document of another user",
+			key:       testKey,
+			principal: models.Principal{UserID: "stranger"},
+			expiresAt: time.Now().Add(time.Hour),
+			setupMocks: func(m mocks) {
+				m.documents.EXPECT().GetByIDs(gomock.Any(), gomock.Any()).Return(docs, nil)
+			},
+			expectedError: errors.ErrAccessDenied,
+		},
+		{
+			name:      "missing document",
+			key:       testKey,
+			principal: owner,
+			expiresAt: time.Now().Add(time.Hour),
+			setupMocks: func(m mocks) {
+				m.documents.EXPECT().GetByIDs(gomock.Any(), gomock.Any()).Return(docs[:1], nil)
+			},
+			expectedError: errors.ErrDocumentNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t, tt.key)
+			if tt.setupMocks != nil {
+				tt.setupMocks(m)
+			}
+			link, err := service.CreateLink(context.Background(), models.ShareLinkCreation{
+				DocumentIDs: []string{"doc-1", "doc-2"},
+				ExpiresAt:   tt.expiresAt,
+			}, tt.principal)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Nil(t, link)
+		})
+	}
+}
+
+func TestService_Open(t *testing.T) {
+	pinHash, err := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	service, _ := newTestService(t, testKey)
+	token := linkID + "." + service.sign(linkID)
+	docs := []*models.Document{
+		{ID: "doc-1", UserID: "owner", File: "file-1.pdf"},
+		{ID: "doc-2", UserID: "someone-else"},
+	}
+
+	activeLink := func() *models.ShareLink {
+		return &models.ShareLink{
+			ID:          linkID,
+			OwnerID:     "owner",
+			DocumentIDs: []string{"doc-1", "doc-2"},
+			PINHash:     string(pinHash),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		token         string
+		pin           string
+		setupMocks    func(m mocks)
+		expectedError error
+	}{
+		{
+			name:  "opens the link and counts the view",
+			token: token,
+			pin:   "1234",
+			setupMocks: func(m mocks) {
+				m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(activeLink(), nil)
+				m.links.EXPECT().RecordView(gomock.Any(), linkID, gomock.Any()).Return(nil)
+				m.documents.EXPECT().GetByIDs(gomock.Any(), []string{"doc-1", "doc-2"}).Return(docs, nil)
+			},
+		},
+		{
+			name:          "forged signature",
+			token:         linkID + ".forged",
+			pin:           "1234",
+			setupMocks:    func(m mocks) {},
+			expectedError: errors.ErrShareLinkNotFound,
+		},
+		{
+			name:  "revoked link",
+			token: token,
+			pin:   "1234",
+			setupMocks: func(m mocks) {
+				link := activeLink()
+				revokedAt := time.Now()
+				link.RevokedAt = &revokedAt
+				m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(link, nil)
+			},
+			expectedError: errors.ErrShareLinkExpired,
+		},
+		{
+			name:  "used up",
+			token: token,
+			pin:   "1234",
+			setupMocks: func(m mocks) {
+				m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(activeLink(), nil)
+				m.links.EXPECT().RecordView(gomock.Any(), linkID, gomock.Any()).Return(errors.ErrShareLinkExpired)
+			},
+			expectedError: errors.ErrShareLinkExpired,
+		},
+		{
+			name:  "missing PIN",
+			token: token,
+			setupMocks: func(m mocks) {
+				m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(activeLink(), nil)
+			},
+			expectedError: errors.ErrInvalidPIN,
+		},
+		{
+			name:  "wrong PIN",
+			token: token,
+			pin:   "0000",
+			setupMocks: func(m mocks) {
+				m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(activeLink(), nil)
+				m.links.EXPECT().AddFailedPINAttempt(gomock.Any(), linkID).Return(1, nil)
+			},
+			expectedError: errors.ErrInvalidPIN,
+		},
+		{
+			name:  "too many wrong PINs revoke the link",
+			token: token,
+			pin:   "0000",
+			setupMocks: func(m mocks) {
+				m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(activeLink(), nil)
+				m.links.EXPECT().AddFailedPINAttempt(gomock.Any(), linkID).Return(3, nil)
+				m.links.EXPECT().Revoke(gomock.Any(), linkID, "owner").Return(nil)
+			},
+			expectedError: errors.ErrShareLinkExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestService(t, testKey)
+			tt.setupMocks(m)
+			bundle, err := service.Open(context.Background(), tt.token, tt.pin)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, bundle)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, bundle.Documents, 1)
+				assert.Equal(t, "doc-1", bundle.Documents[0].ID)
+			}
+		})
+	}
+
+	t.Run("streams attached files only", func(t *testing.T) {
+		service, m := newTestService(t, testKey)
+		m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(activeLink(), nil).Times(2)
+		m.documents.EXPECT().GetByIDs(gomock.Any(), gomock.Any()).Return(docs, nil).Times(2)
+		m.links.EXPECT().RecordDownload(gomock.Any(), linkID, 0, gomock.Any()).Return(nil)
+		m.files.EXPECT().Open(gomock.Any(), "file-1", "owner").Return(io.NopCloser(strings.NewReader("scan")), nil)
+
+		reader, err := service.OpenFile(context.Background(), token, "1234", "file-1")
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "scan", string(data))
+
+		_, err = service.OpenFile(context.Background(), token, "1234", "file-2")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+	})
+	t.Run("a link for one use delivers its files", func(t *testing.T) {
+		service, m := newTestService(t, testKey)
+		link := activeLink()
+		link.MaxUses = 1
+		m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(link, nil)
+		m.links.EXPECT().RecordView(gomock.Any(), linkID, gomock.Any()).Return(nil)
+		m.documents.EXPECT().GetByIDs(gomock.Any(), gomock.Any()).Return(docs, nil)
+
+		bundle, err := service.Open(context.Background(), token, "1234")
+		require.NoError(t, err)
+		require.Len(t, bundle.Documents, 1)
+		assert.Equal(t, "file-1.pdf", bundle.Documents[0].File)
+
+		used := activeLink()
+		used.MaxUses = 1
+		used.Views = 1
+		m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(used, nil).Times(2)
+		m.documents.EXPECT().GetByIDs(gomock.Any(), gomock.Any()).Return(docs, nil)
+		m.links.EXPECT().RecordDownload(gomock.Any(), linkID, 1, gomock.Any()).Return(nil)
+		m.files.EXPECT().Open(gomock.Any(), "file-1.pdf", "owner").Return(io.NopCloser(strings.NewReader("scan")), nil)
+
+		reader, err := service.OpenFile(context.Background(), token, "1234", "file-1.pdf")
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "scan", string(data))
+
+		_, err = service.Open(context.Background(), token, "1234")
+		assert.ErrorIs(t, err, errors.ErrShareLinkExpired)
+	})
+
+	t.Run("downloads used up", func(t *testing.T) {
+		service, m := newTestService(t, testKey)
+		link := activeLink()
+		link.MaxUses = 2
+		link.Views = 2
+		m.links.EXPECT().GetByID(gomock.Any(), linkID).Return(link, nil)
+		m.documents.EXPECT().GetByIDs(gomock.Any(), gomock.Any()).Return(docs, nil)
+		m.links.EXPECT().RecordDownload(gomock.Any(), linkID, 2, gomock.Any()).Return(errors.ErrShareLinkExpired)
+
+		_, err := service.OpenFile(context.Background(), token, "1234", "file-1")
+		assert.ErrorIs(t, err, errors.ErrShareLinkExpired)
+	})
+}
@@ -0,0 +1,154 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestUserService_CreateAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccessTokens := NewMockAccessTokenRepository(ctrl)
+	service := NewUserService(Dependencies{AccessTokens: mockAccessTokens}, Config{JWTSecret: "test-secret"})
+
+	t.Run("creates a token", func(t *testing.T) {
+		mockAccessTokens.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *models.PersonalAccessToken) error {
+				assert.Equal(t, "user-123", token.UserID)
+				assert.Equal(t, []string{models.ScopeDocumentsRead, models.ScopeFilesWrite}, token.Scopes)
+				assert.NotEmpty(t, token.TokenHash)
+				assert.Empty(t, token.Token)
+				token.ID = "token-123"
+				return nil
+			})
+
+		token, err := service.CreateAccessToken(context.Background(), "user-123", models.PersonalAccessTokenCreation{
+			Name:   "scanner",
+			Scopes: []string{models.ScopeFilesWrite, models.ScopeDocumentsRead, models.ScopeFilesWrite},
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token.Token, accessTokenPrefix))
+		assert.Equal(t, hashAccessToken(token.Token), token.TokenHash)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		_, err := service.CreateAccessToken(context.Background(), "user-123", models.PersonalAccessTokenCreation{
+			Name:   "scanner",
+			Scopes: []string{"admin:all"},
+		})
+		assert.ErrorIs(t, err, errors.ErrInvalidAccessToken)
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		_, err := service.CreateAccessToken(context.Background(), "user-123", models.PersonalAccessTokenCreation{
+			Name:      "scanner",
+			Scopes:    []string{models.ScopeFilesWrite},
+			ExpiresAt: &expiresAt,
+		})
+		assert.ErrorIs(t, err, errors.ErrInvalidAccessToken)
+	})
+}
+
+func TestUserService_AuthenticateAccessToken(t *testing.T) {
+	const token = accessTokenPrefix + "secret"
+	recently := time.Now().Add(-time.Second)
+	expired := time.Now().Add(-time.Minute)
+	user := &models.User{ID: "user-123", Roles: []string{models.RolePatient}}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(repo *MockUserRepository, tokens *MockAccessTokenRepository)
+		expectedError error
+	}{
+		{
+			name: "valid token",
+			setupMocks: func(repo *MockUserRepository, tokens *MockAccessTokenRepository) {
+				tokens.EXPECT().GetByHash(gomock.Any(), hashAccessToken(token)).Return(&models.PersonalAccessToken{
+					ID:        "token-123",
+					UserID:    "user-123",
+					Scopes:    []string{models.ScopeFilesWrite},
+					CreatedAt: time.Now().Add(-time.Hour),
+				}, nil)
+				repo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+				tokens.EXPECT().Touch(gomock.Any(), "token-123", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "recently used token",
+			setupMocks: func(repo *MockUserRepository, tokens *MockAccessTokenRepository) {
+				tokens.EXPECT().GetByHash(gomock.Any(), hashAccessToken(token)).Return(&models.PersonalAccessToken{
+					ID:         "token-123",
+					UserID:     "user-123",
+					Scopes:     []string{models.ScopeFilesWrite},
+					LastUsedAt: &recently,
+					CreatedAt:  time.Now().Add(-time.Hour),
+				}, nil)
+				repo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+			},
+		},
+		{
+			name: "unknown token",
+			setupMocks: func(repo *MockUserRepository, tokens *MockAccessTokenRepository) {
+				tokens.EXPECT().GetByHash(gomock.Any(), hashAccessToken(token)).Return(nil, errors.ErrAccessTokenNotFound)
+			},
+			expectedError: errors.ErrInvalidToken,
+		},
+		{
+			name: "expired token",
+			setupMocks: func(repo *MockUserRepository, tokens *MockAccessTokenRepository) {
+				tokens.EXPECT().GetByHash(gomock.Any(), hashAccessToken(token)).Return(&models.PersonalAccessToken{
+					ID:        "token-123",
+					UserID:    "user-123",
+					ExpiresAt: &expired,
+				}, nil)
+			},
+			expectedError: errors.ErrInvalidToken,
+		},
+		{
+			name: "created before logout from all devices",
+			setupMocks: func(repo *MockUserRepository, tokens *MockAccessTokenRepository) {
+				tokens.EXPECT().GetByHash(gomock.Any(), hashAccessToken(token)).Return(&models.PersonalAccessToken{
+					ID:        "token-123",
+					UserID:    "user-123",
+					CreatedAt: time.Now().Add(-time.Hour),
+				}, nil)
+				repo.EXPECT().GetByID(gomock.Any(), "user-123").Return(&models.User{ID: "user-123", TokensValidAfter: time.Now()}, nil)
+			},
+			expectedError: errors.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockUserRepository(ctrl)
+			mockAccessTokens := NewMockAccessTokenRepository(ctrl)
+			service := NewUserService(Dependencies{Users: mockRepo, AccessTokens: mockAccessTokens}, Config{JWTSecret: "test-secret"})
+			tt.setupMocks(mockRepo, mockAccessTokens)
+
+			principal, err := service.Authenticate(context.Background(), token)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-123", principal.UserID)
+			assert.Equal(t, "token-123", principal.AccessTokenID)
+			assert.Equal(t, []string{models.RolePatient}, principal.Roles)
+			assert.True(t, principal.HasScope(models.ScopeFilesWrite))
+			assert.False(t, principal.HasScope(models.ScopeDocumentsRead))
+		})
+	}
+}
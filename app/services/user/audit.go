@@ -0,0 +1,42 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func (s *UserService) record(ctx context.Context, event models.AuditEvent, err error) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, event, err)
+}
+
+// recordLogin records a sign-in attempt on the account of userID, which is
+// empty when the account is unknown. Attempts that only got as far as the
+// two-factor challenge are recorded once the challenge is answered. An
+// unknown email is recorded as a refused sign-in, the way it is reported to
+// the client.
+func (s *UserService) recordLogin(ctx context.Context, userID string, result *models.LoginResult, err error) {
+	if err == nil && result.Challenge != nil {
+		return
+	}
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		err = apperrors.ErrInvalidCredentials
+	}
+
+	event := models.AuditEvent{Action: models.AuditLogin, OwnerID: userID}
+	if err == nil {
+		event.ActorID = userID
+	}
+	s.record(ctx, event, err)
+}
+
+// recordAccountEvent records an operation users carry out on their own
+// account.
+func (s *UserService) recordAccountEvent(ctx context.Context, action string, principal models.Principal, err error) {
+	s.record(ctx, models.AuditEvent{Action: action, ActorID: principal.Actor(), OwnerID: principal.UserID}, err)
+}
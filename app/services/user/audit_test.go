@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/password"
+)
+
+func TestUserService_LoginRecordsAuditEvents(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := &models.User{ID: "user-123", Email: "test@example.com", Password: string(hash)}
+
+	tests := []struct {
+		name          string
+		password      string
+		setupMocks    func(repo *MockUserRepository, sessions *MockSessionRepository)
+		expectedEvent models.AuditEvent
+		expectedError error
+	}{
+		{
+			name:     "successful login",
+			password: "correct-password",
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+				sessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedEvent: models.AuditEvent{Action: models.AuditLogin, ActorID: "user-123", OwnerID: "user-123"},
+		},
+		{
+			name:     "wrong password",
+			password: "wrong-password",
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+			},
+			expectedEvent: models.AuditEvent{Action: models.AuditLogin, OwnerID: "user-123"},
+			expectedError: errors.ErrInvalidCredentials,
+		},
+		{
+			name:     "unknown email",
+			password: "correct-password",
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(nil, errors.ErrUserNotFound)
+			},
+			expectedEvent: models.AuditEvent{Action: models.AuditLogin},
+			expectedError: errors.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockUserRepository(ctrl)
+			mockSessions := NewMockSessionRepository(ctrl)
+			mockAudit := NewMockAuditor(ctrl)
+			service := NewUserService(Dependencies{Users: mockRepo, Sessions: mockSessions, Audit: mockAudit}, Config{
+				JWTSecret:       "test-secret",
+				AccessTokenTTL:  time.Hour,
+				RefreshTokenTTL: 24 * time.Hour,
+				Passwords:       password.NewBcrypt(bcrypt.MinCost),
+			})
+
+			tt.setupMocks(mockRepo, mockSessions)
+			mockAudit.EXPECT().Record(gomock.Any(), tt.expectedEvent, tt.expectedError)
+
+			_, err := service.Login(context.Background(), "test@example.com", tt.password, models.ClientInfo{})
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
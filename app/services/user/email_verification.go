@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// SendVerificationEmail mails a signed link that proves the user controls
+// their address.
+func (s *UserService) SendVerificationEmail(ctx context.Context, user *models.User) error {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return err
+	}
+
+	claims := s.newClaims(tokenTypeEmailVerification, user.ID, "", tokenID, s.emailVerificationTTL)
+	claims.Email = user.Email
+	token, err := s.sign(claims)
+	if err != nil {
+		return err
+	}
+
+	link, err := withToken(s.emailVerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your MedDoc email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nOpen the link below to confirm your email address. It expires in %s.\n\n%s\n\n"+
+				"If you did not create a MedDoc account, you can ignore this email.\n",
+			user.Name, s.emailVerificationTTL, link,
+		),
+	})
+}
+
+// ResendVerificationEmail sends a new verification link to an unverified
+// account. Unknown and already verified addresses are ignored so the endpoint
+// cannot be used to probe for accounts.
+func (s *UserService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	return s.SendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail marks the address in a verification token as verified. Tokens
+// issued for an address the user has since changed are rejected. Tokens from
+// RequestEmailChange switch the account to the new address.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := s.parseToken(token, tokenTypeEmailVerification)
+	if err != nil || claims.Email == "" {
+		return apperrors.ErrInvalidToken
+	}
+
+	if claims.PreviousEmail != "" {
+		return s.changeEmail(ctx, claims)
+	}
+
+	if err := s.repo.MarkEmailVerified(ctx, claims.Subject, claims.Email); err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return apperrors.ErrInvalidToken
+		}
+		return err
+	}
+	return nil
+}
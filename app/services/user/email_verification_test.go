@@ -0,0 +1,130 @@
+package user
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockMailer := NewMockMailer(ctrl)
+	cfg := Config{
+		JWTSecret:            "test-secret",
+		Issuer:               "meddoc",
+		Audience:             "meddoc-api",
+		AccessTokenTTL:       time.Hour,
+		EmailVerificationURL: "https://meddoc.example/verify-email",
+		EmailVerificationTTL: time.Hour,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, Mailer: mockMailer}, cfg)
+
+	user := &models.User{ID: "user-123", Email: "test@example.com", Name: "Test User"}
+
+	var token string
+	mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+	mockMailer.EXPECT().
+		Send(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+			u, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(msg.Body))
+			require.NoError(t, err)
+			assert.Equal(t, "/verify-email", u.Path)
+			token = u.Query().Get("token")
+			return nil
+		})
+
+	require.NoError(t, service.ResendVerificationEmail(context.Background(), "test@example.com"))
+	require.NotEmpty(t, token)
+
+	t.Run("valid token", func(t *testing.T) {
+		mockRepo.EXPECT().MarkEmailVerified(gomock.Any(), "user-123", "test@example.com").Return(nil)
+
+		assert.NoError(t, service.VerifyEmail(context.Background(), token))
+	})
+
+	t.Run("address changed since", func(t *testing.T) {
+		mockRepo.EXPECT().
+			MarkEmailVerified(gomock.Any(), "user-123", "test@example.com").
+			Return(errors.ErrUserNotFound)
+
+		assert.ErrorIs(t, service.VerifyEmail(context.Background(), token), errors.ErrInvalidToken)
+	})
+
+	t.Run("access token is not a verification token", func(t *testing.T) {
+		accessToken, err := service.signToken(tokenTypeAccess, "user-123", "", "token-1", time.Minute)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, service.VerifyEmail(context.Background(), accessToken), errors.ErrInvalidToken)
+	})
+
+	t.Run("already verified accounts get no mail", func(t *testing.T) {
+		verified := *user
+		verified.EmailVerified = true
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(&verified, nil)
+
+		assert.NoError(t, service.ResendVerificationEmail(context.Background(), "test@example.com"))
+	})
+
+	t.Run("unknown accounts get no mail", func(t *testing.T) {
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "nobody@example.com").Return(nil, errors.ErrUserNotFound)
+
+		assert.NoError(t, service.ResendVerificationEmail(context.Background(), "nobody@example.com"))
+	})
+}
+
+func TestUserService_LoginRequiresVerifiedEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	cfg := Config{
+		JWTSecret:                "test-secret",
+		AccessTokenTTL:           time.Hour,
+		RefreshTokenTTL:          time.Hour * 24,
+		RequireEmailVerification: true,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, Sessions: mockSessions}, cfg)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.DefaultCost)
+	user := &models.User{ID: "user-123", Email: "test@example.com", Password: string(hashedPassword)}
+
+	t.Run("unverified", func(t *testing.T) {
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+
+		_, err := service.Login(context.Background(), "test@example.com", "correct-password", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrEmailNotVerified)
+	})
+
+	t.Run("wrong password is reported first", func(t *testing.T) {
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+
+		_, err := service.Login(context.Background(), "test@example.com", "wrong-password", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidCredentials)
+	})
+
+	t.Run("verified", func(t *testing.T) {
+		verified := *user
+		verified.EmailVerified = true
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(&verified, nil)
+		mockSessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := service.Login(context.Background(), "test@example.com", "correct-password", models.ClientInfo{})
+		require.NoError(t, err)
+		assert.NotNil(t, result.Tokens)
+	})
+}
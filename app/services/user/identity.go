@@ -0,0 +1,89 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// LoginWithIdentity signs in a user an OpenID Connect provider authenticated.
+// A provider account seen for the first time is linked to the user with the
+// same verified email address, or a new user without a password is created
+// for it. Accounts with two-factor authentication still get an MFA challenge.
+func (s *UserService) LoginWithIdentity(ctx context.Context, login models.ExternalLogin, client models.ClientInfo) (result *models.LoginResult, err error) {
+	var userID string
+	defer func() { s.recordLogin(ctx, userID, result, err) }()
+
+	user, err := s.repo.GetByIdentity(ctx, login.Provider, login.Subject)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		user, err = s.linkIdentity(ctx, login)
+	}
+	if err != nil {
+		return nil, err
+	}
+	userID = user.ID
+
+	return s.finishLogin(ctx, user, client)
+}
+
+func (s *UserService) linkIdentity(ctx context.Context, login models.ExternalLogin) (*models.User, error) {
+	if login.Email == "" || !login.EmailVerified {
+		return nil, apperrors.ErrIdentityEmailUnverified
+	}
+	identity := models.ExternalIdentity{Provider: login.Provider, Subject: login.Subject}
+
+	user, err := s.repo.GetByEmail(ctx, login.Email)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return s.createFederatedUser(ctx, login, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		// Whoever registered this address never proved they own it, while
+		// the provider vouches for our user. Drop the password and sessions
+		// set up so far, so nobody can claim an account in advance.
+		if err := s.repo.UpdatePassword(ctx, user.ID, ""); err != nil {
+			return nil, err
+		}
+		if err := s.revokeAllTokens(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		if err := s.repo.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+			return nil, err
+		}
+		user.Password = ""
+		user.EmailVerified = true
+	}
+
+	if err := s.repo.AddIdentity(ctx, user.ID, identity); err != nil {
+		return nil, err
+	}
+	user.Identities = append(user.Identities, identity)
+	return user, nil
+}
+
+// createFederatedUser creates a user that signs in through a provider. It has
+// no password until one is set with ResetPassword.
+func (s *UserService) createFederatedUser(ctx context.Context, login models.ExternalLogin, identity models.ExternalIdentity) (*models.User, error) {
+	name := login.Name
+	if name == "" {
+		name = login.Email
+	}
+
+	user := &models.User{
+		Email:         login.Email,
+		Name:          name,
+		EmailVerified: true,
+		Roles:         slices.Clone(models.DefaultRoles),
+		Identities:    []models.ExternalIdentity{identity},
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
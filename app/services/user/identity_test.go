@@ -0,0 +1,160 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestUserService_LoginWithIdentity(t *testing.T) {
+	login := models.ExternalLogin{
+		Provider:      "corp",
+		Subject:       "sub-123",
+		Email:         "test@example.com",
+		EmailVerified: true,
+		Name:          "Test User",
+	}
+	identity := models.ExternalIdentity{Provider: "corp", Subject: "sub-123"}
+
+	tests := []struct {
+		name          string
+		login         models.ExternalLogin
+		setupMocks    func(repo *MockUserRepository, sessions *MockSessionRepository)
+		expectSession bool
+		expectedError error
+	}{
+		{
+			name:  "linked identity",
+			login: login,
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").
+					Return(&models.User{ID: "user-123", Identities: []models.ExternalIdentity{identity}}, nil)
+			},
+			expectSession: true,
+		},
+		{
+			name:  "links account with the same verified email",
+			login: login,
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").Return(nil, errors.ErrUserNotFound)
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").
+					Return(&models.User{ID: "user-123", Email: "test@example.com", Password: "hash", EmailVerified: true}, nil)
+				repo.EXPECT().AddIdentity(gomock.Any(), "user-123", identity).Return(nil)
+			},
+			expectSession: true,
+		},
+		{
+			name:  "links unverified account and drops its password",
+			login: login,
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").Return(nil, errors.ErrUserNotFound)
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").
+					Return(&models.User{ID: "user-123", Email: "test@example.com", Password: "hash"}, nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), "user-123", "").Return(nil)
+				repo.EXPECT().SetTokensValidAfter(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+				sessions.EXPECT().RevokeAllForUser(gomock.Any(), "user-123").Return(nil)
+				repo.EXPECT().MarkEmailVerified(gomock.Any(), "user-123", "test@example.com").Return(nil)
+				repo.EXPECT().AddIdentity(gomock.Any(), "user-123", identity).Return(nil)
+			},
+			expectSession: true,
+		},
+		{
+			name:  "creates a user",
+			login: login,
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").Return(nil, errors.ErrUserNotFound)
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(nil, errors.ErrUserNotFound)
+				repo.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *models.User) error {
+						assert.Equal(t, "test@example.com", user.Email)
+						assert.Equal(t, "Test User", user.Name)
+						assert.Empty(t, user.Password)
+						assert.True(t, user.EmailVerified)
+						assert.Equal(t, models.DefaultRoles, user.Roles)
+						assert.Equal(t, []models.ExternalIdentity{identity}, user.Identities)
+						user.ID = "user-456"
+						return nil
+					})
+			},
+			expectSession: true,
+		},
+		{
+			name: "unverified email",
+			login: models.ExternalLogin{
+				Provider: "corp",
+				Subject:  "sub-123",
+				Email:    "test@example.com",
+			},
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").Return(nil, errors.ErrUserNotFound)
+			},
+			expectedError: errors.ErrIdentityEmailUnverified,
+		},
+		{
+			name:  "identity linked to another user",
+			login: login,
+			setupMocks: func(repo *MockUserRepository, sessions *MockSessionRepository) {
+				repo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").Return(nil, errors.ErrUserNotFound)
+				repo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").
+					Return(&models.User{ID: "user-123", Email: "test@example.com", EmailVerified: true}, nil)
+				repo.EXPECT().AddIdentity(gomock.Any(), "user-123", identity).Return(errors.ErrUserExists)
+			},
+			expectedError: errors.ErrUserExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockUserRepository(ctrl)
+			mockSessions := NewMockSessionRepository(ctrl)
+			service := NewUserService(Dependencies{Users: mockRepo, Sessions: mockSessions}, Config{
+				JWTSecret:       "test-secret",
+				AccessTokenTTL:  time.Hour,
+				RefreshTokenTTL: time.Hour * 24,
+			})
+
+			tt.setupMocks(mockRepo, mockSessions)
+			if tt.expectSession {
+				mockSessions.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, session *models.Session) error {
+						session.ID = "session-123"
+						return nil
+					})
+			}
+
+			result, err := service.LoginWithIdentity(context.Background(), tt.login, models.ClientInfo{})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, result.Tokens)
+			assert.NotEmpty(t, result.Tokens.AccessToken)
+		})
+	}
+}
+
+func TestUserService_LoginWithIdentityRequiresMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockUserRepository(ctrl)
+	service := NewUserService(Dependencies{Users: mockRepo}, Config{JWTSecret: "test-secret", MFACipher: newTestMFACipher(t)})
+
+	mockRepo.EXPECT().GetByIdentity(gomock.Any(), "corp", "sub-123").
+		Return(&models.User{ID: "user-123", MFA: models.MFASettings{Enabled: true}}, nil)
+
+	result, err := service.LoginWithIdentity(context.Background(), models.ExternalLogin{Provider: "corp", Subject: "sub-123"}, models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Nil(t, result.Tokens)
+	require.NotNil(t, result.Challenge)
+	assert.True(t, result.Challenge.MFARequired)
+}
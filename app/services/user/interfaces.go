@@ -2,12 +2,83 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByIdentity(ctx context.Context, provider, subject string) (*models.User, error)
+	AddIdentity(ctx context.Context, id string, identity models.ExternalIdentity) error
+	Update(ctx context.Context, user *models.User) error
+	SetRoles(ctx context.Context, id string, roles []string) error
+	Delete(ctx context.Context, id string) error
+	SetTokensValidAfter(ctx context.Context, id string, validAfter time.Time) error
+	SetMFA(ctx context.Context, id string, mfa models.MFASettings) error
+	ConsumeRecoveryCode(ctx context.Context, id, codeHash string) error
+	ConsumeTOTPStep(ctx context.Context, id string, step int64) error
+	UpdatePassword(ctx context.Context, id, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id, email string) error
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	Rotate(ctx context.Context, id, currentTokenID string, rotation models.SessionRotation) error
+	GetActiveByUserID(ctx context.Context, userID string) ([]*models.Session, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+type RevokedTokenRepository interface {
+	Add(ctx context.Context, tokenID string, expiresAt time.Time) error
+	Exists(ctx context.Context, tokenID string) (bool, error)
+}
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	Consume(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	DeleteAllForUser(ctx context.Context, userID string) error
+}
+
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, key string) (*models.LoginAttempts, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error)
+	Touch(ctx context.Context, id string, at time.Time) error
+	Delete(ctx context.Context, id, userID string) error
+	DeleteAllForUser(ctx context.Context, userID string) error
+}
+
+// DeviceRepository remembers the devices users signed in from.
+type DeviceRepository interface {
+	Remember(ctx context.Context, userID, fingerprint string, seenAt time.Time) (bool, error)
+	CountByUserID(ctx context.Context, userID string) (int64, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// LoginNotifier tells users about sign-ins from devices they have not used
+// before.
+type LoginNotifier interface {
+	NotifyNewDevice(ctx context.Context, user *models.User, session *models.Session) error
+}
+
+// Auditor records sign-ins and changes to how users sign in.
+type Auditor interface {
+	Record(ctx context.Context, event models.AuditEvent, err error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
 }
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// MailLoginNotifier emails users about sign-ins from new devices.
+type MailLoginNotifier struct {
+	mailer Mailer
+}
+
+func NewMailLoginNotifier(mailer Mailer) *MailLoginNotifier {
+	return &MailLoginNotifier{
+		mailer: mailer,
+	}
+}
+
+func (n *MailLoginNotifier) NotifyNewDevice(ctx context.Context, user *models.User, session *models.Session) error {
+	device := session.UserAgent
+	if device == "" {
+		device = "unknown"
+	}
+	ip := session.IP
+	if ip == "" {
+		ip = "unknown"
+	}
+
+	return n.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "New sign-in to your MedDoc account",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nYour account was signed in to from a device it has not been used on before.\n\n"+
+				"Device: %s\nIP address: %s\nTime: %s\n\n"+
+				"If this was you, there is nothing to do. Otherwise sign the session out from your list of "+
+				"sessions and change your password.\n",
+			user.Name, device, ip, session.CreatedAt.UTC().Format(time.RFC1123),
+		),
+	})
+}
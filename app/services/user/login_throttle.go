@@ -0,0 +1,190 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+)
+
+// LoginThrottleConfig limits password and second-factor guessing. Password
+// throttling is disabled when MaxAccountFailures is not positive, the IP
+// lockout when MaxIPFailures is not positive, second-factor throttling when
+// MaxMFAFailures is not positive and the progressive delay when DelayAfter is
+// negative.
+type LoginThrottleConfig struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	MaxMFAFailures     int
+	LockoutDuration    time.Duration
+	FailureWindow      time.Duration
+	DelayAfter         int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+}
+
+const (
+	accountKeyPrefix = "account:"
+	ipKeyPrefix      = "ip:"
+	mfaKeyPrefix     = "mfa:"
+)
+
+type attemptKeys struct {
+	account string
+	ip      string
+}
+
+func (s *UserService) loginThrottleEnabled() bool {
+	return s.throttle.MaxAccountFailures > 0
+}
+
+func (s *UserService) mfaThrottleEnabled() bool {
+	return s.throttle.MaxMFAFailures > 0
+}
+
+func newAttemptKeys(email, ip string) attemptKeys {
+	keys := attemptKeys{account: accountKeyPrefix + strings.ToLower(strings.TrimSpace(email))}
+	if ip != "" {
+		keys.ip = ipKeyPrefix + ip
+	}
+	return keys
+}
+
+// checkLoginAllowed refuses a login while the account or client IP is locked
+// out, or while the progressive delay after repeated failures has not passed.
+func (s *UserService) checkLoginAllowed(ctx context.Context, keys attemptKeys) error {
+	if !s.loginThrottleEnabled() {
+		return nil
+	}
+	now := time.Now()
+
+	if keys.ip != "" {
+		attempts, err := s.loginAttempts.Get(ctx, keys.ip)
+		if err != nil {
+			return err
+		}
+		if now.Before(attempts.LockedUntil) {
+			return &apperrors.LoginThrottledError{Err: apperrors.ErrTooManyAttempts, RetryAfter: attempts.LockedUntil.Sub(now)}
+		}
+	}
+
+	attempts, err := s.loginAttempts.Get(ctx, keys.account)
+	if err != nil {
+		return err
+	}
+	if now.Before(attempts.LockedUntil) {
+		return &apperrors.LoginThrottledError{Err: apperrors.ErrAccountLocked, RetryAfter: attempts.LockedUntil.Sub(now)}
+	}
+	if next := attempts.LastFailureAt.Add(s.loginDelay(attempts.Failures)); now.Before(next) {
+		return &apperrors.LoginThrottledError{Err: apperrors.ErrTooManyAttempts, RetryAfter: next.Sub(now)}
+	}
+
+	return nil
+}
+
+// loginDelay is how long to wait after the last of failures failed attempts.
+func (s *UserService) loginDelay(failures int) time.Duration {
+	if s.throttle.DelayAfter < 0 || failures < s.throttle.DelayAfter {
+		return 0
+	}
+
+	delay := s.throttle.BaseDelay
+	for i := s.throttle.DelayAfter; i < failures && delay < s.throttle.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.throttle.MaxDelay)
+}
+
+func (s *UserService) recordLoginFailure(ctx context.Context, keys attemptKeys) error {
+	if !s.loginThrottleEnabled() {
+		return nil
+	}
+
+	if err := s.recordFailure(ctx, keys.account, s.throttle.MaxAccountFailures); err != nil {
+		return err
+	}
+	if keys.ip != "" && s.throttle.MaxIPFailures > 0 {
+		return s.recordFailure(ctx, keys.ip, s.throttle.MaxIPFailures)
+	}
+	return nil
+}
+
+func (s *UserService) recordFailure(ctx context.Context, key string, maxFailures int) error {
+	_, err := s.recordFailureAndLock(ctx, key, maxFailures)
+	return err
+}
+
+// recordFailureAndLock counts a failure for key and locks it once maxFailures
+// is reached. It reports whether the key got locked.
+func (s *UserService) recordFailureAndLock(ctx context.Context, key string, maxFailures int) (bool, error) {
+	attempts, err := s.loginAttempts.RecordFailure(ctx, key, s.throttle.FailureWindow)
+	if err != nil {
+		return false, err
+	}
+	if attempts.Failures < maxFailures {
+		return false, nil
+	}
+	if err := s.loginAttempts.Lock(ctx, key, time.Now().Add(s.throttle.LockoutDuration)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// resetLoginFailures clears the account counter after a successful login. The
+// IP counter is left alone so one valid account cannot hide guessing at
+// others from the same address.
+func (s *UserService) resetLoginFailures(ctx context.Context, keys attemptKeys) error {
+	if !s.loginThrottleEnabled() {
+		return nil
+	}
+	return s.loginAttempts.Reset(ctx, keys.account)
+}
+
+// checkMFAAllowed refuses second-factor codes for a user locked out after too
+// many wrong ones, or while the progressive delay has not passed. Failures
+// are counted per user rather than per MFA token, so signing in again with
+// the password does not buy more guesses.
+func (s *UserService) checkMFAAllowed(ctx context.Context, userID string) error {
+	if !s.mfaThrottleEnabled() {
+		return nil
+	}
+	now := time.Now()
+
+	attempts, err := s.loginAttempts.Get(ctx, mfaKeyPrefix+userID)
+	if err != nil {
+		return err
+	}
+	if now.Before(attempts.LockedUntil) {
+		return &apperrors.LoginThrottledError{Err: apperrors.ErrAccountLocked, RetryAfter: attempts.LockedUntil.Sub(now)}
+	}
+	if next := attempts.LastFailureAt.Add(s.loginDelay(attempts.Failures)); now.Before(next) {
+		return &apperrors.LoginThrottledError{Err: apperrors.ErrTooManyAttempts, RetryAfter: next.Sub(now)}
+	}
+
+	return nil
+}
+
+// recordMFAFailure counts a wrong second-factor code. The failure that locks
+// the user out is returned as a LoginThrottledError instead of err.
+func (s *UserService) recordMFAFailure(ctx context.Context, userID string, err error) error {
+	if !s.mfaThrottleEnabled() {
+		return err
+	}
+
+	locked, recordErr := s.recordFailureAndLock(ctx, mfaKeyPrefix+userID, s.throttle.MaxMFAFailures)
+	if recordErr != nil {
+		return recordErr
+	}
+	if locked {
+		return &apperrors.LoginThrottledError{Err: apperrors.ErrAccountLocked, RetryAfter: s.throttle.LockoutDuration}
+	}
+	return err
+}
+
+func (s *UserService) resetMFAFailures(ctx context.Context, userID string) error {
+	if !s.mfaThrottleEnabled() {
+		return nil
+	}
+	return s.loginAttempts.Reset(ctx, mfaKeyPrefix+userID)
+}
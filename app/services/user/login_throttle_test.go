@@ -0,0 +1,393 @@
+package user
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestUserService_LoginThrottle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	mockAttempts := NewMockLoginAttemptRepository(ctrl)
+	cfg := Config{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour * 24,
+		LoginThrottle: LoginThrottleConfig{
+			MaxAccountFailures: 5,
+			MaxIPFailures:      20,
+			LockoutDuration:    15 * time.Minute,
+			FailureWindow:      15 * time.Minute,
+			DelayAfter:         3,
+			BaseDelay:          time.Second,
+			MaxDelay:           30 * time.Second,
+		},
+	}
+	service := NewUserService(Dependencies{
+		Users:         mockRepo,
+		Sessions:      mockSessions,
+		LoginAttempts: mockAttempts,
+	}, cfg)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.DefaultCost)
+	user := &models.User{ID: "user-123", Email: "test@example.com", Password: string(hashedPassword)}
+	client := models.ClientInfo{IP: "192.0.2.1"}
+	now := time.Now()
+
+	noAttempts := func(key string) {
+		mockAttempts.EXPECT().Get(gomock.Any(), key).Return(&models.LoginAttempts{Key: key}, nil)
+	}
+
+	tests := []struct {
+		name          string
+		email         string
+		password      string
+		mockSetup     func()
+		expectedError error
+		retryAfter    time.Duration
+	}{
+		{
+			name:     "successful login resets account counter",
+			email:    "Test@Example.com",
+			password: "correct-password",
+			mockSetup: func() {
+				noAttempts("ip:192.0.2.1")
+				noAttempts("account:test@example.com")
+				mockRepo.EXPECT().GetByEmail(gomock.Any(), "Test@Example.com").Return(user, nil)
+				mockAttempts.EXPECT().Reset(gomock.Any(), "account:test@example.com").Return(nil)
+				mockSessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "wrong password records failures",
+			email:    "test@example.com",
+			password: "wrong-password",
+			mockSetup: func() {
+				noAttempts("ip:192.0.2.1")
+				noAttempts("account:test@example.com")
+				mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+				mockAttempts.EXPECT().
+					RecordFailure(gomock.Any(), "account:test@example.com", 15*time.Minute).
+					Return(&models.LoginAttempts{Failures: 1}, nil)
+				mockAttempts.EXPECT().
+					RecordFailure(gomock.Any(), "ip:192.0.2.1", 15*time.Minute).
+					Return(&models.LoginAttempts{Failures: 1}, nil)
+			},
+			expectedError: errors.ErrInvalidCredentials,
+		},
+		{
+			name:     "unknown account records failures",
+			email:    "nobody@example.com",
+			password: "any-password",
+			mockSetup: func() {
+				noAttempts("ip:192.0.2.1")
+				noAttempts("account:nobody@example.com")
+				mockRepo.EXPECT().GetByEmail(gomock.Any(), "nobody@example.com").Return(nil, errors.ErrUserNotFound)
+				mockAttempts.EXPECT().
+					RecordFailure(gomock.Any(), "account:nobody@example.com", gomock.Any()).
+					Return(&models.LoginAttempts{Failures: 1}, nil)
+				mockAttempts.EXPECT().
+					RecordFailure(gomock.Any(), "ip:192.0.2.1", gomock.Any()).
+					Return(&models.LoginAttempts{Failures: 1}, nil)
+			},
+			expectedError: errors.ErrUserNotFound,
+		},
+		{
+			name:     "last allowed failure locks the account",
+			email:    "test@example.com",
+			password: "wrong-password",
+			mockSetup: func() {
+				noAttempts("ip:192.0.2.1")
+				mockAttempts.EXPECT().
+					Get(gomock.Any(), "account:test@example.com").
+					Return(&models.LoginAttempts{Failures: 4, LastFailureAt: now.Add(-time.Minute)}, nil)
+				mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+				mockAttempts.EXPECT().
+					RecordFailure(gomock.Any(), "account:test@example.com", gomock.Any()).
+					Return(&models.LoginAttempts{Failures: 5}, nil)
+				mockAttempts.EXPECT().
+					Lock(gomock.Any(), "account:test@example.com", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, until time.Time) error {
+						assert.WithinDuration(t, time.Now().Add(15*time.Minute), until, time.Second)
+						return nil
+					})
+				mockAttempts.EXPECT().
+					RecordFailure(gomock.Any(), "ip:192.0.2.1", gomock.Any()).
+					Return(&models.LoginAttempts{Failures: 5}, nil)
+			},
+			expectedError: errors.ErrInvalidCredentials,
+		},
+		{
+			name:     "locked account",
+			email:    "test@example.com",
+			password: "correct-password",
+			mockSetup: func() {
+				noAttempts("ip:192.0.2.1")
+				mockAttempts.EXPECT().
+					Get(gomock.Any(), "account:test@example.com").
+					Return(&models.LoginAttempts{Failures: 5, LockedUntil: now.Add(10 * time.Minute)}, nil)
+			},
+			expectedError: errors.ErrAccountLocked,
+			retryAfter:    10 * time.Minute,
+		},
+		{
+			name:     "locked client IP",
+			email:    "test@example.com",
+			password: "correct-password",
+			mockSetup: func() {
+				mockAttempts.EXPECT().
+					Get(gomock.Any(), "ip:192.0.2.1").
+					Return(&models.LoginAttempts{Failures: 20, LockedUntil: now.Add(5 * time.Minute)}, nil)
+			},
+			expectedError: errors.ErrTooManyAttempts,
+			retryAfter:    5 * time.Minute,
+		},
+		{
+			name:     "progressive delay",
+			email:    "test@example.com",
+			password: "correct-password",
+			mockSetup: func() {
+				noAttempts("ip:192.0.2.1")
+				mockAttempts.EXPECT().
+					Get(gomock.Any(), "account:test@example.com").
+					Return(&models.LoginAttempts{Failures: 4, LastFailureAt: now}, nil)
+			},
+			expectedError: errors.ErrTooManyAttempts,
+			retryAfter:    2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := service.Login(context.Background(), tt.email, tt.password, client)
+			if tt.expectedError == nil {
+				require.NoError(t, err)
+				assert.NotNil(t, result.Tokens)
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.retryAfter > 0 {
+				var throttled *errors.LoginThrottledError
+				require.True(t, stderrors.As(err, &throttled))
+				assert.InDelta(t, tt.retryAfter.Seconds(), throttled.RetryAfter.Seconds(), 1)
+			}
+		})
+	}
+}
+
+func TestUserService_LoginThrottleDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	// No expectations: a disabled throttle never reads or counts attempts.
+	mockAttempts := NewMockLoginAttemptRepository(ctrl)
+	service := NewUserService(Dependencies{
+		Users:         mockRepo,
+		Sessions:      mockSessions,
+		LoginAttempts: mockAttempts,
+	}, Config{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour * 24,
+		LoginThrottle: LoginThrottleConfig{
+			MaxAccountFailures: -1,
+			MaxIPFailures:      -1,
+			MaxMFAFailures:     -1,
+			DelayAfter:         -1,
+		},
+	})
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.DefaultCost)
+	user := &models.User{ID: "user-123", Email: "test@example.com", Password: string(hashedPassword)}
+	client := models.ClientInfo{IP: "192.0.2.1"}
+
+	mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil).Times(2)
+	_, err := service.Login(context.Background(), "test@example.com", "wrong-password", client)
+	assert.ErrorIs(t, err, errors.ErrInvalidCredentials)
+
+	mockSessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	_, err = service.Login(context.Background(), "test@example.com", "correct-password", client)
+	assert.NoError(t, err)
+
+	assert.Zero(t, service.loginDelay(100))
+}
+
+func TestUserService_LoginDelay(t *testing.T) {
+	service := NewUserService(Dependencies{}, Config{
+		LoginThrottle: LoginThrottleConfig{
+			MaxAccountFailures: 10,
+			DelayAfter:         3,
+			BaseDelay:          time.Second,
+			MaxDelay:           30 * time.Second,
+		},
+	})
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: 0},
+		{failures: 2, expected: 0},
+		{failures: 3, expected: time.Second},
+		{failures: 4, expected: 2 * time.Second},
+		{failures: 6, expected: 8 * time.Second},
+		{failures: 9, expected: 30 * time.Second},
+		{failures: 100, expected: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, service.loginDelay(tt.failures), "failures: %d", tt.failures)
+	}
+}
+
+func TestUserService_MFAThrottle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	mockRevoked := NewMockRevokedTokenRepository(ctrl)
+	mockAttempts := NewMockLoginAttemptRepository(ctrl)
+	service := NewUserService(Dependencies{
+		Users:         mockRepo,
+		Sessions:      mockSessions,
+		RevokedTokens: mockRevoked,
+		LoginAttempts: mockAttempts,
+	}, Config{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour * 24,
+		MFACipher:       newTestMFACipher(t),
+		LoginThrottle: LoginThrottleConfig{
+			MaxMFAFailures:  3,
+			LockoutDuration: 15 * time.Minute,
+			FailureWindow:   15 * time.Minute,
+			DelayAfter:      2,
+			BaseDelay:       time.Second,
+			MaxDelay:        30 * time.Second,
+		},
+	})
+	revoked := map[string]bool{}
+	mockRevoked.EXPECT().
+		Exists(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, tokenID string) (bool, error) { return revoked[tokenID], nil }).
+		AnyTimes()
+	revoke := func(_ context.Context, tokenID string, _ time.Time) error {
+		revoked[tokenID] = true
+		return nil
+	}
+
+	user := &models.User{
+		ID:  "user-123",
+		MFA: models.MFASettings{Enabled: true, RecoveryCodes: []string{hashRecoveryCode("abcde-fghij")}},
+	}
+	now := time.Now()
+
+	newMFAToken := func(t *testing.T) string {
+		challenge, err := service.mfaChallenge(user)
+		require.NoError(t, err)
+		return challenge.MFAToken
+	}
+	attempts := func(a models.LoginAttempts) {
+		mockAttempts.EXPECT().Get(gomock.Any(), "mfa:user-123").Return(&a, nil)
+	}
+	wrongCode := func(failures int) {
+		mockRepo.EXPECT().ConsumeRecoveryCode(gomock.Any(), "user-123", gomock.Any()).Return(errors.ErrInvalidMFACode)
+		mockAttempts.EXPECT().
+			RecordFailure(gomock.Any(), "mfa:user-123", 15*time.Minute).
+			Return(&models.LoginAttempts{Failures: failures}, nil)
+	}
+
+	t.Run("wrong code is counted", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{})
+		wrongCode(1)
+
+		_, err := service.VerifyMFA(context.Background(), newMFAToken(t), "zzzzz-zzzzz", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidMFACode)
+	})
+
+	t.Run("last allowed failure locks and burns the token", func(t *testing.T) {
+		mfaToken := newMFAToken(t)
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{Failures: 2, LastFailureAt: now.Add(-time.Minute)})
+		wrongCode(3)
+		mockAttempts.EXPECT().
+			Lock(gomock.Any(), "mfa:user-123", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, until time.Time) error {
+				assert.WithinDuration(t, time.Now().Add(15*time.Minute), until, time.Second)
+				return nil
+			})
+		mockRevoked.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(revoke)
+
+		_, err := service.VerifyMFA(context.Background(), mfaToken, "zzzzz-zzzzz", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrAccountLocked)
+		var throttled *errors.LoginThrottledError
+		require.True(t, stderrors.As(err, &throttled))
+		assert.Equal(t, 15*time.Minute, throttled.RetryAfter)
+
+		_, err = service.VerifyMFA(context.Background(), mfaToken, "abcde-fghij", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidToken)
+	})
+
+	t.Run("locked user cannot sign in even with the right code", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{Failures: 3, LockedUntil: now.Add(10 * time.Minute)})
+		mockRevoked.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(revoke)
+
+		_, err := service.VerifyMFA(context.Background(), newMFAToken(t), "abcde-fghij", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrAccountLocked)
+	})
+
+	t.Run("progressive delay", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{Failures: 2, LastFailureAt: now})
+
+		_, err := service.RegenerateRecoveryCodes(context.Background(), "user-123", "abcde-fghij")
+		assert.ErrorIs(t, err, errors.ErrTooManyAttempts)
+	})
+
+	t.Run("locked user cannot disable two-factor authentication", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{Failures: 3, LockedUntil: now.Add(10 * time.Minute)})
+
+		assert.ErrorIs(t, service.DisableTOTP(context.Background(), "user-123", "abcde-fghij"), errors.ErrAccountLocked)
+	})
+
+	t.Run("wrong code when disabling is counted", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{})
+		wrongCode(1)
+
+		assert.ErrorIs(t, service.DisableTOTP(context.Background(), "user-123", "zzzzz-zzzzz"), errors.ErrInvalidMFACode)
+	})
+
+	t.Run("right code resets the counter", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		attempts(models.LoginAttempts{Failures: 1, LastFailureAt: now})
+		mockRepo.EXPECT().ConsumeRecoveryCode(gomock.Any(), "user-123", hashRecoveryCode("abcde-fghij")).Return(nil)
+		mockAttempts.EXPECT().Reset(gomock.Any(), "mfa:user-123").Return(nil)
+		mockRevoked.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(revoke)
+		mockSessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := service.VerifyMFA(context.Background(), newMFAToken(t), "abcde-fghij", models.ClientInfo{})
+		assert.NoError(t, err)
+	})
+}
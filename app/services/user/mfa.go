@@ -0,0 +1,349 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+const (
+	totpPeriod        = 30
+	totpSkew          = 1
+	totpQRSize        = 256
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var totpOpts = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// StartTOTPEnrollment generates a new TOTP secret for the user. It is kept
+// pending until ConfirmTOTPEnrollment proves the authenticator app has it.
+func (s *UserService) StartTOTPEnrollment(ctx context.Context, userID string) (*models.TOTPEnrollment, error) {
+	if s.mfaCipher == nil {
+		return nil, apperrors.ErrMFAUnavailable
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFA.Enabled {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.mfaIssuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.mfaCipher.Seal([]byte(key.Secret()))
+	if err != nil {
+		return nil, err
+	}
+
+	mfa := user.MFA
+	mfa.PendingSecret = sealed
+	if err := s.repo.SetMFA(ctx, user.ID, mfa); err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(totpQRSize, totpQRSize)
+	if err != nil {
+		return nil, err
+	}
+	var qr bytes.Buffer
+	if err := png.Encode(&qr, img); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+		QRCodePNG:  qr.Bytes(),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication once the user
+// enters a valid code for the pending secret. The returned recovery codes are
+// only ever shown here.
+func (s *UserService) ConfirmTOTPEnrollment(ctx context.Context, userID, code string) (*models.RecoveryCodes, error) {
+	if s.mfaCipher == nil {
+		return nil, apperrors.ErrMFAUnavailable
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFA.Enabled {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+	if user.MFA.PendingSecret == "" {
+		return nil, apperrors.ErrMFAEnrollmentNotStarted
+	}
+
+	secret, err := s.mfaCipher.Open(user.MFA.PendingSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := validateTOTP(string(secret), code, time.Now())
+	if !ok {
+		return nil, apperrors.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetMFA(ctx, user.ID, models.MFASettings{
+		Enabled:       true,
+		Secret:        user.MFA.PendingSecret,
+		RecoveryCodes: hashes,
+		LastUsedStep:  step,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It requires a current
+// TOTP or recovery code so a stolen access token alone cannot do it.
+func (s *UserService) DisableTOTP(ctx context.Context, userID, code string) error {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFA.Enabled {
+		return apperrors.ErrMFANotEnabled
+	}
+
+	if _, err := s.verifyMFACode(ctx, user, code); err != nil {
+		return err
+	}
+
+	return s.repo.SetMFA(ctx, user.ID, models.MFASettings{})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user.
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*models.RecoveryCodes, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFA.Enabled {
+		return nil, apperrors.ErrMFANotEnabled
+	}
+
+	step, err := s.verifyMFACode(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	mfa := user.MFA
+	mfa.RecoveryCodes = hashes
+	mfa.LastUsedStep = max(mfa.LastUsedStep, step)
+	if err := s.repo.SetMFA(ctx, user.ID, mfa); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// VerifyMFA completes a login that returned an MFA challenge.
+func (s *UserService) VerifyMFA(ctx context.Context, mfaToken, code string, client models.ClientInfo) (tokens *models.TokenPair, err error) {
+	var userID string
+	defer func() { s.recordLogin(ctx, userID, &models.LoginResult{Tokens: tokens}, err) }()
+
+	claims, err := s.parseToken(mfaToken, tokenTypeMFA)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.isTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, apperrors.ErrInvalidToken
+	}
+	userID = claims.Subject
+
+	user, err := s.repo.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFA.Enabled {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	// The MFA token is good for one successful sign-in, and is given up once
+	// wrong codes lock the user out.
+	if _, err := s.verifyMFACode(ctx, user, code); err != nil {
+		if errors.Is(err, apperrors.ErrAccountLocked) {
+			if revokeErr := s.revokeMFAToken(ctx, claims); revokeErr != nil {
+				return nil, revokeErr
+			}
+		}
+		return nil, err
+	}
+	if err := s.revokeMFAToken(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+func (s *UserService) mfaChallenge(user *models.User) (*models.MFAChallenge, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.signToken(tokenTypeMFA, user.ID, "", tokenID, mfaTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAChallenge{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int(mfaTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *UserService) revokeMFAToken(ctx context.Context, claims *tokenClaims) error {
+	if err := s.revokedTokens.Add(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+	s.revokedCache.Set(claims.ID, true)
+	return nil
+}
+
+// verifyMFACode accepts either a six-digit TOTP code or a recovery code and
+// marks it as used. For TOTP codes it returns the time step that was used.
+// Wrong codes count towards the user's second-factor lockout.
+func (s *UserService) verifyMFACode(ctx context.Context, user *models.User, code string) (int64, error) {
+	if err := s.checkMFAAllowed(ctx, user.ID); err != nil {
+		return 0, err
+	}
+
+	step, err := s.checkMFACode(ctx, user, code)
+	if errors.Is(err, apperrors.ErrInvalidMFACode) {
+		return 0, s.recordMFAFailure(ctx, user.ID, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.resetMFAFailures(ctx, user.ID); err != nil {
+		return 0, err
+	}
+	return step, nil
+}
+
+func (s *UserService) checkMFACode(ctx context.Context, user *models.User, code string) (int64, error) {
+	code = strings.TrimSpace(code)
+	if !isTOTPCode(code) {
+		if err := s.repo.ConsumeRecoveryCode(ctx, user.ID, hashRecoveryCode(code)); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	if s.mfaCipher == nil {
+		return 0, apperrors.ErrMFAUnavailable
+	}
+	secret, err := s.mfaCipher.Open(user.MFA.Secret)
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := validateTOTP(string(secret), code, time.Now())
+	if !ok || step <= user.MFA.LastUsedStep {
+		return 0, apperrors.ErrInvalidMFACode
+	}
+	if err := s.repo.ConsumeTOTPStep(ctx, user.ID, step); err != nil {
+		return 0, err
+	}
+	return step, nil
+}
+
+// validateTOTP checks code against the current time step and one step on
+// either side to tolerate clock drift, returning the step that matched.
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		t := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(secret, t, totpOpts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return t.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
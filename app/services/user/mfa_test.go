@@ -0,0 +1,261 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/secretbox"
+)
+
+func newTestMFACipher(t *testing.T) *secretbox.Box {
+	box, err := secretbox.New([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	return box
+}
+
+func TestUserService_TOTPEnrollment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	cipher := newTestMFACipher(t)
+	cfg := Config{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour * 24,
+		MFAIssuer:       "MedDoc",
+		MFACipher:       cipher,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, Sessions: NewMockSessionRepository(ctrl), RevokedTokens: NewMockRevokedTokenRepository(ctrl)}, cfg)
+
+	user := &models.User{ID: "user-123", Email: "test@example.com"}
+
+	var pending string
+	mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+	mockRepo.EXPECT().
+		SetMFA(gomock.Any(), "user-123", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, mfa models.MFASettings) error {
+			assert.False(t, mfa.Enabled)
+			pending = mfa.PendingSecret
+			return nil
+		})
+
+	enrollment, err := service.StartTOTPEnrollment(context.Background(), "user-123")
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.OTPAuthURI, "otpauth://totp/MedDoc:test@example.com")
+	assert.NotEmpty(t, enrollment.QRCodePNG)
+	assert.NotContains(t, pending, enrollment.Secret)
+
+	secret, err := cipher.Open(pending)
+	require.NoError(t, err)
+	assert.Equal(t, enrollment.Secret, string(secret))
+
+	pendingUser := &models.User{ID: "user-123", MFA: models.MFASettings{PendingSecret: pending}}
+
+	t.Run("invalid code", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(pendingUser, nil)
+
+		_, err := service.ConfirmTOTPEnrollment(context.Background(), "user-123", "000000")
+		assert.ErrorIs(t, err, errors.ErrInvalidMFACode)
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(pendingUser, nil)
+		mockRepo.EXPECT().
+			SetMFA(gomock.Any(), "user-123", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, mfa models.MFASettings) error {
+				assert.True(t, mfa.Enabled)
+				assert.Equal(t, pending, mfa.Secret)
+				assert.Empty(t, mfa.PendingSecret)
+				assert.Len(t, mfa.RecoveryCodes, recoveryCodeCount)
+				assert.NotZero(t, mfa.LastUsedStep)
+				return nil
+			})
+
+		codes, err := service.ConfirmTOTPEnrollment(context.Background(), "user-123", code)
+		require.NoError(t, err)
+		assert.Len(t, codes.Codes, recoveryCodeCount)
+	})
+
+	t.Run("not started", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+
+		_, err := service.ConfirmTOTPEnrollment(context.Background(), "user-123", "123456")
+		assert.ErrorIs(t, err, errors.ErrMFAEnrollmentNotStarted)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		enabled := &models.User{ID: "user-123", MFA: models.MFASettings{Enabled: true}}
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(enabled, nil)
+
+		_, err := service.StartTOTPEnrollment(context.Background(), "user-123")
+		assert.ErrorIs(t, err, errors.ErrMFAAlreadyEnabled)
+	})
+
+	t.Run("unavailable without encryption key", func(t *testing.T) {
+		cfg := cfg
+		cfg.MFACipher = nil
+		service := NewUserService(Dependencies{Users: mockRepo, Sessions: NewMockSessionRepository(ctrl), RevokedTokens: NewMockRevokedTokenRepository(ctrl)}, cfg)
+
+		_, err := service.StartTOTPEnrollment(context.Background(), "user-123")
+		assert.ErrorIs(t, err, errors.ErrMFAUnavailable)
+	})
+}
+
+func TestUserService_LoginWithMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	mockRevoked := NewMockRevokedTokenRepository(ctrl)
+	cipher := newTestMFACipher(t)
+	cfg := Config{
+		JWTSecret:       "test-secret",
+		Issuer:          "meddoc",
+		Audience:        "meddoc-api",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour * 24,
+		MFACipher:       cipher,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, Sessions: mockSessions, RevokedTokens: mockRevoked}, cfg)
+	revoked := map[string]bool{}
+	mockRevoked.EXPECT().
+		Exists(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, tokenID string) (bool, error) { return revoked[tokenID], nil }).
+		AnyTimes()
+	revoke := func(_ context.Context, tokenID string, _ time.Time) error {
+		revoked[tokenID] = true
+		return nil
+	}
+
+	secret := "JBSWY3DPEHPK3PXP"
+	sealed, err := cipher.Seal([]byte(secret))
+	require.NoError(t, err)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.DefaultCost)
+	user := &models.User{
+		ID:       "user-123",
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		MFA: models.MFASettings{
+			Enabled:       true,
+			Secret:        sealed,
+			RecoveryCodes: []string{hashRecoveryCode("abcde-fghij")},
+		},
+	}
+
+	mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+
+	result, err := service.Login(context.Background(), "test@example.com", "correct-password", models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Nil(t, result.Tokens)
+	require.NotNil(t, result.Challenge)
+	assert.True(t, result.Challenge.MFARequired)
+	assert.Equal(t, int(mfaTokenTTL.Seconds()), result.Challenge.ExpiresIn)
+
+	mfaToken := result.Challenge.MFAToken
+	newMFAToken := func(t *testing.T) string {
+		challenge, err := service.mfaChallenge(user)
+		require.NoError(t, err)
+		return challenge.MFAToken
+	}
+
+	t.Run("mfa token is not an access token", func(t *testing.T) {
+		_, err := service.ValidateToken(mfaToken)
+		assert.ErrorIs(t, err, errors.ErrInvalidToken)
+	})
+
+	t.Run("totp code", func(t *testing.T) {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		mockRepo.EXPECT().ConsumeTOTPStep(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+		mockRevoked.EXPECT().
+			Add(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tokenID string, expiresAt time.Time) error {
+				assert.WithinDuration(t, time.Now().Add(mfaTokenTTL), expiresAt, 2*time.Second)
+				return revoke(ctx, tokenID, expiresAt)
+			})
+		mockSessions.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, session *models.Session) error {
+				session.ID = "session-123"
+				return nil
+			})
+
+		tokens, err := service.VerifyMFA(context.Background(), mfaToken, code, models.ClientInfo{})
+		require.NoError(t, err)
+
+		userID, err := service.ValidateToken(tokens.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "user-123", userID)
+	})
+
+	t.Run("used mfa token", func(t *testing.T) {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		_, err = service.VerifyMFA(context.Background(), mfaToken, code, models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidToken)
+	})
+
+	t.Run("replayed totp code", func(t *testing.T) {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		used := *user
+		used.MFA.LastUsedStep = time.Now().Unix()/totpPeriod + 1
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(&used, nil)
+
+		_, err = service.VerifyMFA(context.Background(), newMFAToken(t), code, models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidMFACode)
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		mockRepo.EXPECT().ConsumeRecoveryCode(gomock.Any(), "user-123", hashRecoveryCode("abcde-fghij")).Return(nil)
+		mockRevoked.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(revoke)
+		mockSessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := service.VerifyMFA(context.Background(), newMFAToken(t), "ABCDE FGHIJ", models.ClientInfo{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown recovery code", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		mockRepo.EXPECT().ConsumeRecoveryCode(gomock.Any(), "user-123", gomock.Any()).Return(errors.ErrInvalidMFACode)
+
+		_, err := service.VerifyMFA(context.Background(), newMFAToken(t), "zzzzz-zzzzz", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidMFACode)
+	})
+
+	t.Run("access token is not an mfa token", func(t *testing.T) {
+		accessToken, err := service.signToken(tokenTypeAccess, "user-123", "", "token-1", time.Minute)
+		require.NoError(t, err)
+
+		_, err = service.VerifyMFA(context.Background(), accessToken, "123456", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidToken)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		mockRepo.EXPECT().ConsumeRecoveryCode(gomock.Any(), "user-123", hashRecoveryCode("abcde-fghij")).Return(nil)
+		mockRepo.EXPECT().SetMFA(gomock.Any(), "user-123", models.MFASettings{}).Return(nil)
+
+		assert.NoError(t, service.DisableTOTP(context.Background(), "user-123", "abcde-fghij"))
+	})
+}
@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// ForgotPassword mails a password reset link to the user. Unknown addresses
+// are ignored so the endpoint cannot be used to probe for accounts.
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.resetTokens.Create(ctx, &models.PasswordResetToken{
+		TokenHash: hashResetToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.passwordResetTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	link, err := withToken(s.passwordResetURL, token)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your MedDoc password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nOpen the link below to choose a new password. It expires in %s.\n\n%s\n\n"+
+				"If you did not ask for a password reset, you can ignore this email.\n",
+			user.Name, s.passwordResetTTL, link,
+		),
+	})
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword. The
+// token is consumed, and every session and token of the user is revoked.
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	var userID string
+	defer func() { s.recordAccountEvent(ctx, models.AuditPasswordReset, models.Principal{UserID: userID}, err) }()
+
+	resetToken, err := s.resetTokens.Consume(ctx, hashResetToken(token))
+	if err != nil {
+		return err
+	}
+	userID = resetToken.UserID
+
+	hashedPassword, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, resetToken.UserID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := s.revokeAllTokens(ctx, resetToken.UserID); err != nil {
+		return err
+	}
+
+	return s.resetTokens.DeleteAllForUser(ctx, resetToken.UserID)
+}
+
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func withToken(rawURL, token string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
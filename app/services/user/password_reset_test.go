@@ -0,0 +1,125 @@
+package user
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+func TestUserService_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockResetTokens := NewMockPasswordResetRepository(ctrl)
+	mockMailer := NewMockMailer(ctrl)
+	cfg := Config{
+		JWTSecret:        "test-secret",
+		PasswordResetURL: "https://meddoc.example/reset-password",
+		PasswordResetTTL: time.Hour,
+	}
+	service := NewUserService(Dependencies{
+		Users:       mockRepo,
+		ResetTokens: mockResetTokens,
+		Mailer:      mockMailer,
+	}, cfg)
+
+	t.Run("mails a reset link", func(t *testing.T) {
+		user := &models.User{ID: "user-123", Email: "test@example.com", Name: "Test User"}
+
+		var stored *models.PasswordResetToken
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+		mockResetTokens.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *models.PasswordResetToken) error {
+				stored = token
+				return nil
+			})
+		mockMailer.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+				assert.Equal(t, "test@example.com", msg.To)
+
+				link := regexp.MustCompile(`https://\S+`).FindString(msg.Body)
+				u, err := url.Parse(link)
+				require.NoError(t, err)
+				assert.Equal(t, "/reset-password", u.Path)
+
+				token := u.Query().Get("token")
+				require.NotEmpty(t, token)
+				assert.Equal(t, hashResetToken(token), stored.TokenHash)
+				assert.NotEqual(t, token, stored.TokenHash)
+				return nil
+			})
+
+		require.NoError(t, service.ForgotPassword(context.Background(), "test@example.com"))
+		assert.Equal(t, "user-123", stored.UserID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Second)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "nobody@example.com").Return(nil, errors.ErrUserNotFound)
+
+		assert.NoError(t, service.ForgotPassword(context.Background(), "nobody@example.com"))
+	})
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	mockResetTokens := NewMockPasswordResetRepository(ctrl)
+	cfg := Config{
+		JWTSecret:          "test-secret",
+		AccessTokenTTL:     time.Hour,
+		RevocationCacheTTL: time.Minute,
+	}
+	service := NewUserService(Dependencies{
+		Users:       mockRepo,
+		Sessions:    mockSessions,
+		ResetTokens: mockResetTokens,
+	}, cfg)
+
+	t.Run("successful reset", func(t *testing.T) {
+		mockResetTokens.EXPECT().
+			Consume(gomock.Any(), hashResetToken("reset-token")).
+			Return(&models.PasswordResetToken{UserID: "user-123"}, nil)
+		mockRepo.EXPECT().
+			UpdatePassword(gomock.Any(), "user-123", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, hash string) error {
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")))
+				return nil
+			})
+		mockRepo.EXPECT().SetTokensValidAfter(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+		mockSessions.EXPECT().RevokeAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockResetTokens.EXPECT().DeleteAllForUser(gomock.Any(), "user-123").Return(nil)
+
+		require.NoError(t, service.ResetPassword(context.Background(), "reset-token", "new-password"))
+
+		validAfter, err := service.tokensValidAfter(context.Background(), "user-123")
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), validAfter, time.Second)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		mockResetTokens.EXPECT().
+			Consume(gomock.Any(), hashResetToken("used-token")).
+			Return(nil, errors.ErrInvalidResetToken)
+
+		err := service.ResetPassword(context.Background(), "used-token", "new-password")
+		assert.ErrorIs(t, err, errors.ErrInvalidResetToken)
+	})
+}
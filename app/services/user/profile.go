@@ -0,0 +1,196 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+// VerifyPassword re-confirms the password of a signed-in user before a
+// sensitive change and returns the user.
+func (s *UserService) VerifyPassword(ctx context.Context, userID, password string) (*models.User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.passwords.Verify(user.Password, password); err != nil {
+		return nil, apperrors.ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func (s *UserService) GetProfile(ctx context.Context, userID string) (*models.User, error) {
+	return s.repo.GetByID(ctx, userID)
+}
+
+// UpdateProfile applies the fields set in update. The email address is
+// changed through RequestEmailChange instead, since it has to be confirmed.
+func (s *UserService) UpdateProfile(ctx context.Context, userID string, update models.UserUpdate) (*models.User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the password after checking the current one. Every
+// session and token of the user is revoked, including the caller's, and a
+// fresh token pair is returned so the caller stays signed in.
+func (s *UserService) ChangePassword(ctx context.Context, principal models.Principal, currentPassword, newPassword string, client models.ClientInfo) (_ *models.TokenPair, err error) {
+	defer func() { s.recordAccountEvent(ctx, models.AuditPasswordChange, principal, err) }()
+
+	user, err := s.VerifyPassword(ctx, principal.UserID, currentPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeAllTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// RequestEmailChange mails a confirmation link to newEmail. The address is
+// only changed once the link is opened, see VerifyEmail.
+func (s *UserService) RequestEmailChange(ctx context.Context, userID, newEmail, password string) error {
+	user, err := s.VerifyPassword(ctx, userID, password)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return apperrors.ErrUserExists
+	}
+	if err := s.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return err
+	}
+
+	claims := s.newClaims(tokenTypeEmailVerification, user.ID, "", tokenID, s.emailVerificationTTL)
+	claims.Email = newEmail
+	claims.PreviousEmail = user.Email
+	token, err := s.sign(claims)
+	if err != nil {
+		return err
+	}
+
+	link, err := withToken(s.emailVerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new MedDoc email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nOpen the link below to use this address for your MedDoc account. It expires in %s.\n\n%s\n\n"+
+				"If you did not ask for this change, you can ignore this email.\n",
+			user.Name, s.emailVerificationTTL, link,
+		),
+	})
+}
+
+// changeEmail applies a confirmed email change. The token is rejected when
+// the account no longer uses the address it was issued for, so an older
+// link cannot undo a later change.
+func (s *UserService) changeEmail(ctx context.Context, claims *tokenClaims) error {
+	user, err := s.repo.GetByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return apperrors.ErrInvalidToken
+		}
+		return err
+	}
+	if user.Email != claims.PreviousEmail {
+		return apperrors.ErrInvalidToken
+	}
+	if err := s.ensureEmailAvailable(ctx, claims.Email); err != nil {
+		return err
+	}
+
+	user.Email = claims.Email
+	user.EmailVerified = true
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      claims.PreviousEmail,
+		Subject: "Your MedDoc email address was changed",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nYour MedDoc account now uses %s. If you did not make this change, "+
+				"reset your password and contact support.\n",
+			user.Name, claims.Email,
+		),
+	}); err != nil {
+		logger.Error("failed to send email change notice", err, "user_id", user.ID)
+	}
+	return nil
+}
+
+func (s *UserService) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := s.repo.GetByEmail(ctx, email)
+	if err == nil {
+		return apperrors.ErrUserExists
+	}
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil
+	}
+	return err
+}
+
+// DeleteUser removes the account and everything the user service keeps about
+// it. Tokens issued so far stop working. Deleting a user that is already gone
+// succeeds, so an interrupted deletion can be repeated.
+func (s *UserService) DeleteUser(ctx context.Context, userID string) error {
+	if err := s.sessions.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.resetTokens.DeleteAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.accessTokens.DeleteAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	if s.devices != nil {
+		if err := s.devices.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Delete(ctx, userID); err != nil && !errors.Is(err, apperrors.ErrUserNotFound) {
+		return err
+	}
+
+	s.validAfterCache.Set(userID, time.Now())
+	return nil
+}
@@ -0,0 +1,214 @@
+package user
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
+)
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	service := NewUserService(Dependencies{Users: mockRepo}, Config{JWTSecret: "test-secret"})
+
+	t.Run("updates name", func(t *testing.T) {
+		name := "New Name"
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(&models.User{ID: "user-123", Name: "Old Name"}, nil)
+		mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, user *models.User) error {
+				assert.Equal(t, "New Name", user.Name)
+				return nil
+			})
+
+		user, err := service.UpdateProfile(context.Background(), "user-123", models.UserUpdate{Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, "New Name", user.Name)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(nil, errors.ErrUserNotFound)
+
+		_, err := service.UpdateProfile(context.Background(), "user-123", models.UserUpdate{})
+		assert.ErrorIs(t, err, errors.ErrUserNotFound)
+	})
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	cfg := Config{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour * 24,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, Sessions: mockSessions}, cfg)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: "user-123", Email: "test@example.com", Password: string(hashedPassword)}
+	principal := models.Principal{UserID: "user-123", SessionID: "session-123", TokenID: "token-1"}
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+
+		_, err := service.ChangePassword(context.Background(), principal, "wrong-password", "newpassword123", models.ClientInfo{})
+		assert.ErrorIs(t, err, errors.ErrInvalidCredentials)
+	})
+
+	t.Run("revokes sessions and starts a new one", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+		mockRepo.EXPECT().
+			UpdatePassword(gomock.Any(), "user-123", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, hash string) error {
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpassword123")))
+				return nil
+			})
+		mockRepo.EXPECT().SetTokensValidAfter(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+		mockSessions.EXPECT().RevokeAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockSessions.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, session *models.Session) error {
+				session.ID = "session-456"
+				return nil
+			})
+
+		tokens, err := service.ChangePassword(context.Background(), principal, "password123", "newpassword123", models.ClientInfo{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+	})
+}
+
+func TestUserService_ChangeEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockMailer := NewMockMailer(ctrl)
+	cfg := Config{
+		JWTSecret:            "test-secret",
+		Issuer:               "meddoc",
+		EmailVerificationURL: "https://meddoc.example/verify-email",
+		EmailVerificationTTL: time.Hour,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, Mailer: mockMailer}, cfg)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := func() *models.User {
+		return &models.User{ID: "user-123", Email: "old@example.com", Password: string(hashedPassword), EmailVerified: true}
+	}
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user(), nil)
+
+		err := service.RequestEmailChange(context.Background(), "user-123", "new@example.com", "wrong-password")
+		assert.ErrorIs(t, err, errors.ErrInvalidCredentials)
+	})
+
+	t.Run("address taken", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user(), nil)
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(&models.User{ID: "user-456"}, nil)
+
+		err := service.RequestEmailChange(context.Background(), "user-123", "new@example.com", "password123")
+		assert.ErrorIs(t, err, errors.ErrUserExists)
+	})
+
+	var token string
+	mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user(), nil)
+	mockRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, errors.ErrUserNotFound)
+	mockMailer.EXPECT().
+		Send(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+			assert.Equal(t, "new@example.com", msg.To)
+			u, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(msg.Body))
+			require.NoError(t, err)
+			token = u.Query().Get("token")
+			return nil
+		})
+
+	require.NoError(t, service.RequestEmailChange(context.Background(), "user-123", "new@example.com", "password123"))
+	require.NotEmpty(t, token)
+
+	t.Run("confirm change", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user(), nil)
+		mockRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, errors.ErrUserNotFound)
+		mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, u *models.User) error {
+				assert.Equal(t, "new@example.com", u.Email)
+				assert.True(t, u.EmailVerified)
+				return nil
+			})
+		mockMailer.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+				assert.Equal(t, "old@example.com", msg.To)
+				return nil
+			})
+
+		assert.NoError(t, service.VerifyEmail(context.Background(), token))
+	})
+
+	t.Run("address changed since", func(t *testing.T) {
+		changed := user()
+		changed.Email = "other@example.com"
+		mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(changed, nil)
+
+		assert.ErrorIs(t, service.VerifyEmail(context.Background(), token), errors.ErrInvalidToken)
+	})
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockSessions := NewMockSessionRepository(ctrl)
+	mockResetTokens := NewMockPasswordResetRepository(ctrl)
+	mockAccessTokens := NewMockAccessTokenRepository(ctrl)
+	mockDevices := NewMockDeviceRepository(ctrl)
+	cfg := Config{JWTSecret: "test-secret", RevocationCacheTTL: time.Minute}
+	service := NewUserService(Dependencies{Users: mockRepo, Sessions: mockSessions, ResetTokens: mockResetTokens, AccessTokens: mockAccessTokens, Devices: mockDevices}, cfg)
+
+	t.Run("deletes user", func(t *testing.T) {
+		mockSessions.EXPECT().RevokeAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockResetTokens.EXPECT().DeleteAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockAccessTokens.EXPECT().DeleteAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockDevices.EXPECT().DeleteByUserID(gomock.Any(), "user-123").Return(nil)
+		mockRepo.EXPECT().Delete(gomock.Any(), "user-123").Return(nil)
+
+		require.NoError(t, service.DeleteUser(context.Background(), "user-123"))
+
+		validAfter, err := service.tokensValidAfter(context.Background(), "user-123")
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), validAfter, time.Second)
+	})
+
+	t.Run("already deleted", func(t *testing.T) {
+		mockSessions.EXPECT().RevokeAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockResetTokens.EXPECT().DeleteAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockAccessTokens.EXPECT().DeleteAllForUser(gomock.Any(), "user-123").Return(nil)
+		mockDevices.EXPECT().DeleteByUserID(gomock.Any(), "user-123").Return(nil)
+		mockRepo.EXPECT().Delete(gomock.Any(), "user-123").Return(errors.ErrUserNotFound)
+
+		assert.NoError(t, service.DeleteUser(context.Background(), "user-123"))
+	})
+}
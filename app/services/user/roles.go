@@ -0,0 +1,34 @@
+package user
+
+import (
+	"context"
+	"slices"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// GetUser returns any user. It backs administrative endpoints, callers have
+// to check the role of the requester.
+func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	return s.repo.GetByID(ctx, userID)
+}
+
+// SetRoles replaces the roles of a user. They show up in the user's access
+// tokens from the next refresh on.
+func (s *UserService) SetRoles(ctx context.Context, userID string, roles []string) (*models.User, error) {
+	if len(roles) == 0 {
+		return nil, apperrors.ErrInvalidRole
+	}
+	for _, role := range roles {
+		if !models.IsValidRole(role) {
+			return nil, apperrors.ErrInvalidRole
+		}
+	}
+	roles = slices.Compact(slices.Sorted(slices.Values(roles)))
+
+	if err := s.repo.SetRoles(ctx, userID, roles); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, userID)
+}
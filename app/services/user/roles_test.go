@@ -0,0 +1,99 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestUserService_SetRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	service := NewUserService(Dependencies{Users: mockRepo}, Config{JWTSecret: "test-secret"})
+
+	tests := []struct {
+		name          string
+		roles         []string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:  "sorts and deduplicates",
+			roles: []string{models.RolePatient, models.RoleAdmin, models.RolePatient},
+			mockSetup: func() {
+				mockRepo.EXPECT().SetRoles(gomock.Any(), "user-123", []string{models.RoleAdmin, models.RolePatient}).Return(nil)
+				mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(&models.User{ID: "user-123"}, nil)
+			},
+		},
+		{
+			name:          "unknown role",
+			roles:         []string{"superuser"},
+			mockSetup:     func() {},
+			expectedError: errors.ErrInvalidRole,
+		},
+		{
+			name:          "no roles",
+			roles:         []string{},
+			mockSetup:     func() {},
+			expectedError: errors.ErrInvalidRole,
+		},
+		{
+			name:  "user not found",
+			roles: []string{models.RoleClinician},
+			mockSetup: func() {
+				mockRepo.EXPECT().SetRoles(gomock.Any(), "user-123", []string{models.RoleClinician}).Return(errors.ErrUserNotFound)
+			},
+			expectedError: errors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			_, err := service.SetRoles(context.Background(), "user-123", tt.roles)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUserService_RolesInAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockUserRepository(ctrl)
+	mockRevokedTokens := NewMockRevokedTokenRepository(ctrl)
+	cfg := Config{
+		JWTSecret:       "test-secret",
+		Issuer:          "meddoc",
+		Audience:        "meddoc-api",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour,
+	}
+	service := NewUserService(Dependencies{Users: mockRepo, RevokedTokens: mockRevokedTokens}, cfg)
+
+	user := &models.User{ID: "user-123", Roles: []string{models.RoleClinician, models.RolePatient}}
+	tokens, err := service.generateTokenPair(user, "session-123", "token-1", "access-1")
+	require.NoError(t, err)
+
+	mockRevokedTokens.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(false, nil)
+	mockRepo.EXPECT().GetByID(gomock.Any(), "user-123").Return(user, nil)
+
+	principal, err := service.Authenticate(context.Background(), tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.Roles, principal.Roles)
+	assert.True(t, principal.HasRole(models.RoleClinician))
+	assert.False(t, principal.HasRole(models.RoleAdmin))
+}
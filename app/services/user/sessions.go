@@ -0,0 +1,79 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// ListSessions returns the active sessions of the principal's user, marking
+// the one the request was made with.
+func (s *UserService) ListSessions(ctx context.Context, principal models.Principal) ([]*models.Session, error) {
+	sessions, err := s.sessions.GetActiveByUserID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == principal.SessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the user out on one device. Besides ending the
+// session, the latest access token issued for it is revoked, so the device
+// loses access right away rather than when that token expires.
+func (s *UserService) RevokeSession(ctx context.Context, userID, id string) error {
+	session, err := s.sessions.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || !session.IsActive(time.Now()) {
+		return apperrors.ErrSessionNotFound
+	}
+
+	if err := s.sessions.Revoke(ctx, session.ID); err != nil {
+		return err
+	}
+	return s.revokeAccessToken(ctx, models.Principal{
+		TokenID:   session.AccessTokenID,
+		ExpiresAt: time.Now().Add(s.accessTokenTTL),
+	})
+}
+
+// checkDevice remembers the device a session was started on and notifies
+// the user when it is new. The first device of an account is not reported,
+// and failing to notify does not fail the sign-in.
+func (s *UserService) checkDevice(ctx context.Context, user *models.User, session *models.Session) error {
+	if s.devices == nil {
+		return nil
+	}
+
+	known, err := s.devices.CountByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	isNew, err := s.devices.Remember(ctx, user.ID, deviceFingerprint(session.UserAgent), session.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if !isNew || known == 0 || s.loginNotifier == nil {
+		return nil
+	}
+
+	if err := s.loginNotifier.NotifyNewDevice(ctx, user, session); err != nil {
+		logger.Error("failed to notify about new device", err, "user_id", user.ID, "session_id", session.ID)
+	}
+	return nil
+}
+
+// deviceFingerprint identifies a device by its user agent. IP addresses are
+// left out since they change as devices move between networks.
+func deviceFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestUserService_ListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockSessions := NewMockSessionRepository(ctrl)
+	service := NewUserService(Dependencies{Sessions: mockSessions}, Config{JWTSecret: "test-secret"})
+
+	mockSessions.EXPECT().GetActiveByUserID(gomock.Any(), "user-123").Return([]*models.Session{
+		{ID: "session-1", UserID: "user-123"},
+		{ID: "session-2", UserID: "user-123"},
+	}, nil)
+
+	sessions, err := service.ListSessions(context.Background(), models.Principal{UserID: "user-123", SessionID: "session-2"})
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestUserService_RevokeSession(t *testing.T) {
+	active := func() *models.Session {
+		return &models.Session{
+			ID:            "session-1",
+			UserID:        "user-123",
+			AccessTokenID: "access-1",
+			ExpiresAt:     time.Now().Add(time.Hour),
+		}
+	}
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		setupMocks    func(sessions *MockSessionRepository, revoked *MockRevokedTokenRepository)
+		expectedError error
+	}{
+		{
+			name: "revokes the session and its access token",
+			setupMocks: func(sessions *MockSessionRepository, revoked *MockRevokedTokenRepository) {
+				sessions.EXPECT().GetByID(gomock.Any(), "session-1").Return(active(), nil)
+				sessions.EXPECT().Revoke(gomock.Any(), "session-1").Return(nil)
+				revoked.EXPECT().Add(gomock.Any(), "access-1", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "session of another user",
+			setupMocks: func(sessions *MockSessionRepository, revoked *MockRevokedTokenRepository) {
+				session := active()
+				session.UserID = "user-456"
+				sessions.EXPECT().GetByID(gomock.Any(), "session-1").Return(session, nil)
+			},
+			expectedError: errors.ErrSessionNotFound,
+		},
+		{
+			name: "already revoked",
+			setupMocks: func(sessions *MockSessionRepository, revoked *MockRevokedTokenRepository) {
+				session := active()
+				session.RevokedAt = &revokedAt
+				sessions.EXPECT().GetByID(gomock.Any(), "session-1").Return(session, nil)
+			},
+			expectedError: errors.ErrSessionNotFound,
+		},
+		{
+			name: "unknown session",
+			setupMocks: func(sessions *MockSessionRepository, revoked *MockRevokedTokenRepository) {
+				sessions.EXPECT().GetByID(gomock.Any(), "session-1").Return(nil, errors.ErrSessionNotFound)
+			},
+			expectedError: errors.ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockSessions := NewMockSessionRepository(ctrl)
+			mockRevoked := NewMockRevokedTokenRepository(ctrl)
+			service := NewUserService(Dependencies{Sessions: mockSessions, RevokedTokens: mockRevoked}, Config{
+				JWTSecret:      "test-secret",
+				AccessTokenTTL: 15 * time.Minute,
+			})
+			tt.setupMocks(mockSessions, mockRevoked)
+
+			err := service.RevokeSession(context.Background(), "user-123", "session-1")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUserService_NewDeviceNotification(t *testing.T) {
+	user := &models.User{ID: "user-123", Email: "test@example.com", Name: "Test User"}
+	client := models.ClientInfo{IP: "198.51.100.7", UserAgent: "Firefox"}
+
+	tests := []struct {
+		name       string
+		setupMocks func(devices *MockDeviceRepository, notifier *MockLoginNotifier)
+	}{
+		{
+			name: "new device",
+			setupMocks: func(devices *MockDeviceRepository, notifier *MockLoginNotifier) {
+				devices.EXPECT().CountByUserID(gomock.Any(), "user-123").Return(int64(1), nil)
+				devices.EXPECT().Remember(gomock.Any(), "user-123", deviceFingerprint("Firefox"), gomock.Any()).Return(true, nil)
+				notifier.EXPECT().
+					NotifyNewDevice(gomock.Any(), user, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ *models.User, session *models.Session) error {
+						assert.Equal(t, "Firefox", session.UserAgent)
+						assert.Equal(t, "198.51.100.7", session.IP)
+						return nil
+					})
+			},
+		},
+		{
+			name: "known device",
+			setupMocks: func(devices *MockDeviceRepository, notifier *MockLoginNotifier) {
+				devices.EXPECT().CountByUserID(gomock.Any(), "user-123").Return(int64(2), nil)
+				devices.EXPECT().Remember(gomock.Any(), "user-123", deviceFingerprint("Firefox"), gomock.Any()).Return(false, nil)
+			},
+		},
+		{
+			name: "first device of the account",
+			setupMocks: func(devices *MockDeviceRepository, notifier *MockLoginNotifier) {
+				devices.EXPECT().CountByUserID(gomock.Any(), "user-123").Return(int64(0), nil)
+				devices.EXPECT().Remember(gomock.Any(), "user-123", deviceFingerprint("Firefox"), gomock.Any()).Return(true, nil)
+			},
+		},
+		{
+			name: "notification failure does not fail the sign-in",
+			setupMocks: func(devices *MockDeviceRepository, notifier *MockLoginNotifier) {
+				devices.EXPECT().CountByUserID(gomock.Any(), "user-123").Return(int64(1), nil)
+				devices.EXPECT().Remember(gomock.Any(), "user-123", deviceFingerprint("Firefox"), gomock.Any()).Return(true, nil)
+				notifier.EXPECT().NotifyNewDevice(gomock.Any(), user, gomock.Any()).Return(errors.ErrInternal)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockSessions := NewMockSessionRepository(ctrl)
+			mockDevices := NewMockDeviceRepository(ctrl)
+			mockNotifier := NewMockLoginNotifier(ctrl)
+			service := NewUserService(Dependencies{
+				Sessions:      mockSessions,
+				Devices:       mockDevices,
+				LoginNotifier: mockNotifier,
+			}, Config{JWTSecret: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour})
+
+			mockSessions.EXPECT().
+				Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, session *models.Session) error {
+					assert.Equal(t, "Firefox", session.UserAgent)
+					assert.NotEmpty(t, session.AccessTokenID)
+					session.ID = "session-1"
+					return nil
+				})
+			tt.setupMocks(mockDevices, mockNotifier)
+
+			tokens, err := service.startSession(context.Background(), user, client)
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokens.AccessToken)
+		})
+	}
+}
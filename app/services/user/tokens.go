@@ -0,0 +1,227 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+	tokenTypeMFA     = "mfa"
+	// tokenTypeEmailVerification tokens are sent in verification links and
+	// carry the address they verify.
+	tokenTypeEmailVerification = "email_verification"
+)
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Type      string `json:"typ"`
+	SessionID string `json:"sid,omitempty"`
+	Email     string `json:"email,omitempty"`
+	// PreviousEmail is set on email verification tokens that confirm a
+	// change of address, and must still be the user's address when used.
+	PreviousEmail string `json:"prev_email,omitempty"`
+	// Roles is set on access tokens.
+	Roles []string `json:"roles,omitempty"`
+}
+
+func (s *UserService) generateTokenPair(user *models.User, sessionID, refreshTokenID, accessTokenID string) (*models.TokenPair, error) {
+	accessClaims := s.newClaims(tokenTypeAccess, user.ID, sessionID, accessTokenID, s.accessTokenTTL)
+	accessClaims.Roles = user.Roles
+	accessTokenString, err := s.sign(accessClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenString, err := s.signToken(tokenTypeRefresh, user.ID, sessionID, refreshTokenID, s.refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessTokenString,
+		RefreshToken: refreshTokenString,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// audienceFor returns the audience a token type is issued to. Access tokens
+// are meant for the API, every other type only ever comes back to the issuer.
+func (s *UserService) audienceFor(tokenType string) string {
+	if tokenType == tokenTypeAccess {
+		return s.audience
+	}
+	return s.issuer
+}
+
+func (s *UserService) signToken(tokenType, subject, sessionID, tokenID string, ttl time.Duration) (string, error) {
+	return s.sign(s.newClaims(tokenType, subject, sessionID, tokenID, ttl))
+}
+
+func (s *UserService) newClaims(tokenType, subject, sessionID, tokenID string, ttl time.Duration) *tokenClaims {
+	now := time.Now()
+	claims := &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        tokenID,
+		},
+		Type:      tokenType,
+		SessionID: sessionID,
+	}
+	if aud := s.audienceFor(tokenType); aud != "" {
+		claims.Audience = jwt.ClaimStrings{aud}
+	}
+	return claims
+}
+
+func (s *UserService) sign(claims *tokenClaims) (string, error) {
+	key := s.keys.SigningKey()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Private)
+}
+
+func (s *UserService) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := s.keys.Key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("token algorithm does not match key")
+	}
+	return key.Public, nil
+}
+
+// JWKS returns the public keys other services need to verify our tokens.
+func (s *UserService) JWKS() keyring.JWKS {
+	return s.keys.JWKS()
+}
+
+func (s *UserService) parseToken(tokenString, tokenType string) (*tokenClaims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(s.keys.Algorithms()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if s.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.issuer))
+	}
+	if aud := s.audienceFor(tokenType); aud != "" {
+		opts = append(opts, jwt.WithAudience(aud))
+	}
+
+	claims := &tokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey, opts...)
+	if err != nil || !token.Valid {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	if claims.Type != tokenType || claims.Subject == "" {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (s *UserService) ValidateToken(tokenString string) (string, error) {
+	claims, err := s.parseToken(tokenString, tokenTypeAccess)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Subject, nil
+}
+
+// Authenticate validates an access token and checks that it has not been
+// revoked, either individually or by a logout from all devices. Revocation
+// state is cached in-process so most requests don't reach the database.
+// Personal access tokens are accepted as well.
+func (s *UserService) Authenticate(ctx context.Context, tokenString string) (*models.Principal, error) {
+	if strings.HasPrefix(tokenString, accessTokenPrefix) {
+		return s.authenticateAccessToken(ctx, tokenString)
+	}
+
+	claims, err := s.parseToken(tokenString, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.isTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	validAfter, err := s.tokensValidAfter(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	// iat only has second precision, so compare against the start of the
+	// second the revocation happened in.
+	if claims.IssuedAt.Time.Before(validAfter.Truncate(time.Second)) {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	return &models.Principal{
+		UserID:    claims.Subject,
+		SessionID: claims.SessionID,
+		TokenID:   claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+		Roles:     claims.Roles,
+	}, nil
+}
+
+func (s *UserService) isTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	if tokenID == "" {
+		return false, nil
+	}
+	if revoked, ok := s.revokedCache.Get(tokenID); ok {
+		return revoked, nil
+	}
+
+	revoked, err := s.revokedTokens.Exists(ctx, tokenID)
+	if err != nil {
+		return false, err
+	}
+	s.revokedCache.Set(tokenID, revoked)
+	return revoked, nil
+}
+
+func (s *UserService) tokensValidAfter(ctx context.Context, userID string) (time.Time, error) {
+	if validAfter, ok := s.validAfterCache.Get(userID); ok {
+		return validAfter, nil
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, apperrors.ErrInvalidToken
+	}
+	s.validAfterCache.Set(userID, user.TokensValidAfter)
+	return user.TokensValidAfter, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -2,49 +2,204 @@ package user
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
 	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
 	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/pkg/cache"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+	"github.com/gruzdev-dev/meddoc/pkg/password"
+	"github.com/gruzdev-dev/meddoc/pkg/secretbox"
 )
 
 type Config struct {
+	// Keys signs and verifies tokens. When nil, tokens are signed with
+	// JWTSecret using HS256.
+	Keys            *keyring.KeyRing
 	JWTSecret       string
+	Issuer          string
+	Audience        string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	// RevocationCacheTTL bounds how long a revocation made on another
+	// instance may go unnoticed by this one.
+	RevocationCacheTTL time.Duration
+	// Passwords hashes and verifies passwords. When nil, passwords are
+	// hashed with bcrypt at its default cost.
+	Passwords *password.Hasher
+	// MFAIssuer is the account issuer shown in authenticator apps.
+	MFAIssuer string
+	// MFACipher encrypts TOTP secrets at rest. Two-factor enrollment is
+	// unavailable when it is nil.
+	MFACipher *secretbox.Box
+	// PasswordResetURL is the page reset links point to; the token is added
+	// as the "token" query parameter.
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+	// EmailVerificationURL is the page verification links point to.
+	EmailVerificationURL string
+	EmailVerificationTTL time.Duration
+	// RequireEmailVerification makes Login refuse accounts whose email has
+	// not been verified.
+	RequireEmailVerification bool
+	LoginThrottle            LoginThrottleConfig
 }
 
 type UserService struct {
-	repo            UserRepository
-	jwtSecret       []byte
-	accessTokenTTL  time.Duration
-	refreshTokenTTL time.Duration
+	repo                     UserRepository
+	sessions                 SessionRepository
+	revokedTokens            RevokedTokenRepository
+	resetTokens              PasswordResetRepository
+	loginAttempts            LoginAttemptRepository
+	accessTokens             AccessTokenRepository
+	devices                  DeviceRepository
+	loginNotifier            LoginNotifier
+	audit                    Auditor
+	mailer                   Mailer
+	keys                     *keyring.KeyRing
+	passwords                *password.Hasher
+	issuer                   string
+	audience                 string
+	accessTokenTTL           time.Duration
+	refreshTokenTTL          time.Duration
+	revokedCache             *cache.TTL[string, bool]
+	validAfterCache          *cache.TTL[string, time.Time]
+	mfaIssuer                string
+	mfaCipher                *secretbox.Box
+	passwordResetURL         string
+	passwordResetTTL         time.Duration
+	emailVerificationURL     string
+	emailVerificationTTL     time.Duration
+	requireEmailVerification bool
+	throttle                 LoginThrottleConfig
+	// dummyHash is what logins are checked against when there is no hash to
+	// check, made on first use.
+	dummyHash func() (string, error)
 }
 
-func NewUserService(repo UserRepository, cfg Config) *UserService {
+// Dependencies are the stores and external services UserService works with.
+type Dependencies struct {
+	Users         UserRepository
+	Sessions      SessionRepository
+	RevokedTokens RevokedTokenRepository
+	ResetTokens   PasswordResetRepository
+	LoginAttempts LoginAttemptRepository
+	AccessTokens  AccessTokenRepository
+	// Devices, LoginNotifier and Audit are optional. Without Devices
+	// sign-ins are not matched against known devices; without
+	// LoginNotifier nobody is told about new ones; without Audit sign-ins
+	// are not recorded.
+	Devices       DeviceRepository
+	LoginNotifier LoginNotifier
+	Audit         Auditor
+	Mailer        Mailer
+}
+
+func NewUserService(deps Dependencies, cfg Config) *UserService {
+	keys := cfg.Keys
+	if keys == nil {
+		keys = keyring.NewHMAC([]byte(cfg.JWTSecret))
+	}
+	passwords := cfg.Passwords
+	if passwords == nil {
+		passwords = password.NewBcrypt(bcrypt.DefaultCost)
+	}
+
 	return &UserService{
-		repo:            repo,
-		jwtSecret:       []byte(cfg.JWTSecret),
-		accessTokenTTL:  cfg.AccessTokenTTL,
-		refreshTokenTTL: cfg.RefreshTokenTTL,
+		repo:                     deps.Users,
+		sessions:                 deps.Sessions,
+		revokedTokens:            deps.RevokedTokens,
+		resetTokens:              deps.ResetTokens,
+		loginAttempts:            deps.LoginAttempts,
+		accessTokens:             deps.AccessTokens,
+		devices:                  deps.Devices,
+		loginNotifier:            deps.LoginNotifier,
+		audit:                    deps.Audit,
+		mailer:                   deps.Mailer,
+		keys:                     keys,
+		passwords:                passwords,
+		issuer:                   cfg.Issuer,
+		audience:                 cfg.Audience,
+		accessTokenTTL:           cfg.AccessTokenTTL,
+		refreshTokenTTL:          cfg.RefreshTokenTTL,
+		revokedCache:             cache.NewTTL[string, bool](cfg.RevocationCacheTTL),
+		validAfterCache:          cache.NewTTL[string, time.Time](cfg.RevocationCacheTTL),
+		mfaIssuer:                cfg.MFAIssuer,
+		mfaCipher:                cfg.MFACipher,
+		passwordResetURL:         cfg.PasswordResetURL,
+		passwordResetTTL:         cfg.PasswordResetTTL,
+		emailVerificationURL:     cfg.EmailVerificationURL,
+		emailVerificationTTL:     cfg.EmailVerificationTTL,
+		requireEmailVerification: cfg.RequireEmailVerification,
+		throttle:                 cfg.LoginThrottle,
+		dummyHash: sync.OnceValues(func() (string, error) {
+			return passwords.Hash("meddoc-dummy-password")
+		}),
 	}
 }
 
-func NewUserServiceFromConfig(repo UserRepository, cfg *config.Config) *UserService {
-	return NewUserService(repo, Config{
-		JWTSecret:       cfg.Auth.Secret,
-		AccessTokenTTL:  cfg.Auth.AccessTokenTTL,
-		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+func NewUserServiceFromConfig(deps Dependencies, keys *keyring.KeyRing, cfg *config.Config) (*UserService, error) {
+	var mfaCipher *secretbox.Box
+	if cfg.Auth.MFA.EncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.Auth.MFA.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mfa encryption key: %w", err)
+		}
+		mfaCipher, err = secretbox.New(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mfa encryption key: %w", err)
+		}
+	}
+
+	passwords := password.NewArgon2id(password.Argon2Params{
+		Time:    cfg.Auth.Passwords.Argon2.Time,
+		Memory:  cfg.Auth.Passwords.Argon2.Memory,
+		Threads: cfg.Auth.Passwords.Argon2.Threads,
 	})
+	if cfg.Auth.Passwords.Algorithm == password.Bcrypt {
+		passwords = password.NewBcrypt(cfg.Auth.Passwords.BcryptCost)
+	}
+
+	return NewUserService(deps, Config{
+		Keys:                     keys,
+		JWTSecret:                cfg.Auth.Secret,
+		Issuer:                   cfg.Auth.Issuer,
+		Audience:                 cfg.Auth.Audience,
+		AccessTokenTTL:           cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL:          cfg.Auth.RefreshTokenTTL,
+		RevocationCacheTTL:       cfg.Auth.RevocationCacheTTL,
+		Passwords:                passwords,
+		MFAIssuer:                cfg.Auth.MFA.Issuer,
+		MFACipher:                mfaCipher,
+		PasswordResetURL:         cfg.Auth.PasswordReset.URL,
+		PasswordResetTTL:         cfg.Auth.PasswordReset.TTL,
+		EmailVerificationURL:     cfg.Auth.EmailVerification.URL,
+		EmailVerificationTTL:     cfg.Auth.EmailVerification.TTL,
+		RequireEmailVerification: cfg.Auth.EmailVerification.Required,
+		LoginThrottle: LoginThrottleConfig{
+			MaxAccountFailures: cfg.Auth.LoginThrottle.MaxAccountFailures,
+			MaxIPFailures:      cfg.Auth.LoginThrottle.MaxIPFailures,
+			MaxMFAFailures:     cfg.Auth.LoginThrottle.MaxMFAFailures,
+			LockoutDuration:    cfg.Auth.LoginThrottle.LockoutDuration,
+			FailureWindow:      cfg.Auth.LoginThrottle.FailureWindow,
+			DelayAfter:         cfg.Auth.LoginThrottle.DelayAfter,
+			BaseDelay:          cfg.Auth.LoginThrottle.BaseDelay,
+			MaxDelay:           cfg.Auth.LoginThrottle.MaxDelay,
+		},
+	}), nil
 }
 
 func (s *UserService) Register(ctx context.Context, reg models.UserRegistration) (*models.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reg.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(reg.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +207,8 @@ func (s *UserService) Register(ctx context.Context, reg models.UserRegistration)
 	user := &models.User{
 		Email:    reg.Email,
 		Name:     reg.Name,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
+		Roles:    slices.Clone(models.DefaultRoles),
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -62,77 +218,245 @@ func (s *UserService) Register(ctx context.Context, reg models.UserRegistration)
 		return nil, err
 	}
 
+	// The account exists at this point; a lost verification mail can be
+	// sent again with ResendVerificationEmail.
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		logger.Error("failed to send verification email", err, "user_id", user.ID)
+	}
+
 	return user, nil
 }
 
-func (s *UserService) Login(ctx context.Context, email, password string) (*models.TokenPair, error) {
+// Login checks the user's credentials. Accounts with two-factor
+// authentication get an MFA challenge instead of tokens, to be completed with
+// VerifyMFA. Repeated failures for an account or client IP are throttled.
+func (s *UserService) Login(ctx context.Context, email, password string, client models.ClientInfo) (result *models.LoginResult, err error) {
+	var userID string
+	defer func() { s.recordLogin(ctx, userID, result, err) }()
+
+	keys := newAttemptKeys(email, client.IP)
+	if err := s.checkLoginAllowed(ctx, keys); err != nil {
+		return nil, err
+	}
+
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			_ = s.verifyPassword("", password)
+			if err := s.recordLoginFailure(ctx, keys); err != nil {
+				return nil, err
+			}
+		}
 		return nil, err
 	}
+	userID = user.ID
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	if err := s.verifyPassword(user.Password, password); err != nil {
+		if err := s.recordLoginFailure(ctx, keys); err != nil {
+			return nil, err
+		}
 		return nil, apperrors.ErrInvalidCredentials
 	}
 
-	return s.generateTokenPair(user)
+	if err := s.resetLoginFailures(ctx, keys); err != nil {
+		return nil, err
+	}
+	s.rehashPassword(ctx, user, password)
+
+	if s.requireEmailVerification && !user.EmailVerified {
+		return nil, apperrors.ErrEmailNotVerified
+	}
+
+	return s.finishLogin(ctx, user, client)
 }
 
-func (s *UserService) RefreshToken(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
-	claims := &jwt.RegisteredClaims{}
-	token, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (any, error) {
-		return s.jwtSecret, nil
-	})
+// verifyPassword checks password against hash. Unknown accounts and accounts
+// without a password are checked against a dummy hash instead, so a failed
+// login takes as long either way and does not tell which emails have accounts.
+func (s *UserService) verifyPassword(hash, password string) error {
+	if hash == "" {
+		if dummy, err := s.dummyHash(); err == nil {
+			_ = s.passwords.Verify(dummy, password)
+		}
+		return apperrors.ErrInvalidCredentials
+	}
+	return s.passwords.Verify(hash, password)
+}
 
-	if err != nil || !token.Valid {
-		return nil, apperrors.ErrInvalidRefreshToken
+// rehashPassword replaces a stored hash made with an outdated algorithm or
+// cost, now that the plaintext is at hand. Failing to do so only means the
+// old hash is kept until the next login.
+func (s *UserService) rehashPassword(ctx context.Context, user *models.User, plaintext string) {
+	if !s.passwords.NeedsRehash(user.Password) {
+		return
 	}
 
-	user, err := s.repo.GetByID(ctx, claims.Subject)
+	hash, err := s.passwords.Hash(plaintext)
+	if err == nil {
+		err = s.repo.UpdatePassword(ctx, user.ID, hash)
+	}
 	if err != nil {
-		return nil, err
+		logger.Error("failed to rehash password", err, "user_id", user.ID)
+		return
+	}
+	user.Password = hash
+}
+
+// finishLogin starts a session for an authenticated user, or returns the MFA
+// challenge when the account has two-factor authentication.
+func (s *UserService) finishLogin(ctx context.Context, user *models.User, client models.ClientInfo) (*models.LoginResult, error) {
+	if user.MFA.Enabled {
+		challenge, err := s.mfaChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResult{Challenge: challenge}, nil
 	}
 
-	return s.generateTokenPair(user)
+	tokens, err := s.startSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResult{Tokens: tokens}, nil
 }
 
-func (s *UserService) generateTokenPair(user *models.User) (*models.TokenPair, error) {
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(s.accessTokenTTL).Unix(),
-	})
+// RefreshToken exchanges a refresh token for a new token pair and records the
+// client as the session's latest. Every refresh token is single-use:
+// presenting one that has already been rotated means it leaked, so the whole
+// session is revoked.
+func (s *UserService) RefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.TokenPair, error) {
+	claims, err := s.parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil || claims.SessionID == "" || claims.ID == "" {
+		return nil, apperrors.ErrInvalidRefreshToken
+	}
 
-	accessTokenString, err := accessToken.SignedString(s.jwtSecret)
+	session, err := s.sessions.GetByID(ctx, claims.SessionID)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrSessionNotFound) {
+			return nil, apperrors.ErrInvalidRefreshToken
+		}
 		return nil, err
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(s.refreshTokenTTL).Unix(),
-	})
+	if !session.IsActive(time.Now()) || session.UserID != claims.Subject {
+		return nil, apperrors.ErrInvalidRefreshToken
+	}
 
-	refreshTokenString, err := refreshToken.SignedString(s.jwtSecret)
+	if session.TokenID != claims.ID {
+		return nil, s.revokeReusedSession(ctx, session.ID)
+	}
+
+	user, err := s.repo.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	accessTokenID, err := newTokenID()
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
-		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
-	}, nil
+	rotation := models.SessionRotation{
+		TokenID:       tokenID,
+		AccessTokenID: accessTokenID,
+		ExpiresAt:     time.Now().Add(s.refreshTokenTTL),
+		Client:        client,
+	}
+	if err := s.sessions.Rotate(ctx, session.ID, claims.ID, rotation); err != nil {
+		if errors.Is(err, apperrors.ErrSessionNotFound) {
+			return nil, s.revokeReusedSession(ctx, session.ID)
+		}
+		return nil, err
+	}
+
+	return s.generateTokenPair(user, session.ID, tokenID, accessTokenID)
 }
 
-func (s *UserService) ValidateToken(tokenString string) (string, error) {
-	claims := &jwt.RegisteredClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-		return s.jwtSecret, nil
-	})
+// Logout ends the session the principal authenticated with and revokes the
+// access token used for the request.
+func (s *UserService) Logout(ctx context.Context, principal models.Principal) (err error) {
+	defer func() { s.recordAccountEvent(ctx, models.AuditLogout, principal, err) }()
+
+	if principal.SessionID != "" {
+		if err := s.sessions.Revoke(ctx, principal.SessionID); err != nil && !errors.Is(err, apperrors.ErrSessionNotFound) {
+			return err
+		}
+	}
+	return s.revokeAccessToken(ctx, principal)
+}
+
+// LogoutAll ends every session of the user and invalidates all tokens issued
+// so far.
+func (s *UserService) LogoutAll(ctx context.Context, principal models.Principal) (err error) {
+	defer func() { s.recordAccountEvent(ctx, models.AuditLogoutAll, principal, err) }()
+
+	if err := s.revokeAllTokens(ctx, principal.UserID); err != nil {
+		return err
+	}
+	return s.revokeAccessToken(ctx, principal)
+}
 
-	if err != nil || !token.Valid {
-		return "", apperrors.ErrInvalidToken
+func (s *UserService) revokeAllTokens(ctx context.Context, userID string) error {
+	validAfter := time.Now()
+	if err := s.repo.SetTokensValidAfter(ctx, userID, validAfter); err != nil {
+		return err
 	}
+	s.validAfterCache.Set(userID, validAfter)
 
-	return claims.Subject, nil
+	return s.sessions.RevokeAllForUser(ctx, userID)
+}
+
+func (s *UserService) revokeAccessToken(ctx context.Context, principal models.Principal) error {
+	if principal.TokenID == "" {
+		return nil
+	}
+	if err := s.revokedTokens.Add(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+		return err
+	}
+	s.revokedCache.Set(principal.TokenID, true)
+	return nil
+}
+
+func (s *UserService) startSession(ctx context.Context, user *models.User, client models.ClientInfo) (*models.TokenPair, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	accessTokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:        user.ID,
+		TokenID:       tokenID,
+		AccessTokenID: accessTokenID,
+		UserAgent:     client.UserAgent,
+		IP:            client.IP,
+		ExpiresAt:     now.Add(s.refreshTokenTTL),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		LastUsedAt:    now,
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDevice(ctx, user, session); err != nil {
+		return nil, err
+	}
+
+	return s.generateTokenPair(user, session.ID, tokenID, accessTokenID)
+}
+
+func (s *UserService) revokeReusedSession(ctx context.Context, sessionID string) error {
+	if err := s.sessions.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+	return apperrors.ErrRefreshTokenReused
 }
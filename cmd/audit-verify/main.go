@@ -0,0 +1,90 @@
+// Command audit-verify checks that the audit log in MongoDB has not been
+// changed: it walks the hash chain from the first event, recomputing every
+// hash, and checks the chain against the signed checkpoints. It exits with
+// status 1 and names the first broken link when the chain is not intact.
+//
+//	go run ./cmd/audit-verify -config config.yaml
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/database"
+	"github.com/gruzdev-dev/meddoc/database/repositories"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "path to the configuration file")
+	flag.Parse()
+
+	if err := run(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.Setup(logger.Config{
+		Level:  "error",
+		Format: cfg.Log.Format,
+	})
+
+	keys := keyring.NewHMAC([]byte(cfg.Auth.Secret))
+	if cfg.Auth.KeysDir != "" {
+		keys, err = keyring.Load(cfg.Auth.KeysDir, cfg.Auth.SigningKeyID)
+		if err != nil {
+			return fmt.Errorf("failed to load signing keys: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mongoDB, err := database.NewMongoDB(ctx, database.MongoDBConfig{
+		URI:      cfg.MongoDB.URI,
+		Database: cfg.MongoDB.Database,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = mongoDB.Close(ctx)
+	}()
+
+	auditService := audit.NewService(audit.Dependencies{
+		Events:      repositories.NewAuditRepository(mongoDB.Database().Collection("audit_events")),
+		Checkpoints: repositories.NewAuditCheckpointRepository(mongoDB.Database().Collection("audit_checkpoints")),
+		Keys:        keys,
+	}, audit.Config{})
+
+	report, err := auditService.Verify(context.Background())
+	var chainErr *audit.ChainError
+	if errors.As(err, &chainErr) {
+		return chainErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+
+	if report.Head == nil {
+		fmt.Println("audit log is empty")
+		return nil
+	}
+	fmt.Printf("audit chain intact: %d events, %d signed checkpoints, head %d %s\n",
+		report.Events, report.Checkpoints, report.Head.Seq, report.Head.Hash)
+	return nil
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net/netip"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,6 +15,13 @@ type Config struct {
 	Server struct {
 		Host string `yaml:"host"`
 		Port int    `yaml:"port"`
+		// TrustProxyHeaders takes the client IP from X-Forwarded-For or
+		// X-Real-IP. Only enable it behind a proxy that sets them.
+		TrustProxyHeaders bool `yaml:"trust_proxy_headers"`
+		// TrustedProxies are the addresses or CIDR ranges of the proxies in
+		// front of the server. The client IP is the rightmost X-Forwarded-For
+		// entry not among them; without any it is the rightmost entry.
+		TrustedProxies []string `yaml:"trusted_proxies"`
 	} `yaml:"server"`
 	MongoDB struct {
 		URI      string `yaml:"uri"`
@@ -23,9 +33,143 @@ type Config struct {
 	} `yaml:"log"`
 	Auth struct {
 		Secret          string        `yaml:"secret"`
+		KeysDir         string        `yaml:"keys_dir"`
+		SigningKeyID    string        `yaml:"signing_key_id"`
+		Issuer          string        `yaml:"issuer"`
+		Audience        string        `yaml:"audience"`
 		AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
 		RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+		// RevocationCacheTTL is how long revocation checks are cached in-process.
+		RevocationCacheTTL time.Duration `yaml:"revocation_cache_ttl"`
+		Passwords          struct {
+			// Algorithm new password hashes are made with, "argon2id" or
+			// "bcrypt". Stored hashes made otherwise are replaced at the
+			// next login.
+			Algorithm string `yaml:"algorithm"`
+			Argon2    struct {
+				Time uint32 `yaml:"time"`
+				// Memory is in KiB.
+				Memory  uint32 `yaml:"memory"`
+				Threads uint8  `yaml:"threads"`
+			} `yaml:"argon2"`
+			BcryptCost int `yaml:"bcrypt_cost"`
+		} `yaml:"passwords"`
+		MFA struct {
+			Issuer string `yaml:"issuer"`
+			// EncryptionKey is a base64-encoded 32-byte key used to encrypt
+			// TOTP secrets. Two-factor enrollment is disabled without it.
+			EncryptionKey string `yaml:"encryption_key"`
+		} `yaml:"mfa"`
+		PasswordReset struct {
+			// URL is the page password reset links point to.
+			URL string        `yaml:"url"`
+			TTL time.Duration `yaml:"ttl"`
+		} `yaml:"password_reset"`
+		EmailVerification struct {
+			// URL is the page email verification links point to.
+			URL string        `yaml:"url"`
+			TTL time.Duration `yaml:"ttl"`
+			// Required makes login refuse accounts with an unverified email.
+			Required bool `yaml:"required"`
+		} `yaml:"email_verification"`
+		LoginThrottle struct {
+			// MaxAccountFailures failed logins for one email lock the account
+			// for LockoutDuration; MaxIPFailures do the same for a client IP.
+			// Zero picks the default and a negative value turns the limit
+			// off; without the account limit logins are not throttled at all.
+			MaxAccountFailures int           `yaml:"max_account_failures"`
+			MaxIPFailures      int           `yaml:"max_ip_failures"`
+			LockoutDuration    time.Duration `yaml:"lockout_duration"`
+			// MaxMFAFailures wrong two-factor codes lock a user's second
+			// factor for LockoutDuration; a negative value turns it off.
+			MaxMFAFailures int `yaml:"max_mfa_failures"`
+			// FailureWindow is how long failures are remembered after the
+			// last one.
+			FailureWindow time.Duration `yaml:"failure_window"`
+			// After DelayAfter failures every further attempt has to wait
+			// BaseDelay, doubling per failure up to MaxDelay. A negative
+			// DelayAfter turns the delay off.
+			DelayAfter int           `yaml:"delay_after"`
+			BaseDelay  time.Duration `yaml:"base_delay"`
+			MaxDelay   time.Duration `yaml:"max_delay"`
+		} `yaml:"login_throttle"`
+		OIDC struct {
+			// LoginTTL is how long a user has to finish signing in at a
+			// provider.
+			LoginTTL  time.Duration  `yaml:"login_ttl"`
+			Providers []OIDCProvider `yaml:"providers"`
+		} `yaml:"oidc"`
 	} `yaml:"auth"`
+	Account struct {
+		Deletion struct {
+			// GracePeriod is how long a requested account deletion can be
+			// cancelled before it is carried out.
+			GracePeriod  time.Duration `yaml:"grace_period"`
+			PollInterval time.Duration `yaml:"poll_interval"`
+			// Lease is how long a started deletion may run before another
+			// instance resumes it.
+			Lease time.Duration `yaml:"lease"`
+			// URL is the page deletion confirmation links for accounts
+			// without a password point to.
+			URL string `yaml:"url"`
+			// ConfirmationTTL is how long such a link can be used.
+			ConfirmationTTL time.Duration `yaml:"confirmation_ttl"`
+		} `yaml:"deletion"`
+	} `yaml:"account"`
+	Audit struct {
+		// CheckpointInterval is how often the head of the audit chain is
+		// signed.
+		CheckpointInterval time.Duration `yaml:"checkpoint_interval"`
+	} `yaml:"audit"`
+	Sharing struct {
+		Links struct {
+			// Key is a base64-encoded 32-byte key share link tokens are
+			// signed with. Share links are disabled without it.
+			Key string `yaml:"key"`
+			// URL is the page share links point to.
+			URL string `yaml:"url"`
+			// MaxTTL is the longest a share link may stay valid.
+			MaxTTL time.Duration `yaml:"max_ttl"`
+			// MaxPINAttempts wrong PINs revoke a PIN-protected link.
+			MaxPINAttempts int `yaml:"max_pin_attempts"`
+		} `yaml:"links"`
+		Delegations struct {
+			// URL is the page delegation invitations point to.
+			URL string `yaml:"url"`
+			// InviteTTL is how long an invitation can be accepted.
+			InviteTTL time.Duration `yaml:"invite_ttl"`
+		} `yaml:"delegations"`
+		BreakGlass struct {
+			// TTL is how long a clinician's emergency access to a
+			// patient's documents lasts.
+			TTL time.Duration `yaml:"ttl"`
+		} `yaml:"break_glass"`
+	} `yaml:"sharing"`
+	Mail struct {
+		From string `yaml:"from"`
+		// Dir is where messages are written when no SMTP host is configured.
+		Dir  string `yaml:"dir"`
+		SMTP struct {
+			Host     string `yaml:"host"`
+			Port     int    `yaml:"port"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"smtp"`
+	} `yaml:"mail"`
+}
+
+// OIDCProvider is an OpenID Connect provider users can sign in with.
+type OIDCProvider struct {
+	// Name identifies the provider in the sign-in URLs.
+	Name string `yaml:"name"`
+	// Issuer is the provider's issuer URL; its configuration is discovered
+	// from /.well-known/openid-configuration below it.
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// RedirectURL is the callback registered with the provider.
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
 }
 
 func (c *Config) validate() error {
@@ -35,6 +179,11 @@ func (c *Config) validate() error {
 	if c.Server.Port <= 0 {
 		return fmt.Errorf("server port must be positive")
 	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := ParsePrefix(proxy); err != nil {
+			return fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+	}
 	if c.MongoDB.URI == "" {
 		return fmt.Errorf("mongodb uri is required")
 	}
@@ -47,8 +196,14 @@ func (c *Config) validate() error {
 	if c.Log.Format == "" {
 		c.Log.Format = "json"
 	}
-	if c.Auth.Secret == "" {
-		return fmt.Errorf("auth secret is required")
+	if c.Auth.Secret == "" && c.Auth.KeysDir == "" {
+		return fmt.Errorf("auth secret or keys dir is required")
+	}
+	if c.Auth.Issuer == "" {
+		c.Auth.Issuer = "meddoc"
+	}
+	if c.Auth.Audience == "" {
+		c.Auth.Audience = "meddoc-api"
 	}
 	if c.Auth.AccessTokenTTL == 0 {
 		return fmt.Errorf("access token TTL is required")
@@ -56,9 +211,161 @@ func (c *Config) validate() error {
 	if c.Auth.RefreshTokenTTL == 0 {
 		return fmt.Errorf("refresh token TTL is required")
 	}
+	if c.Auth.RevocationCacheTTL == 0 {
+		c.Auth.RevocationCacheTTL = 30 * time.Second
+	}
+	switch c.Auth.Passwords.Algorithm {
+	case "":
+		c.Auth.Passwords.Algorithm = "argon2id"
+	case "argon2id", "bcrypt":
+	default:
+		return fmt.Errorf("unknown password hashing algorithm %q", c.Auth.Passwords.Algorithm)
+	}
+	if c.Auth.Passwords.Argon2.Time == 0 {
+		c.Auth.Passwords.Argon2.Time = 3
+	}
+	if c.Auth.Passwords.Argon2.Memory == 0 {
+		c.Auth.Passwords.Argon2.Memory = 64 * 1024
+	}
+	if c.Auth.Passwords.Argon2.Threads == 0 {
+		c.Auth.Passwords.Argon2.Threads = 4
+	}
+	if c.Auth.Passwords.BcryptCost == 0 {
+		c.Auth.Passwords.BcryptCost = 12
+	}
+	if c.Auth.Passwords.BcryptCost < 4 || c.Auth.Passwords.BcryptCost > 31 {
+		return fmt.Errorf("bcrypt cost must be between 4 and 31")
+	}
+	if c.Auth.MFA.Issuer == "" {
+		c.Auth.MFA.Issuer = "MedDoc"
+	}
+	if c.Auth.MFA.EncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.Auth.MFA.EncryptionKey)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("mfa encryption key must be 32 base64-encoded bytes")
+		}
+	}
+	if c.Auth.PasswordReset.URL == "" {
+		c.Auth.PasswordReset.URL = fmt.Sprintf("http://localhost:%d/reset-password", c.Server.Port)
+	}
+	if c.Auth.PasswordReset.TTL == 0 {
+		c.Auth.PasswordReset.TTL = time.Hour
+	}
+	if c.Auth.EmailVerification.URL == "" {
+		c.Auth.EmailVerification.URL = fmt.Sprintf("http://localhost:%d/verify-email", c.Server.Port)
+	}
+	if c.Auth.EmailVerification.TTL == 0 {
+		c.Auth.EmailVerification.TTL = 48 * time.Hour
+	}
+	if c.Auth.LoginThrottle.MaxAccountFailures == 0 {
+		c.Auth.LoginThrottle.MaxAccountFailures = 10
+	}
+	if c.Auth.LoginThrottle.MaxIPFailures == 0 {
+		c.Auth.LoginThrottle.MaxIPFailures = 100
+	}
+	if c.Auth.LoginThrottle.MaxMFAFailures == 0 {
+		c.Auth.LoginThrottle.MaxMFAFailures = 5
+	}
+	if c.Auth.LoginThrottle.LockoutDuration == 0 {
+		c.Auth.LoginThrottle.LockoutDuration = 15 * time.Minute
+	}
+	if c.Auth.LoginThrottle.FailureWindow == 0 {
+		c.Auth.LoginThrottle.FailureWindow = 15 * time.Minute
+	}
+	if c.Auth.LoginThrottle.DelayAfter == 0 {
+		c.Auth.LoginThrottle.DelayAfter = 3
+	}
+	if c.Auth.LoginThrottle.BaseDelay == 0 {
+		c.Auth.LoginThrottle.BaseDelay = time.Second
+	}
+	if c.Auth.LoginThrottle.MaxDelay == 0 {
+		c.Auth.LoginThrottle.MaxDelay = 30 * time.Second
+	}
+	if c.Auth.OIDC.LoginTTL == 0 {
+		c.Auth.OIDC.LoginTTL = 10 * time.Minute
+	}
+	names := make(map[string]bool)
+	for i := range c.Auth.OIDC.Providers {
+		p := &c.Auth.OIDC.Providers[i]
+		if p.Name == "" || p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			return fmt.Errorf("oidc provider %d needs a name, issuer, client id and redirect url", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("oidc provider %q is configured twice", p.Name)
+		}
+		names[p.Name] = true
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+	}
+	if c.Account.Deletion.GracePeriod == 0 {
+		c.Account.Deletion.GracePeriod = 7 * 24 * time.Hour
+	}
+	if c.Account.Deletion.PollInterval == 0 {
+		c.Account.Deletion.PollInterval = time.Minute
+	}
+	if c.Account.Deletion.Lease == 0 {
+		c.Account.Deletion.Lease = 10 * time.Minute
+	}
+	if c.Account.Deletion.URL == "" {
+		c.Account.Deletion.URL = fmt.Sprintf("http://localhost:%d/confirm-deletion", c.Server.Port)
+	}
+	if c.Account.Deletion.ConfirmationTTL == 0 {
+		c.Account.Deletion.ConfirmationTTL = 24 * time.Hour
+	}
+	if c.Audit.CheckpointInterval == 0 {
+		c.Audit.CheckpointInterval = time.Hour
+	}
+	if c.Sharing.Links.Key != "" {
+		key, err := base64.StdEncoding.DecodeString(c.Sharing.Links.Key)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("share link key must be 32 base64-encoded bytes")
+		}
+	}
+	if c.Sharing.Links.URL == "" {
+		c.Sharing.Links.URL = fmt.Sprintf("http://localhost:%d/share", c.Server.Port)
+	}
+	if c.Sharing.Links.MaxTTL == 0 {
+		c.Sharing.Links.MaxTTL = 30 * 24 * time.Hour
+	}
+	if c.Sharing.Links.MaxPINAttempts == 0 {
+		c.Sharing.Links.MaxPINAttempts = 5
+	}
+	if c.Sharing.Delegations.URL == "" {
+		c.Sharing.Delegations.URL = fmt.Sprintf("http://localhost:%d/accept-delegation", c.Server.Port)
+	}
+	if c.Sharing.Delegations.InviteTTL == 0 {
+		c.Sharing.Delegations.InviteTTL = 7 * 24 * time.Hour
+	}
+	if c.Sharing.BreakGlass.TTL == 0 {
+		c.Sharing.BreakGlass.TTL = time.Hour
+	}
+	if c.Mail.From == "" {
+		c.Mail.From = "MedDoc <no-reply@meddoc.local>"
+	}
+	if c.Mail.Dir == "" {
+		c.Mail.Dir = "storage/mail"
+	}
+	if c.Mail.SMTP.Host != "" && c.Mail.SMTP.Port == 0 {
+		c.Mail.SMTP.Port = 587
+	}
 	return nil
 }
 
+// ParsePrefix parses a CIDR range, or a single address as the range of just
+// that address.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
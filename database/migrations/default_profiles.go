@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// assignDefaultProfiles files the documents and files stored before patient
+// profiles existed under the default profile of their account, creating
+// that profile where needed.
+func assignDefaultProfiles(ctx context.Context, db *mongo.Database) error {
+	collections := []*mongo.Collection{db.Collection("documents"), db.Collection("files")}
+	unassigned := bson.M{"profile_id": bson.M{"$exists": false}}
+
+	for _, collection := range collections {
+		userIDs, err := collection.Distinct(ctx, "user_id", unassigned)
+		if err != nil {
+			return err
+		}
+
+		for _, value := range userIDs {
+			userID, ok := value.(string)
+			if !ok {
+				continue
+			}
+			profileID, err := ensureDefaultProfile(ctx, db, userID)
+			if err != nil {
+				return err
+			}
+
+			_, err = collection.UpdateMany(ctx,
+				bson.M{"user_id": userID, "profile_id": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"profile_id": profileID}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureDefaultProfile returns the ID of the default profile of an account,
+// named after the account holder like the ones the profile service creates.
+func ensureDefaultProfile(ctx context.Context, db *mongo.Database, userID string) (string, error) {
+	name := ""
+	if objectID, err := primitive.ObjectIDFromHex(userID); err == nil {
+		var user struct {
+			Email string `bson:"email"`
+			Name  string `bson:"name"`
+		}
+		err := db.Collection("users").FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return "", err
+		}
+		name = user.Name
+		if name == "" {
+			name = user.Email
+		}
+	}
+
+	now := time.Now()
+	var profile struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err := db.Collection("profiles").FindOneAndUpdate(ctx,
+		bson.M{"user_id": userID, "default": true},
+		bson.M{"$setOnInsert": bson.M{
+			"name":         name,
+			"relationship": "self",
+			"created_at":   now,
+			"updated_at":   now,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&profile)
+	if err != nil {
+		return "", err
+	}
+	return profile.ID.Hex(), nil
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fillDocumentSearchText stores the search text of documents saved before
+// full-text search existed: their title, description and content values,
+// once in English and once in Russian.
+func fillDocumentSearchText(ctx context.Context, db *mongo.Database) error {
+	texts := bson.A{}
+	for _, language := range []string{"english", "russian"} {
+		texts = append(texts, bson.M{
+			"language":    language,
+			"title":       "$title",
+			"description": "$description",
+			"content": bson.M{"$map": bson.M{
+				"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$content", bson.M{}}}},
+				"in":    "$$this.v",
+			}},
+		})
+	}
+
+	_, err := db.Collection("documents").UpdateMany(ctx,
+		bson.M{"search": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"search": texts}}}},
+	)
+	return err
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fillDocumentSortKeys stores an empty date and a zero priority on documents
+// that were saved without them. Listing pages through documents by these
+// fields and cannot step past a missing one.
+func fillDocumentSortKeys(ctx context.Context, db *mongo.Database) error {
+	documents := db.Collection("documents")
+	defaults := bson.M{"date": "", "priority": 0}
+
+	for field, value := range defaults {
+		_, err := documents.UpdateMany(ctx,
+			bson.M{field: bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{field: value}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
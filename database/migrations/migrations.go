@@ -0,0 +1,58 @@
+// Package migrations brings the data of an existing database in line with
+// what the current code expects. Every migration runs once per database;
+// the ones that are done are recorded in the migrations collection.
+//
+// Migrations must be safe to run again, since an instance can stop halfway
+// through one or two instances can start at the same time.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+type Migration struct {
+	// ID names the migration in the migrations collection. It must never
+	// change once released.
+	ID string
+	Up func(ctx context.Context, db *mongo.Database) error
+}
+
+// All lists the migrations in the order they run.
+var All = []Migration{
+	{ID: "0001_default_profiles", Up: assignDefaultProfiles},
+	{ID: "0002_document_sort_keys", Up: fillDocumentSortKeys},
+	{ID: "0003_document_search", Up: fillDocumentSearchText},
+}
+
+// Run applies the migrations that have not been applied to db yet.
+func Run(ctx context.Context, db *mongo.Database, migrations []Migration) error {
+	applied := db.Collection("migrations")
+	for _, migration := range migrations {
+		err := applied.FindOne(ctx, bson.M{"_id": migration.ID}).Err()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		logger.Info("running migration", "id", migration.ID)
+		if err := migration.Up(ctx, db); err != nil {
+			return fmt.Errorf("migration %s: %w", migration.ID, err)
+		}
+
+		_, err = applied.InsertOne(ctx, bson.M{"_id": migration.ID, "applied_at": time.Now()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return nil
+}
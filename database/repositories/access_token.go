@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// AccessTokenRepository stores personal access tokens, looked up by the hash
+// of the token. Expired tokens are removed by a TTL index.
+type AccessTokenRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoAccessToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     string             `bson:"user_id"`
+	Name       string             `bson:"name"`
+	Scopes     []string           `bson:"scopes"`
+	TokenHash  string             `bson:"token_hash"`
+	ExpiresAt  *time.Time         `bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+func fromMongoAccessToken(t mongoAccessToken) *models.PersonalAccessToken {
+	return &models.PersonalAccessToken{
+		ID:         t.ID.Hex(),
+		UserID:     t.UserID,
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		TokenHash:  t.TokenHash,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+func NewAccessTokenRepository(collection *mongo.Collection) *AccessTokenRepository {
+	return &AccessTokenRepository{
+		collection: collection,
+	}
+}
+
+func (r *AccessTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *AccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	result, err := r.collection.InsertOne(ctx, mongoAccessToken{
+		UserID:    token.UserID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	token.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *AccessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token mongoAccessToken
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrAccessTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoAccessToken(token), nil
+}
+
+func (r *AccessTokenRepository) GetByUserID(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tokens := []*models.PersonalAccessToken{}
+	for cursor.Next(ctx) {
+		var token mongoAccessToken
+		if err := cursor.Decode(&token); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, fromMongoAccessToken(token))
+	}
+	return tokens, cursor.Err()
+}
+
+// Touch records when a token was last used.
+func (r *AccessTokenRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrAccessTokenNotFound
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"last_used_at": at}})
+	return err
+}
+
+// Delete removes a token of the user.
+func (r *AccessTokenRepository) Delete(ctx context.Context, id, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrAccessTokenNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+func (r *AccessTokenRepository) DeleteAllForUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
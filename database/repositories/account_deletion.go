@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// AccountDeletionRepository stores scheduled account deletions. Completed
+// ones are kept as deletion receipts.
+type AccountDeletionRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoAccountDeletion struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	UserID           string             `bson:"user_id"`
+	Email            string             `bson:"email,omitempty"`
+	Status           string             `bson:"status"`
+	RequestedAt      time.Time          `bson:"requested_at"`
+	ScheduledFor     time.Time          `bson:"scheduled_for"`
+	LeaseUntil       time.Time          `bson:"lease_until,omitempty"`
+	TokenHash        string             `bson:"token_hash,omitempty"`
+	ConfirmBy        time.Time          `bson:"confirm_by,omitempty"`
+	CompletedAt      *time.Time         `bson:"completed_at,omitempty"`
+	DocumentsDeleted int64              `bson:"documents_deleted"`
+	FilesDeleted     int64              `bson:"files_deleted"`
+}
+
+func fromMongoAccountDeletion(d mongoAccountDeletion) *models.AccountDeletion {
+	return &models.AccountDeletion{
+		ID:               d.ID.Hex(),
+		UserID:           d.UserID,
+		Email:            d.Email,
+		Status:           d.Status,
+		RequestedAt:      d.RequestedAt,
+		ScheduledFor:     d.ScheduledFor,
+		LeaseUntil:       d.LeaseUntil,
+		TokenHash:        d.TokenHash,
+		ConfirmBy:        d.ConfirmBy,
+		CompletedAt:      d.CompletedAt,
+		DocumentsDeleted: d.DocumentsDeleted,
+		FilesDeleted:     d.FilesDeleted,
+	}
+}
+
+func NewAccountDeletionRepository(collection *mongo.Collection) *AccountDeletionRepository {
+	return &AccountDeletionRepository{
+		collection: collection,
+	}
+}
+
+func (r *AccountDeletionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "scheduled_for", Value: 1}}},
+	})
+	return err
+}
+
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *models.AccountDeletion) error {
+	result, err := r.collection.InsertOne(ctx, mongoAccountDeletion{
+		UserID:       deletion.UserID,
+		Email:        deletion.Email,
+		Status:       deletion.Status,
+		RequestedAt:  deletion.RequestedAt,
+		ScheduledFor: deletion.ScheduledFor,
+		TokenHash:    deletion.TokenHash,
+		ConfirmBy:    deletion.ConfirmBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	deletion.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// GetActiveByUserID returns the pending or running deletion of the user.
+func (r *AccountDeletionRepository) GetActiveByUserID(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	var deletion mongoAccountDeletion
+	err := r.collection.FindOne(ctx, bson.M{
+		"user_id": userID,
+		"status":  bson.M{"$in": bson.A{models.AccountDeletionPending, models.AccountDeletionInProgress}},
+	}).Decode(&deletion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoAccountDeletion(deletion), nil
+}
+
+// Confirm schedules the user's unconfirmed deletion with the given token
+// hash for scheduledFor, as long as it can still be confirmed at now.
+func (r *AccountDeletionRepository) Confirm(ctx context.Context, userID, tokenHash string, now, scheduledFor time.Time) (*models.AccountDeletion, error) {
+	var deletion mongoAccountDeletion
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"user_id":    userID,
+			"status":     models.AccountDeletionUnconfirmed,
+			"token_hash": tokenHash,
+			"confirm_by": bson.M{"$gt": now},
+		},
+		bson.M{
+			"$set":   bson.M{"status": models.AccountDeletionPending, "requested_at": now, "scheduled_for": scheduledFor},
+			"$unset": bson.M{"token_hash": "", "confirm_by": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&deletion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoAccountDeletion(deletion), nil
+}
+
+// Cancel cancels the user's deletion as long as it has not started.
+func (r *AccountDeletionRepository) Cancel(ctx context.Context, userID string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID, "status": models.AccountDeletionPending},
+		bson.M{"$set": bson.M{"status": models.AccountDeletionCancelled}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrDeletionNotFound
+	}
+	return nil
+}
+
+// ClaimDue marks one deletion whose grace period has passed, or whose worker
+// lease ran out, as in progress until leaseUntil and returns it. Only one
+// caller can claim a deletion at a time.
+func (r *AccountDeletionRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*models.AccountDeletion, error) {
+	var deletion mongoAccountDeletion
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"status": models.AccountDeletionPending, "scheduled_for": bson.M{"$lte": now}},
+			bson.M{"status": models.AccountDeletionInProgress, "lease_until": bson.M{"$lt": now}},
+		}},
+		bson.M{"$set": bson.M{"status": models.AccountDeletionInProgress, "lease_until": leaseUntil}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "scheduled_for", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&deletion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoAccountDeletion(deletion), nil
+}
+
+// AddProgress adds to the counts of deleted data as each step finishes, so a
+// resumed deletion still reports everything it removed.
+func (r *AccountDeletionRepository) AddProgress(ctx context.Context, id string, documents, files int64) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrDeletionNotFound
+	}
+
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$inc": bson.M{"documents_deleted": documents, "files_deleted": files}},
+	)
+	return err
+}
+
+// Complete marks the deletion as done and drops the email address, which is
+// the last piece of personal data it holds.
+func (r *AccountDeletionRepository) Complete(ctx context.Context, id string, completedAt time.Time) (*models.AccountDeletion, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrDeletionNotFound
+	}
+
+	var deletion mongoAccountDeletion
+	err = r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{
+			"$set":   bson.M{"status": models.AccountDeletionCompleted, "completed_at": completedAt},
+			"$unset": bson.M{"email": "", "lease_until": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&deletion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoAccountDeletion(deletion), nil
+}
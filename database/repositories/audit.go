@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// AuditRepository stores audit events. It only appends and reads; nothing
+// in the application changes or removes an event once written.
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoAuditEvent struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	Seq          int64              `bson:"seq"`
+	Time         time.Time          `bson:"time"`
+	ActorID      string             `bson:"actor_id,omitempty"`
+	OwnerID      string             `bson:"owner_id,omitempty"`
+	Action       string             `bson:"action"`
+	ResourceID   string             `bson:"resource_id,omitempty"`
+	Outcome      string             `bson:"outcome"`
+	RequestID    string             `bson:"request_id,omitempty"`
+	IP           string             `bson:"ip,omitempty"`
+	BreakGlassID string             `bson:"break_glass_id,omitempty"`
+	PrevHash     string             `bson:"prev_hash,omitempty"`
+	Hash         string             `bson:"hash"`
+}
+
+func fromMongoAuditEvent(e mongoAuditEvent) *models.AuditEvent {
+	return &models.AuditEvent{
+		ID:           e.ID.Hex(),
+		Seq:          e.Seq,
+		Time:         e.Time,
+		ActorID:      e.ActorID,
+		OwnerID:      e.OwnerID,
+		Action:       e.Action,
+		ResourceID:   e.ResourceID,
+		Outcome:      e.Outcome,
+		RequestID:    e.RequestID,
+		IP:           e.IP,
+		BreakGlassID: e.BreakGlassID,
+		PrevHash:     e.PrevHash,
+		Hash:         e.Hash,
+	}
+}
+
+func NewAuditRepository(collection *mongo.Collection) *AuditRepository {
+	return &AuditRepository{
+		collection: collection,
+	}
+}
+
+func (r *AuditRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "seq", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "resource_id", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+	})
+	return err
+}
+
+// Insert appends event. It returns ErrAuditSeqTaken when an event with the
+// same sequence number exists already.
+func (r *AuditRepository) Insert(ctx context.Context, event *models.AuditEvent) error {
+	result, err := r.collection.InsertOne(ctx, mongoAuditEvent{
+		Seq:          event.Seq,
+		Time:         event.Time,
+		ActorID:      event.ActorID,
+		OwnerID:      event.OwnerID,
+		Action:       event.Action,
+		ResourceID:   event.ResourceID,
+		Outcome:      event.Outcome,
+		RequestID:    event.RequestID,
+		IP:           event.IP,
+		BreakGlassID: event.BreakGlassID,
+		PrevHash:     event.PrevHash,
+		Hash:         event.Hash,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.ErrAuditSeqTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	event.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// Find returns the events matching filter, newest first.
+func (r *AuditRepository) Find(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	query := bson.M{}
+	actor := bson.M{}
+	if filter.ActorID != "" {
+		actor["$eq"] = filter.ActorID
+	}
+	if filter.ExcludeActorID != "" {
+		actor["$ne"] = filter.ExcludeActorID
+	}
+	if len(actor) > 0 {
+		query["actor_id"] = actor
+	}
+	if filter.OwnerID != "" {
+		query["owner_id"] = filter.OwnerID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.ResourceID != "" {
+		query["resource_id"] = filter.ResourceID
+	}
+	if filter.Outcome != "" {
+		query["outcome"] = filter.Outcome
+	}
+	if filter.RequestID != "" {
+		query["request_id"] = filter.RequestID
+	}
+	if filter.BreakGlass {
+		query["break_glass_id"] = bson.M{"$exists": true}
+	}
+	period := bson.M{}
+	if filter.From != nil {
+		period["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		period["$lt"] = *filter.To
+	}
+	if len(period) > 0 {
+		query["time"] = period
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}, {Key: "_id", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []*models.AuditEvent{}
+	for cursor.Next(ctx) {
+		var event mongoAuditEvent
+		if err := cursor.Decode(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, fromMongoAuditEvent(event))
+	}
+	return events, cursor.Err()
+}
+
+// Last returns the event with the highest sequence number.
+func (r *AuditRepository) Last(ctx context.Context) (*models.AuditEvent, error) {
+	var event mongoAuditEvent
+	err := r.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})).Decode(&event)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrAuditEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromMongoAuditEvent(event), nil
+}
+
+// Walk calls fn for every event in sequence order and stops at the first
+// error fn returns.
+func (r *AuditRepository) Walk(ctx context.Context, fn func(*models.AuditEvent) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var event mongoAuditEvent
+		if err := cursor.Decode(&event); err != nil {
+			return err
+		}
+		if err := fn(fromMongoAuditEvent(event)); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type AuditCheckpointRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoAuditCheckpoint struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Seq       int64              `bson:"seq"`
+	Hash      string             `bson:"hash"`
+	Time      time.Time          `bson:"time"`
+	KeyID     string             `bson:"key_id"`
+	Signature string             `bson:"signature"`
+}
+
+func fromMongoAuditCheckpoint(c mongoAuditCheckpoint) *models.AuditCheckpoint {
+	return &models.AuditCheckpoint{
+		ID:        c.ID.Hex(),
+		Seq:       c.Seq,
+		Hash:      c.Hash,
+		Time:      c.Time,
+		KeyID:     c.KeyID,
+		Signature: c.Signature,
+	}
+}
+
+func NewAuditCheckpointRepository(collection *mongo.Collection) *AuditCheckpointRepository {
+	return &AuditCheckpointRepository{
+		collection: collection,
+	}
+}
+
+func (r *AuditCheckpointRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "seq", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Insert stores checkpoint. It returns ErrAuditSeqTaken when the same event
+// has been checkpointed already.
+func (r *AuditCheckpointRepository) Insert(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	result, err := r.collection.InsertOne(ctx, mongoAuditCheckpoint{
+		Seq:       checkpoint.Seq,
+		Hash:      checkpoint.Hash,
+		Time:      checkpoint.Time,
+		KeyID:     checkpoint.KeyID,
+		Signature: checkpoint.Signature,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.ErrAuditSeqTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	checkpoint.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// Last returns the checkpoint of the latest event.
+func (r *AuditCheckpointRepository) Last(ctx context.Context) (*models.AuditCheckpoint, error) {
+	var checkpoint mongoAuditCheckpoint
+	err := r.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})).Decode(&checkpoint)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrAuditCheckpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromMongoAuditCheckpoint(checkpoint), nil
+}
+
+// All returns every checkpoint in sequence order.
+func (r *AuditCheckpointRepository) All(ctx context.Context) ([]*models.AuditCheckpoint, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	checkpoints := []*models.AuditCheckpoint{}
+	for cursor.Next(ctx) {
+		var checkpoint mongoAuditCheckpoint
+		if err := cursor.Decode(&checkpoint); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, fromMongoAuditCheckpoint(checkpoint))
+	}
+	return checkpoints, cursor.Err()
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// BreakGlassRepository stores emergency accesses. Expired ones are kept
+// along with their justification as a record of why they were opened.
+type BreakGlassRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoBreakGlassAccess struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	ClinicianID   string             `bson:"clinician_id"`
+	PatientID     string             `bson:"patient_id"`
+	Justification string             `bson:"justification"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	ExpiresAt     time.Time          `bson:"expires_at"`
+}
+
+func fromMongoBreakGlassAccess(a mongoBreakGlassAccess) *models.BreakGlassAccess {
+	return &models.BreakGlassAccess{
+		ID:            a.ID.Hex(),
+		ClinicianID:   a.ClinicianID,
+		PatientID:     a.PatientID,
+		Justification: a.Justification,
+		CreatedAt:     a.CreatedAt,
+		ExpiresAt:     a.ExpiresAt,
+	}
+}
+
+func NewBreakGlassRepository(collection *mongo.Collection) *BreakGlassRepository {
+	return &BreakGlassRepository{
+		collection: collection,
+	}
+}
+
+func (r *BreakGlassRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "clinician_id", Value: 1}, {Key: "patient_id", Value: 1}, {Key: "expires_at", Value: -1}}},
+		{Keys: bson.D{{Key: "patient_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (r *BreakGlassRepository) Create(ctx context.Context, access *models.BreakGlassAccess) error {
+	result, err := r.collection.InsertOne(ctx, mongoBreakGlassAccess{
+		ClinicianID:   access.ClinicianID,
+		PatientID:     access.PatientID,
+		Justification: access.Justification,
+		CreatedAt:     access.CreatedAt,
+		ExpiresAt:     access.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	access.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// GetActive returns the access of clinicianID to the documents of patientID
+// that is open at now and ends last.
+func (r *BreakGlassRepository) GetActive(ctx context.Context, clinicianID, patientID string, now time.Time) (*models.BreakGlassAccess, error) {
+	var access mongoBreakGlassAccess
+	err := r.collection.FindOne(ctx, bson.M{
+		"clinician_id": clinicianID,
+		"patient_id":   patientID,
+		"expires_at":   bson.M{"$gt": now},
+	}, options.FindOne().SetSort(bson.D{{Key: "expires_at", Value: -1}})).Decode(&access)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrBreakGlassNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoBreakGlassAccess(access), nil
+}
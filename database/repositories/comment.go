@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// CommentRepository stores the comments left on documents.
+type CommentRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoComment struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	DocumentID string             `bson:"document_id"`
+	OwnerID    string             `bson:"owner_id"`
+	AuthorID   string             `bson:"author_id"`
+	Text       string             `bson:"text"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+func fromMongoComment(c mongoComment) *models.Comment {
+	return &models.Comment{
+		ID:         c.ID.Hex(),
+		DocumentID: c.DocumentID,
+		OwnerID:    c.OwnerID,
+		AuthorID:   c.AuthorID,
+		Text:       c.Text,
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+func NewCommentRepository(collection *mongo.Collection) *CommentRepository {
+	return &CommentRepository{
+		collection: collection,
+	}
+}
+
+func (r *CommentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "document_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "owner_id", Value: 1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}}},
+	})
+	return err
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	result, err := r.collection.InsertOne(ctx, mongoComment{
+		DocumentID: comment.DocumentID,
+		OwnerID:    comment.OwnerID,
+		AuthorID:   comment.AuthorID,
+		Text:       comment.Text,
+		CreatedAt:  comment.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	comment.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// GetByDocumentID returns the comments on a document, oldest first.
+func (r *CommentRepository) GetByDocumentID(ctx context.Context, documentID string) ([]*models.Comment, error) {
+	cursor, err := r.collection.Find(
+		ctx,
+		bson.M{"document_id": documentID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			logger.Error("failed to close cursor", err)
+		}
+	}()
+
+	comments := []*models.Comment{}
+	for cursor.Next(ctx) {
+		var comment mongoComment
+		if err := cursor.Decode(&comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, fromMongoComment(comment))
+	}
+	return comments, cursor.Err()
+}
+
+func (r *CommentRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"document_id": documentID})
+	return err
+}
+
+// DeleteByUserID removes the comments on the user's documents and the ones
+// the user wrote on documents of others.
+func (r *CommentRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"owner_id": userID},
+		bson.M{"author_id": userID},
+	}})
+	return err
+}
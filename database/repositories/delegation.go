@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// DelegationRepository stores delegations. Invitations that were not
+// accepted in time are removed by a TTL index; accepting one clears its
+// token and invitation expiry.
+type DelegationRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoDelegation struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	GrantorID       string             `bson:"grantor_id"`
+	DelegateID      string             `bson:"delegate_id,omitempty"`
+	Email           string             `bson:"email"`
+	Permission      string             `bson:"permission"`
+	Status          string             `bson:"status"`
+	TokenHash       string             `bson:"token_hash,omitempty"`
+	InviteExpiresAt *time.Time         `bson:"invite_expires_at,omitempty"`
+	ExpiresAt       *time.Time         `bson:"expires_at,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	AcceptedAt      *time.Time         `bson:"accepted_at,omitempty"`
+}
+
+func fromMongoDelegation(d mongoDelegation) *models.Delegation {
+	return &models.Delegation{
+		ID:              d.ID.Hex(),
+		GrantorID:       d.GrantorID,
+		DelegateID:      d.DelegateID,
+		Email:           d.Email,
+		Permission:      d.Permission,
+		Status:          d.Status,
+		TokenHash:       d.TokenHash,
+		InviteExpiresAt: d.InviteExpiresAt,
+		ExpiresAt:       d.ExpiresAt,
+		CreatedAt:       d.CreatedAt,
+		AcceptedAt:      d.AcceptedAt,
+	}
+}
+
+func NewDelegationRepository(collection *mongo.Collection) *DelegationRepository {
+	return &DelegationRepository{
+		collection: collection,
+	}
+}
+
+func (r *DelegationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"token_hash": bson.M{"$exists": true}}),
+		},
+		{
+			// A delegate holds at most one delegation per grantor.
+			Keys: bson.D{{Key: "grantor_id", Value: 1}, {Key: "delegate_id", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"delegate_id": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "grantor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "delegate_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "invite_expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *DelegationRepository) Create(ctx context.Context, delegation *models.Delegation) error {
+	result, err := r.collection.InsertOne(ctx, mongoDelegation{
+		GrantorID:       delegation.GrantorID,
+		Email:           delegation.Email,
+		Permission:      delegation.Permission,
+		Status:          delegation.Status,
+		TokenHash:       delegation.TokenHash,
+		InviteExpiresAt: delegation.InviteExpiresAt,
+		ExpiresAt:       delegation.ExpiresAt,
+		CreatedAt:       delegation.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	delegation.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// GetInvitation returns the pending delegation with the token hash unless
+// its invitation has expired.
+func (r *DelegationRepository) GetInvitation(ctx context.Context, tokenHash string) (*models.Delegation, error) {
+	return r.findOne(ctx, bson.M{
+		"token_hash":        tokenHash,
+		"status":            models.DelegationPending,
+		"invite_expires_at": bson.M{"$gt": time.Now()},
+	})
+}
+
+// Accept turns a pending delegation into an active one held by delegateID.
+// The invitation token cannot be used again afterwards.
+func (r *DelegationRepository) Accept(ctx context.Context, id, delegateID string, acceptedAt time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrDelegationNotFound
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "status": models.DelegationPending},
+		bson.M{
+			"$set": bson.M{
+				"delegate_id": delegateID,
+				"status":      models.DelegationActive,
+				"accepted_at": acceptedAt,
+			},
+			"$unset": bson.M{"token_hash": "", "invite_expires_at": ""},
+		},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.ErrDelegationExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrDelegationNotFound
+	}
+	return nil
+}
+
+// GetActive returns the accepted delegation from grantorID to delegateID.
+// Whether it has expired is left to the caller.
+func (r *DelegationRepository) GetActive(ctx context.Context, grantorID, delegateID string) (*models.Delegation, error) {
+	return r.findOne(ctx, bson.M{
+		"grantor_id":  grantorID,
+		"delegate_id": delegateID,
+		"status":      models.DelegationActive,
+	})
+}
+
+func (r *DelegationRepository) findOne(ctx context.Context, filter bson.M) (*models.Delegation, error) {
+	var delegation mongoDelegation
+	err := r.collection.FindOne(ctx, filter).Decode(&delegation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrDelegationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoDelegation(delegation), nil
+}
+
+// GetByGrantorID returns the delegations and pending invitations the user
+// gave, newest first.
+func (r *DelegationRepository) GetByGrantorID(ctx context.Context, grantorID string) ([]*models.Delegation, error) {
+	return r.find(ctx, bson.M{"grantor_id": grantorID})
+}
+
+// GetByDelegateID returns the delegations the user accepted, newest first.
+func (r *DelegationRepository) GetByDelegateID(ctx context.Context, delegateID string) ([]*models.Delegation, error) {
+	return r.find(ctx, bson.M{"delegate_id": delegateID})
+}
+
+func (r *DelegationRepository) find(ctx context.Context, filter bson.M) ([]*models.Delegation, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	delegations := []*models.Delegation{}
+	for cursor.Next(ctx) {
+		var delegation mongoDelegation
+		if err := cursor.Decode(&delegation); err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, fromMongoDelegation(delegation))
+	}
+	return delegations, cursor.Err()
+}
+
+// Delete removes a delegation the user gave or holds.
+func (r *DelegationRepository) Delete(ctx context.Context, id, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrDelegationNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id": objectID,
+		"$or": bson.A{bson.M{"grantor_id": userID}, bson.M{"delegate_id": userID}},
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.ErrDelegationNotFound
+	}
+	return nil
+}
+
+// DeleteAllForUser removes every delegation the user gave or holds.
+func (r *DelegationRepository) DeleteAllForUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{
+		"$or": bson.A{bson.M{"grantor_id": userID}, bson.M{"delegate_id": userID}},
+	})
+	return err
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeviceRepository remembers the devices each user has signed in from. A
+// device is identified by a fingerprint the caller derives from the client,
+// and outlives the sessions started on it.
+type DeviceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDeviceRepository(collection *mongo.Collection) *DeviceRepository {
+	return &DeviceRepository{
+		collection: collection,
+	}
+}
+
+func (r *DeviceRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "fingerprint", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Remember records that the user signed in from the device at seenAt and
+// reports whether the device had not been seen before.
+func (r *DeviceRepository) Remember(ctx context.Context, userID, fingerprint string, seenAt time.Time) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "fingerprint": fingerprint},
+		bson.M{
+			"$set":         bson.M{"last_seen_at": seenAt},
+			"$setOnInsert": bson.M{"first_seen_at": seenAt},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent sign-in from the same device inserted it first.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// CountByUserID returns how many devices the user has signed in from.
+func (r *DeviceRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+func (r *DeviceRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
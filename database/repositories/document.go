@@ -2,15 +2,20 @@ package repositories
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/fulltext"
 	"github.com/gruzdev-dev/meddoc/pkg/logger"
 )
 
@@ -22,14 +27,67 @@ type mongoDocument struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
 	Title       string             `bson:"title"`
 	Description string             `bson:"description,omitempty"`
-	Date        string             `bson:"date,omitempty"`
+	Date        string             `bson:"date"`
 	File        string             `bson:"file,omitempty"`
 	Category    string             `bson:"category,omitempty"`
-	Priority    int                `bson:"priority,omitempty"`
+	Priority    int                `bson:"priority"`
 	Content     map[string]string  `bson:"content,omitempty"`
 	UserID      string             `bson:"user_id"`
+	ProfileID   string             `bson:"profile_id"`
+	CreatedBy   string             `bson:"created_by,omitempty"`
+	UpdatedBy   string             `bson:"updated_by,omitempty"`
 	CreatedAt   time.Time          `bson:"created_at"`
 	UpdatedAt   time.Time          `bson:"updated_at"`
+	Search      []mongoSearchText  `bson:"search,omitempty"`
+}
+
+// withoutSearch leaves the search text out of documents read back; only the
+// index needs it.
+var withoutSearch = bson.M{"search": 0}
+
+// mongoSearchText is the text of a document the search index covers. It is
+// stored once per language so words are indexed by the stemming rules of
+// each.
+type mongoSearchText struct {
+	Language    string   `bson:"language"`
+	Title       string   `bson:"title"`
+	Description string   `bson:"description,omitempty"`
+	Content     []string `bson:"content,omitempty"`
+}
+
+func fromMongoDocument(d mongoDocument) *models.Document {
+	return &models.Document{
+		ID:          d.ID.Hex(),
+		Title:       d.Title,
+		Description: d.Description,
+		Date:        d.Date,
+		File:        d.File,
+		Category:    d.Category,
+		Priority:    d.Priority,
+		Content:     d.Content,
+		UserID:      d.UserID,
+		ProfileID:   d.ProfileID,
+		CreatedBy:   d.CreatedBy,
+		UpdatedBy:   d.UpdatedBy,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+var documentSorts = []string{
+	models.DocumentSortDate,
+	models.DocumentSortPriority,
+	models.DocumentSortCreatedAt,
+	models.DocumentSortUpdatedAt,
+}
+
+// documentCursor is what a page cursor carries: the sort and the sort key
+// and ID of the last document on the page.
+type documentCursor struct {
+	Sort       string             `bson:"s"`
+	Descending bool               `bson:"d"`
+	Value      any                `bson:"v"`
+	ID         primitive.ObjectID `bson:"id"`
 }
 
 func NewDocumentRepository(collection *mongo.Collection) *DocumentRepository {
@@ -38,6 +96,39 @@ func NewDocumentRepository(collection *mongo.Collection) *DocumentRepository {
 	}
 }
 
+func (r *DocumentRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "profile_id", Value: 1}}},
+	}
+	// One index per sort, ending in _id like the sort itself. MongoDB walks
+	// them backwards for descending pages.
+	for _, field := range documentSorts {
+		indexes = append(indexes, mongo.IndexModel{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: field, Value: 1}, {Key: "_id", Value: 1}},
+		})
+	}
+	// Only documents of one user are searched, so user_id leads the text
+	// index. Matches in the title count the most.
+	indexes = append(indexes, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "user_id", Value: 1},
+			{Key: "search.title", Value: "text"},
+			{Key: "search.description", Value: "text"},
+			{Key: "search.content", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("document_search").
+			SetDefaultLanguage(fulltext.English).
+			SetWeights(bson.D{
+				{Key: "search.title", Value: 10},
+				{Key: "search.description", Value: 5},
+				{Key: "search.content", Value: 1},
+			}),
+	})
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
 func (r *DocumentRepository) Create(ctx context.Context, doc *models.Document) error {
 	mongoDoc := mongoDocument{
 		Title:       doc.Title,
@@ -48,8 +139,12 @@ func (r *DocumentRepository) Create(ctx context.Context, doc *models.Document) e
 		Priority:    doc.Priority,
 		Content:     doc.Content,
 		UserID:      doc.UserID,
+		ProfileID:   doc.ProfileID,
+		CreatedBy:   doc.CreatedBy,
+		UpdatedBy:   doc.UpdatedBy,
 		CreatedAt:   doc.CreatedAt,
 		UpdatedAt:   doc.UpdatedAt,
+		Search:      searchTexts(doc),
 	}
 
 	result, err := r.collection.InsertOne(ctx, mongoDoc)
@@ -64,11 +159,11 @@ func (r *DocumentRepository) Create(ctx context.Context, doc *models.Document) e
 func (r *DocumentRepository) GetByID(ctx context.Context, id string) (*models.Document, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.ErrDocumentNotFound
 	}
 
 	var mongoDoc mongoDocument
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mongoDoc)
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}, options.FindOne().SetProjection(withoutSearch)).Decode(&mongoDoc)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return nil, apperrors.ErrDocumentNotFound
 	}
@@ -76,23 +171,164 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id string) (*models.Do
 		return nil, err
 	}
 
-	return &models.Document{
-		ID:          mongoDoc.ID.Hex(),
-		Title:       mongoDoc.Title,
-		Description: mongoDoc.Description,
-		Date:        mongoDoc.Date,
-		File:        mongoDoc.File,
-		Category:    mongoDoc.Category,
-		Priority:    mongoDoc.Priority,
-		Content:     mongoDoc.Content,
-		UserID:      mongoDoc.UserID,
-		CreatedAt:   mongoDoc.CreatedAt,
-		UpdatedAt:   mongoDoc.UpdatedAt,
-	}, nil
-}
-
-func (r *DocumentRepository) GetByUserID(ctx context.Context, userID string) ([]*models.Document, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID})
+	return fromMongoDocument(mongoDoc), nil
+}
+
+// GetByUserID returns the page of the user's documents that filter selects.
+// It returns ErrInvalidCursor when the cursor is malformed or was issued for
+// another sort.
+func (r *DocumentRepository) GetByUserID(ctx context.Context, userID string, filter models.DocumentFilter) (*models.DocumentPage, error) {
+	sortField := filter.Sort
+	if sortField == "" {
+		sortField = models.DocumentSortCreatedAt
+	}
+	if !slices.Contains(documentSorts, sortField) {
+		return nil, fmt.Errorf("unknown document sort %q", sortField)
+	}
+
+	query := documentQuery(userID, filter)
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	order, after := 1, "$gt"
+	if filter.Descending {
+		order, after = -1, "$lt"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeDocumentCursor(filter.Cursor)
+		if err != nil || cursor.Sort != sortField || cursor.Descending != filter.Descending {
+			return nil, apperrors.ErrInvalidCursor
+		}
+		query["$or"] = bson.A{
+			bson.M{sortField: bson.M{after: cursor.Value}},
+			bson.M{sortField: cursor.Value, "_id": bson.M{after: cursor.ID}},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: sortField, Value: order}, {Key: "_id", Value: order}})
+	if filter.Limit > 0 {
+		// One more than asked tells whether there is a next page.
+		opts.SetLimit(int64(filter.Limit) + 1)
+	}
+	documents, err := r.find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.DocumentPage{Documents: documents, Total: total}
+	if page.Documents == nil {
+		page.Documents = []*models.Document{}
+	}
+	if filter.Limit > 0 && len(documents) > filter.Limit {
+		page.Documents = documents[:filter.Limit]
+		last := page.Documents[filter.Limit-1]
+		page.NextCursor, err = encodeDocumentCursor(sortField, filter.Descending, last)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// documentQuery matches the documents of userID that pass filter, leaving
+// out the cursor.
+func documentQuery(userID string, filter models.DocumentFilter) bson.M {
+	query := bson.M{"user_id": userID}
+	if filter.ProfileID != "" {
+		query["profile_id"] = filter.ProfileID
+	}
+	if filter.Category != "" {
+		query["category"] = filter.Category
+	}
+	priority := bson.M{}
+	if filter.MinPriority != nil {
+		priority["$gte"] = *filter.MinPriority
+	}
+	if filter.MaxPriority != nil {
+		priority["$lte"] = *filter.MaxPriority
+	}
+	if len(priority) > 0 {
+		query["priority"] = priority
+	}
+	if filter.DateFrom != "" || filter.DateTo != "" {
+		// Documents without a date store it empty.
+		date := bson.M{"$gt": ""}
+		if filter.DateFrom != "" {
+			date["$gte"] = filter.DateFrom
+		}
+		if filter.DateTo != "" {
+			date["$lte"] = filter.DateTo
+		}
+		query["date"] = date
+	}
+	if filter.HasFile != nil {
+		noFile := bson.A{nil, ""}
+		if *filter.HasFile {
+			query["file"] = bson.M{"$nin": noFile}
+		} else {
+			query["file"] = bson.M{"$in": noFile}
+		}
+	}
+	return query
+}
+
+func encodeDocumentCursor(sortField string, descending bool, doc *models.Document) (string, error) {
+	id, err := primitive.ObjectIDFromHex(doc.ID)
+	if err != nil {
+		return "", err
+	}
+	cursor := documentCursor{Sort: sortField, Descending: descending, ID: id}
+	switch sortField {
+	case models.DocumentSortDate:
+		cursor.Value = doc.Date
+	case models.DocumentSortPriority:
+		cursor.Value = doc.Priority
+	case models.DocumentSortCreatedAt:
+		cursor.Value = doc.CreatedAt
+	case models.DocumentSortUpdatedAt:
+		cursor.Value = doc.UpdatedAt
+	}
+	data, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeDocumentCursor(s string) (documentCursor, error) {
+	var cursor documentCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+	if err := bson.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.Value == nil || cursor.ID.IsZero() {
+		return cursor, errors.New("incomplete cursor")
+	}
+	return cursor, nil
+}
+
+// GetByIDs returns the documents with the given IDs. IDs of documents that
+// do not exist are skipped.
+func (r *DocumentRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Document, error) {
+	objectIDs := make(bson.A, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+}
+
+func (r *DocumentRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Document, error) {
+	opts = append([]*options.FindOptions{options.Find().SetProjection(withoutSearch)}, opts...)
+	cursor, err := r.collection.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -108,21 +344,7 @@ func (r *DocumentRepository) GetByUserID(ctx context.Context, userID string) ([]
 		if err := cursor.Decode(&mongoDoc); err != nil {
 			return nil, err
 		}
-
-		doc := &models.Document{
-			ID:          mongoDoc.ID.Hex(),
-			Title:       mongoDoc.Title,
-			Description: mongoDoc.Description,
-			Date:        mongoDoc.Date,
-			File:        mongoDoc.File,
-			Category:    mongoDoc.Category,
-			Priority:    mongoDoc.Priority,
-			Content:     mongoDoc.Content,
-			UserID:      mongoDoc.UserID,
-			CreatedAt:   mongoDoc.CreatedAt,
-			UpdatedAt:   mongoDoc.UpdatedAt,
-		}
-		documents = append(documents, doc)
+		documents = append(documents, fromMongoDocument(mongoDoc))
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -150,7 +372,7 @@ func (r *DocumentRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *DocumentRepository) Update(ctx context.Context, id string, update models.DocumentUpdate) error {
+func (r *DocumentRepository) Update(ctx context.Context, id string, update models.DocumentUpdate, updatedBy string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
@@ -177,12 +399,26 @@ func (r *DocumentRepository) Update(ctx context.Context, id string, update model
 	if update.Content != nil {
 		set["content"] = update.Content
 	}
+	if update.ProfileID != nil {
+		set["profile_id"] = *update.ProfileID
+	}
+	set["updated_by"] = updatedBy
 	set["updated_at"] = time.Now()
 
+	// A pipeline rebuilds the search text from the updated fields in the
+	// same write. Values are wrapped in $literal so text starting with $ is
+	// not taken for a field path.
+	literals := bson.M{}
+	for field, value := range set {
+		literals[field] = bson.M{"$literal": value}
+	}
 	result, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": objectID},
-		bson.M{"$set": set},
+		mongo.Pipeline{
+			{{Key: "$set", Value: literals}},
+			{{Key: "$set", Value: bson.M{"search": searchTextExpression()}}},
+		},
 	)
 	if err != nil {
 		return err
@@ -192,3 +428,100 @@ func (r *DocumentRepository) Update(ctx context.Context, id string, update model
 	}
 	return nil
 }
+
+// Search returns the documents of userID that match search, most relevant
+// first. The query is stemmed by the rules of the language it is written in.
+func (r *DocumentRepository) Search(ctx context.Context, userID string, search models.DocumentSearch) ([]*models.DocumentSearchResult, error) {
+	query := bson.M{
+		"user_id": userID,
+		"$text":   bson.M{"$search": search.Query, "$language": fulltext.Language(search.Query)},
+	}
+	if search.ProfileID != "" {
+		query["profile_id"] = search.ProfileID
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score, "search": 0}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}})
+	if search.Limit > 0 {
+		opts.SetLimit(int64(search.Limit))
+	}
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			logger.Error("failed to close cursor", err)
+		}
+	}()
+
+	results := []*models.DocumentSearchResult{}
+	for cursor.Next(ctx) {
+		var found struct {
+			mongoDocument `bson:",inline"`
+			Score         float64 `bson:"score"`
+		}
+		if err := cursor.Decode(&found); err != nil {
+			return nil, err
+		}
+		results = append(results, &models.DocumentSearchResult{
+			Document: fromMongoDocument(found.mongoDocument),
+			Score:    found.Score,
+		})
+	}
+	return results, cursor.Err()
+}
+
+// searchTexts returns the search text of a new document.
+func searchTexts(doc *models.Document) []mongoSearchText {
+	var content []string
+	for _, value := range doc.Content {
+		content = append(content, value)
+	}
+	texts := make([]mongoSearchText, 0, len(fulltext.Languages))
+	for _, language := range fulltext.Languages {
+		texts = append(texts, mongoSearchText{
+			Language:    language,
+			Title:       doc.Title,
+			Description: doc.Description,
+			Content:     content,
+		})
+	}
+	return texts
+}
+
+// searchTextExpression computes the search text from the stored fields of a
+// document in an update pipeline, the way searchTexts does for new ones.
+func searchTextExpression() bson.A {
+	texts := bson.A{}
+	for _, language := range fulltext.Languages {
+		texts = append(texts, bson.M{
+			"language":    language,
+			"title":       "$title",
+			"description": "$description",
+			"content": bson.M{"$map": bson.M{
+				"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$content", bson.M{}}}},
+				"in":    "$$this.v",
+			}},
+		})
+	}
+	return texts
+}
+
+func (r *DocumentRepository) CountByFile(ctx context.Context, userID, file string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "file": file})
+}
+
+func (r *DocumentRepository) CountByProfile(ctx context.Context, userID, profileID string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "profile_id": profileID})
+}
+
+func (r *DocumentRepository) DeleteByUserID(ctx context.Context, userID string) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -10,17 +10,20 @@ import (
 
 	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
 )
 
 type mongoFileRecord struct {
 	ID          primitive.ObjectID `bson:"_id"`
 	UserID      string             `bson:"user_id"`
+	ProfileID   string             `bson:"profile_id"`
 	StorageType string             `bson:"storage_type"`
 }
 
 func toMongoFileRecord(file *models.FileCreation) bson.M {
 	return bson.M{
 		"user_id":      file.UserID,
+		"profile_id":   file.ProfileID,
 		"storage_type": file.StorageType,
 	}
 }
@@ -29,6 +32,7 @@ func fromMongoFileRecord(mongoFile mongoFileRecord) *models.FileRecord {
 	return &models.FileRecord{
 		ID:          mongoFile.ID.Hex(),
 		UserID:      mongoFile.UserID,
+		ProfileID:   mongoFile.ProfileID,
 		StorageType: mongoFile.StorageType,
 	}
 }
@@ -43,6 +47,14 @@ func NewFileRepository(collection *mongo.Collection) *FileRepository {
 	}
 }
 
+func (r *FileRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "profile_id", Value: 1}}},
+	})
+	return err
+}
+
 func (r *FileRepository) Create(ctx context.Context, file *models.FileCreation) (*models.FileRecord, error) {
 	mongoFile := toMongoFileRecord(file)
 
@@ -55,6 +67,7 @@ func (r *FileRepository) Create(ctx context.Context, file *models.FileCreation)
 	return &models.FileRecord{
 		ID:          id.Hex(),
 		UserID:      file.UserID,
+		ProfileID:   file.ProfileID,
 		StorageType: file.StorageType,
 	}, nil
 }
@@ -76,3 +89,52 @@ func (r *FileRepository) GetByID(ctx context.Context, id string) (*models.FileRe
 
 	return fromMongoFileRecord(mongoFile), nil
 }
+
+func (r *FileRepository) GetByUserID(ctx context.Context, userID string) ([]*models.FileRecord, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			logger.Error("failed to close cursor", err)
+		}
+	}()
+
+	var files []*models.FileRecord
+	for cursor.Next(ctx) {
+		var mongoFile mongoFileRecord
+		if err := cursor.Decode(&mongoFile); err != nil {
+			return nil, err
+		}
+		files = append(files, fromMongoFileRecord(mongoFile))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (r *FileRepository) CountByProfile(ctx context.Context, userID, profileID string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "profile_id": profileID})
+}
+
+func (r *FileRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	return nil
+}
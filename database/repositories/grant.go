@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// GrantRepository stores document grants. It reads the documents collection
+// to find the documents a file is attached to.
+type GrantRepository struct {
+	collection *mongo.Collection
+	documents  *mongo.Collection
+}
+
+type mongoGrant struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	DocumentID string             `bson:"document_id"`
+	OwnerID    string             `bson:"owner_id"`
+	GranteeID  string             `bson:"grantee_id"`
+	Permission string             `bson:"permission"`
+	ExpiresAt  *time.Time         `bson:"expires_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+func fromMongoGrant(g mongoGrant) *models.Grant {
+	return &models.Grant{
+		ID:         g.ID.Hex(),
+		DocumentID: g.DocumentID,
+		OwnerID:    g.OwnerID,
+		GranteeID:  g.GranteeID,
+		Permission: g.Permission,
+		ExpiresAt:  g.ExpiresAt,
+		CreatedAt:  g.CreatedAt,
+	}
+}
+
+func NewGrantRepository(collection, documents *mongo.Collection) *GrantRepository {
+	return &GrantRepository{
+		collection: collection,
+		documents:  documents,
+	}
+}
+
+func (r *GrantRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "document_id", Value: 1}, {Key: "grantee_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "grantee_id", Value: 1}, {Key: "owner_id", Value: 1}}},
+		{Keys: bson.D{{Key: "owner_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// activeFilter matches grants that have not expired yet. Expired grants are
+// removed by a TTL index, but only about once a minute.
+func activeFilter(filter bson.M, now time.Time) bson.M {
+	filter["$or"] = bson.A{
+		bson.M{"expires_at": bson.M{"$exists": false}},
+		bson.M{"expires_at": bson.M{"$gt": now}},
+	}
+	return filter
+}
+
+// Upsert creates the grant, or replaces the permission and expiry of the
+// existing grant for the same document and grantee. grant is updated with
+// the stored ID and creation time.
+func (r *GrantRepository) Upsert(ctx context.Context, grant *models.Grant) error {
+	update := bson.M{
+		"$set": bson.M{
+			"owner_id":   grant.OwnerID,
+			"permission": grant.Permission,
+		},
+		"$setOnInsert": bson.M{"created_at": grant.CreatedAt},
+	}
+	if grant.ExpiresAt != nil {
+		update["$set"].(bson.M)["expires_at"] = *grant.ExpiresAt
+	} else {
+		update["$unset"] = bson.M{"expires_at": ""}
+	}
+
+	var stored mongoGrant
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"document_id": grant.DocumentID, "grantee_id": grant.GranteeID},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&stored)
+	if err != nil {
+		return err
+	}
+
+	grant.ID = stored.ID.Hex()
+	grant.CreatedAt = stored.CreatedAt
+	return nil
+}
+
+func (r *GrantRepository) GetByDocumentID(ctx context.Context, documentID string) ([]*models.Grant, error) {
+	return r.find(ctx, activeFilter(bson.M{"document_id": documentID}, time.Now()))
+}
+
+func (r *GrantRepository) GetByGranteeID(ctx context.Context, granteeID string) ([]*models.Grant, error) {
+	return r.find(ctx, activeFilter(bson.M{"grantee_id": granteeID}, time.Now()))
+}
+
+func (r *GrantRepository) Delete(ctx context.Context, id, documentID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrGrantNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID, "document_id": documentID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.ErrGrantNotFound
+	}
+	return nil
+}
+
+// DeleteByUserID removes the grants a user gave and the ones they received.
+func (r *GrantRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"owner_id": userID},
+		bson.M{"grantee_id": userID},
+	}})
+	return err
+}
+
+// DeleteByDocumentID removes every grant on a document, for when the
+// document is deleted.
+func (r *GrantRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"document_id": documentID})
+	return err
+}
+
+// DocumentPermission returns the permission of the active grant on a
+// document, or "" when the grantee has none.
+func (r *GrantRepository) DocumentPermission(ctx context.Context, documentID, granteeID string) (string, error) {
+	var grant mongoGrant
+	err := r.collection.FindOne(
+		ctx,
+		activeFilter(bson.M{"document_id": documentID, "grantee_id": granteeID}, time.Now()),
+	).Decode(&grant)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return grant.Permission, nil
+}
+
+// FilePermission returns the strongest permission among the active grants
+// on documents of ownerID that have the file attached, or "" when there is
+// none. Documents may refer to the file with or without an extension.
+func (r *GrantRepository) FilePermission(ctx context.Context, ownerID, fileID, granteeID string) (string, error) {
+	grants, err := r.find(ctx, activeFilter(bson.M{"owner_id": ownerID, "grantee_id": granteeID}, time.Now()))
+	if err != nil || len(grants) == 0 {
+		return "", err
+	}
+
+	permissions := make(map[primitive.ObjectID]string, len(grants))
+	ids := make(bson.A, 0, len(grants))
+	for _, grant := range grants {
+		objectID, err := primitive.ObjectIDFromHex(grant.DocumentID)
+		if err != nil {
+			continue
+		}
+		permissions[objectID] = grant.Permission
+		ids = append(ids, objectID)
+	}
+
+	cursor, err := r.documents.Find(
+		ctx,
+		bson.M{
+			"_id":     bson.M{"$in": ids},
+			"user_id": ownerID,
+			"file":    primitive.Regex{Pattern: "^" + regexp.QuoteMeta(fileID) + `(\.[^.]*)?$`},
+		},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			logger.Error("failed to close cursor", err)
+		}
+	}()
+
+	strongest := ""
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return "", err
+		}
+		if permission := permissions[doc.ID]; strongest == "" || models.GrantAllows(permission, strongest) {
+			strongest = permission
+		}
+	}
+	return strongest, cursor.Err()
+}
+
+func (r *GrantRepository) find(ctx context.Context, filter bson.M) ([]*models.Grant, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			logger.Error("failed to close cursor", err)
+		}
+	}()
+
+	grants := []*models.Grant{}
+	for cursor.Next(ctx) {
+		var grant mongoGrant
+		if err := cursor.Decode(&grant); err != nil {
+			return nil, err
+		}
+		grants = append(grants, fromMongoGrant(grant))
+	}
+	return grants, cursor.Err()
+}
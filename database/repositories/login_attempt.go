@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// LoginAttemptRepository keeps failed login counters per key. A counter is
+// forgotten once expires_at passes without further failures or lockouts.
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoLoginAttempts struct {
+	Key           string    `bson:"_id"`
+	Failures      int       `bson:"failures"`
+	LastFailureAt time.Time `bson:"last_failure_at"`
+	LockedUntil   time.Time `bson:"locked_until,omitempty"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
+
+func fromMongoLoginAttempts(a mongoLoginAttempts) *models.LoginAttempts {
+	return &models.LoginAttempts{
+		Key:           a.Key,
+		Failures:      a.Failures,
+		LastFailureAt: a.LastFailureAt,
+		LockedUntil:   a.LockedUntil,
+	}
+}
+
+func NewLoginAttemptRepository(collection *mongo.Collection) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		collection: collection,
+	}
+}
+
+func (r *LoginAttemptRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Get returns the counter for key, or an empty one if there were no recent
+// failures.
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*models.LoginAttempts, error) {
+	var attempts mongoLoginAttempts
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&attempts)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &models.LoginAttempts{Key: key}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoLoginAttempts(attempts), nil
+}
+
+// RecordFailure atomically counts a failed login and returns the updated
+// counter. A counter that already expired but was not yet removed by the TTL
+// monitor starts over.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error) {
+	now := time.Now()
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "failures", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$expires_at", now}}},
+				bson.D{{Key: "$add", Value: bson.A{"$failures", 1}}},
+				1,
+			}}}},
+			{Key: "last_failure_at", Value: now},
+			{Key: "expires_at", Value: bson.D{{Key: "$max", Value: bson.A{
+				now.Add(window),
+				bson.D{{Key: "$ifNull", Value: bson.A{"$locked_until", now}}},
+			}}}},
+		}}},
+	}
+
+	var attempts mongoLoginAttempts
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": key},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoLoginAttempts(attempts), nil
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$set": bson.M{"locked_until": until},
+			"$max": bson.M{"expires_at": until},
+		},
+	)
+	return err
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
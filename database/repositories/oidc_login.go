@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// OIDCLoginRepository stores started OpenID Connect sign-ins keyed by the
+// hash of their state. Abandoned ones are removed by a TTL index.
+type OIDCLoginRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoOIDCLogin struct {
+	StateHash    string    `bson:"_id"`
+	Provider     string    `bson:"provider"`
+	Nonce        string    `bson:"nonce"`
+	CodeVerifier string    `bson:"code_verifier"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+	CreatedAt    time.Time `bson:"created_at"`
+}
+
+func NewOIDCLoginRepository(collection *mongo.Collection) *OIDCLoginRepository {
+	return &OIDCLoginRepository{
+		collection: collection,
+	}
+}
+
+func (r *OIDCLoginRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *OIDCLoginRepository) Create(ctx context.Context, login *models.OIDCLogin) error {
+	_, err := r.collection.InsertOne(ctx, mongoOIDCLogin{
+		StateHash:    login.StateHash,
+		Provider:     login.Provider,
+		Nonce:        login.Nonce,
+		CodeVerifier: login.CodeVerifier,
+		ExpiresAt:    login.ExpiresAt,
+		CreatedAt:    login.CreatedAt,
+	})
+	return err
+}
+
+// Consume deletes an unexpired sign-in and returns it, so every state can be
+// redeemed at most once.
+func (r *OIDCLoginRepository) Consume(ctx context.Context, stateHash string) (*models.OIDCLogin, error) {
+	var login mongoOIDCLogin
+	err := r.collection.FindOneAndDelete(ctx, bson.M{
+		"_id":        stateHash,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&login)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrInvalidOIDCLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCLogin{
+		StateHash:    login.StateHash,
+		Provider:     login.Provider,
+		Nonce:        login.Nonce,
+		CodeVerifier: login.CodeVerifier,
+		ExpiresAt:    login.ExpiresAt,
+		CreatedAt:    login.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// PasswordResetRepository stores password reset tokens keyed by their hash.
+// Expired tokens are removed by a TTL index.
+type PasswordResetRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoPasswordResetToken struct {
+	TokenHash string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewPasswordResetRepository(collection *mongo.Collection) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		collection: collection,
+	}
+}
+
+func (r *PasswordResetRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	_, err := r.collection.InsertOne(ctx, mongoPasswordResetToken{
+		TokenHash: token.TokenHash,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	})
+	return err
+}
+
+// Consume deletes an unexpired token and returns it, so every token can be
+// redeemed at most once even under concurrent requests.
+func (r *PasswordResetRepository) Consume(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token mongoPasswordResetToken
+	err := r.collection.FindOneAndDelete(ctx, bson.M{
+		"_id":        tokenHash,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PasswordResetToken{
+		TokenHash: token.TokenHash,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}, nil
+}
+
+func (r *PasswordResetRepository) DeleteAllForUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
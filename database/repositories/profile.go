@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+// ProfileRepository stores the patient profiles of accounts. Profiles are
+// always looked up together with the account that owns them.
+type ProfileRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoProfile struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	UserID       string             `bson:"user_id"`
+	Name         string             `bson:"name"`
+	DateOfBirth  string             `bson:"date_of_birth,omitempty"`
+	Relationship string             `bson:"relationship"`
+	Default      bool               `bson:"default"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at"`
+}
+
+func fromMongoProfile(p mongoProfile) *models.Profile {
+	return &models.Profile{
+		ID:           p.ID.Hex(),
+		UserID:       p.UserID,
+		Name:         p.Name,
+		DateOfBirth:  p.DateOfBirth,
+		Relationship: p.Relationship,
+		Default:      p.Default,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+	}
+}
+
+func NewProfileRepository(collection *mongo.Collection) *ProfileRepository {
+	return &ProfileRepository{
+		collection: collection,
+	}
+}
+
+func (r *ProfileRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{
+			// At most one default profile per account.
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "default", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"default": true}),
+		},
+	})
+	return err
+}
+
+func (r *ProfileRepository) Create(ctx context.Context, profile *models.Profile) error {
+	result, err := r.collection.InsertOne(ctx, mongoProfile{
+		UserID:       profile.UserID,
+		Name:         profile.Name,
+		DateOfBirth:  profile.DateOfBirth,
+		Relationship: profile.Relationship,
+		CreatedAt:    profile.CreatedAt,
+		UpdatedAt:    profile.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	profile.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// CreateDefault stores profile as the default profile of its account unless
+// the account already has one, and returns the default profile.
+func (r *ProfileRepository) CreateDefault(ctx context.Context, profile *models.Profile) (*models.Profile, error) {
+	filter := bson.M{"user_id": profile.UserID, "default": true}
+	update := bson.M{"$setOnInsert": bson.M{
+		"name":         profile.Name,
+		"relationship": profile.Relationship,
+		"created_at":   profile.CreatedAt,
+		"updated_at":   profile.UpdatedAt,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var created mongoProfile
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&created)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request created it first.
+		return r.GetDefault(ctx, profile.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoProfile(created), nil
+}
+
+func (r *ProfileRepository) GetDefault(ctx context.Context, userID string) (*models.Profile, error) {
+	return r.findOne(ctx, bson.M{"user_id": userID, "default": true})
+}
+
+func (r *ProfileRepository) GetByID(ctx context.Context, id, userID string) (*models.Profile, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrProfileNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": objectID, "user_id": userID})
+}
+
+func (r *ProfileRepository) findOne(ctx context.Context, filter bson.M) (*models.Profile, error) {
+	var profile mongoProfile
+	err := r.collection.FindOne(ctx, filter).Decode(&profile)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoProfile(profile), nil
+}
+
+// GetByUserID returns the profiles of an account, the default profile first.
+func (r *ProfileRepository) GetByUserID(ctx context.Context, userID string) ([]*models.Profile, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "default", Value: -1}, {Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	profiles := []*models.Profile{}
+	for cursor.Next(ctx) {
+		var profile mongoProfile
+		if err := cursor.Decode(&profile); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, fromMongoProfile(profile))
+	}
+	return profiles, cursor.Err()
+}
+
+func (r *ProfileRepository) Update(ctx context.Context, id, userID string, update models.ProfileUpdate) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrProfileNotFound
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.DateOfBirth != nil {
+		set["date_of_birth"] = *update.DateOfBirth
+	}
+	if update.Relationship != nil {
+		set["relationship"] = *update.Relationship
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "user_id": userID}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrProfileNotFound
+	}
+	return nil
+}
+
+// Delete removes a profile of the user other than the default one.
+func (r *ProfileRepository) Delete(ctx context.Context, id, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrProfileNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID, "user_id": userID, "default": bson.M{"$ne": true}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.ErrProfileNotFound
+	}
+	return nil
+}
+
+func (r *ProfileRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
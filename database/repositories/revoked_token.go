@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RevokedTokenRepository is a denylist of access token IDs. Entries are
+// removed by a TTL index once the token would have expired anyway.
+type RevokedTokenRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoRevokedToken struct {
+	ID        string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func NewRevokedTokenRepository(collection *mongo.Collection) *RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		collection: collection,
+	}
+}
+
+func (r *RevokedTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (r *RevokedTokenRepository) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": tokenID},
+		bson.M{"$set": mongoRevokedToken{ID: tokenID, ExpiresAt: expiresAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *RevokedTokenRepository) Exists(ctx context.Context, tokenID string) (bool, error) {
+	err := r.collection.FindOne(ctx, bson.M{"_id": tokenID}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
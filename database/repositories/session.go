@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+type SessionRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoSession struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	UserID        string             `bson:"user_id"`
+	TokenID       string             `bson:"token_id"`
+	AccessTokenID string             `bson:"access_token_id,omitempty"`
+	UserAgent     string             `bson:"user_agent,omitempty"`
+	IP            string             `bson:"ip,omitempty"`
+	ExpiresAt     time.Time          `bson:"expires_at"`
+	RevokedAt     *time.Time         `bson:"revoked_at,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at"`
+	LastUsedAt    time.Time          `bson:"last_used_at"`
+}
+
+func fromMongoSession(s mongoSession) *models.Session {
+	lastUsedAt := s.LastUsedAt
+	if lastUsedAt.IsZero() {
+		// Sessions started before last use was recorded.
+		lastUsedAt = s.UpdatedAt
+	}
+	return &models.Session{
+		ID:            s.ID.Hex(),
+		UserID:        s.UserID,
+		TokenID:       s.TokenID,
+		AccessTokenID: s.AccessTokenID,
+		UserAgent:     s.UserAgent,
+		IP:            s.IP,
+		ExpiresAt:     s.ExpiresAt,
+		RevokedAt:     s.RevokedAt,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+		LastUsedAt:    lastUsedAt,
+	}
+}
+
+func NewSessionRepository(collection *mongo.Collection) *SessionRepository {
+	return &SessionRepository{
+		collection: collection,
+	}
+}
+
+func (r *SessionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	mongoSession := mongoSession{
+		UserID:        session.UserID,
+		TokenID:       session.TokenID,
+		AccessTokenID: session.AccessTokenID,
+		UserAgent:     session.UserAgent,
+		IP:            session.IP,
+		ExpiresAt:     session.ExpiresAt,
+		CreatedAt:     session.CreatedAt,
+		UpdatedAt:     session.UpdatedAt,
+		LastUsedAt:    session.LastUsedAt,
+	}
+
+	result, err := r.collection.InsertOne(ctx, mongoSession)
+	if err != nil {
+		return err
+	}
+
+	session.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrSessionNotFound
+	}
+
+	var mongoSession mongoSession
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mongoSession)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoSession(mongoSession), nil
+}
+
+// Rotate replaces the current refresh token of an active session and records
+// the client that used it. The update only matches while currentTokenID is
+// still the latest one, so concurrent refreshes with the same token cannot
+// both succeed.
+func (r *SessionRepository) Rotate(ctx context.Context, id, currentTokenID string, rotation models.SessionRotation) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrSessionNotFound
+	}
+
+	now := time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":        objectID,
+			"token_id":   currentTokenID,
+			"revoked_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			"token_id":        rotation.TokenID,
+			"access_token_id": rotation.AccessTokenID,
+			"user_agent":      rotation.Client.UserAgent,
+			"ip":              rotation.Client.IP,
+			"expires_at":      rotation.ExpiresAt,
+			"updated_at":      now,
+			"last_used_at":    now,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrSessionNotFound
+	}
+	return nil
+}
+
+// GetActiveByUserID returns the sessions of the user that are neither
+// revoked nor expired, most recently used first.
+func (r *SessionRepository) GetActiveByUserID(ctx context.Context, userID string) ([]*models.Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []*models.Session{}
+	for cursor.Next(ctx) {
+		var session mongoSession
+		if err := cursor.Decode(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, fromMongoSession(session))
+	}
+	return sessions, cursor.Err()
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrSessionNotFound
+	}
+
+	now := time.Now()
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}},
+	)
+	return err
+}
+
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}},
+	)
+	return err
+}
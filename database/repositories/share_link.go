@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/gruzdev-dev/meddoc/app/errors"
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// ShareLinkRepository stores public share links. Links are removed by a TTL
+// index once they expire.
+type ShareLinkRepository struct {
+	collection *mongo.Collection
+}
+
+type mongoShareLink struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerID           string             `bson:"owner_id"`
+	DocumentIDs       []string           `bson:"document_ids"`
+	PINHash           string             `bson:"pin_hash,omitempty"`
+	ExpiresAt         time.Time          `bson:"expires_at"`
+	MaxUses           int                `bson:"max_uses"`
+	Views             int                `bson:"views"`
+	Downloads         int                `bson:"downloads"`
+	FailedPINAttempts int                `bson:"failed_pin_attempts"`
+	RevokedAt         *time.Time         `bson:"revoked_at,omitempty"`
+	CreatedAt         time.Time          `bson:"created_at"`
+}
+
+func fromMongoShareLink(l mongoShareLink) *models.ShareLink {
+	return &models.ShareLink{
+		ID:                l.ID.Hex(),
+		OwnerID:           l.OwnerID,
+		DocumentIDs:       l.DocumentIDs,
+		PINHash:           l.PINHash,
+		PINProtected:      l.PINHash != "",
+		ExpiresAt:         l.ExpiresAt,
+		MaxUses:           l.MaxUses,
+		Views:             l.Views,
+		Downloads:         l.Downloads,
+		FailedPINAttempts: l.FailedPINAttempts,
+		RevokedAt:         l.RevokedAt,
+		CreatedAt:         l.CreatedAt,
+	}
+}
+
+func NewShareLinkRepository(collection *mongo.Collection) *ShareLinkRepository {
+	return &ShareLinkRepository{
+		collection: collection,
+	}
+}
+
+func (r *ShareLinkRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *ShareLinkRepository) Create(ctx context.Context, link *models.ShareLink) error {
+	mongoLink := mongoShareLink{
+		OwnerID:     link.OwnerID,
+		DocumentIDs: link.DocumentIDs,
+		PINHash:     link.PINHash,
+		ExpiresAt:   link.ExpiresAt,
+		MaxUses:     link.MaxUses,
+		CreatedAt:   link.CreatedAt,
+	}
+
+	result, err := r.collection.InsertOne(ctx, mongoLink)
+	if err != nil {
+		return err
+	}
+
+	link.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *ShareLinkRepository) GetByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrShareLinkNotFound
+	}
+
+	var link mongoShareLink
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&link)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoShareLink(link), nil
+}
+
+func (r *ShareLinkRepository) GetByOwnerID(ctx context.Context, ownerID string) ([]*models.ShareLink, error) {
+	cursor, err := r.collection.Find(
+		ctx,
+		bson.M{"owner_id": ownerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			logger.Error("failed to close cursor", err)
+		}
+	}()
+
+	links := []*models.ShareLink{}
+	for cursor.Next(ctx) {
+		var link mongoShareLink
+		if err := cursor.Decode(&link); err != nil {
+			return nil, err
+		}
+		links = append(links, fromMongoShareLink(link))
+	}
+	return links, cursor.Err()
+}
+
+// DeleteByOwnerID removes every link the owner created.
+func (r *ShareLinkRepository) DeleteByOwnerID(ctx context.Context, ownerID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	return err
+}
+
+// RemoveDocument takes a deleted document out of every link it was shared
+// through. Links left without documents are deleted.
+func (r *ShareLinkRepository) RemoveDocument(ctx context.Context, documentID string) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"document_ids": documentID},
+		bson.M{"$pull": bson.M{"document_ids": documentID}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"document_ids": bson.M{"$size": 0}})
+	return err
+}
+
+// Revoke disables a link of ownerID. Links that are already revoked are
+// reported as not found.
+func (r *ShareLinkRepository) Revoke(ctx context.Context, id, ownerID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrShareLinkNotFound
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "owner_id": ownerID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// RecordView counts one use of a link. It fails with ErrShareLinkExpired
+// when the link was revoked, has expired or has been used MaxUses times,
+// checked in the same update so concurrent views cannot exceed the limit.
+func (r *ShareLinkRepository) RecordView(ctx context.Context, id string, now time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrShareLinkNotFound
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":        objectID,
+			"revoked_at": bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": now},
+			"$or": bson.A{
+				bson.M{"max_uses": 0},
+				bson.M{"$expr": bson.M{"$lt": bson.A{"$views", "$max_uses"}}},
+			},
+		},
+		bson.M{"$inc": bson.M{"views": 1}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrShareLinkExpired
+	}
+	return nil
+}
+
+// RecordDownload counts one download of a file of a link. It fails with
+// ErrShareLinkExpired when the link was revoked, has expired or limit
+// downloads were made already; a limit of 0 means unlimited.
+func (r *ShareLinkRepository) RecordDownload(ctx context.Context, id string, limit int, now time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrShareLinkNotFound
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	if limit > 0 {
+		filter["downloads"] = bson.M{"$lt": limit}
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"downloads": 1}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrShareLinkExpired
+	}
+	return nil
+}
+
+// AddFailedPINAttempt counts a wrong PIN and returns the number of wrong
+// PINs entered so far.
+func (r *ShareLinkRepository) AddFailedPINAttempt(ctx context.Context, id string) (int, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, apperrors.ErrShareLinkNotFound
+	}
+
+	var link mongoShareLink
+	err = r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$inc": bson.M{"failed_pin_attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&link)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, apperrors.ErrShareLinkNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return link.FailedPINAttempts, nil
+}
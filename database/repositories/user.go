@@ -10,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository struct {
@@ -17,12 +18,59 @@ type UserRepository struct {
 }
 
 type mongoUser struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Email     string             `bson:"email"`
-	Name      string             `bson:"name"`
-	Password  string             `bson:"password"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	Email            string             `bson:"email"`
+	Name             string             `bson:"name"`
+	Password         string             `bson:"password"`
+	EmailVerified    bool               `bson:"email_verified"`
+	Roles            []string           `bson:"roles,omitempty"`
+	TokensValidAfter time.Time          `bson:"tokens_valid_after,omitempty"`
+	MFA              mongoMFA           `bson:"mfa"`
+	Identities       []mongoIdentity    `bson:"identities,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at"`
+}
+
+type mongoIdentity struct {
+	Provider string `bson:"provider"`
+	Subject  string `bson:"subject"`
+}
+
+type mongoMFA struct {
+	Enabled       bool     `bson:"enabled"`
+	Secret        string   `bson:"secret,omitempty"`
+	PendingSecret string   `bson:"pending_secret,omitempty"`
+	RecoveryCodes []string `bson:"recovery_codes,omitempty"`
+	LastUsedStep  int64    `bson:"last_used_step"`
+}
+
+func fromMongoUser(u mongoUser) *models.User {
+	if len(u.Roles) == 0 {
+		u.Roles = models.DefaultRoles
+	}
+	var identities []models.ExternalIdentity
+	for _, identity := range u.Identities {
+		identities = append(identities, models.ExternalIdentity{Provider: identity.Provider, Subject: identity.Subject})
+	}
+	return &models.User{
+		ID:               u.ID.Hex(),
+		Email:            u.Email,
+		Name:             u.Name,
+		Password:         u.Password,
+		EmailVerified:    u.EmailVerified,
+		Roles:            u.Roles,
+		TokensValidAfter: u.TokensValidAfter,
+		MFA: models.MFASettings{
+			Enabled:       u.MFA.Enabled,
+			Secret:        u.MFA.Secret,
+			PendingSecret: u.MFA.PendingSecret,
+			RecoveryCodes: u.MFA.RecoveryCodes,
+			LastUsedStep:  u.MFA.LastUsedStep,
+		},
+		Identities: identities,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+	}
 }
 
 func NewUserRepository(collection *mongo.Collection) *UserRepository {
@@ -31,6 +79,19 @@ func NewUserRepository(collection *mongo.Collection) *UserRepository {
 	}
 }
 
+// EnsureIndexes makes every external identity belong to at most one user.
+func (r *UserRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"identities.subject": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	var existingUser mongoUser
 	err := r.collection.FindOne(ctx, bson.M{"email": user.Email}).Decode(&existingUser)
@@ -41,11 +102,16 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	}
 
 	mongoUser := mongoUser{
-		Email:     user.Email,
-		Name:      user.Name,
-		Password:  user.Password,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		Email:         user.Email,
+		Name:          user.Name,
+		Password:      user.Password,
+		EmailVerified: user.EmailVerified,
+		Roles:         user.Roles,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+	for _, identity := range user.Identities {
+		mongoUser.Identities = append(mongoUser.Identities, mongoIdentity{Provider: identity.Provider, Subject: identity.Subject})
 	}
 
 	result, err := r.collection.InsertOne(ctx, mongoUser)
@@ -67,34 +133,268 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 		return nil, err
 	}
 
-	return &models.User{
-		ID:        mongoUser.ID.Hex(),
-		Email:     mongoUser.Email,
-		Name:      mongoUser.Name,
-		Password:  mongoUser.Password,
-		CreatedAt: mongoUser.CreatedAt,
-		UpdatedAt: mongoUser.UpdatedAt,
-	}, nil
+	return fromMongoUser(mongoUser), nil
+}
+
+// GetByIdentity returns the user linked to the provider account subject.
+func (r *UserRepository) GetByIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	var mongoUser mongoUser
+	err := r.collection.FindOne(ctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}},
+	}).Decode(&mongoUser)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMongoUser(mongoUser), nil
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.ErrUserNotFound
 	}
 
 	var mongoUser mongoUser
 	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mongoUser)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apperrors.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.User{
-		ID:        mongoUser.ID.Hex(),
-		Email:     mongoUser.Email,
-		Name:      mongoUser.Name,
-		Password:  mongoUser.Password,
-		CreatedAt: mongoUser.CreatedAt,
-		UpdatedAt: mongoUser.UpdatedAt,
-	}, nil
+	return fromMongoUser(mongoUser), nil
+}
+
+// Update saves the user's profile fields. Passwords, tokens and two-factor
+// settings have dedicated methods.
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	objectID, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return err
+	}
+
+	user.UpdatedAt = time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{
+			"email":          user.Email,
+			"name":           user.Name,
+			"email_verified": user.EmailVerified,
+			"updated_at":     user.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) SetRoles(ctx context.Context, id string, roles []string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrUserNotFound
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{"roles": roles, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrUserNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) SetTokensValidAfter(ctx context.Context, id string, validAfter time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{"tokens_valid_after": validAfter, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) SetMFA(ctx context.Context, id string, mfa models.MFASettings) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{
+			"mfa": mongoMFA{
+				Enabled:       mfa.Enabled,
+				Secret:        mfa.Secret,
+				PendingSecret: mfa.PendingSecret,
+				RecoveryCodes: mfa.RecoveryCodes,
+				LastUsedStep:  mfa.LastUsedStep,
+			},
+			"updated_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode removes a recovery code so it can only be used once.
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, id, codeHash string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "mfa.enabled": true, "mfa.recovery_codes": codeHash},
+		bson.M{
+			"$pull": bson.M{"mfa.recovery_codes": codeHash},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return apperrors.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// ConsumeTOTPStep records the time step of an accepted TOTP code. It fails
+// when the same or a later step was already used, which rejects replays.
+func (r *UserRepository) ConsumeTOTPStep(ctx context.Context, id string, step int64) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "mfa.enabled": true, "mfa.last_used_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"mfa.last_used_step": step, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return apperrors.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{"password": passwordHash, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+// MarkEmailVerified verifies the user's address, but only while it is still
+// email, so a link for a previous address cannot verify a new one.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id, email string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "email": email},
+		bson.M{"$set": bson.M{"email_verified": true, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+// AddIdentity links an external account to the user. Linking an account that
+// already belongs to another user fails with ErrUserExists.
+func (r *UserRepository) AddIdentity(ctx context.Context, id string, identity models.ExternalIdentity) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.ErrUserNotFound
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{
+			"$addToSet": bson.M{"identities": mongoIdentity{Provider: identity.Provider, Subject: identity.Subject}},
+			"$set":      bson.M{"updated_at": time.Now()},
+		},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.ErrUserExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
 }
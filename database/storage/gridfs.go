@@ -68,3 +68,17 @@ func (s *GridFSStorage) Download(ctx context.Context, id string) (io.ReadCloser,
 
 	return stream, nil
 }
+
+// Delete removes the file and its chunks. Deleting a file that does not exist
+// is not an error, so interrupted deletions can be retried.
+func (s *GridFSStorage) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid file ID: %w", err)
+	}
+
+	if err := s.bucket.Delete(objectID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
@@ -8,14 +8,25 @@ import (
 
 	"github.com/gruzdev-dev/meddoc/app/handlers"
 	"github.com/gruzdev-dev/meddoc/app/server"
+	"github.com/gruzdev-dev/meddoc/app/services/account"
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
 	"github.com/gruzdev-dev/meddoc/app/services/document"
 	"github.com/gruzdev-dev/meddoc/app/services/file"
+	"github.com/gruzdev-dev/meddoc/app/services/grant"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+	"github.com/gruzdev-dev/meddoc/app/services/profile"
+	"github.com/gruzdev-dev/meddoc/app/services/sharelink"
 	"github.com/gruzdev-dev/meddoc/app/services/user"
 	"github.com/gruzdev-dev/meddoc/config"
 	"github.com/gruzdev-dev/meddoc/database"
+	"github.com/gruzdev-dev/meddoc/database/migrations"
 	"github.com/gruzdev-dev/meddoc/database/repositories"
 	dbstorage "github.com/gruzdev-dev/meddoc/database/storage"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
 	"github.com/gruzdev-dev/meddoc/pkg/logger"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
 	localstorage "github.com/gruzdev-dev/meddoc/pkg/storage"
 )
 
@@ -50,12 +61,100 @@ func main() {
 	}()
 
 	userRepo := repositories.NewUserRepository(mongoDB.Database().Collection("users"))
-	userService := user.NewUserServiceFromConfig(userRepo, cfg)
+	if err := userRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create user indexes", err)
+	}
+	sessionRepo := repositories.NewSessionRepository(mongoDB.Database().Collection("sessions"))
+	if err := sessionRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create session indexes", err)
+	}
+	revokedTokenRepo := repositories.NewRevokedTokenRepository(mongoDB.Database().Collection("revoked_tokens"))
+	if err := revokedTokenRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create revoked token indexes", err)
+	}
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(mongoDB.Database().Collection("login_attempts"))
+	if err := loginAttemptRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create login attempt indexes", err)
+	}
+	resetTokenRepo := repositories.NewPasswordResetRepository(mongoDB.Database().Collection("password_reset_tokens"))
+	if err := resetTokenRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create password reset indexes", err)
+	}
+	accessTokenRepo := repositories.NewAccessTokenRepository(mongoDB.Database().Collection("access_tokens"))
+	if err := accessTokenRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create access token indexes", err)
+	}
+	oidcLoginRepo := repositories.NewOIDCLoginRepository(mongoDB.Database().Collection("oidc_logins"))
+	if err := oidcLoginRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create oidc login indexes", err)
+	}
+	auditRepo := repositories.NewAuditRepository(mongoDB.Database().Collection("audit_events"))
+	if err := auditRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create audit indexes", err)
+	}
+	auditCheckpointRepo := repositories.NewAuditCheckpointRepository(mongoDB.Database().Collection("audit_checkpoints"))
+	if err := auditCheckpointRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create audit checkpoint indexes", err)
+	}
+	deviceRepo := repositories.NewDeviceRepository(mongoDB.Database().Collection("devices"))
+	if err := deviceRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create device indexes", err)
+	}
+	keys := keyring.NewHMAC([]byte(cfg.Auth.Secret))
+	if cfg.Auth.KeysDir != "" {
+		keys, err = keyring.Load(cfg.Auth.KeysDir, cfg.Auth.SigningKeyID)
+		if err != nil {
+			logger.Fatal("failed to load signing keys", err)
+		}
+	}
+	auditService := audit.NewService(audit.Dependencies{
+		Events:      auditRepo,
+		Checkpoints: auditCheckpointRepo,
+		Keys:        keys,
+	}, audit.Config{
+		CheckpointInterval: cfg.Audit.CheckpointInterval,
+	})
+	var mail user.Mailer
+	if cfg.Mail.SMTP.Host != "" {
+		mail = mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Mail.SMTP.Password,
+			From:     cfg.Mail.From,
+		})
+	} else {
+		mail, err = mailer.NewFile(cfg.Mail.Dir, cfg.Mail.From)
+		if err != nil {
+			logger.Fatal("failed to create file mailer", err)
+		}
+	}
+	userService, err := user.NewUserServiceFromConfig(user.Dependencies{
+		Users:         userRepo,
+		Sessions:      sessionRepo,
+		RevokedTokens: revokedTokenRepo,
+		ResetTokens:   resetTokenRepo,
+		LoginAttempts: loginAttemptRepo,
+		AccessTokens:  accessTokenRepo,
+		Devices:       deviceRepo,
+		Mailer:        mail,
+		LoginNotifier: user.NewMailLoginNotifier(mail),
+		Audit:         auditService,
+	}, keys, cfg)
+	if err != nil {
+		logger.Fatal("failed to create user service", err)
+	}
 
-	documentRepo := repositories.NewDocumentRepository(mongoDB.Database().Collection("documents"))
-	documentService := document.NewService(documentRepo)
+	grantRepo := repositories.NewGrantRepository(mongoDB.Database().Collection("grants"), mongoDB.Database().Collection("documents"))
+	if err := grantRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create grant indexes", err)
+	}
+	accessPolicy := policy.New(policy.DefaultPermissions, grantRepo)
 
 	fileRepo := repositories.NewFileRepository(mongoDB.Database().Collection("files"))
+	if err := fileRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create file indexes", err)
+	}
 
 	localStorage, err := localstorage.NewLocal("storage/files")
 	if err != nil {
@@ -67,9 +166,107 @@ func main() {
 		logger.Fatal("failed to create grid storage", err)
 	}
 
-	fileService := file.NewService(fileRepo, localStorage, gridStorage)
+	documentRepo := repositories.NewDocumentRepository(mongoDB.Database().Collection("documents"))
+	if err := documentRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create document indexes", err)
+	}
+	profileRepo := repositories.NewProfileRepository(mongoDB.Database().Collection("profiles"))
+	if err := profileRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create profile indexes", err)
+	}
+	if err := migrations.Run(context.Background(), mongoDB.Database(), migrations.All); err != nil {
+		logger.Fatal("failed to migrate database", err)
+	}
+	profileService := profile.NewService(profile.Dependencies{
+		Profiles:  profileRepo,
+		Documents: documentRepo,
+		Files:     fileRepo,
+		Users:     userService,
+	})
+
+	breakGlassRepo := repositories.NewBreakGlassRepository(mongoDB.Database().Collection("break_glass"))
+	if err := breakGlassRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create break-glass indexes", err)
+	}
+	fileService := file.NewService(fileRepo, localStorage, gridStorage, profileService, accessPolicy, auditService, breakGlassRepo)
+	commentRepo := repositories.NewCommentRepository(mongoDB.Database().Collection("comments"))
+	if err := commentRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create comment indexes", err)
+	}
+	shareLinkRepo := repositories.NewShareLinkRepository(mongoDB.Database().Collection("share_links"))
+	if err := shareLinkRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create share link indexes", err)
+	}
+	documentService := document.NewService(document.Dependencies{
+		Documents:  documentRepo,
+		Comments:   commentRepo,
+		Grants:     grantRepo,
+		ShareLinks: shareLinkRepo,
+		Files:      fileService,
+		Profiles:   profileService,
+		Policy:     accessPolicy,
+		Audit:      auditService,
+		BreakGlass: breakGlassRepo,
+		Users:      userService,
+		Mailer:     mail,
+	}, document.Config{
+		BreakGlassTTL: cfg.Sharing.BreakGlass.TTL,
+	})
+	grantService := grant.NewService(grantRepo, documentRepo, userRepo, accessPolicy)
+
+	shareLinkService, err := sharelink.NewServiceFromConfig(sharelink.Dependencies{
+		Links:     shareLinkRepo,
+		Documents: documentRepo,
+		Files:     fileService,
+		Policy:    accessPolicy,
+	}, cfg)
+	if err != nil {
+		logger.Fatal("failed to create share link service", err)
+	}
+
+	delegationRepo := repositories.NewDelegationRepository(mongoDB.Database().Collection("delegations"))
+	if err := delegationRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create delegation indexes", err)
+	}
+	delegationService := delegation.NewServiceFromConfig(delegation.Dependencies{
+		Delegations: delegationRepo,
+		Users:       userService,
+		Mailer:      mail,
+	}, cfg)
+
+	deletionRepo := repositories.NewAccountDeletionRepository(mongoDB.Database().Collection("account_deletions"))
+	if err := deletionRepo.EnsureIndexes(ctx); err != nil {
+		logger.Fatal("failed to create account deletion indexes", err)
+	}
+	accountService := account.NewService(account.Dependencies{
+		Deletions:   deletionRepo,
+		Users:       userService,
+		Documents:   documentService,
+		Grants:      grantService,
+		Files:       fileService,
+		Profiles:    profileService,
+		Delegations: delegationService,
+		ShareLinks:  shareLinkService,
+		Mailer:      mail,
+	}, account.Config{
+		GracePeriod:     cfg.Account.Deletion.GracePeriod,
+		PollInterval:    cfg.Account.Deletion.PollInterval,
+		Lease:           cfg.Account.Deletion.Lease,
+		ConfirmationURL: cfg.Account.Deletion.URL,
+		ConfirmationTTL: cfg.Account.Deletion.ConfirmationTTL,
+	})
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go accountService.Run(workerCtx)
+	go auditService.Run(workerCtx)
+
+	oidcService := oidc.NewServiceFromConfig(oidc.Dependencies{
+		Logins: oidcLoginRepo,
+		Users:  userService,
+	}, cfg)
 
-	handlers := handlers.NewHandlers(userService, documentService, fileService)
+	handlers := handlers.NewHandlers(userService, documentService, fileService, accountService, grantService, shareLinkService, oidcService, profileService, delegationService, auditService)
 
 	srv := server.NewServer(cfg, handlers)
 	if err := srv.Start(); err != nil {
@@ -0,0 +1,174 @@
+// Package fulltext picks the language of search queries and highlights the
+// words of a text that match one. Words are compared by their Snowball
+// stems, the way MongoDB's text index compares them, so a query for
+// "reports" highlights "report" and "кардиолог" highlights "кардиологии".
+package fulltext
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/snowballstem"
+	"github.com/blevesearch/snowballstem/english"
+	"github.com/blevesearch/snowballstem/russian"
+)
+
+// Languages text is indexed in, named as MongoDB names them.
+const (
+	English = "english"
+	Russian = "russian"
+)
+
+// Languages lists every language text is indexed in.
+var Languages = []string{English, Russian}
+
+const (
+	// snippetLength is how many characters of a long text a snippet shows.
+	snippetLength = 200
+	// snippetLead is how many characters before the first match a snippet
+	// starts.
+	snippetLead = 60
+	ellipsis    = "…"
+)
+
+// Language returns the language a query is most likely written in: Russian
+// when it has more Cyrillic than Latin letters, English otherwise.
+func Language(query string) string {
+	cyrillic, latin := 0, 0
+	for _, r := range query {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if cyrillic > latin {
+		return Russian
+	}
+	return English
+}
+
+// Terms returns the stems of the words of query that documents are meant to
+// contain. Words excluded with a leading minus are left out.
+func Terms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, w := range words(field) {
+			stem := Stem(field[w.start:w.end])
+			if !seen[stem] {
+				seen[stem] = true
+				terms = append(terms, stem)
+			}
+		}
+	}
+	return terms
+}
+
+// Stem returns the lower-cased stem of a word, using the Russian stemmer for
+// Cyrillic words and the English one for the rest.
+func Stem(word string) string {
+	word = strings.ToLower(word)
+	env := snowballstem.NewEnv(word)
+	if strings.IndexFunc(word, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }) >= 0 {
+		russian.Stem(env)
+	} else {
+		english.Stem(env)
+	}
+	return env.Current()
+}
+
+// Highlight returns an excerpt of text with the words whose stems are among
+// terms wrapped in <mark> tags, and false when no word matches. The rest of
+// the excerpt is HTML-escaped, so it can be shown as HTML as it is. Texts
+// longer than a snippet are cut around the first match.
+func Highlight(text string, terms []string) (string, bool) {
+	var matches []span
+	for _, w := range words(text) {
+		stem := Stem(text[w.start:w.end])
+		for _, term := range terms {
+			if stem == term {
+				matches = append(matches, w)
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	start, end := excerpt(text, matches[0].start)
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := start
+	for _, m := range matches {
+		if m.start < start || m.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:m.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString("</mark>")
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString(ellipsis)
+	}
+	return b.String(), true
+}
+
+// span is a word of a text, as byte offsets.
+type span struct {
+	start, end int
+}
+
+// words splits text into runs of letters and digits.
+func words(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+	return spans
+}
+
+// excerpt returns the byte range of text a snippet shows: all of a short
+// text, or about snippetLength characters starting a little before the match
+// at byte offset at. The range does not cut words.
+func excerpt(text string, at int) (int, int) {
+	runes := []rune(text)
+	if len(runes) <= snippetLength {
+		return 0, len(text)
+	}
+
+	first := len([]rune(text[:at]))
+	from := max(0, first-snippetLead)
+	to := min(len(runes), from+snippetLength)
+	from = max(0, to-snippetLength)
+	// Move the edges outwards to the nearest space so no word is cut.
+	for from > 0 && !unicode.IsSpace(runes[from-1]) {
+		from--
+	}
+	for to < len(runes) && !unicode.IsSpace(runes[to]) {
+		to++
+	}
+	return len(string(runes[:from])), len(string(runes[:to]))
+}
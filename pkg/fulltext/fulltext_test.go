@@ -0,0 +1,78 @@
+package fulltext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguage(t *testing.T) {
+	assert.Equal(t, English, Language("cardiology report"))
+	assert.Equal(t, Russian, Language("заключение кардиолога"))
+	assert.Equal(t, Russian, Language("ЭКГ ECG заключение"))
+	assert.Equal(t, English, Language("2022"))
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"cardiolog", "report"}, Terms("Cardiology reports report"))
+	assert.Equal(t, []string{"анализ", "кров"}, Terms("анализы крови"))
+	assert.Equal(t, []string{"mri"}, Terms("MRI -knee"))
+	assert.Empty(t, Terms("  ,. "))
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		query   string
+		want    string
+		matched bool
+	}{
+		{
+			name:    "stemmed English",
+			text:    "Reports of the cardiologist",
+			query:   "report",
+			want:    "<mark>Reports</mark> of the cardiologist",
+			matched: true,
+		},
+		{
+			name:    "stemmed Russian",
+			text:    "Результаты анализов крови",
+			query:   "анализ крови",
+			want:    "Результаты <mark>анализов</mark> <mark>крови</mark>",
+			matched: true,
+		},
+		{
+			name:    "escapes the text",
+			text:    "<b>MRI</b> & more",
+			query:   "mri",
+			want:    "&lt;b&gt;<mark>MRI</mark>&lt;/b&gt; &amp; more",
+			matched: true,
+		},
+		{
+			name:  "no match",
+			text:  "Blood test",
+			query: "x-ray",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matched := Highlight(tt.text, Terms(tt.query))
+			assert.Equal(t, tt.matched, matched)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHighlight_LongText(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 50) + "echocardiogram shows normal function " + strings.Repeat("dolor sit ", 50)
+
+	got, matched := Highlight(text, Terms("echocardiogram"))
+	assert.True(t, matched)
+	assert.True(t, strings.HasPrefix(got, "…lorem") || strings.HasPrefix(got, "…ipsum"), got)
+	assert.True(t, strings.HasSuffix(got, "…"), got)
+	assert.Contains(t, got, "<mark>echocardiogram</mark> shows normal function")
+	assert.Less(t, len([]rune(got)), snippetLength+len("<mark></mark>")+20)
+}
@@ -0,0 +1,215 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+	AlgHS256 = "HS256"
+
+	hmacKeyID = "default"
+)
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+type Key struct {
+	ID        string
+	Algorithm string
+	// Private is nil for keys that are only kept around to verify tokens
+	// signed before a rotation.
+	Private any
+	Public  any
+}
+
+func (k *Key) CanSign() bool {
+	return k.Private != nil
+}
+
+// KeyRing holds every key tokens may be verified with and the one new tokens
+// are signed with. Keys are looked up by their "kid".
+type KeyRing struct {
+	keys   map[string]*Key
+	active *Key
+}
+
+// NewHMAC returns a key ring with a single shared secret. It exists for
+// deployments that have not provisioned asymmetric keys yet; such keys are
+// never published in the JWKS.
+func NewHMAC(secret []byte) *KeyRing {
+	key := &Key{
+		ID:        hmacKeyID,
+		Algorithm: AlgHS256,
+		Private:   secret,
+		Public:    secret,
+	}
+	return &KeyRing{
+		keys:   map[string]*Key{key.ID: key},
+		active: key,
+	}
+}
+
+// Load reads every *.pem file in dir. The file name without extension becomes
+// the key ID. Private keys (PKCS#8 Ed25519/RSA or PKCS#1 RSA) can sign and
+// verify, public keys only verify. New tokens are signed with activeID, or
+// with the private key whose ID sorts last when activeID is empty, so naming
+// key files by date rotates to the newest one.
+func Load(dir, activeID string) (*KeyRing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	sort.Strings(paths)
+
+	ring := &KeyRing{keys: make(map[string]*Key)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+		}
+
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := parseKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+		}
+
+		ring.keys[id] = key
+		if activeID == "" && key.CanSign() {
+			ring.active = key
+		}
+	}
+
+	if activeID != "" {
+		ring.active = ring.keys[activeID]
+	}
+	if ring.active == nil || !ring.active.CanSign() {
+		return nil, fmt.Errorf("no private signing key in %s: %w", dir, ErrKeyNotFound)
+	}
+
+	return ring, nil
+}
+
+func parseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgEdDSA, Private: k, Public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Algorithm: AlgEdDSA, Public: k}, nil
+	case *rsa.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgRS256, Private: k, Public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: id, Algorithm: AlgRS256, Public: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+func (r *KeyRing) SigningKey() *Key {
+	return r.active
+}
+
+func (r *KeyRing) Key(id string) (*Key, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *KeyRing) Algorithms() []string {
+	seen := make(map[string]struct{})
+	var algs []string
+	for _, key := range r.keys {
+		if _, ok := seen[key.Algorithm]; ok {
+			continue
+		}
+		seen[key.Algorithm] = struct{}{}
+		algs = append(algs, key.Algorithm)
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every asymmetric key in the ring.
+func (r *KeyRing) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	ids := make([]string, 0, len(r.keys))
+	for id := range r.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		key := r.keys[id]
+		jwk := JWK{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+		switch pub := key.Public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
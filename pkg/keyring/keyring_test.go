@@ -0,0 +1,218 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	ed25519 ed25519.PrivateKey
+	rsa     *rsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testKeys{ed25519: edKey, rsa: rsaKey}
+}
+
+func pemPKCS8(t *testing.T, key any) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func pemPKIX(t *testing.T, key any) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func writeKeys(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	keys := newTestKeys(t)
+	_, olderEd25519, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaPKCS1 := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(keys.rsa)}))
+
+	tests := []struct {
+		name          string
+		files         map[string]string
+		activeID      string
+		wantActive    string
+		wantAlgorithm string
+		verifyOnly    []string
+		wantErr       error
+	}{
+		{
+			name: "newest private key signs",
+			files: map[string]string{
+				"2024-01.pem": pemPKCS8(t, olderEd25519),
+				"2025-01.pem": pemPKCS8(t, keys.ed25519),
+				"notes.txt":   "not a key",
+			},
+			wantActive:    "2025-01",
+			wantAlgorithm: AlgEdDSA,
+		},
+		{
+			name: "configured key signs",
+			files: map[string]string{
+				"2024-01.pem": pemPKCS8(t, olderEd25519),
+				"2025-01.pem": pemPKCS8(t, keys.ed25519),
+			},
+			activeID:      "2024-01",
+			wantActive:    "2024-01",
+			wantAlgorithm: AlgEdDSA,
+		},
+		{
+			name: "rotated public key is kept for verification",
+			files: map[string]string{
+				"2024-01.pem": pemPKIX(t, olderEd25519.Public()),
+				"2025-01.pem": pemPKCS8(t, keys.ed25519),
+			},
+			wantActive:    "2025-01",
+			wantAlgorithm: AlgEdDSA,
+			verifyOnly:    []string{"2024-01"},
+		},
+		{
+			name:          "RSA in PKCS#1",
+			files:         map[string]string{"rsa.pem": rsaPKCS1},
+			wantActive:    "rsa",
+			wantAlgorithm: AlgRS256,
+		},
+		{
+			name:          "RSA in PKCS#8",
+			files:         map[string]string{"rsa.pem": pemPKCS8(t, keys.rsa)},
+			wantActive:    "rsa",
+			wantAlgorithm: AlgRS256,
+		},
+		{
+			name:     "configured key is only public",
+			files:    map[string]string{"2024-01.pem": pemPKIX(t, olderEd25519.Public()), "2025-01.pem": pemPKCS8(t, keys.ed25519)},
+			activeID: "2024-01",
+			wantErr:  ErrKeyNotFound,
+		},
+		{
+			name:     "configured key is missing",
+			files:    map[string]string{"2025-01.pem": pemPKCS8(t, keys.ed25519)},
+			activeID: "2026-01",
+			wantErr:  ErrKeyNotFound,
+		},
+		{
+			name:    "no private key",
+			files:   map[string]string{"2024-01.pem": pemPKIX(t, olderEd25519.Public())},
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name:    "empty directory",
+			files:   map[string]string{},
+			wantErr: ErrKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := Load(writeKeys(t, tt.files), tt.activeID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, ring)
+				return
+			}
+			require.NoError(t, err)
+
+			active := ring.SigningKey()
+			assert.Equal(t, tt.wantActive, active.ID)
+			assert.Equal(t, tt.wantAlgorithm, active.Algorithm)
+			assert.True(t, active.CanSign())
+
+			for _, id := range tt.verifyOnly {
+				key, err := ring.Key(id)
+				require.NoError(t, err)
+				assert.False(t, key.CanSign())
+				assert.NotNil(t, key.Public)
+			}
+
+			_, err = ring.Key("unknown")
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		})
+	}
+}
+
+func TestLoad_InvalidKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "not PEM", content: "not a key"},
+		{name: "unsupported block", content: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}))},
+		{name: "corrupt key", content: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1, 2, 3}}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeKeys(t, map[string]string{"bad.pem": tt.content}), "")
+			assert.ErrorContains(t, err, "failed to parse key")
+		})
+	}
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	ring, err := Load(writeKeys(t, map[string]string{
+		"a-ed25519.pem": pemPKIX(t, keys.ed25519.Public()),
+		"b-rsa.pem":     pemPKCS8(t, keys.rsa),
+	}), "b-rsa")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{AlgEdDSA, AlgRS256}, ring.Algorithms())
+	assert.Equal(t, JWKS{Keys: []JWK{
+		{
+			KeyType:   "OKP",
+			KeyID:     "a-ed25519",
+			Algorithm: AlgEdDSA,
+			Use:       "sig",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(keys.ed25519.Public().(ed25519.PublicKey)),
+		},
+		{
+			KeyType:   "RSA",
+			KeyID:     "b-rsa",
+			Algorithm: AlgRS256,
+			Use:       "sig",
+			N:         base64.RawURLEncoding.EncodeToString(keys.rsa.N.Bytes()),
+			E:         "AQAB",
+		},
+	}}, ring.JWKS())
+}
+
+func TestNewHMAC(t *testing.T) {
+	ring := NewHMAC([]byte("secret"))
+
+	key := ring.SigningKey()
+	assert.Equal(t, AlgHS256, key.Algorithm)
+	assert.True(t, key.CanSign())
+	assert.Equal(t, []string{AlgHS256}, ring.Algorithms())
+
+	// Shared secrets are never published.
+	assert.Empty(t, ring.JWKS().Keys)
+}
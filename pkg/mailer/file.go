@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruzdev-dev/meddoc/pkg/logger"
+)
+
+// File writes every message to its own .eml file instead of sending it. It is
+// meant for development and tests, where the files can be opened directly.
+type File struct {
+	dir  string
+	from string
+}
+
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &File{dir: dir, from: from}, nil
+}
+
+func (m *File) Send(ctx context.Context, msg Message) error {
+	recipient := strings.NewReplacer("/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, format(m.from, msg), 0600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	logger.Info("mail written to file", "to", msg.To, "subject", msg.Subject, "path", path)
+	return nil
+}
@@ -0,0 +1,168 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+const (
+	saltLength = 16
+	keyLength  = 32
+)
+
+var (
+	ErrMismatchedPassword = errors.New("password does not match")
+	ErrUnknownHash        = errors.New("unknown password hash format")
+)
+
+// Argon2Params are the argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// DefaultArgon2Params follow the second recommended option of RFC 9106.
+var DefaultArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// Hasher hashes passwords with one configured algorithm and verifies hashes
+// made with any supported one. Hashes describe their algorithm and
+// parameters, so stored hashes stay verifiable when the configuration
+// changes, and NeedsRehash tells which ones no longer match it.
+//
+// Argon2id hashes use the PHC string format
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>; bcrypt
+// hashes are stored as bcrypt produces them.
+type Hasher struct {
+	algorithm  string
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+func NewArgon2id(params Argon2Params) *Hasher {
+	return &Hasher{algorithm: Argon2id, argon2: params}
+}
+
+func NewBcrypt(cost int) *Hasher {
+	return &Hasher{algorithm: Bcrypt, bcryptCost: cost}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.argon2.Time, h.argon2.Memory, h.argon2.Threads, keyLength)
+	return encodeArgon2(h.argon2, salt, key), nil
+}
+
+// Verify returns nil when password matches hash, ErrMismatchedPassword when
+// it does not and ErrUnknownHash when hash was not made by a supported
+// algorithm.
+func (h *Hasher) Verify(hash, password string) error {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, salt, key, err := decodeArgon2(hash)
+		if err != nil {
+			return err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return ErrMismatchedPassword
+		}
+		return nil
+	case isBcrypt(hash):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedPassword
+		}
+		if err != nil {
+			return ErrUnknownHash
+		}
+		return nil
+	default:
+		return ErrUnknownHash
+	}
+}
+
+// NeedsRehash reports whether hash was made with another algorithm or other
+// parameters than the hasher uses.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	switch h.algorithm {
+	case Bcrypt:
+		if !isBcrypt(hash) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.bcryptCost
+	default:
+		if !strings.HasPrefix(hash, "$argon2id$") {
+			return true
+		}
+		params, _, key, err := decodeArgon2(hash)
+		return err != nil || params != h.argon2 || len(key) != keyLength
+	}
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func encodeArgon2(params Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2(hash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+	if params.Time == 0 || params.Threads == 0 {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	return params, salt, key, nil
+}
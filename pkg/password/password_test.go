@@ -0,0 +1,97 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testArgon2Params = Argon2Params{Time: 1, Memory: 1024, Threads: 1}
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		hasher *Hasher
+		prefix string
+	}{
+		{name: "argon2id", hasher: NewArgon2id(testArgon2Params), prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "bcrypt", hasher: NewBcrypt(bcrypt.MinCost), prefix: "$2a$04$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := tt.hasher.Hash("password123")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, tt.prefix), hash)
+
+			assert.NoError(t, tt.hasher.Verify(hash, "password123"))
+			assert.ErrorIs(t, tt.hasher.Verify(hash, "password124"), ErrMismatchedPassword)
+
+			other, err := tt.hasher.Hash("password123")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other, "hashes are salted")
+		})
+	}
+}
+
+func TestHasher_VerifyAcrossAlgorithms(t *testing.T) {
+	argon := NewArgon2id(testArgon2Params)
+	bcryptHasher := NewBcrypt(bcrypt.MinCost)
+
+	bcryptHash, err := bcryptHasher.Hash("password123")
+	require.NoError(t, err)
+	assert.NoError(t, argon.Verify(bcryptHash, "password123"))
+
+	argonHash, err := argon.Hash("password123")
+	require.NoError(t, err)
+	assert.NoError(t, bcryptHasher.Verify(argonHash, "password123"))
+}
+
+func TestHasher_VerifyMalformed(t *testing.T) {
+	hasher := NewArgon2id(testArgon2Params)
+
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+		"$2a$04$short",
+	} {
+		assert.ErrorIs(t, hasher.Verify(hash, "password123"), ErrUnknownHash, hash)
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	argon := NewArgon2id(testArgon2Params)
+	argonHash, err := argon.Hash("password123")
+	require.NoError(t, err)
+	bcryptHash, err := NewBcrypt(bcrypt.MinCost).Hash("password123")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		hasher   *Hasher
+		hash     string
+		expected bool
+	}{
+		{name: "same argon2id parameters", hasher: argon, hash: argonHash, expected: false},
+		{name: "other argon2id memory", hasher: NewArgon2id(Argon2Params{Time: 1, Memory: 2048, Threads: 1}), hash: argonHash, expected: true},
+		{name: "other argon2id time", hasher: NewArgon2id(Argon2Params{Time: 2, Memory: 1024, Threads: 1}), hash: argonHash, expected: true},
+		{name: "bcrypt hash with argon2id configured", hasher: argon, hash: bcryptHash, expected: true},
+		{name: "same bcrypt cost", hasher: NewBcrypt(bcrypt.MinCost), hash: bcryptHash, expected: false},
+		{name: "higher bcrypt cost", hasher: NewBcrypt(bcrypt.MinCost + 1), hash: bcryptHash, expected: true},
+		{name: "argon2id hash with bcrypt configured", hasher: NewBcrypt(bcrypt.MinCost), hash: argonHash, expected: true},
+		{name: "unknown hash", hasher: argon, hash: "plaintext", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.hasher.NeedsRehash(tt.hash))
+		})
+	}
+}
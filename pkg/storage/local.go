@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -40,6 +41,16 @@ func (s *Local) Upload(ctx context.Context, id string, reader io.Reader) error {
 	return nil
 }
 
+// Delete removes the file. Deleting a file that does not exist is not an
+// error, so interrupted deletions can be retried.
+func (s *Local) Delete(ctx context.Context, id string) error {
+	filePath := filepath.Join(s.basePath, id)
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
 func (s *Local) Download(ctx context.Context, id string) (io.ReadCloser, error) {
 	filePath := filepath.Join(s.basePath, id)
 	file, err := os.Open(filePath)
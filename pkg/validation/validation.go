@@ -0,0 +1,198 @@
+// Package validation checks struct fields against their `binding` tags.
+//
+// Supported rules are required, omitempty, email, date (YYYY-MM-DD), min=N,
+// max=N and oneof=a b c. min and max compare the length of strings (in
+// characters), slices and maps, and the value of numbers. Pointer fields are
+// only checked when set, so optional fields of partial updates can still
+// carry rules.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const tagName = "binding"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Struct validates v, a struct or a pointer to one. It returns Errors with
+// one entry per invalid field, or nil.
+func Struct(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: %T is not a struct", v))
+	}
+
+	var errs Errors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *Errors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		value := rv.Field(i)
+
+		if tag, ok := field.Tag.Lookup(tagName); ok {
+			if fe := validateField(value, tag); fe != nil {
+				fe.Field = name
+				*errs = append(*errs, *fe)
+				continue
+			}
+		}
+
+		value = reflect.Indirect(value)
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			validateStruct(value, name+".", errs)
+		}
+	}
+}
+
+// fieldName reports fields by their JSON name, which is what clients see.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func validateField(value reflect.Value, tag string) *FieldError {
+	rules := strings.Split(tag, ",")
+
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if hasRule(rules, "required") {
+				return &FieldError{Rule: "required", Message: "is required"}
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if hasRule(rules, "omitempty") && value.IsZero() {
+		return nil
+	}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		if msg := check(name, param, value); msg != "" {
+			return &FieldError{Rule: name, Message: msg}
+		}
+	}
+	return nil
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// check applies a single rule and returns a message describing the failure,
+// or "" when the value passes.
+func check(rule, param string, value reflect.Value) string {
+	switch rule {
+	case "omitempty":
+		return ""
+	case "required":
+		if (value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "") || value.IsZero() {
+			return "is required"
+		}
+	case "email":
+		if !isEmail(value.String()) {
+			return "must be a valid email address"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value.String()); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+	case "min":
+		n := intParam(rule, param)
+		if size, unit := measure(value); size < n {
+			return fmt.Sprintf("must be at least %d%s", n, unit)
+		}
+	case "max":
+		n := intParam(rule, param)
+		if size, unit := measure(value); size > n {
+			return fmt.Sprintf("must be at most %d%s", n, unit)
+		}
+	case "oneof":
+		options := strings.Fields(param)
+		actual := fmt.Sprint(value.Interface())
+		for _, option := range options {
+			if actual == option {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	default:
+		panic(fmt.Sprintf("validation: unknown rule %q", rule))
+	}
+	return ""
+}
+
+func isEmail(s string) bool {
+	if len(s) > 254 {
+		return false
+	}
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+func intParam(rule, param string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid parameter %q for %s", param, rule))
+	}
+	return n
+}
+
+// measure returns what min and max compare for a value, and the unit to name
+// in messages.
+func measure(value reflect.Value) (int, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(value.String()), " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len(), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(value.Uint()), ""
+	default:
+		panic(fmt.Sprintf("validation: min/max not supported for %s", value.Kind()))
+	}
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type request struct {
+	Email    string            `json:"email" binding:"required,email"`
+	Password string            `json:"password" binding:"required,min=8"`
+	Date     string            `json:"date" binding:"omitempty,date"`
+	Title    *string           `json:"title,omitempty" binding:"min=1,max=5"`
+	Priority int               `json:"priority" binding:"min=0,max=3"`
+	Kind     string            `json:"kind" binding:"omitempty,oneof=a b"`
+	Tags     map[string]string `json:"tags" binding:"max=1"`
+	Address  address           `json:"address"`
+	Internal string            `json:"-" binding:"max=3"`
+}
+
+func validRequest() request {
+	return request{
+		Email:    "test@example.com",
+		Password: "password123",
+		Address:  address{City: "Berlin"},
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(r *request)
+		expected Errors
+	}{
+		{
+			name:   "valid",
+			modify: func(r *request) {},
+		},
+		{
+			name: "valid optional fields",
+			modify: func(r *request) {
+				r.Date = "2024-03-20"
+				r.Title = stringPtr("Title")
+				r.Kind = "b"
+			},
+		},
+		{
+			name: "missing required fields",
+			modify: func(r *request) {
+				r.Email = ""
+				r.Password = "   "
+			},
+			expected: Errors{
+				{Field: "email", Rule: "required", Message: "is required"},
+				{Field: "password", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name: "invalid values",
+			modify: func(r *request) {
+				r.Email = "Test <test@example.com>"
+				r.Password = "short"
+				r.Date = "20.03.2024"
+				r.Priority = 4
+				r.Kind = "c"
+				r.Tags = map[string]string{"a": "1", "b": "2"}
+				r.Internal = "toolong"
+			},
+			expected: Errors{
+				{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				{Field: "password", Rule: "min", Message: "must be at least 8 characters"},
+				{Field: "date", Rule: "date", Message: "must be a date in YYYY-MM-DD format"},
+				{Field: "priority", Rule: "max", Message: "must be at most 3"},
+				{Field: "kind", Rule: "oneof", Message: "must be one of a, b"},
+				{Field: "tags", Rule: "max", Message: "must be at most 1 items"},
+				{Field: "Internal", Rule: "max", Message: "must be at most 3 characters"},
+			},
+		},
+		{
+			name: "set pointer is checked",
+			modify: func(r *request) {
+				r.Title = stringPtr("")
+			},
+			expected: Errors{
+				{Field: "title", Rule: "min", Message: "must be at least 1 characters"},
+			},
+		},
+		{
+			name: "nested struct",
+			modify: func(r *request) {
+				r.Address.City = ""
+			},
+			expected: Errors{
+				{Field: "address.city", Rule: "required", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.modify(&req)
+
+			err := Struct(&req)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var errs Errors
+			require.ErrorAs(t, err, &errs)
+			assert.Equal(t, tt.expected, errs)
+		})
+	}
+}
+
+func TestStruct_UnknownRule(t *testing.T) {
+	type invalid struct {
+		Name string `binding:"uppercase"`
+	}
+
+	assert.Panics(t, func() {
+		_ = Struct(invalid{})
+	})
+}
@@ -0,0 +1,94 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestPersonalAccessTokens(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "script@example.com", Password: "password123", Name: "Script User"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, "script@example.com", "password123")
+	tokensURL := server.URL + "/api/v1/users/me/tokens"
+
+	resp = authorizedRequest(t, http.MethodPost, tokensURL, tokens.AccessToken, models.PersonalAccessTokenCreation{
+		Name:   "scanner",
+		Scopes: []string{models.ScopeFilesWrite, models.ScopeDocumentsRead},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var pat models.PersonalAccessToken
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&pat))
+	require.NotEmpty(t, pat.Token)
+
+	t.Run("unknown scope", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, tokensURL, tokens.AccessToken, models.PersonalAccessTokenCreation{
+			Name:   "admin",
+			Scopes: []string{"users:write"},
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("token works within its scopes", func(t *testing.T) {
+		file := uploadFile(t, server.URL, pat.Token, "scan.jpg", jpegContent)
+		assert.NotEmpty(t, file.ID)
+
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", pat.Token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", pat.Token, models.DocumentCreation{
+			Title: "Scan",
+			File:  file.ID + ".jpg",
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("token cannot manage the account", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", pat.Token, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, tokensURL, pat.Token, models.PersonalAccessTokenCreation{
+			Name:   "escalated",
+			Scopes: []string{models.ScopeDocumentsWrite},
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("list hides the token", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, tokensURL, tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var list []models.PersonalAccessToken
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		require.Len(t, list, 1)
+		assert.Equal(t, pat.ID, list[0].ID)
+		assert.Empty(t, list[0].Token)
+		assert.NotNil(t, list[0].LastUsedAt)
+	})
+
+	t.Run("revoked token stops working", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodDelete, tokensURL+"/"+pat.ID, tokens.AccessToken, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", pat.Token, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodDelete, tokensURL+"/"+pat.ID, tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,281 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc/oidctest"
+	"github.com/gruzdev-dev/meddoc/config"
+)
+
+// jpegContent is a minimal JPEG accepted by the upload handler.
+var jpegContent = []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 0x4A, 0x46, 0x49, 0x46, 0x00, 0x01, 0x01, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0xFF, 0xD9}
+
+func TestDocumentDeletionRemovesFile(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "docfile@example.com",
+		Password: "password123",
+		Name:     "Doc File User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+	file := uploadFile(t, server.URL, tokens.AccessToken, "scan.jpg", jpegContent)
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", tokens.AccessToken, models.DocumentCreation{
+		Title: "Scan",
+		File:  file.ID,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	resp = authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/documents/"+doc.ID, tokens.AccessToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/files/"+file.ID, tokens.AccessToken, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NoFileExists(t, filepath.Join("test_storage", file.ID))
+}
+
+func TestDocumentDeletionByAdmin(t *testing.T) {
+	server, userService := setupTestServer(t)
+	defer server.Close()
+
+	register := func(email string) models.User {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: "Cleanup User"})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		return user
+	}
+	register("cleanup-owner@example.com")
+	register("cleanup-specialist@example.com")
+	admin := register("cleanup-admin@example.com")
+	_, err := userService.SetRoles(context.Background(), admin.ID, []string{models.RolePatient, models.RoleAdmin})
+	require.NoError(t, err)
+
+	ownerTokens := loginUser(t, server.URL, "cleanup-owner@example.com", "password123")
+	specialistTokens := loginUser(t, server.URL, "cleanup-specialist@example.com", "password123")
+	adminTokens := loginUser(t, server.URL, "cleanup-admin@example.com", "password123")
+
+	file := uploadFile(t, server.URL, ownerTokens.AccessToken, "scan.jpg", jpegContent)
+	resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", ownerTokens.AccessToken, models.DocumentCreation{
+		Title: "Scan",
+		File:  file.ID,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents/"+doc.ID+"/grants", ownerTokens.AccessToken, models.GrantCreation{
+		Email:      "cleanup-specialist@example.com",
+		Permission: models.GrantRead,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/share-links", ownerTokens.AccessToken, models.ShareLinkCreation{
+		DocumentIDs: []string{doc.ID},
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var link models.ShareLink
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+
+	resp = authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/documents/"+doc.ID, adminTokens.AccessToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// The file goes with the document even though the admin does not own it.
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/files/"+file.ID, ownerTokens.AccessToken, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NoFileExists(t, filepath.Join("test_storage", file.ID))
+
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/shared/documents", specialistTokens.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var shared []models.SharedDocument
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&shared))
+	assert.Empty(t, shared)
+
+	resp, err = http.Get(server.URL + "/api/v1/share/" + link.Token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAccountDeletion(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "erase@example.com",
+		Password: "password123",
+		Name:     "Erase User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+	file := uploadFile(t, server.URL, tokens.AccessToken, "scan.jpg", jpegContent)
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", tokens.AccessToken, models.DocumentCreation{
+		Title: "Scan",
+		File:  file.ID,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/share-links", tokens.AccessToken, models.ShareLinkCreation{
+		DocumentIDs: []string{doc.ID},
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var link models.ShareLink
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+
+	requestDeletion := func(password string) *http.Response {
+		return authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/users/me", tokens.AccessToken, models.AccountDeletionRequest{
+			Password: password,
+		})
+	}
+
+	t.Run("wrong password", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, requestDeletion("wrong-password").StatusCode)
+	})
+
+	t.Run("cancel scheduled deletion", func(t *testing.T) {
+		require.Equal(t, http.StatusAccepted, requestDeletion(regData.Password).StatusCode)
+		assert.Equal(t, http.StatusConflict, requestDeletion(regData.Password).StatusCode)
+
+		resp := authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/users/me/deletion", tokens.AccessToken, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me/deletion", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("deletion runs after grace period", func(t *testing.T) {
+		resp := requestDeletion(regData.Password)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		var deletion models.AccountDeletion
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&deletion))
+		assert.Equal(t, models.AccountDeletionPending, deletion.Status)
+
+		require.Eventually(t, func() bool {
+			paths, _ := filepath.Glob(filepath.Join("test_mail", "*-"+regData.Email+".eml"))
+			for _, path := range paths {
+				data, err := os.ReadFile(path)
+				if err == nil && bytes.Contains(data, []byte("Receipt: "+deletion.ID)) {
+					return true
+				}
+			}
+			return false
+		}, 10*time.Second, 100*time.Millisecond)
+
+		receipt := lastMailTo(t, "test_mail", regData.Email)
+		assert.Contains(t, receipt, "Documents deleted: 1")
+		assert.Contains(t, receipt, "Files deleted: 1")
+
+		body, err := json.Marshal(models.UserLogin{Email: regData.Email, Password: regData.Password})
+		require.NoError(t, err)
+		resp, err = http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		assert.NoFileExists(t, filepath.Join("test_storage", file.ID))
+
+		resp, err = http.Get(server.URL + "/api/v1/share/" + link.Token)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestAccountDeletionWithoutPassword(t *testing.T) {
+	provider := oidctest.NewProvider("meddoc", "provider-secret")
+	defer provider.Close()
+
+	server, _ := setupTestServerWith(t, func(cfg *config.Config, serverURL string) {
+		cfg.Auth.OIDC.Providers = []config.OIDCProvider{{
+			Name:         "corp",
+			Issuer:       provider.Issuer(),
+			ClientID:     "meddoc",
+			ClientSecret: "provider-secret",
+			RedirectURL:  serverURL + "/api/v1/auth/oidc/corp/callback",
+			Scopes:       []string{"openid", "email", "profile"},
+		}}
+	})
+	defer server.Close()
+
+	provider.SignIn(oidctest.User{Subject: "dave-1", Email: "dave@example.com", EmailVerified: true, Name: "Dave"})
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Jar: jar}).Get(server.URL + "/api/v1/auth/oidc/corp/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var tokens models.TokenPair
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+	confirm := func(token string) *http.Response {
+		return authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/users/me/deletion/confirm", tokens.AccessToken, models.AccountDeletionConfirmation{
+			Token: token,
+		})
+	}
+
+	resp = authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/users/me", tokens.AccessToken, models.AccountDeletionRequest{})
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var deletion models.AccountDeletion
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&deletion))
+	assert.Equal(t, models.AccountDeletionUnconfirmed, deletion.Status)
+
+	// Nothing is scheduled until the mailed link is opened.
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me/deletion", tokens.AccessToken, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, confirm("not-the-token").StatusCode)
+
+	resp = confirm(tokenFromMail(t, lastMailTo(t, "test_mail", "dave@example.com")))
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&deletion))
+	assert.Equal(t, models.AccountDeletionPending, deletion.Status)
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(lastMailTo(t, "test_mail", "dave@example.com"), "Receipt: "+deletion.ID)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", tokens.AccessToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
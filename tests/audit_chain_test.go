@@ -0,0 +1,73 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/database"
+	"github.com/gruzdev-dev/meddoc/database/repositories"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+)
+
+func TestAuditChain(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "chain@example.com", Password: "password123", Name: "Chain User"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	tokens := loginUser(t, server.URL, "chain@example.com", "password123")
+	for _, title := range []string{"Blood test", "X-ray", "Vaccination"} {
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", tokens.AccessToken, models.DocumentCreation{Title: title})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	cfg, err := config.Load("test_config.yaml")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mongoDB, err := database.NewMongoDB(ctx, database.MongoDBConfig{URI: cfg.MongoDB.URI, Database: cfg.MongoDB.Database})
+	require.NoError(t, err)
+	defer mongoDB.Close(ctx)
+
+	events := mongoDB.Database().Collection("audit_events")
+	auditService := audit.NewService(audit.Dependencies{
+		Events:      repositories.NewAuditRepository(events),
+		Checkpoints: repositories.NewAuditCheckpointRepository(mongoDB.Database().Collection("audit_checkpoints")),
+		Keys:        keyring.NewHMAC([]byte(cfg.Auth.Secret)),
+	}, audit.Config{})
+
+	checkpoint, err := auditService.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, int64(4), checkpoint.Seq)
+
+	report, err := auditService.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), report.Events)
+	assert.Equal(t, 1, report.Checkpoints)
+	assert.Equal(t, checkpoint.Hash, report.Head.Hash)
+
+	_, err = events.UpdateOne(ctx, bson.M{"seq": 2}, bson.M{"$set": bson.M{"outcome": models.AuditDenied}})
+	require.NoError(t, err)
+
+	_, err = auditService.Verify(ctx)
+	var chainErr *audit.ChainError
+	require.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, int64(2), chainErr.Seq)
+}
@@ -0,0 +1,109 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestAuditLog(t *testing.T) {
+	server, userService := setupTestServer(t)
+	defer server.Close()
+
+	register := func(t *testing.T, email string) models.User {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: "Audit User"})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		return user
+	}
+	events := func(t *testing.T, url, accessToken string) []models.AuditEvent {
+		resp := authorizedRequest(t, http.MethodGet, url, accessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var events []models.AuditEvent
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&events))
+		return events
+	}
+
+	patient := register(t, "patient@example.com")
+	admin := register(t, "admin@example.com")
+	_, err := userService.SetRoles(context.Background(), admin.ID, []string{models.RolePatient, models.RoleAdmin})
+	require.NoError(t, err)
+	patientTokens := loginUser(t, server.URL, "patient@example.com", "password123")
+	adminTokens := loginUser(t, server.URL, "admin@example.com", "password123")
+
+	resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", patientTokens.AccessToken, models.DocumentCreation{Title: "Blood test"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/documents/"+doc.ID, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminTokens.AccessToken)
+	req.Header.Set("X-Request-ID", "audit-test-request")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	body, err := json.Marshal(models.UserLogin{Email: "patient@example.com", Password: "wrong-password"})
+	require.NoError(t, err)
+	resp, err = http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	t.Run("owner sees what others did with their data", func(t *testing.T) {
+		own := events(t, server.URL+"/api/v1/audit", patientTokens.AccessToken)
+		require.Len(t, own, 2)
+
+		assert.Equal(t, models.AuditLogin, own[0].Action)
+		assert.Equal(t, models.AuditDenied, own[0].Outcome)
+		assert.Empty(t, own[0].ActorID)
+
+		assert.Equal(t, models.AuditDocumentRead, own[1].Action)
+		assert.Equal(t, admin.ID, own[1].ActorID)
+		assert.Equal(t, patient.ID, own[1].OwnerID)
+		assert.Equal(t, doc.ID, own[1].ResourceID)
+		assert.Equal(t, models.AuditDenied, own[1].Outcome)
+		assert.Equal(t, "audit-test-request", own[1].RequestID)
+		assert.NotEmpty(t, own[1].IP)
+
+		filtered := events(t, server.URL+"/api/v1/audit?action="+models.AuditLogin, patientTokens.AccessToken)
+		assert.Len(t, filtered, 1)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/audit?from=yesterday", patientTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("patients cannot query the whole log", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/admin/audit", patientTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("admin queries the log", func(t *testing.T) {
+		byRequest := events(t, server.URL+"/api/v1/admin/audit?request_id=audit-test-request", adminTokens.AccessToken)
+		require.Len(t, byRequest, 1)
+		assert.Equal(t, doc.ID, byRequest[0].ResourceID)
+
+		byActor := events(t, server.URL+"/api/v1/admin/audit?actor_id="+patient.ID, adminTokens.AccessToken)
+		actions := make([]string, 0, len(byActor))
+		for _, event := range byActor {
+			actions = append(actions, event.Action)
+		}
+		assert.Contains(t, actions, models.AuditLogin)
+		assert.Contains(t, actions, models.AuditDocumentCreate)
+	})
+}
@@ -7,11 +7,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
 )
 
 func TestAuthFlow(t *testing.T) {
@@ -83,6 +86,79 @@ func TestAuthFlow(t *testing.T) {
 		assert.Greater(t, newTokens.ExpiresIn, 0)
 	})
 
+	t.Run("refresh token reuse revokes session", func(t *testing.T) {
+		loginData := models.UserLogin{
+			Email:    regData.Email,
+			Password: regData.Password,
+		}
+
+		body, err := json.Marshal(loginData)
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+		refresh := func(refreshToken string) (*http.Response, models.TokenPair) {
+			body, err := json.Marshal(models.RefreshToken{RefreshToken: refreshToken})
+			require.NoError(t, err)
+
+			resp, err := http.Post(server.URL+"/api/v1/auth/refresh", "application/json", bytes.NewBuffer(body))
+			require.NoError(t, err)
+
+			var pair models.TokenPair
+			if resp.StatusCode == http.StatusOK {
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&pair))
+			}
+			return resp, pair
+		}
+
+		resp, rotated := refresh(tokens.RefreshToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEqual(t, tokens.RefreshToken, rotated.RefreshToken)
+
+		resp, _ = refresh(tokens.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, _ = refresh(rotated.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("token types are not interchangeable", func(t *testing.T) {
+		loginData := models.UserLogin{
+			Email:    regData.Email,
+			Password: regData.Password,
+		}
+
+		body, err := json.Marshal(loginData)
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+		body, err = json.Marshal(models.RefreshToken{RefreshToken: tokens.AccessToken})
+		require.NoError(t, err)
+
+		resp, err = http.Post(server.URL+"/api/v1/auth/refresh", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/documents", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+tokens.RefreshToken)
+
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
 	t.Run("invalid login", func(t *testing.T) {
 		loginData := models.UserLogin{
 			Email:    regData.Email,
@@ -110,3 +186,398 @@ func TestAuthFlow(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 }
+
+func TestJWKS(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/.well-known/jwks.json")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var jwks keyring.JWKS
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "test-key", jwks.Keys[0].KeyID)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Algorithm)
+	assert.NotEmpty(t, jwks.Keys[0].X)
+}
+
+func TestLogout(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "logout@example.com",
+		Password: "password123",
+		Name:     "Logout User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	refresh := func(refreshToken string) int {
+		body, err := json.Marshal(models.RefreshToken{RefreshToken: refreshToken})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/refresh", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	t.Run("logout revokes current session", func(t *testing.T) {
+		tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/auth/logout", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		assert.Equal(t, http.StatusUnauthorized, refresh(tokens.RefreshToken))
+	})
+
+	t.Run("logout everywhere revokes all sessions", func(t *testing.T) {
+		first := loginUser(t, server.URL, regData.Email, regData.Password)
+		second := loginUser(t, server.URL, regData.Email, regData.Password)
+
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/auth/logout-all", first.AccessToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", first.AccessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		assert.Equal(t, http.StatusUnauthorized, refresh(first.RefreshToken))
+		assert.Equal(t, http.StatusUnauthorized, refresh(second.RefreshToken))
+	})
+}
+
+func TestMFA(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "mfa@example.com",
+		Password: "password123",
+		Name:     "MFA User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/users/me/mfa/totp", tokens.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var enrollment models.TOTPEnrollment
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&enrollment))
+	assert.NotEmpty(t, enrollment.OTPAuthURI)
+	assert.NotEmpty(t, enrollment.QRCodePNG)
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+
+	resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/users/me/mfa/totp/confirm", tokens.AccessToken, models.MFACode{Code: code})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var recovery models.RecoveryCodes
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&recovery))
+	require.NotEmpty(t, recovery.Codes)
+
+	login := func() models.MFAChallenge {
+		body, err := json.Marshal(models.UserLogin{Email: regData.Email, Password: regData.Password})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var challenge models.MFAChallenge
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&challenge))
+		require.True(t, challenge.MFARequired)
+		return challenge
+	}
+
+	verify := func(mfaToken, code string) *http.Response {
+		body, err := json.Marshal(models.MFAVerification{MFAToken: mfaToken, Code: code})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/mfa/verify", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("login requires second factor", func(t *testing.T) {
+		challenge := login()
+
+		resp := verify(challenge.MFAToken, "000000")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = verify(challenge.MFAToken, recovery.Codes[0])
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("recovery codes are single use", func(t *testing.T) {
+		challenge := login()
+
+		resp := verify(challenge.MFAToken, recovery.Codes[0])
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("totp codes cannot be replayed", func(t *testing.T) {
+		challenge := login()
+
+		resp := verify(challenge.MFAToken, code)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("mfa tokens are single use", func(t *testing.T) {
+		challenge := login()
+
+		resp := verify(challenge.MFAToken, recovery.Codes[1])
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = verify(challenge.MFAToken, recovery.Codes[2])
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("wrong codes lock the second factor", func(t *testing.T) {
+		challenge := login()
+		for i := 0; i < 4; i++ {
+			resp := verify(challenge.MFAToken, "000000")
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+		resp := verify(challenge.MFAToken, "000000")
+		assert.Equal(t, http.StatusLocked, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+		// Neither a fresh MFA token nor a valid code gets past the lock.
+		resp = verify(login().MFAToken, recovery.Codes[2])
+		assert.Equal(t, http.StatusLocked, resp.StatusCode)
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "reset@example.com",
+		Password: "password123",
+		Name:     "Reset User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+
+	forgot := func(email string) int {
+		body, err := json.Marshal(models.ForgotPassword{Email: email})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/password/forgot", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	reset := func(token, password string) int {
+		body, err := json.Marshal(models.PasswordReset{Token: token, Password: password})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/password/reset", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	t.Run("unknown email is accepted", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, forgot("nobody@example.com"))
+	})
+
+	t.Run("reset with mailed token", func(t *testing.T) {
+		require.Equal(t, http.StatusAccepted, forgot(regData.Email))
+		token := tokenFromMail(t, lastMailTo(t, "test_mail", regData.Email))
+
+		assert.Equal(t, http.StatusNoContent, reset(token, "new-password123"))
+
+		loginUser(t, server.URL, regData.Email, "new-password123")
+
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		t.Run("token is single use", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, reset(token, "another-password123"))
+		})
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, reset("not-a-token", "new-password123"))
+	})
+}
+
+func TestEmailVerification(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "verify@example.com",
+		Password: "password123",
+		Name:     "Verify User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var user models.User
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+	assert.False(t, user.EmailVerified)
+
+	verify := func(token string) int {
+		body, err := json.Marshal(models.EmailVerification{Token: token})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/email/verify", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, verify("not-a-token"))
+	})
+
+	t.Run("resend and verify", func(t *testing.T) {
+		body, err := json.Marshal(models.ResendVerification{Email: regData.Email})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/email/resend", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		token := tokenFromMail(t, lastMailTo(t, "test_mail", regData.Email))
+		assert.Equal(t, http.StatusNoContent, verify(token))
+	})
+}
+
+func TestLoginLockout(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "lockout@example.com",
+		Password: "password123",
+		Name:     "Lockout User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	login := func(password string) *http.Response {
+		body, err := json.Marshal(models.UserLogin{Email: regData.Email, Password: password})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	for i := 0; i < 3; i++ {
+		resp := login("wrong-password")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	resp = login(regData.Password)
+	assert.Equal(t, http.StatusLocked, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestRequestValidation(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	type fieldError struct {
+		Field string `json:"field"`
+		Rule  string `json:"rule"`
+	}
+	type validationError struct {
+		Error  string       `json:"error"`
+		Fields []fieldError `json:"fields"`
+	}
+
+	decode := func(t *testing.T, resp *http.Response) validationError {
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var body validationError
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body
+	}
+
+	t.Run("registration", func(t *testing.T) {
+		body, err := json.Marshal(models.UserRegistration{Email: "not-an-email", Password: "short"})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+
+		result := decode(t, resp)
+		assert.Equal(t, "validation failed", result.Error)
+		assert.ElementsMatch(t, []fieldError{
+			{Field: "email", Rule: "email"},
+			{Field: "password", Rule: "min"},
+			{Field: "name", Rule: "required"},
+		}, result.Fields)
+	})
+
+	t.Run("document", func(t *testing.T) {
+		regData := models.UserRegistration{
+			Email:    "validation@example.com",
+			Password: "password123",
+			Name:     "Validation User",
+		}
+		body, err := json.Marshal(regData)
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+
+		resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", tokens.AccessToken, models.DocumentCreation{Date: "tomorrow"})
+		result := decode(t, resp)
+		assert.ElementsMatch(t, []fieldError{
+			{Field: "title", Rule: "required"},
+			{Field: "date", Rule: "date"},
+		}, result.Fields)
+	})
+}
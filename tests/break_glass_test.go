@@ -0,0 +1,168 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/config"
+)
+
+func TestBreakGlass(t *testing.T) {
+	server, userService := setupTestServerWith(t, func(cfg *config.Config, _ string) {
+		cfg.Sharing.BreakGlass.TTL = 2 * time.Second
+	})
+	defer server.Close()
+
+	register := func(t *testing.T, email, name string) models.User {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: name})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		return user
+	}
+	patient := register(t, "patient@example.com", "Pat Patient")
+	clinician := register(t, "doctor@example.com", "Dr Casey")
+	register(t, "other@example.com", "Other")
+	_, err := userService.SetRoles(context.Background(), clinician.ID, []string{models.RolePatient, models.RoleClinician})
+	require.NoError(t, err)
+
+	patientTokens := loginUser(t, server.URL, "patient@example.com", "password123")
+	clinicianTokens := loginUser(t, server.URL, "doctor@example.com", "password123")
+	otherTokens := loginUser(t, server.URL, "other@example.com", "password123")
+
+	file := uploadFile(t, server.URL, patientTokens.AccessToken, "allergies.jpg", jpegContent)
+	resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", patientTokens.AccessToken, models.DocumentCreation{
+		Title: "Allergy list",
+		File:  file.ID + ".jpg",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	breakGlassURL := server.URL + "/api/v1/break-glass"
+	documentURL := server.URL + "/api/v1/documents/" + doc.ID
+	patientDocsURL := breakGlassURL + "/" + patient.ID + "/documents"
+	fileURL := server.URL + "/api/v1/files/" + file.ID
+	justification := "Patient brought in unconscious, checking allergies before treatment"
+
+	t.Run("no access before breaking glass", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, documentURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, patientDocsURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, fileURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("only clinicians can break glass", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, breakGlassURL, otherTokens.AccessToken, models.BreakGlassRequest{
+			PatientID:     patient.ID,
+			Justification: justification,
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("justification is required", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, breakGlassURL, clinicianTokens.AccessToken, models.BreakGlassRequest{
+			PatientID:     patient.ID,
+			Justification: "emergency",
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unknown patient", func(t *testing.T) {
+		for _, patientID := range []string{"000000000000000000000000", "not-a-user-id"} {
+			resp := authorizedRequest(t, http.MethodPost, breakGlassURL, clinicianTokens.AccessToken, models.BreakGlassRequest{
+				PatientID:     patientID,
+				Justification: justification,
+			})
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, patientID)
+		}
+	})
+
+	resp = authorizedRequest(t, http.MethodPost, breakGlassURL, clinicianTokens.AccessToken, models.BreakGlassRequest{
+		PatientID:     patient.ID,
+		Justification: justification,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var access models.BreakGlassAccess
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&access))
+	assert.Equal(t, clinician.ID, access.ClinicianID)
+
+	t.Run("patient is notified", func(t *testing.T) {
+		mail := lastMailTo(t, "test_mail", "patient@example.com")
+		assert.Contains(t, mail, "Emergency access to your MedDoc records")
+		assert.Contains(t, mail, "Dr Casey")
+		assert.Contains(t, mail, justification)
+	})
+
+	t.Run("clinician reads the patient's documents", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, patientDocsURL, clinicianTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page models.DocumentPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Len(t, page.Documents, 1)
+
+		resp = authorizedRequest(t, http.MethodGet, documentURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, fileURL, clinicianTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		content, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, jpegContent, content)
+	})
+
+	t.Run("clinician cannot change documents", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPatch, documentURL, clinicianTokens.AccessToken, models.DocumentUpdate{Title: stringPtr("Changed")})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodDelete, fileURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("accesses are flagged in the patient's audit log", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/audit", patientTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var events []models.AuditEvent
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&events))
+
+		flagged := map[string]int{}
+		for _, event := range events {
+			if event.BreakGlassID == access.ID {
+				assert.Equal(t, clinician.ID, event.ActorID)
+				flagged[event.Action]++
+			}
+		}
+		assert.Equal(t, 1, flagged[models.AuditBreakGlass])
+		assert.Equal(t, 1, flagged[models.AuditDocumentList])
+		assert.Equal(t, 1, flagged[models.AuditDocumentRead])
+		assert.Equal(t, 1, flagged[models.AuditFileDownload])
+	})
+
+	t.Run("access ends", func(t *testing.T) {
+		time.Sleep(time.Until(access.ExpiresAt) + 100*time.Millisecond)
+
+		resp := authorizedRequest(t, http.MethodGet, documentURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, fileURL, clinicianTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
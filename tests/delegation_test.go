@@ -0,0 +1,167 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestDelegations(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	register := func(t *testing.T, email, name string) (models.User, models.TokenPair) {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: name})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var registered models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+		return registered, loginUser(t, server.URL, email, "password123")
+	}
+	grantor, grantorTokens := register(t, "parent@example.com", "Pat Parent")
+	delegate, delegateTokens := register(t, "carer@example.com", "Casey Carer")
+	_, otherTokens := register(t, "other@example.com", "Other")
+
+	delegationsURL := server.URL + "/api/v1/delegations"
+	documentsURL := server.URL + "/api/v1/documents"
+
+	invite := func(t *testing.T, permission string) string {
+		resp := authorizedRequest(t, http.MethodPost, delegationsURL, grantorTokens.AccessToken, models.DelegationInvite{
+			Email:      "carer@example.com",
+			Permission: permission,
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		return tokenFromMail(t, lastMailTo(t, "test_mail", "carer@example.com"))
+	}
+	accept := func(t *testing.T, accessToken, token string) *http.Response {
+		return authorizedRequest(t, http.MethodPost, delegationsURL+"/accept", accessToken, models.DelegationAcceptance{Token: token})
+	}
+
+	resp := authorizedRequest(t, http.MethodPost, documentsURL, grantorTokens.AccessToken, models.DocumentCreation{Title: "Cardiology report"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var grantorDoc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&grantorDoc))
+	assert.Equal(t, grantor.ID, grantorDoc.CreatedBy)
+
+	t.Run("no access without a delegation", func(t *testing.T) {
+		resp := actingRequest(t, http.MethodGet, documentsURL, delegateTokens.AccessToken, grantor.ID, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("cannot invite yourself", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, delegationsURL, grantorTokens.AccessToken, models.DelegationInvite{
+			Email:      "parent@example.com",
+			Permission: models.DelegationRead,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("read delegation", func(t *testing.T) {
+		token := invite(t, models.DelegationRead)
+
+		resp := accept(t, otherTokens.AccessToken, token)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = accept(t, delegateTokens.AccessToken, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var accepted models.Delegation
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&accepted))
+		assert.Equal(t, models.DelegationActive, accepted.Status)
+		assert.Equal(t, delegate.ID, accepted.DelegateID)
+
+		resp = accept(t, delegateTokens.AccessToken, token)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = actingRequest(t, http.MethodGet, documentsURL, delegateTokens.AccessToken, grantor.ID, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page models.DocumentPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Len(t, page.Documents, 1)
+		assert.Equal(t, grantorDoc.ID, page.Documents[0].ID)
+
+		resp = actingRequest(t, http.MethodPost, documentsURL, delegateTokens.AccessToken, grantor.ID, models.DocumentCreation{Title: "Not allowed"})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = actingRequest(t, http.MethodGet, documentsURL, otherTokens.AccessToken, grantor.ID, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, delegationsURL+"/received", delegateTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var received []models.Delegation
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&received))
+		require.Len(t, received, 1)
+
+		resp = authorizedRequest(t, http.MethodDelete, delegationsURL+"/"+accepted.ID, grantorTokens.AccessToken, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = actingRequest(t, http.MethodGet, documentsURL, delegateTokens.AccessToken, grantor.ID, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("manage delegation", func(t *testing.T) {
+		resp := accept(t, delegateTokens.AccessToken, invite(t, models.DelegationManage))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = actingRequest(t, http.MethodPost, documentsURL, delegateTokens.AccessToken, grantor.ID, models.DocumentCreation{Title: "Discharge letter"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var created models.Document
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, delegate.ID, created.CreatedBy)
+
+		resp = actingRequest(t, http.MethodPatch, documentsURL+"/"+grantorDoc.ID, delegateTokens.AccessToken, grantor.ID, models.DocumentUpdate{Title: stringPtr("Cardiology report 2024")})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var updated models.Document
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+		assert.Equal(t, grantor.ID, updated.CreatedBy)
+		assert.Equal(t, delegate.ID, updated.UpdatedBy)
+
+		// The document belongs to the grantor, not the delegate.
+		resp = authorizedRequest(t, http.MethodGet, documentsURL+"/"+created.ID, grantorTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = authorizedRequest(t, http.MethodGet, documentsURL+"/"+created.ID, delegateTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("account routes cannot be used on behalf of others", func(t *testing.T) {
+		resp := actingRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", delegateTokens.AccessToken, grantor.ID, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = actingRequest(t, http.MethodPost, delegationsURL, delegateTokens.AccessToken, grantor.ID, models.DelegationInvite{
+			Email:      "other@example.com",
+			Permission: models.DelegationRead,
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func actingRequest(t *testing.T, method, url, accessToken, grantorID string, body any) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("X-Act-As", grantorID)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
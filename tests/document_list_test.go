@@ -0,0 +1,118 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestDocumentListing(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "lister@example.com", Password: "password123", Name: "Lister"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	tokens := loginUser(t, server.URL, "lister@example.com", "password123")
+
+	documentsURL := server.URL + "/api/v1/documents"
+	for _, doc := range []models.DocumentCreation{
+		{Title: "Blood test", Date: "2024-01-10", Category: "lab", Priority: 2, File: "file-1"},
+		{Title: "X-ray", Date: "2024-03-05", Category: "imaging", Priority: 5, File: "file-2"},
+		{Title: "Cholesterol", Date: "2024-02-20", Category: "lab", Priority: 5},
+		{Title: "Vaccination", Date: "2023-11-01", Category: "immunization", Priority: 1},
+		{Title: "Note without date", Category: "lab"},
+	} {
+		resp := authorizedRequest(t, http.MethodPost, documentsURL, tokens.AccessToken, doc)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	list := func(t *testing.T, query url.Values) models.DocumentPage {
+		resp := authorizedRequest(t, http.MethodGet, documentsURL+"?"+query.Encode(), tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page models.DocumentPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page
+	}
+	titles := func(docs []*models.Document) []string {
+		var titles []string
+		for _, doc := range docs {
+			titles = append(titles, doc.Title)
+		}
+		return titles
+	}
+
+	t.Run("pages through every document", func(t *testing.T) {
+		query := url.Values{"sort": {"-priority"}, "limit": {"2"}}
+		var seen []string
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 3)
+			page := list(t, query)
+			assert.EqualValues(t, 5, page.Total)
+			seen = append(seen, titles(page.Documents)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", page.NextCursor)
+		}
+		// Ties keep to the sort direction, so the later of the two fives comes first.
+		assert.Equal(t, []string{"Cholesterol", "X-ray", "Blood test", "Vaccination", "Note without date"}, seen)
+	})
+
+	t.Run("sorts by date", func(t *testing.T) {
+		page := list(t, url.Values{"sort": {"date"}})
+		assert.Equal(t, []string{"Note without date", "Vaccination", "Blood test", "Cholesterol", "X-ray"}, titles(page.Documents))
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		page := list(t, url.Values{"category": {"lab"}, "min_priority": {"2"}, "sort": {"date"}})
+		assert.Equal(t, []string{"Blood test", "Cholesterol"}, titles(page.Documents))
+		assert.EqualValues(t, 2, page.Total)
+
+		page = list(t, url.Values{"date_from": {"2024-01-01"}, "date_to": {"2024-02-29"}, "sort": {"date"}})
+		assert.Equal(t, []string{"Blood test", "Cholesterol"}, titles(page.Documents))
+
+		page = list(t, url.Values{"date_to": {"2024-01-01"}})
+		assert.Equal(t, []string{"Vaccination"}, titles(page.Documents))
+
+		page = list(t, url.Values{"has_file": {"true"}, "sort": {"date"}})
+		assert.Equal(t, []string{"Blood test", "X-ray"}, titles(page.Documents))
+
+		page = list(t, url.Values{"has_file": {"false"}, "max_priority": {"1"}, "sort": {"priority"}})
+		assert.Equal(t, []string{"Note without date", "Vaccination"}, titles(page.Documents))
+
+		page = list(t, url.Values{"category": {"surgery"}})
+		assert.Empty(t, page.Documents)
+		assert.Zero(t, page.Total)
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		cursor := list(t, url.Values{"sort": {"date"}, "limit": {"1"}}).NextCursor
+		require.NotEmpty(t, cursor)
+
+		for _, query := range []url.Values{
+			{"sort": {"title"}},
+			{"limit": {"0"}},
+			{"min_priority": {"-1"}},
+			{"date_from": {"10.01.2024"}},
+			{"has_file": {"maybe"}},
+			{"cursor": {"not-a-cursor"}},
+			{"cursor": {cursor}, "sort": {"-date"}},
+		} {
+			resp := authorizedRequest(t, http.MethodGet, documentsURL+"?"+query.Encode(), tokens.AccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query.Encode())
+		}
+	})
+}
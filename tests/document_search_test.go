@@ -0,0 +1,114 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestDocumentSearch(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	register := func(t *testing.T, email string) models.TokenPair {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: "Searcher"})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		return loginUser(t, server.URL, email, "password123")
+	}
+	tokens := register(t, "searcher@example.com")
+	otherTokens := register(t, "other-searcher@example.com")
+
+	documentsURL := server.URL + "/api/v1/documents"
+	create := func(t *testing.T, accessToken string, doc models.DocumentCreation) models.Document {
+		resp := authorizedRequest(t, http.MethodPost, documentsURL, accessToken, doc)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var created models.Document
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		return created
+	}
+	search := func(t *testing.T, accessToken, q string) []models.DocumentSearchResult {
+		resp := authorizedRequest(t, http.MethodGet, documentsURL+"/search?"+url.Values{"q": {q}}.Encode(), accessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var results []models.DocumentSearchResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+		return results
+	}
+
+	report := create(t, tokens.AccessToken, models.DocumentCreation{
+		Title:       "Cardiology report",
+		Description: "Annual check-up",
+		Date:        "2022-05-14",
+	})
+	letter := create(t, tokens.AccessToken, models.DocumentCreation{
+		Title:   "Discharge letter",
+		Content: map[string]string{"notes": "Referred for cardiology reports follow-up"},
+	})
+	russian := create(t, tokens.AccessToken, models.DocumentCreation{
+		Title:       "Заключение кардиолога",
+		Description: "Результаты анализов крови в норме",
+	})
+	create(t, otherTokens.AccessToken, models.DocumentCreation{Title: "Cardiology report of someone else"})
+
+	t.Run("ranks title matches first", func(t *testing.T) {
+		results := search(t, tokens.AccessToken, "cardiology reports")
+		require.Len(t, results, 2)
+		assert.Equal(t, report.ID, results[0].Document.ID)
+		assert.Equal(t, letter.ID, results[1].Document.ID)
+		assert.Greater(t, results[0].Score, results[1].Score)
+
+		assert.Equal(t, []models.Highlight{
+			{Field: "title", Snippet: "<mark>Cardiology</mark> <mark>report</mark>"},
+		}, results[0].Highlights)
+		assert.Equal(t, []models.Highlight{
+			{Field: "content.notes", Snippet: "Referred for <mark>cardiology</mark> <mark>reports</mark> follow-up"},
+		}, results[1].Highlights)
+	})
+
+	t.Run("stems Russian", func(t *testing.T) {
+		results := search(t, tokens.AccessToken, "анализ")
+		require.Len(t, results, 1)
+		assert.Equal(t, russian.ID, results[0].Document.ID)
+		assert.Equal(t, []models.Highlight{
+			{Field: "description", Snippet: "Результаты <mark>анализов</mark> крови в норме"},
+		}, results[0].Highlights)
+	})
+
+	t.Run("follows updates", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPatch, documentsURL+"/"+letter.ID, tokens.AccessToken, models.DocumentUpdate{Title: stringPtr("Echocardiogram $result")})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		results := search(t, tokens.AccessToken, "echocardiogram")
+		require.Len(t, results, 1)
+		assert.Equal(t, "Echocardiogram $result", results[0].Document.Title)
+
+		// The content was left as it was and is still found.
+		results = search(t, tokens.AccessToken, "referred")
+		require.Len(t, results, 1)
+		assert.Equal(t, letter.ID, results[0].Document.ID)
+	})
+
+	t.Run("only searches own documents", func(t *testing.T) {
+		results := search(t, otherTokens.AccessToken, "cardiology")
+		require.Len(t, results, 1)
+		assert.Equal(t, "Cardiology report of someone else", results[0].Document.Title)
+
+		assert.Empty(t, search(t, tokens.AccessToken, "someone"))
+	})
+
+	t.Run("requires a query", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, documentsURL+"/search", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
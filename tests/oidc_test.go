@@ -0,0 +1,136 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc/oidctest"
+	"github.com/gruzdev-dev/meddoc/config"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	provider := oidctest.NewProvider("meddoc", "provider-secret")
+	defer provider.Close()
+
+	server, _ := setupTestServerWith(t, func(cfg *config.Config, serverURL string) {
+		cfg.Auth.OIDC.Providers = []config.OIDCProvider{{
+			Name:         "corp",
+			Issuer:       provider.Issuer(),
+			ClientID:     "meddoc",
+			ClientSecret: "provider-secret",
+			RedirectURL:  serverURL + "/api/v1/auth/oidc/corp/callback",
+			Scopes:       []string{"openid", "email", "profile"},
+		}}
+	})
+	defer server.Close()
+
+	// signIn follows the whole redirect chain like a browser would.
+	signIn := func(t *testing.T) *http.Response {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		client := &http.Client{Jar: jar}
+
+		resp, err := client.Get(server.URL + "/api/v1/auth/oidc/corp/login")
+		require.NoError(t, err)
+		return resp
+	}
+	profile := func(t *testing.T, tokens models.TokenPair) models.User {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		return user
+	}
+
+	var userID string
+
+	t.Run("creates a user on first sign-in", func(t *testing.T) {
+		provider.SignIn(oidctest.User{Subject: "alice-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice"})
+
+		resp := signIn(t)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+		user := profile(t, tokens)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, "Alice", user.Name)
+		assert.True(t, user.EmailVerified)
+		userID = user.ID
+	})
+
+	t.Run("signs in the same user again", func(t *testing.T) {
+		resp := signIn(t)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+		assert.Equal(t, userID, profile(t, tokens).ID)
+	})
+
+	t.Run("links an unverified local account and drops its password", func(t *testing.T) {
+		body, err := json.Marshal(models.UserRegistration{Email: "bob@example.com", Password: "password123", Name: "Bob"})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var registered models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+
+		provider.SignIn(oidctest.User{Subject: "bob-1", Email: "bob@example.com", EmailVerified: true})
+		resp = signIn(t)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+		assert.Equal(t, registered.ID, profile(t, tokens).ID)
+
+		body, err = json.Marshal(models.UserLogin{Email: "bob@example.com", Password: "password123"})
+		require.NoError(t, err)
+		resp, err = http.Post(server.URL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("rejects unverified provider email", func(t *testing.T) {
+		provider.SignIn(oidctest.User{Subject: "carol-1", Email: "carol@example.com"})
+
+		resp := signIn(t)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("rejects a callback from another browser", func(t *testing.T) {
+		provider.SignIn(oidctest.User{Subject: "alice-1", Email: "alice@example.com", EmailVerified: true})
+
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		client := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// Stop before the callback reaches our server.
+			if req.URL.Host != provider.Listener.Addr().String() {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		}}
+		resp, err := client.Get(server.URL + "/api/v1/auth/oidc/corp/login")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		callback := resp.Header.Get("Location")
+
+		resp, err = http.Get(callback)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/v1/auth/oidc/other/login")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
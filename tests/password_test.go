@@ -0,0 +1,69 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/database"
+)
+
+func TestPasswordRehashOnLogin(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "legacy@example.com", Password: "password123", Name: "Lee Legacy"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var registered models.User
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+
+	cfg, err := config.Load("test_config.yaml")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mongoDB, err := database.NewMongoDB(ctx, database.MongoDBConfig{URI: cfg.MongoDB.URI, Database: cfg.MongoDB.Database})
+	require.NoError(t, err)
+	defer mongoDB.Close(ctx)
+	users := mongoDB.Database().Collection("users")
+	userID, err := primitive.ObjectIDFromHex(registered.ID)
+	require.NoError(t, err)
+
+	storedHash := func(t *testing.T) string {
+		var stored struct {
+			Password string `bson:"password"`
+		}
+		require.NoError(t, users.FindOne(ctx, bson.M{"_id": userID}).Decode(&stored))
+		return stored.Password
+	}
+	assert.True(t, strings.HasPrefix(storedHash(t), "$argon2id$v=19$m=8192,t=1,p=1$"))
+
+	// An account created while passwords were hashed with bcrypt.
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	_, err = users.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": bson.M{"password": string(legacyHash)}})
+	require.NoError(t, err)
+
+	loginUser(t, server.URL, "legacy@example.com", "password123")
+	rehashed := storedHash(t)
+	assert.True(t, strings.HasPrefix(rehashed, "$argon2id$"), rehashed)
+
+	loginUser(t, server.URL, "legacy@example.com", "password123")
+	assert.Equal(t, rehashed, storedHash(t), "a current hash is kept")
+}
@@ -0,0 +1,183 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+	"github.com/gruzdev-dev/meddoc/config"
+	"github.com/gruzdev-dev/meddoc/database"
+	"github.com/gruzdev-dev/meddoc/database/migrations"
+)
+
+func TestPatientProfiles(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "parent@example.com", Password: "password123", Name: "Pat Parent"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	tokens := loginUser(t, server.URL, "parent@example.com", "password123")
+
+	profilesURL := server.URL + "/api/v1/profiles"
+	documentsURL := server.URL + "/api/v1/documents"
+	listDocuments := func(t *testing.T, profileID string) []*models.Document {
+		resp := authorizedRequest(t, http.MethodGet, documentsURL+"?profile_id="+profileID, tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page models.DocumentPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page.Documents
+	}
+
+	resp = authorizedRequest(t, http.MethodGet, profilesURL, tokens.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var profiles []models.Profile
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&profiles))
+	require.Len(t, profiles, 1)
+	own := profiles[0]
+	assert.True(t, own.Default)
+	assert.Equal(t, "Pat Parent", own.Name)
+	assert.Equal(t, models.RelationshipSelf, own.Relationship)
+
+	resp = authorizedRequest(t, http.MethodPost, profilesURL, tokens.AccessToken, models.ProfileCreation{
+		Name:         "Kim Parent",
+		DateOfBirth:  "2019-05-04",
+		Relationship: models.RelationshipChild,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var child models.Profile
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&child))
+	assert.False(t, child.Default)
+
+	resp = authorizedRequest(t, http.MethodPost, documentsURL, tokens.AccessToken, models.DocumentCreation{Title: "Own checkup"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var ownDoc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ownDoc))
+	assert.Equal(t, own.ID, ownDoc.ProfileID)
+
+	resp = authorizedRequest(t, http.MethodPost, documentsURL, tokens.AccessToken, models.DocumentCreation{Title: "Vaccination card", ProfileID: child.ID})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var childDoc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&childDoc))
+	assert.Equal(t, child.ID, childDoc.ProfileID)
+
+	t.Run("files go to the default profile", func(t *testing.T) {
+		file := uploadFile(t, server.URL, tokens.AccessToken, "scan.jpg", jpegContent)
+		assert.Equal(t, own.ID, file.ProfileID)
+	})
+
+	t.Run("filter documents by profile", func(t *testing.T) {
+		assert.Len(t, listDocuments(t, ""), 2)
+
+		docs := listDocuments(t, child.ID)
+		require.Len(t, docs, 1)
+		assert.Equal(t, childDoc.ID, docs[0].ID)
+
+		docs = listDocuments(t, own.ID)
+		require.Len(t, docs, 1)
+		assert.Equal(t, ownDoc.ID, docs[0].ID)
+	})
+
+	t.Run("profiles of other accounts are not visible", func(t *testing.T) {
+		body, err := json.Marshal(models.UserRegistration{Email: "other@example.com", Password: "password123", Name: "Other"})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		other := loginUser(t, server.URL, "other@example.com", "password123")
+
+		resp = authorizedRequest(t, http.MethodGet, profilesURL+"/"+child.ID, other.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, documentsURL+"?profile_id="+child.ID, other.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, documentsURL, other.AccessToken, models.DocumentCreation{Title: "Planted", ProfileID: child.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("profiles with records cannot be deleted", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodDelete, profilesURL+"/"+child.ID, tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodDelete, profilesURL+"/"+own.ID, tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("move a document and delete the empty profile", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPatch, documentsURL+"/"+childDoc.ID, tokens.AccessToken, models.DocumentUpdate{ProfileID: &own.ID})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, listDocuments(t, child.ID))
+
+		resp = authorizedRequest(t, http.MethodDelete, profilesURL+"/"+child.ID, tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, profilesURL+"/"+child.ID, tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestDefaultProfileMigration(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "legacy@example.com", Password: "password123", Name: "Lee Legacy"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var registered models.User
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+	tokens := loginUser(t, server.URL, "legacy@example.com", "password123")
+
+	cfg, err := config.Load("test_config.yaml")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mongoDB, err := database.NewMongoDB(ctx, database.MongoDBConfig{URI: cfg.MongoDB.URI, Database: cfg.MongoDB.Database})
+	require.NoError(t, err)
+	defer mongoDB.Close(ctx)
+	db := mongoDB.Database()
+
+	// A document stored before profiles existed, and a database that has
+	// not seen the migration yet.
+	_, err = db.Collection("documents").InsertOne(ctx, bson.M{
+		"title":      "Old lab result",
+		"user_id":    registered.ID,
+		"created_at": time.Now(),
+		"updated_at": time.Now(),
+	})
+	require.NoError(t, err)
+	_, err = db.Collection("migrations").DeleteMany(ctx, bson.M{})
+	require.NoError(t, err)
+
+	require.NoError(t, migrations.Run(ctx, db, migrations.All))
+	require.NoError(t, migrations.Run(ctx, db, migrations.All))
+
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/profiles", tokens.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var profiles []models.Profile
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&profiles))
+	require.Len(t, profiles, 1)
+	assert.True(t, profiles[0].Default)
+	assert.Equal(t, "Lee Legacy", profiles[0].Name)
+
+	resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents?profile_id="+profiles[0].ID, tokens.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var page models.DocumentPage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	require.Len(t, page.Documents, 1)
+	assert.Equal(t, "Old lab result", page.Documents[0].Title)
+}
@@ -0,0 +1,106 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestProfile(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	regData := models.UserRegistration{
+		Email:    "profile@example.com",
+		Password: "password123",
+		Name:     "Profile User",
+	}
+
+	body, err := json.Marshal(regData)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, regData.Email, regData.Password)
+
+	t.Run("get and update profile", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.Equal(t, regData.Email, user.Email)
+		assert.Equal(t, regData.Name, user.Name)
+
+		name := "Renamed User"
+		resp = authorizedRequest(t, http.MethodPatch, server.URL+"/api/v1/users/me", tokens.AccessToken, models.UserUpdate{Name: &name})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.Equal(t, name, user.Name)
+	})
+
+	t.Run("change password", func(t *testing.T) {
+		other := loginUser(t, server.URL, regData.Email, regData.Password)
+
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/users/me/password", tokens.AccessToken, models.PasswordChange{
+			CurrentPassword: "wrong-password",
+			NewPassword:     "newpassword123",
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/users/me/password", tokens.AccessToken, models.PasswordChange{
+			CurrentPassword: regData.Password,
+			NewPassword:     "newpassword123",
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+		body, err := json.Marshal(models.RefreshToken{RefreshToken: other.RefreshToken})
+		require.NoError(t, err)
+		resp, err = http.Post(server.URL+"/api/v1/auth/refresh", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", tokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		loginUser(t, server.URL, regData.Email, "newpassword123")
+	})
+
+	t.Run("change email", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/users/me/email", tokens.AccessToken, models.EmailChange{
+			NewEmail: "changed@example.com",
+			Password: "newpassword123",
+		})
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		token := tokenFromMail(t, lastMailTo(t, "test_mail", "changed@example.com"))
+		body, err := json.Marshal(models.EmailVerification{Token: token})
+		require.NoError(t, err)
+
+		resp, err = http.Post(server.URL+"/api/v1/auth/email/verify", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.Equal(t, "changed@example.com", user.Email)
+		assert.True(t, user.EmailVerified)
+
+		loginUser(t, server.URL, "changed@example.com", "newpassword123")
+	})
+}
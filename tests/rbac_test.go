@@ -0,0 +1,94 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestRoles(t *testing.T) {
+	server, userService := setupTestServer(t)
+	defer server.Close()
+
+	register := func(email string) models.User {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: "Role User"})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		return user
+	}
+
+	patient := register("patient@example.com")
+	admin := register("admin@example.com")
+	assert.Equal(t, []string{models.RolePatient}, patient.Roles)
+
+	patientTokens := loginUser(t, server.URL, "patient@example.com", "password123")
+	resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", patientTokens.AccessToken, models.DocumentCreation{
+		Title: "Blood test",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	t.Run("patients cannot use admin endpoints", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/admin/users/"+admin.ID, patientTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	_, err := userService.SetRoles(context.Background(), admin.ID, []string{models.RolePatient, models.RoleAdmin})
+	require.NoError(t, err)
+	adminTokens := loginUser(t, server.URL, "admin@example.com", "password123")
+
+	t.Run("admin assigns roles", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPut, server.URL+"/api/v1/admin/users/"+patient.ID+"/roles", adminTokens.AccessToken, models.RoleAssignment{
+			Roles: []string{models.RolePatient, "superuser"},
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPut, server.URL+"/api/v1/admin/users/"+patient.ID+"/roles", adminTokens.AccessToken, models.RoleAssignment{
+			Roles: []string{models.RolePatient, models.RoleClinician},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var user models.User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.ElementsMatch(t, []string{models.RoleClinician, models.RolePatient}, user.Roles)
+	})
+
+	t.Run("unknown users", func(t *testing.T) {
+		for _, id := range []string{"000000000000000000000000", "not-a-user-id"} {
+			resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/admin/users/"+id, adminTokens.AccessToken, nil)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, id)
+
+			resp = authorizedRequest(t, http.MethodPut, server.URL+"/api/v1/admin/users/"+id+"/roles", adminTokens.AccessToken, models.RoleAssignment{
+				Roles: []string{models.RolePatient},
+			})
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, id)
+		}
+	})
+
+	t.Run("admin cannot read other users' documents", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/documents/"+doc.ID, adminTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("admin can delete other users' documents", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/documents/"+doc.ID, adminTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
@@ -0,0 +1,115 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestSessions(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "test@example.com", Password: "password123", Name: "Test User"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	login := func(t *testing.T, userAgent string) models.TokenPair {
+		body, err := json.Marshal(models.UserLogin{Email: "test@example.com", Password: "password123"})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/auth/login", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var tokens models.TokenPair
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+		return tokens
+	}
+	mails := func(t *testing.T) int {
+		paths, err := filepath.Glob(filepath.Join("test_mail", "*-test@example.com.eml"))
+		require.NoError(t, err)
+		return len(paths)
+	}
+	sessionsURL := server.URL + "/api/v1/users/me/sessions"
+
+	// Registration already sent a verification email.
+	sent := mails(t)
+
+	laptop := login(t, "Laptop Browser")
+	assert.Equal(t, sent, mails(t), "the first device is not announced")
+
+	login(t, "Laptop Browser")
+	assert.Equal(t, sent, mails(t), "a known device is not announced")
+
+	phone := login(t, "Phone App")
+	assert.Equal(t, sent+1, mails(t))
+	assert.Contains(t, lastMailTo(t, "test_mail", "test@example.com"), "Phone App")
+
+	resp = authorizedRequest(t, http.MethodGet, sessionsURL, laptop.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var sessions []models.Session
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+	require.Len(t, sessions, 3)
+	assert.Equal(t, "Phone App", sessions[0].UserAgent)
+	assert.False(t, sessions[0].Current)
+
+	var current int
+	for _, session := range sessions {
+		assert.NotEmpty(t, session.IP)
+		if session.Current {
+			current++
+			assert.Equal(t, "Laptop Browser", session.UserAgent)
+		}
+	}
+	assert.Equal(t, 1, current)
+
+	t.Run("sign out another device", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodDelete, sessionsURL+"/"+sessions[0].ID, laptop.AccessToken, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/users/me", phone.AccessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		body, err := json.Marshal(models.RefreshToken{RefreshToken: phone.RefreshToken})
+		require.NoError(t, err)
+		resp, err = http.Post(server.URL+"/api/v1/auth/refresh", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodDelete, sessionsURL+"/"+sessions[0].ID, laptop.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, sessionsURL, laptop.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var remaining []models.Session
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&remaining))
+		assert.Len(t, remaining, 2)
+	})
+
+	t.Run("sessions of other users are not found", func(t *testing.T) {
+		body, err := json.Marshal(models.UserRegistration{Email: "other@example.com", Password: "password123", Name: "Other"})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		other := loginUser(t, server.URL, "other@example.com", "password123")
+
+		resp = authorizedRequest(t, http.MethodDelete, sessionsURL+"/"+sessions[1].ID, other.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -3,9 +3,22 @@
 package tests
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"testing"
 	"time"
 
@@ -13,21 +26,45 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/gruzdev-dev/meddoc/app/handlers"
+	"github.com/gruzdev-dev/meddoc/app/models"
 	"github.com/gruzdev-dev/meddoc/app/server/middleware"
+	"github.com/gruzdev-dev/meddoc/app/services/account"
+	"github.com/gruzdev-dev/meddoc/app/services/audit"
+	"github.com/gruzdev-dev/meddoc/app/services/delegation"
 	"github.com/gruzdev-dev/meddoc/app/services/document"
 	"github.com/gruzdev-dev/meddoc/app/services/file"
+	"github.com/gruzdev-dev/meddoc/app/services/grant"
+	"github.com/gruzdev-dev/meddoc/app/services/oidc"
+	"github.com/gruzdev-dev/meddoc/app/services/policy"
+	"github.com/gruzdev-dev/meddoc/app/services/profile"
+	"github.com/gruzdev-dev/meddoc/app/services/sharelink"
 	"github.com/gruzdev-dev/meddoc/app/services/user"
 	"github.com/gruzdev-dev/meddoc/config"
 	"github.com/gruzdev-dev/meddoc/database"
+	"github.com/gruzdev-dev/meddoc/database/migrations"
 	"github.com/gruzdev-dev/meddoc/database/repositories"
 	dbstorage "github.com/gruzdev-dev/meddoc/database/storage"
+	"github.com/gruzdev-dev/meddoc/pkg/keyring"
+	"github.com/gruzdev-dev/meddoc/pkg/mailer"
 	localstorage "github.com/gruzdev-dev/meddoc/pkg/storage"
 )
 
 func setupTestServer(t *testing.T) (*httptest.Server, *user.UserService) {
+	return setupTestServerWith(t, nil)
+}
+
+// setupTestServerWith lets configure adjust the test config before the
+// services are built. It gets the URL the server will listen on.
+func setupTestServerWith(t *testing.T, configure func(cfg *config.Config, serverURL string)) (*httptest.Server, *user.UserService) {
 	cfg, err := config.Load("test_config.yaml")
 	require.NoError(t, err)
 
+	server := httptest.NewUnstartedServer(nil)
+	t.Cleanup(server.Close)
+	if configure != nil {
+		configure(cfg, "http://"+server.Listener.Addr().String())
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	mongoCfg := database.MongoDBConfig{
@@ -54,9 +91,59 @@ func setupTestServer(t *testing.T) (*httptest.Server, *user.UserService) {
 	})
 
 	userRepo := repositories.NewUserRepository(mongoDB.Database().Collection("users"))
-	userService := user.NewUserServiceFromConfig(userRepo, cfg)
+	require.NoError(t, userRepo.EnsureIndexes(ctx))
+	sessionRepo := repositories.NewSessionRepository(mongoDB.Database().Collection("sessions"))
+	require.NoError(t, sessionRepo.EnsureIndexes(ctx))
+	revokedTokenRepo := repositories.NewRevokedTokenRepository(mongoDB.Database().Collection("revoked_tokens"))
+	require.NoError(t, revokedTokenRepo.EnsureIndexes(ctx))
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(mongoDB.Database().Collection("login_attempts"))
+	require.NoError(t, loginAttemptRepo.EnsureIndexes(ctx))
+	resetTokenRepo := repositories.NewPasswordResetRepository(mongoDB.Database().Collection("password_reset_tokens"))
+	require.NoError(t, resetTokenRepo.EnsureIndexes(ctx))
+	accessTokenRepo := repositories.NewAccessTokenRepository(mongoDB.Database().Collection("access_tokens"))
+	require.NoError(t, accessTokenRepo.EnsureIndexes(ctx))
+	oidcLoginRepo := repositories.NewOIDCLoginRepository(mongoDB.Database().Collection("oidc_logins"))
+	require.NoError(t, oidcLoginRepo.EnsureIndexes(ctx))
+	auditRepo := repositories.NewAuditRepository(mongoDB.Database().Collection("audit_events"))
+	require.NoError(t, auditRepo.EnsureIndexes(ctx))
+	auditCheckpointRepo := repositories.NewAuditCheckpointRepository(mongoDB.Database().Collection("audit_checkpoints"))
+	require.NoError(t, auditCheckpointRepo.EnsureIndexes(ctx))
+	deviceRepo := repositories.NewDeviceRepository(mongoDB.Database().Collection("devices"))
+	require.NoError(t, deviceRepo.EnsureIndexes(ctx))
+	keys, err := keyring.Load(writeTestSigningKey(t), "")
+	require.NoError(t, err)
+	auditService := audit.NewService(audit.Dependencies{
+		Events:      auditRepo,
+		Checkpoints: auditCheckpointRepo,
+		Keys:        keys,
+	}, audit.Config{
+		CheckpointInterval: cfg.Audit.CheckpointInterval,
+	})
+	require.NoError(t, os.RemoveAll(cfg.Mail.Dir))
+	t.Cleanup(func() {
+		_ = os.RemoveAll(cfg.Mail.Dir)
+	})
+	mail, err := mailer.NewFile(cfg.Mail.Dir, cfg.Mail.From)
+	require.NoError(t, err)
+	userService, err := user.NewUserServiceFromConfig(user.Dependencies{
+		Users:         userRepo,
+		Sessions:      sessionRepo,
+		RevokedTokens: revokedTokenRepo,
+		ResetTokens:   resetTokenRepo,
+		LoginAttempts: loginAttemptRepo,
+		AccessTokens:  accessTokenRepo,
+		Devices:       deviceRepo,
+		Mailer:        mail,
+		LoginNotifier: user.NewMailLoginNotifier(mail),
+		Audit:         auditService,
+	}, keys, cfg)
+	require.NoError(t, err)
 
+	grantRepo := repositories.NewGrantRepository(mongoDB.Database().Collection("grants"), mongoDB.Database().Collection("documents"))
+	require.NoError(t, grantRepo.EnsureIndexes(ctx))
+	accessPolicy := policy.New(policy.DefaultPermissions, grantRepo)
 	fileRepo := repositories.NewFileRepository(mongoDB.Database().Collection("files"))
+	require.NoError(t, fileRepo.EnsureIndexes(ctx))
 
 	localStorage, err := localstorage.NewLocal(testStorageDir)
 	require.NoError(t, err)
@@ -65,12 +152,89 @@ func setupTestServer(t *testing.T) (*httptest.Server, *user.UserService) {
 	require.NoError(t, err)
 
 	documentRepo := repositories.NewDocumentRepository(mongoDB.Database().Collection("documents"))
-	documentService := document.NewService(documentRepo)
-	fileService := file.NewService(fileRepo, localStorage, gridStorage)
+	require.NoError(t, documentRepo.EnsureIndexes(ctx))
+	profileRepo := repositories.NewProfileRepository(mongoDB.Database().Collection("profiles"))
+	require.NoError(t, profileRepo.EnsureIndexes(ctx))
+	require.NoError(t, migrations.Run(ctx, mongoDB.Database(), migrations.All))
+	profileService := profile.NewService(profile.Dependencies{
+		Profiles:  profileRepo,
+		Documents: documentRepo,
+		Files:     fileRepo,
+		Users:     userService,
+	})
+	breakGlassRepo := repositories.NewBreakGlassRepository(mongoDB.Database().Collection("break_glass"))
+	require.NoError(t, breakGlassRepo.EnsureIndexes(ctx))
+	fileService := file.NewService(fileRepo, localStorage, gridStorage, profileService, accessPolicy, auditService, breakGlassRepo)
+	commentRepo := repositories.NewCommentRepository(mongoDB.Database().Collection("comments"))
+	require.NoError(t, commentRepo.EnsureIndexes(ctx))
+	shareLinkRepo := repositories.NewShareLinkRepository(mongoDB.Database().Collection("share_links"))
+	require.NoError(t, shareLinkRepo.EnsureIndexes(ctx))
+	documentService := document.NewService(document.Dependencies{
+		Documents:  documentRepo,
+		Comments:   commentRepo,
+		Grants:     grantRepo,
+		ShareLinks: shareLinkRepo,
+		Files:      fileService,
+		Profiles:   profileService,
+		Policy:     accessPolicy,
+		Audit:      auditService,
+		BreakGlass: breakGlassRepo,
+		Users:      userService,
+		Mailer:     mail,
+	}, document.Config{
+		BreakGlassTTL: cfg.Sharing.BreakGlass.TTL,
+	})
+	grantService := grant.NewService(grantRepo, documentRepo, userRepo, accessPolicy)
+	shareLinkService, err := sharelink.NewServiceFromConfig(sharelink.Dependencies{
+		Links:     shareLinkRepo,
+		Documents: documentRepo,
+		Files:     fileService,
+		Policy:    accessPolicy,
+	}, cfg)
+	require.NoError(t, err)
+
+	delegationRepo := repositories.NewDelegationRepository(mongoDB.Database().Collection("delegations"))
+	require.NoError(t, delegationRepo.EnsureIndexes(ctx))
+	delegationService := delegation.NewServiceFromConfig(delegation.Dependencies{
+		Delegations: delegationRepo,
+		Users:       userService,
+		Mailer:      mail,
+	}, cfg)
 
-	handlers := handlers.NewHandlers(userService, documentService, fileService)
+	deletionRepo := repositories.NewAccountDeletionRepository(mongoDB.Database().Collection("account_deletions"))
+	require.NoError(t, deletionRepo.EnsureIndexes(ctx))
+	accountService := account.NewService(account.Dependencies{
+		Deletions:   deletionRepo,
+		Users:       userService,
+		Documents:   documentService,
+		Grants:      grantService,
+		Files:       fileService,
+		Profiles:    profileService,
+		Delegations: delegationService,
+		ShareLinks:  shareLinkService,
+		Mailer:      mail,
+	}, account.Config{
+		GracePeriod:     cfg.Account.Deletion.GracePeriod,
+		PollInterval:    cfg.Account.Deletion.PollInterval,
+		Lease:           cfg.Account.Deletion.Lease,
+		ConfirmationURL: cfg.Account.Deletion.URL,
+		ConfirmationTTL: cfg.Account.Deletion.ConfirmationTTL,
+	})
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	t.Cleanup(stopWorkers)
+	go accountService.Run(workerCtx)
+	go auditService.Run(workerCtx)
+
+	oidcService := oidc.NewServiceFromConfig(oidc.Dependencies{
+		Logins: oidcLoginRepo,
+		Users:  userService,
+	}, cfg)
+
+	handlers := handlers.NewHandlers(userService, documentService, fileService, accountService, grantService, shareLinkService, oidcService, profileService, delegationService, auditService)
 	router := mux.NewRouter()
 	router.Use(middleware.RequestID())
+	router.Use(middleware.ClientIP(cfg.Server.TrustProxyHeaders, cfg.Server.TrustedProxies))
+	router.Use(middleware.AuditOrigin())
 	router.Use(middleware.Logging())
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Compression())
@@ -78,5 +242,101 @@ func setupTestServer(t *testing.T) (*httptest.Server, *user.UserService) {
 	api := router.PathPrefix("/api/v1").Subrouter()
 	handlers.RegisterRoutes(api)
 
-	return httptest.NewServer(router), userService
+	server.Config.Handler = router
+	server.Start()
+	return server, userService
+}
+
+func writeTestSigningKey(t *testing.T) string {
+	dir := t.TempDir()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test-key.pem"), data, 0600))
+
+	return dir
+}
+
+func loginUser(t *testing.T, baseURL, email, password string) models.TokenPair {
+	body, err := json.Marshal(models.UserLogin{Email: email, Password: password})
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/api/v1/auth/login", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var tokens models.TokenPair
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	return tokens
+}
+
+func authorizedRequest(t *testing.T, method, url, accessToken string, body any) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func uploadFile(t *testing.T, baseURL, accessToken, name string, content []byte) models.FileResponse {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+	h.Set("Content-Type", "image/jpeg")
+	part, err := writer.CreatePart(h)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/api/v1/files/upload", body)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var file models.FileResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&file))
+	return file
+}
+
+// lastMailTo returns the body of the newest message the file mailer wrote
+// for the recipient.
+func lastMailTo(t *testing.T, dir, recipient string) string {
+	paths, err := filepath.Glob(filepath.Join(dir, "*-"+recipient+".eml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths, "no mail sent to %s", recipient)
+	sort.Strings(paths)
+
+	data, err := os.ReadFile(paths[len(paths)-1])
+	require.NoError(t, err)
+	return string(data)
+}
+
+// tokenFromMail extracts the token query parameter of the link in a mail.
+func tokenFromMail(t *testing.T, body string) string {
+	match := regexp.MustCompile(`token=([A-Za-z0-9_\-.]+)`).FindStringSubmatch(body)
+	require.Len(t, match, 2, "no token in mail")
+	return match[1]
 }
@@ -0,0 +1,146 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestShareLinks(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	body, err := json.Marshal(models.UserRegistration{Email: "linkowner@example.com", Password: "password123", Name: "Link Owner"})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tokens := loginUser(t, server.URL, "linkowner@example.com", "password123")
+	file := uploadFile(t, server.URL, tokens.AccessToken, "xray.jpg", jpegContent)
+
+	var docIDs []string
+	for _, doc := range []models.DocumentCreation{{Title: "X-ray", File: file.ID}, {Title: "Referral"}} {
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", tokens.AccessToken, doc)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var created models.Document
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		docIDs = append(docIDs, created.ID)
+	}
+
+	createLink := func(data models.ShareLinkCreation) models.ShareLink {
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/share-links", tokens.AccessToken, data)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var link models.ShareLink
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+		require.NotEmpty(t, link.Token)
+		return link
+	}
+
+	open := func(path, pin string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/share/"+path, nil)
+		require.NoError(t, err)
+		if pin != "" {
+			req.Header.Set("X-Share-PIN", pin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("bundle with file and view limit", func(t *testing.T) {
+		link := createLink(models.ShareLinkCreation{
+			DocumentIDs: docIDs,
+			ExpiresAt:   time.Now().Add(time.Hour),
+			MaxUses:     1,
+		})
+
+		resp := open(link.Token, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var bundle models.SharedBundle
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&bundle))
+		assert.Len(t, bundle.Documents, 2)
+
+		resp = open(link.Token+"/files/"+file.ID, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, jpegContent, data)
+
+		// One use covers one visit and one download of each file.
+		assert.Equal(t, http.StatusGone, open(link.Token, "").StatusCode)
+		assert.Equal(t, http.StatusGone, open(link.Token+"/files/"+file.ID, "").StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/share-links", tokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var links []models.ShareLink
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&links))
+		require.Len(t, links, 1)
+		assert.Equal(t, 1, links[0].Views)
+		assert.Equal(t, 1, links[0].Downloads)
+	})
+
+	t.Run("forged token", func(t *testing.T) {
+		link := createLink(models.ShareLinkCreation{DocumentIDs: docIDs[:1], ExpiresAt: time.Now().Add(time.Hour)})
+		assert.Equal(t, http.StatusNotFound, open(link.ID+".forged", "").StatusCode)
+	})
+
+	t.Run("PIN", func(t *testing.T) {
+		link := createLink(models.ShareLinkCreation{
+			DocumentIDs: docIDs[:1],
+			ExpiresAt:   time.Now().Add(time.Hour),
+			PIN:         "2468",
+		})
+		assert.True(t, link.PINProtected)
+
+		assert.Equal(t, http.StatusUnauthorized, open(link.Token, "").StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, open(link.Token, "1111").StatusCode)
+		assert.Equal(t, http.StatusOK, open(link.Token, "2468").StatusCode)
+
+		assert.Equal(t, http.StatusUnauthorized, open(link.Token, "2222").StatusCode)
+		assert.Equal(t, http.StatusGone, open(link.Token, "3333").StatusCode)
+		assert.Equal(t, http.StatusGone, open(link.Token, "2468").StatusCode)
+	})
+
+	t.Run("revoked link", func(t *testing.T) {
+		link := createLink(models.ShareLinkCreation{DocumentIDs: docIDs[:1], ExpiresAt: time.Now().Add(time.Hour)})
+
+		resp := authorizedRequest(t, http.MethodDelete, server.URL+"/api/v1/share-links/"+link.ID, tokens.AccessToken, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		assert.Equal(t, http.StatusGone, open(link.Token, "").StatusCode)
+		assert.Equal(t, http.StatusGone, open(link.Token+"/files/"+file.ID, "").StatusCode)
+	})
+
+	t.Run("file not in the bundle", func(t *testing.T) {
+		link := createLink(models.ShareLinkCreation{DocumentIDs: docIDs[1:], ExpiresAt: time.Now().Add(time.Hour)})
+		assert.Equal(t, http.StatusNotFound, open(link.Token+"/files/"+file.ID, "").StatusCode)
+	})
+
+	t.Run("invalid links", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/share-links", tokens.AccessToken, models.ShareLinkCreation{
+			DocumentIDs: docIDs,
+			ExpiresAt:   time.Now().Add(-time.Minute),
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/share-links", tokens.AccessToken, models.ShareLinkCreation{
+			DocumentIDs: []string{"507f1f77bcf86cd799439011"},
+			ExpiresAt:   time.Now().Add(time.Hour),
+		})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,192 @@
+//go:build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruzdev-dev/meddoc/app/models"
+)
+
+func TestDocumentSharing(t *testing.T) {
+	server, _ := setupTestServer(t)
+	defer server.Close()
+
+	for _, email := range []string{"owner@example.com", "specialist@example.com"} {
+		body, err := json.Marshal(models.UserRegistration{Email: email, Password: "password123", Name: "Sharing User"})
+		require.NoError(t, err)
+
+		resp, err := http.Post(server.URL+"/api/v1/auth/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	ownerTokens := loginUser(t, server.URL, "owner@example.com", "password123")
+	specialistTokens := loginUser(t, server.URL, "specialist@example.com", "password123")
+
+	file := uploadFile(t, server.URL, ownerTokens.AccessToken, "scan.jpg", jpegContent)
+	resp := authorizedRequest(t, http.MethodPost, server.URL+"/api/v1/documents", ownerTokens.AccessToken, models.DocumentCreation{
+		Title: "MRI",
+		File:  file.ID + ".jpg",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var doc models.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	documentURL := server.URL + "/api/v1/documents/" + doc.ID
+	grantsURL := documentURL + "/grants"
+	commentsURL := documentURL + "/comments"
+	fileURL := server.URL + "/api/v1/files/" + file.ID
+
+	share := func(permission string, expiresAt *time.Time) models.Grant {
+		resp := authorizedRequest(t, http.MethodPost, grantsURL, ownerTokens.AccessToken, models.GrantCreation{
+			Email:      "specialist@example.com",
+			Permission: permission,
+			ExpiresAt:  expiresAt,
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var grant models.Grant
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&grant))
+		return grant
+	}
+
+	t.Run("not shared yet", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, documentURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, fileURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("invalid grants", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodPost, grantsURL, ownerTokens.AccessToken, models.GrantCreation{
+			Email:      "nobody@example.com",
+			Permission: models.GrantRead,
+		})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, grantsURL, ownerTokens.AccessToken, models.GrantCreation{
+			Email:      "owner@example.com",
+			Permission: models.GrantRead,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, grantsURL, ownerTokens.AccessToken, models.GrantCreation{
+			Email:      "specialist@example.com",
+			Permission: "owner",
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	grant := share(models.GrantRead, nil)
+
+	t.Run("read grant", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, documentURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, fileURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPatch, documentURL, specialistTokens.AccessToken, models.DocumentUpdate{
+			Title: stringPtr("Changed"),
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodDelete, documentURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, grantsURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodPost, commentsURL, specialistTokens.AccessToken, models.CommentCreation{Text: "Looks fine"})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("shared with me", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodGet, server.URL+"/api/v1/shared/documents", specialistTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var shared []models.SharedDocument
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&shared))
+		require.Len(t, shared, 1)
+		assert.Equal(t, doc.ID, shared[0].Document.ID)
+		assert.Equal(t, models.GrantRead, shared[0].Permission)
+	})
+
+	t.Run("comment grant", func(t *testing.T) {
+		share(models.GrantComment, nil)
+
+		resp := authorizedRequest(t, http.MethodPost, commentsURL, specialistTokens.AccessToken, models.CommentCreation{Text: "Repeat in 3 months"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var comment models.Comment
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&comment))
+		assert.Equal(t, doc.ID, comment.DocumentID)
+
+		resp = authorizedRequest(t, http.MethodPatch, documentURL, specialistTokens.AccessToken, models.DocumentUpdate{
+			Title: stringPtr("Changed"),
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, commentsURL, ownerTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var comments []models.Comment
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&comments))
+		require.Len(t, comments, 1)
+		assert.Equal(t, comment.ID, comments[0].ID)
+		assert.Equal(t, "Repeat in 3 months", comments[0].Text)
+	})
+
+	t.Run("edit grant replaces the read grant", func(t *testing.T) {
+		edit := share(models.GrantEdit, nil)
+		assert.Equal(t, grant.ID, edit.ID)
+
+		resp := authorizedRequest(t, http.MethodPatch, documentURL, specialistTokens.AccessToken, models.DocumentUpdate{
+			Title: stringPtr("MRI, annotated"),
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, grantsURL, ownerTokens.AccessToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var grants []models.Grant
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&grants))
+		require.Len(t, grants, 1)
+		assert.Equal(t, models.GrantEdit, grants[0].Permission)
+	})
+
+	t.Run("revoked grant", func(t *testing.T) {
+		resp := authorizedRequest(t, http.MethodDelete, grantsURL+"/"+grant.ID, ownerTokens.AccessToken, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, documentURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodDelete, grantsURL+"/"+grant.ID, ownerTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("expired grant", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Second)
+		share(models.GrantRead, &expiresAt)
+
+		resp := authorizedRequest(t, http.MethodGet, documentURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		time.Sleep(time.Until(expiresAt) + 100*time.Millisecond)
+
+		resp = authorizedRequest(t, http.MethodGet, documentURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = authorizedRequest(t, http.MethodGet, fileURL, specialistTokens.AccessToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}